@@ -0,0 +1,163 @@
+package anilist
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmcvetta/napping"
+	logging "github.com/op/go-logging"
+
+	"github.com/projectx13/projectx/cache"
+	"github.com/projectx13/projectx/util"
+)
+
+const (
+	// APIURL is AniList GraphQL endpoint
+	APIURL = "https://graphql.anilist.co"
+
+	// AniList allows 90 requests per minute
+	burstRate               = 90
+	burstTime               = time.Minute
+	simultaneousConnections = 5
+
+	cacheExpiration         = 7 * 24 * time.Hour
+	cacheNotFoundExpiration = 24 * time.Hour
+)
+
+var (
+	log = logging.MustGetLogger("anilist")
+
+	rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections).WithPool(util.MetadataPool)
+)
+
+const mediaQuery = `query ($search: String, $year: Int) {
+  Media(search: $search, seasonYear: $year, type: ANIME, sort: SEARCH_MATCH) {
+    id
+    idMal
+    title { romaji english native }
+    synonyms
+    episodes
+    seasonYear
+  }
+}`
+
+// Title is a title of the anime in AniList
+type Title struct {
+	Romaji  string `json:"romaji"`
+	English string `json:"english"`
+	Native  string `json:"native"`
+}
+
+// Media is an anime in AniList. Every season, or cour, of the show is a separate media.
+type Media struct {
+	ID         int      `json:"id"`
+	IDMal      int      `json:"idMal"`
+	Title      *Title   `json:"title"`
+	Synonyms   []string `json:"synonyms"`
+	Episodes   int      `json:"episodes"`
+	SeasonYear int      `json:"seasonYear"`
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type mediaResponse struct {
+	Data struct {
+		Media *Media `json:"Media"`
+	} `json:"data"`
+}
+
+// Post makes GraphQL request to AniList
+func Post(query string, variables map[string]interface{}, result interface{}) (err error) {
+	header := http.Header{
+		"Content-type": []string{"application/json"},
+		"Accept":       []string{"application/json"},
+	}
+	req := napping.Request{
+		Url:     APIURL,
+		Method:  "POST",
+		Payload: &graphQLRequest{Query: query, Variables: variables},
+		Result:  result,
+		Header:  &header,
+	}
+
+	rl.Call(func() error {
+		resp, errSend := napping.Send(&req)
+		if errSend != nil {
+			err = errSend
+			return nil
+		} else if resp.Status() == 429 {
+			log.Warningf("Rate limit exceeded on %s, cooling down...", APIURL)
+			rl.CoolDown(resp.HttpResponse().Header)
+			err = util.ErrExceeded
+			return util.ErrExceeded
+		} else if resp.Status() == 404 {
+			err = util.ErrNotFound
+		} else if resp.Status() != 200 {
+			err = util.ErrHTTP
+		}
+		return nil
+	})
+	return
+}
+
+// FindShow returns anime by its title, first aired in the year, or nil.
+// Year is used to tell apart remakes and sequels, which share the title.
+func FindShow(title string, year int) *Media {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil
+	}
+
+	var media *Media
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.anilist.show.%s.%d", strings.ToLower(title), year)
+	if err := cacheStore.Get(key, &media); err == nil {
+		if media == nil || media.ID == 0 {
+			return nil
+		}
+		return media
+	}
+
+	media = findShow(title, year)
+	if media == nil && year > 0 {
+		// AniList year is the year of the first cour, which could differ from TMDB air date
+		media = findShow(title, 0)
+	}
+	if media == nil {
+		cacheStore.Set(key, &Media{}, cacheNotFoundExpiration)
+		return nil
+	}
+
+	log.Debugf("Found AniList anime %d for %s (%d)", media.ID, title, year)
+	cacheStore.Set(key, media, cacheExpiration)
+	return media
+}
+
+func findShow(title string, year int) *Media {
+	variables := map[string]interface{}{"search": title}
+	if year > 0 {
+		variables["year"] = year
+	}
+
+	var resp mediaResponse
+	if err := Post(mediaQuery, variables, &resp); err != nil {
+		if err != util.ErrNotFound {
+			log.Warningf("Could not find anime %s (%d): %s", title, year, err)
+		}
+		return nil
+	}
+	return resp.Data.Media
+}
+
+// Titles returns romaji and English titles of the anime, which releases are named by
+func (m *Media) Titles() (romaji string, english string) {
+	if m == nil || m.Title == nil {
+		return
+	}
+	return m.Title.Romaji, m.Title.English
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/projectx13/projectx/bittorrent"
+)
+
+// Colors of bandwidth hints, from the palette of resolutions
+const (
+	bandwidthColorOK   = "FF539A02"
+	bandwidthColorNear = "FFA56F01"
+	bandwidthColorSlow = "FFFC3401"
+)
+
+// bandwidthHint returns bitrate, which is needed to stream the torrent, estimated by its size
+// and duration of the video in seconds. It is colored by connection speed: green, when it is
+// enough with a margin, orange, when it is close to the limit, and red, when it is not enough.
+func bandwidthHint(torrent *bittorrent.TorrentFile, duration int, speed int64) string {
+	if duration <= 0 {
+		return ""
+	}
+
+	size := torrent.SizeParsed
+	if size == 0 && torrent.Size != "" {
+		size, _ = humanize.ParseBytes(torrent.Size)
+	}
+	if size == 0 {
+		return ""
+	}
+
+	// Bytes per second
+	required := int64(size) / int64(duration)
+	label := fmt.Sprintf("~%.1f Mbit/s", float64(required)*8/1000/1000)
+	if speed <= 0 {
+		return label
+	}
+
+	color := bandwidthColorOK
+	if required > speed {
+		color = bandwidthColorSlow
+	} else if required > speed*3/4 {
+		color = bandwidthColorNear
+	}
+	return fmt.Sprintf("[COLOR %s]%s[/COLOR]", color, label)
+}
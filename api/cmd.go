@@ -1,9 +1,14 @@
 package api
 
 import (
+	"fmt"
+	"io"
+
+	"github.com/dustin/go-humanize"
 	"github.com/gin-gonic/gin"
 	"github.com/op/go-logging"
 
+	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/library"
@@ -36,6 +41,21 @@ func ClearCache(ctx *gin.Context) {
 	xbmc.Notify("projectx", "LOCALIZE[30200]", config.AddonIcon())
 }
 
+// InvalidateCache removes cached entries with selected key prefix
+func InvalidateCache(ctx *gin.Context) {
+	prefix := ctx.Query("prefix")
+	removed, err := cache.InvalidateNamespace(prefix)
+	if err != nil {
+		ctx.AbortWithError(400, err)
+		return
+	}
+
+	// Rendered lists could contain invalidated items
+	library.ClearPageCache()
+
+	ctx.JSON(200, gin.H{"prefix": prefix, "removed": removed})
+}
+
 // ClearCacheTMDB ...
 func ClearCacheTMDB(ctx *gin.Context) {
 	log.Debug("Removing TMDB cache")
@@ -199,3 +219,100 @@ func ClearDatabase(ctx *gin.Context) {
 	ctx.String(200, "")
 	return
 }
+
+// CompactDatabase rewrites cache database to reclaim unused space
+func CompactDatabase(ctx *gin.Context) {
+	log.Debug("Compacting cache database")
+
+	reclaimed, err := database.GetCache().Compact()
+	if err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	if reclaimed < 0 {
+		reclaimed = 0
+	}
+	xbmc.Notify("projectx", fmt.Sprintf("LOCALIZE[30706];;%s", humanize.Bytes(uint64(reclaimed))), config.AddonIcon())
+
+	ctx.String(200, "")
+}
+
+func exportTarget(ctx *gin.Context) (string, func(io.Writer) error, func(io.Reader) (int, error)) {
+	if ctx.DefaultQuery("db", "library") == "cache" {
+		db := database.GetCache()
+		return db.GetFilename(), db.Export, db.Import
+	}
+
+	db := database.GetStorm()
+	return db.GetFilename(), db.Export, db.Import
+}
+
+// ExportDatabase streams database contents as newline-delimited JSON,
+// library database is exported by default, use ?db=cache for cache database
+func ExportDatabase(ctx *gin.Context) {
+	fileName, export, _ := exportTarget(ctx)
+	log.Infof("Exporting database %s", fileName)
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.jsonl"`, fileName))
+	ctx.Status(200)
+
+	if err := export(ctx.Writer); err != nil {
+		log.Warningf("Could not export database %s: %s", fileName, err)
+	}
+}
+
+// ImportDatabase reads newline-delimited JSON, created by ExportDatabase, from request body
+func ImportDatabase(ctx *gin.Context) {
+	fileName, _, importer := exportTarget(ctx)
+	log.Infof("Importing database %s", fileName)
+
+	count, err := importer(ctx.Request.Body)
+	if err != nil {
+		log.Warningf("Could not import database %s: %s", fileName, err)
+		ctx.JSON(400, gin.H{"imported": count, "error": err.Error()})
+		return
+	}
+
+	log.Infof("Imported %d items into database %s", count, fileName)
+	ctx.JSON(200, gin.H{"imported": count})
+}
+
+// RestoreDatabase selects database backup generation to be restored on the next start,
+// library database is used by default, use ?db=cache for cache database
+func RestoreDatabase(ctx *gin.Context) {
+	databasePath, backupPath := database.GetStorm().GetPath(), database.GetStorm().GetBackupPath()
+	if ctx.DefaultQuery("db", "library") == "cache" {
+		databasePath, backupPath = database.GetCache().GetPath(), database.GetCache().GetBackupPath()
+	}
+
+	generations := database.BackupGenerations(backupPath)
+	if len(generations) == 0 {
+		xbmc.Notify("projectx", "LOCALIZE[30712]", config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	items := make([]string, 0, len(generations))
+	for _, g := range generations {
+		items = append(items, database.BackupTime(g).Format("2006-01-02 15:04:05"))
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30710]", items...)
+	if choice < 0 || choice >= len(generations) {
+		ctx.String(200, "")
+		return
+	}
+
+	log.Infof("Scheduling restore of %s from %s", databasePath, generations[choice])
+	if err := database.ScheduleRestore(databasePath, generations[choice]); err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	xbmc.Notify("projectx", "LOCALIZE[30711]", config.AddonIcon())
+	ctx.String(200, "")
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// discoverSortLabels are labels of tmdb.DiscoverSortTypes
+var discoverSortLabels = []string{
+	"LOCALIZE[30754]",
+	"LOCALIZE[30755]",
+	"LOCALIZE[30756]",
+	"LOCALIZE[30757]",
+	"LOCALIZE[30758]",
+	"LOCALIZE[30759]",
+	"LOCALIZE[30760]",
+}
+
+// DiscoverMovies lists movies, matching filters from URL query
+func DiscoverMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	q := tmdb.ParseDiscoverQuery(ctx.Request.URL.Query())
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.DiscoverMovies(q, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// DiscoverMoviesBuild shows dialogs to choose discover filters,
+// and then opens the list of movies with these filters
+func DiscoverMoviesBuild(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	q := tmdb.ParseDiscoverQuery(ctx.Request.URL.Query())
+	for {
+		items := []string{
+			"LOCALIZE[30741];;" + discoverRangeLabel(q.YearFrom, q.YearTo),
+			"LOCALIZE[30742];;" + discoverGenresLabel(q),
+			"LOCALIZE[30743];;" + discoverValueLabel(strconv.FormatFloat(q.VoteAverage, 'f', -1, 64), q.VoteAverage > 0),
+			"LOCALIZE[30744];;" + discoverRangeLabel(q.RuntimeFrom, q.RuntimeTo),
+			"LOCALIZE[30745];;" + discoverValueLabel(q.Certification, q.Certification != ""),
+			"LOCALIZE[30746];;" + discoverValueLabel(q.OriginalLanguage, q.OriginalLanguage != ""),
+			"LOCALIZE[30747];;" + discoverValueLabel(q.WatchRegion, q.Providers != ""),
+			"LOCALIZE[30748];;" + discoverSortLabel(q.SortBy),
+			"LOCALIZE[30749]",
+		}
+
+		choice := xbmc.ListDialog("LOCALIZE[30740]", items...)
+		switch choice {
+		case 0:
+			q.YearFrom, q.YearTo = discoverRangeKeyboard("LOCALIZE[30751]", q.YearFrom, q.YearTo)
+		case 1:
+			discoverGenresDialog(q)
+		case 2:
+			ratings := []float64{0, 5, 6, 6.5, 7, 7.5, 8, 8.5}
+			labels := make([]string, 0, len(ratings))
+			for _, r := range ratings {
+				labels = append(labels, discoverValueLabel(strconv.FormatFloat(r, 'f', -1, 64), r > 0))
+			}
+			if c := xbmc.ListDialog("LOCALIZE[30743]", labels...); c >= 0 && c < len(ratings) {
+				q.VoteAverage = ratings[c]
+			}
+		case 3:
+			q.RuntimeFrom, q.RuntimeTo = discoverRangeKeyboard("LOCALIZE[30752]", q.RuntimeFrom, q.RuntimeTo)
+		case 4:
+			certifications := tmdb.GetMovieCertifications(tmdb.CertificationCountry())
+			labels := []string{"LOCALIZE[30750]"}
+			for _, c := range certifications {
+				labels = append(labels, c.Certification)
+			}
+			if c := xbmc.ListDialog("LOCALIZE[30745]", labels...); c == 0 {
+				q.Certification = ""
+			} else if c > 0 && c < len(labels) {
+				q.Certification = certifications[c-1].Certification
+			}
+		case 5:
+			languages := tmdb.GetLanguages(config.Get().Language)
+			labels := []string{"LOCALIZE[30750]"}
+			for _, l := range languages {
+				labels = append(labels, l.Name)
+			}
+			if c := xbmc.ListDialog("LOCALIZE[30746]", labels...); c == 0 {
+				q.OriginalLanguage = ""
+			} else if c > 0 && c < len(labels) {
+				q.OriginalLanguage = languages[c-1].Iso639_1
+			}
+		case 6:
+			// Toggles services, selected in settings
+			if q.Providers != "" || config.Get().WatchProviders == "" {
+				q.Providers = ""
+				q.WatchRegion = ""
+			} else {
+				q.Providers = config.Get().WatchProviders
+				q.WatchRegion = config.Get().WatchRegion
+			}
+		case 7:
+			if c := xbmc.ListDialog("LOCALIZE[30748]", discoverSortLabels...); c >= 0 && c < len(tmdb.DiscoverSortTypes) {
+				q.SortBy = tmdb.DiscoverSortTypes[c]
+			}
+		case 8:
+			go xbmc.UpdatePath(URLForXBMC("/movies/discover?%s", q.Values().Encode()))
+			ctx.String(200, "")
+			return
+		default:
+			ctx.String(200, "")
+			return
+		}
+	}
+}
+
+// discoverGenresDialog toggles genres one by one, until dialog is closed
+func discoverGenresDialog(q *tmdb.DiscoverQuery) {
+	genres := tmdb.GetMovieGenres(config.Get().Language)
+	for {
+		labels := []string{discoverMatchAllLabel(q.GenresAll)}
+		for _, g := range genres {
+			label := g.Name
+			if discoverHasGenre(q, g.ID) {
+				label = "[B]" + label + "[/B]"
+			}
+			labels = append(labels, label)
+		}
+
+		c := xbmc.ListDialog("LOCALIZE[30742]", labels...)
+		if c < 0 || c >= len(labels) {
+			return
+		} else if c == 0 {
+			q.GenresAll = !q.GenresAll
+			continue
+		}
+
+		id := strconv.Itoa(genres[c-1].ID)
+		if discoverHasGenre(q, genres[c-1].ID) {
+			selected := make([]string, 0, len(q.Genres))
+			for _, g := range q.Genres {
+				if g != id {
+					selected = append(selected, g)
+				}
+			}
+			q.Genres = selected
+		} else {
+			q.Genres = append(q.Genres, id)
+		}
+	}
+}
+
+func discoverHasGenre(q *tmdb.DiscoverQuery, id int) bool {
+	for _, g := range q.Genres {
+		if g == strconv.Itoa(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func discoverGenresLabel(q *tmdb.DiscoverQuery) string {
+	if len(q.Genres) == 0 {
+		return "LOCALIZE[30750]"
+	}
+
+	names := make([]string, 0, len(q.Genres))
+	for _, g := range tmdb.GetMovieGenres(config.Get().Language) {
+		if discoverHasGenre(q, g.ID) {
+			names = append(names, g.Name)
+		}
+	}
+
+	separator := " | "
+	if q.GenresAll {
+		separator = " + "
+	}
+	return strings.Join(names, separator)
+}
+
+func discoverMatchAllLabel(all bool) string {
+	if all {
+		return "LOCALIZE[30753];;LOCALIZE[30761]"
+	}
+	return "LOCALIZE[30753];;LOCALIZE[30762]"
+}
+
+func discoverSortLabel(sortBy string) string {
+	for i, s := range tmdb.DiscoverSortTypes {
+		if s == sortBy {
+			return discoverSortLabels[i]
+		}
+	}
+	return discoverSortLabels[0]
+}
+
+func discoverValueLabel(value string, isSet bool) string {
+	if !isSet {
+		return "LOCALIZE[30750]"
+	}
+	return value
+}
+
+func discoverRangeLabel(from, to int) string {
+	if from <= 0 && to <= 0 {
+		return "LOCALIZE[30750]"
+	} else if to <= 0 {
+		return fmt.Sprintf("%d-", from)
+	} else if from <= 0 {
+		return fmt.Sprintf("-%d", to)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+// discoverRangeKeyboard asks for range, like "1990-1999", "2010-" or "-120",
+// empty input clears the range
+func discoverRangeKeyboard(title string, from, to int) (int, int) {
+	current := ""
+	if from > 0 || to > 0 {
+		current = discoverRangeLabel(from, to)
+	}
+
+	input := strings.TrimSpace(xbmc.Keyboard(current, title))
+	if input == "" {
+		return 0, 0
+	}
+
+	parts := strings.SplitN(input, "-", 2)
+	from, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+	to = from
+	if len(parts) > 1 {
+		to, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+	if from > 0 && to > 0 && from > to {
+		from, to = to, from
+	}
+	return from, to
+}
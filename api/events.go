@@ -0,0 +1,59 @@
+package api
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/projectx13/projectx/events"
+)
+
+// eventsWriteTimeout closes connections of clients, which do not read events
+const eventsWriteTimeout = 10 * time.Second
+
+// eventsServer accepts connections without Origin too, since clients are not only browsers
+var eventsServer = websocket.Server{
+	Handshake: func(*websocket.Config, *http.Request) error { return nil },
+	Handler:   serveEvents,
+}
+
+// Events streams JSON events of torrents, playback and library over WebSocket
+func Events(ctx *gin.Context) {
+	eventsServer.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+func serveEvents(ws *websocket.Conn) {
+	defer ws.Close()
+
+	received, stop := events.Listen()
+	defer stop()
+
+	log.Infof("Events client connected from %s", ws.Request().RemoteAddr)
+	defer log.Infof("Events client from %s disconnected", ws.Request().RemoteAddr)
+
+	// Messages of clients are not expected, reading only detects closed connection
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(ioutil.Discard, ws)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case ev, ok := <-received:
+			if !ok {
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+			if err := websocket.JSON.Send(ws, ev); err != nil {
+				return
+			}
+		}
+	}
+}
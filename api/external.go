@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// Inter-addon API lets other Kodi addons use projectx as a player backend.
+//
+// Plugin interface, for use with xbmc.Player().play() or setResolvedUrl():
+//
+//	plugin://<addon id>/external/v1/movie/<tmdb id>
+//	plugin://<addon id>/external/v1/episode/<tmdb show id>/<season>/<episode>
+//	plugin://<addon id>/external/v1/uri?uri=<magnet, .torrent url or path>
+//
+// JSON interface, served at http://localhost:<port>/external/v1/play:
+//
+//	POST {"type": "movie", "tmdb_id": 603}
+//	POST {"type": "episode", "tmdb_id": 1399, "season": 1, "episode": 1}
+//	POST {"type": "uri", "uri": "magnet:?xt=..."}
+//
+// Optional "wait" field, in seconds, delays the response until playback starts.
+// Response is {"result": "success", "url": "<plugin url>", "playing": true|false},
+// or {"result": "error", "error": "<message>"} with 400 status.
+// GET /external/v1/status returns projectx version and player state.
+
+const externalMaxWait = 300
+
+// ExternalPlayRequest is a JSON request of inter-addon API
+type ExternalPlayRequest struct {
+	Type    string `json:"type"`
+	TmdbID  int    `json:"tmdb_id"`
+	Season  int    `json:"season"`
+	Episode int    `json:"episode"`
+	URI     string `json:"uri"`
+	Wait    int    `json:"wait"`
+}
+
+// ExternalPlayResponse is a JSON response of inter-addon API
+type ExternalPlayResponse struct {
+	Result  string `json:"result"`
+	URL     string `json:"url,omitempty"`
+	Playing bool   `json:"playing"`
+	Error   string `json:"error,omitempty"`
+}
+
+// externalPlayURL returns plugin URL, which starts playback of the requested item
+func externalPlayURL(req *ExternalPlayRequest) (string, error) {
+	switch strings.ToLower(req.Type) {
+	case "movie":
+		if req.TmdbID <= 0 {
+			return "", fmt.Errorf("tmdb_id is required for movies")
+		}
+		return URLForXBMC("/movie/%d/play", req.TmdbID), nil
+	case "episode":
+		if req.TmdbID <= 0 || req.Season < 0 || req.Episode <= 0 {
+			return "", fmt.Errorf("tmdb_id, season and episode are required for episodes")
+		}
+		return URLForXBMC("/show/%d/season/%d/episode/%d/play", req.TmdbID, req.Season, req.Episode), nil
+	case "uri":
+		if req.URI == "" {
+			return "", fmt.Errorf("uri is required")
+		}
+		return URLQuery(URLForXBMC("/play"), "uri", req.URI), nil
+	}
+
+	return "", fmt.Errorf("unknown type %q, expected movie, episode or uri", req.Type)
+}
+
+// ExternalPlay is a plugin:// entry point of inter-addon API,
+// it redirects to the play route of the requested item
+func ExternalPlay(media string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := &ExternalPlayRequest{
+			Type:    media,
+			TmdbID:  strToInt(ctx.Params.ByName("tmdbId"), 0),
+			Season:  strToInt(ctx.Params.ByName("season"), -1),
+			Episode: strToInt(ctx.Params.ByName("episode"), 0),
+			URI:     ctx.Query("uri"),
+		}
+
+		rURL, err := externalPlayURL(req)
+		if err != nil {
+			log.Warningf("Invalid external play request: %s", err)
+			xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+			ctx.String(400, err.Error())
+			return
+		}
+
+		log.Infof("External play request for %s", rURL)
+		ctx.Redirect(302, rURL)
+	}
+}
+
+// ExternalPlayJSON is a JSON entry point of inter-addon API
+func ExternalPlayJSON(ctx *gin.Context) {
+	var req ExternalPlayRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(400, ExternalPlayResponse{Result: "error", Error: err.Error()})
+		return
+	}
+
+	rURL, err := externalPlayURL(&req)
+	if err != nil {
+		ctx.JSON(400, ExternalPlayResponse{Result: "error", Error: err.Error()})
+		return
+	}
+
+	log.Infof("External play request for %s", rURL)
+	xbmc.PlayURL(rURL)
+
+	resp := ExternalPlayResponse{Result: "success", URL: rURL}
+	if req.Wait > externalMaxWait {
+		req.Wait = externalMaxWait
+	}
+	for deadline := time.Now().Add(time.Duration(req.Wait) * time.Second); time.Now().Before(deadline); time.Sleep(time.Second) {
+		if resp.Playing = xbmc.PlayerIsPlaying(); resp.Playing {
+			break
+		}
+	}
+
+	ctx.JSON(200, resp)
+}
+
+// ExternalStatus returns version and player state for inter-addon API users
+func ExternalStatus(ctx *gin.Context) {
+	ctx.JSON(200, gin.H{
+		"version": util.GetVersion(),
+		"playing": xbmc.PlayerIsPlaying(),
+		"file":    xbmc.PlayerGetPlayingFile(),
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// guestBlockedRoutes are changing settings, library, torrents or Trakt account,
+// browsing, playback and downloads stay available in guest mode
+var guestBlockedRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/settings/`),
+	regexp.MustCompile(`^/(history|search)/(remove|clear)`),
+	regexp.MustCompile(`^/transmission/`),
+	regexp.MustCompile(`^/torrents/(add|import|pause|resume|move|recheck|episodes|delete|downloadall|undownloadall|selectfile|downloadfile|passkey|superseed|uploadslots)`),
+	regexp.MustCompile(`^/(movie|show)/[^/]+/(watchlist|collection|tmdblist)/`),
+	regexp.MustCompile(`^/show/[^/]+/(ordering|artwork)`),
+	regexp.MustCompile(`^/movies/collection/[^/]+/(watched|unwatched)`),
+	regexp.MustCompile(`^/library/(movie|show)/(add|remove|list)/`),
+	regexp.MustCompile(`^/library/(update|removed/|import/|failed/)`),
+	regexp.MustCompile(`^/provider/[^/]+/(enable|disable|settings)`),
+	regexp.MustCompile(`^/providers/`),
+	regexp.MustCompile(`^/trakt/`),
+	regexp.MustCompile(`^/tmdb/`),
+	regexp.MustCompile(`^/cmd/`),
+	regexp.MustCompile(`^/menu/`),
+}
+
+// guestBlockedMethods are modifying requests of JSON API v2, which are matched with method and path
+var guestBlockedMethods = []*regexp.Regexp{
+	regexp.MustCompile(`^(POST|DELETE) /api/v2/torrents`),
+}
+
+// GuestGuard blocks modifying requests when guest mode is enabled
+func GuestGuard() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !config.Get().GuestMode {
+			return
+		}
+
+		path := ctx.Request.URL.Path
+		for _, re := range guestBlockedRoutes {
+			if re.MatchString(path) {
+				log.Infof("Blocked %s in guest mode", path)
+				xbmc.Notify("projectx", "LOCALIZE[30709]", config.AddonIcon())
+				ctx.AbortWithStatus(403)
+				return
+			}
+		}
+		for _, re := range guestBlockedMethods {
+			if re.MatchString(ctx.Request.Method + " " + path) {
+				log.Infof("Blocked %s %s in guest mode", ctx.Request.Method, path)
+				ctx.AbortWithStatusJSON(403, gin.H{"error": "blocked in guest mode"})
+				return
+			}
+		}
+	}
+}
@@ -2,11 +2,13 @@ package api
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/asdine/storm"
 	"github.com/gin-gonic/gin"
 
+	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/xbmc"
 )
@@ -25,12 +27,24 @@ func History(ctx *gin.Context) {
 		return
 	}
 
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage := config.Get().ResultsPerPage
+
 	items := []*xbmc.ListItem{}
 	var ths []database.TorrentHistory
-	if err := database.GetStormDB().AllByIndex("Dt", &ths, storm.Reverse()); err != nil {
+	// Request one more item to know if there is a next page
+	if err := database.GetStormDB().AllByIndex("Dt", &ths, storm.Reverse(), storm.Skip((page-1)*perPage), storm.Limit(perPage+1)); err != nil {
 		log.Infof("Could not get list of history items: %s", err)
 	}
 
+	hasNextPage := len(ths) > perPage
+	if hasNextPage {
+		ths = ths[:perPage]
+	}
+
 	for _, th := range ths {
 		items = append(items, &xbmc.ListItem{
 			Label: th.Name,
@@ -47,6 +61,13 @@ func History(ctx *gin.Context) {
 			IsPlayable: true,
 		})
 	}
+	if hasNextPage {
+		items = append(items, &xbmc.ListItem{
+			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
+			Path:      URLQuery(URLForXBMC("/history"), "page", strconv.Itoa(page+1)),
+			Thumbnail: config.AddonResource("img", "nextpage.png"),
+		})
+	}
 
 	ctx.JSON(200, xbmc.NewView("", items))
 }
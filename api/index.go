@@ -8,6 +8,9 @@ import (
 
 	"github.com/projectx13/projectx/bittorrent"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/parental"
 	"github.com/projectx13/projectx/xbmc"
 )
 
@@ -38,6 +41,25 @@ func Index(s *bittorrent.Service) gin.HandlerFunc {
 			{Label: "LOCALIZE[30393]", Path: URLForXBMC("/status"), Thumbnail: config.AddonResource("img", "clock.png")},
 			{Label: "LOCALIZE[30527]", Path: URLForXBMC("/donate"), Thumbnail: config.AddonResource("img", "faq8.png")},
 			{Label: "LOCALIZE[30579]", Path: URLForXBMC("/settings/plugin.video.projectx"), Thumbnail: config.AddonResource("img", "settings.png")},
+			{Label: "LOCALIZE[30768]", Path: URLForXBMC("/settings/search"), Thumbnail: config.AddonResource("img", "search.png")},
+		}
+
+		if count, err := database.GetStormDB().Count(&database.LibraryTombstone{}); err == nil && count > 0 {
+			li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30704]", Path: URLForXBMC("/library/removed"), Thumbnail: config.AddonResource("img", "clock.png")})
+		}
+		if count, err := database.GetStormDB().Count(&database.LibraryFailure{}); err == nil && count > 0 {
+			li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30773]", Path: URLForXBMC("/library/failed"), Thumbnail: config.AddonResource("img", "clock.png")})
+		}
+		if len(library.Jobs()) > 0 {
+			li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30822]", Path: URLForXBMC("/library/jobs"), Thumbnail: config.AddonResource("img", "clock.png")})
+		}
+
+		if config.Get().ParentalControl {
+			if parental.IsUnlocked() {
+				li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30784]", Path: URLForXBMC("/parental/lock"), Thumbnail: config.AddonResource("img", "shield.png")})
+			} else {
+				li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30783]", Path: URLForXBMC("/parental/unlock"), Thumbnail: config.AddonResource("img", "shield.png")})
+			}
 		}
 
 		// Adding Settings urls for each search provider found locally.
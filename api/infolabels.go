@@ -175,13 +175,13 @@ func GetEpisodeLabels(showID, seasonNumber, episodeNumber int) (item *xbmc.ListI
 	if item.Art.FanArt == "" {
 		fanarts := make([]string, 0)
 		for _, backdrop := range show.Images.Backdrops {
-			fanarts = append(fanarts, tmdb.ImageURL(backdrop.FilePath, "w1280"))
+			fanarts = append(fanarts, tmdb.ArtURL(backdrop.FilePath, tmdb.ArtFanart))
 		}
 		if len(fanarts) > 0 {
 			item.Art.FanArt = fanarts[rand.Intn(len(fanarts))]
 		}
 	}
-	item.Art.Poster = tmdb.ImageURL(season.Poster, "w500")
+	item.Art.Poster = tmdb.ArtURL(season.Poster, tmdb.ArtPoster)
 
 	return
 }
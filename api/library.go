@@ -9,7 +9,9 @@ import (
 
 	"github.com/projectx13/projectx/bittorrent"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/trakt"
 	"github.com/projectx13/projectx/xbmc"
 )
@@ -35,38 +37,16 @@ var (
 	showsLibraryPath  string
 )
 
-// AddMovie ...
+// AddMovie queues the movie to be added to the library in background
 func AddMovie(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
 
-	movie, err := library.AddMovie(tmdbID, force)
-	if err != nil {
-		ctx.String(200, err.Error())
-		return
-	}
-	if config.Get().TraktToken != "" && config.Get().TraktSyncAddedMovies {
-		go trakt.SyncAddedItem("movies", tmdbID, config.Get().TraktSyncAddedMoviesLocation)
-	}
-
-	label := "LOCALIZE[30277]"
-	logMsg := "%s (%s) added to library"
-	if force {
-		label = "LOCALIZE[30286]"
-		logMsg = "%s (%s) merged to library"
-	}
-
-	log.Noticef(logMsg, movie.Title, tmdbID)
-	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("projectx", fmt.Sprintf("%s;;%s", label, movie.Title))) {
-		xbmc.VideoLibraryScanDirectory(library.MoviesLibraryPath(), true)
-	} else {
-		if ctx != nil {
-			ctx.Abort()
-		}
-		library.ClearPageCache()
-	}
+	job := library.AddJob(library.MovieType, tmdbID, force)
+	log.Infof("Library job %d queued for movie %s", job.ID, tmdbID)
+	ctx.String(200, "")
 }
 
 // AddMoviesList ...
@@ -94,6 +74,9 @@ func RemoveMovie(ctx *gin.Context) {
 	if err != nil {
 		ctx.String(200, err.Error())
 	}
+	if movie != nil {
+		database.GetStorm().AddTombstone(tmdbID, library.MovieType, movie.Title)
+	}
 	if config.Get().TraktToken != "" && config.Get().TraktSyncRemovedMovies {
 		go trakt.SyncRemovedItem("movies", tmdbStr, config.Get().TraktSyncRemovedMoviesLocation)
 	}
@@ -113,35 +96,16 @@ func RemoveMovie(ctx *gin.Context) {
 // Shows externals
 //
 
-// AddShow ...
+// AddShow queues the show to be added to the library in background
 func AddShow(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
 
-	show, err := library.AddShow(tmdbID, force)
-	if err != nil {
-		ctx.String(200, err.Error())
-		return
-	}
-	if config.Get().TraktToken != "" && config.Get().TraktSyncAddedShows {
-		go trakt.SyncAddedItem("shows", tmdbID, config.Get().TraktSyncAddedShowsLocation)
-	}
-
-	label := "LOCALIZE[30277]"
-	logMsg := "%s (%s) added to library"
-	if force {
-		label = "LOCALIZE[30286]"
-		logMsg = "%s (%s) merged to library"
-	}
-
-	log.Noticef(logMsg, show.Name, tmdbID)
-	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("projectx", fmt.Sprintf("%s;;%s", label, show.Name))) {
-		xbmc.VideoLibraryScanDirectory(library.ShowsLibraryPath(), true)
-	} else {
-		library.ClearPageCache()
-	}
+	job := library.AddJob(library.ShowType, tmdbID, force)
+	log.Infof("Library job %d queued for show %s", job.ID, tmdbID)
+	ctx.String(200, "")
 }
 
 // AddShowsList ...
@@ -168,6 +132,9 @@ func RemoveShow(ctx *gin.Context) {
 	if err != nil {
 		ctx.String(200, err.Error())
 	}
+	if show != nil {
+		database.GetStorm().AddTombstone(show.ID, library.ShowType, show.Name)
+	}
 	if config.Get().TraktToken != "" && config.Get().TraktSyncRemovedShows {
 		go trakt.SyncRemovedItem("shows", tmdbID, config.Get().TraktSyncRemovedShowsLocation)
 	}
@@ -206,6 +173,191 @@ func UpdateTrakt(ctx *gin.Context) {
 	}()
 }
 
+// RemovedItems lists items that were removed by the user and are skipped by Trakt sync
+func RemovedItems(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{}
+	for _, ts := range database.GetStorm().GetTombstones() {
+		title := ts.Title
+		media := movieType
+		if ts.MediaType == library.ShowType {
+			media = showType
+			if title == "" {
+				if show := tmdb.GetShow(ts.TmdbID, config.Get().Language); show != nil {
+					title = show.Name
+				}
+			}
+		} else if title == "" {
+			if movie := tmdb.GetMovie(ts.TmdbID, config.Get().Language); movie != nil {
+				title = movie.Title
+			}
+		}
+		if title == "" {
+			title = strconv.Itoa(ts.TmdbID)
+		}
+
+		restoreURL := URLForXBMC("/library/removed/restore/%s/%d", media, ts.TmdbID)
+		items = append(items, &xbmc.ListItem{
+			Label: fmt.Sprintf("%s [I](%s)[/I]", title, ts.Dt.Format("2006-01-02")),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30700]", fmt.Sprintf("XBMC.RunPlugin(%s)", restoreURL)},
+				{"LOCALIZE[30701]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/removed/clear"))},
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("", items))
+}
+
+// RemovedItemRestore removes tombstone, so item can be added again by Trakt sync
+func RemovedItemRestore(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	mediaType := library.MovieType
+	if ctx.Params.ByName("media") == showType {
+		mediaType = library.ShowType
+	}
+
+	database.GetStorm().RemoveTombstone(tmdbID, mediaType)
+	xbmc.Notify("projectx", "LOCALIZE[30702]", config.AddonIcon())
+	xbmc.Refresh()
+
+	ctx.String(200, "")
+}
+
+// RemovedItemsClear removes all tombstones
+func RemovedItemsClear(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	if !xbmc.DialogConfirm("projectx", "LOCALIZE[30703]") {
+		ctx.String(200, "")
+		return
+	}
+
+	if err := database.GetStormDB().Drop(&database.LibraryTombstone{}); err != nil {
+		log.Infof("Could not clean removed items: %s", err)
+	}
+	database.GetStormDB().ReIndex(&database.LibraryTombstone{})
+	xbmc.Refresh()
+
+	ctx.String(200, "")
+}
+
+// FailedItems lists library items, which could not be written on sync and are retried later
+func FailedItems(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{}
+	for _, f := range database.GetStorm().GetLibraryFailures() {
+		title := f.Title
+		media := movieType
+		if f.MediaType == library.ShowType {
+			media = showType
+			if title == "" {
+				if show := tmdb.GetShow(f.TmdbID, config.Get().Language); show != nil {
+					title = show.Name
+				}
+			}
+		} else if title == "" {
+			if movie := tmdb.GetMovie(f.TmdbID, config.Get().Language); movie != nil {
+				title = movie.Title
+			}
+		}
+		if title == "" {
+			title = strconv.Itoa(f.TmdbID)
+		}
+
+		retryURL := URLForXBMC("/library/failed/retry/%s/%d", media, f.TmdbID)
+		items = append(items, &xbmc.ListItem{
+			Label: fmt.Sprintf("%s [I](%d, %s)[/I]", title, f.Attempts, f.LastTry.Format("2006-01-02 15:04")),
+			Info: &xbmc.ListItemInfo{
+				Plot: f.Error,
+			},
+			ContextMenu: [][]string{
+				{"LOCALIZE[30774]", fmt.Sprintf("XBMC.RunPlugin(%s)", retryURL)},
+				{"LOCALIZE[30775]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/failed/dismiss/%s/%d", media, f.TmdbID))},
+				{"LOCALIZE[30776]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/failed/retry"))},
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("", items))
+}
+
+// FailedItemRetry writes failed library item again
+func FailedItemRetry(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, mediaType := failedItemParams(ctx)
+	if err := library.RetryFailedItem(tmdbID, mediaType); err != nil {
+		xbmc.Notify("projectx", xbmc.Localizef(30777, 0, 1), config.AddonIcon())
+	} else {
+		xbmc.Notify("projectx", xbmc.Localizef(30777, 1, 0), config.AddonIcon())
+	}
+	xbmc.Refresh()
+
+	ctx.String(200, "")
+}
+
+// FailedItemsRetry writes all failed library items again, without waiting for their next attempt
+func FailedItemsRetry(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	added, failed := library.RetryFailedItems(true)
+	xbmc.Notify("projectx", xbmc.Localizef(30777, added, failed), config.AddonIcon())
+	xbmc.Refresh()
+
+	ctx.String(200, "")
+}
+
+// FailedItemDismiss forgets failed library item, so it is not retried anymore
+func FailedItemDismiss(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, mediaType := failedItemParams(ctx)
+	database.GetStorm().RemoveLibraryFailure(tmdbID, mediaType)
+	xbmc.Notify("projectx", "LOCALIZE[30778]", config.AddonIcon())
+	xbmc.Refresh()
+
+	ctx.String(200, "")
+}
+
+// jobStateLabels are labels of library job states, indexed by state
+var jobStateLabels = []string{"LOCALIZE[30823]", "LOCALIZE[30824]", "LOCALIZE[30825]", "LOCALIZE[30826]"}
+
+// LibraryJobs lists queued, running and recently finished additions to the library
+func LibraryJobs(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{}
+	for _, j := range library.Jobs() {
+		title := j.Title
+		if title == "" {
+			title = j.TmdbID
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label: fmt.Sprintf("%s [I](%s, %s)[/I]", title, jobStateLabels[j.State], j.Created.Format("15:04:05")),
+			Info: &xbmc.ListItemInfo{
+				Plot: j.Error,
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("", items))
+}
+
+func failedItemParams(ctx *gin.Context) (tmdbID int, mediaType int) {
+	tmdbID, _ = strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	mediaType = library.MovieType
+	if ctx.Params.ByName("media") == showType {
+		mediaType = library.ShowType
+	}
+	return
+}
+
 // PlayMovie ...
 func PlayMovie(s *bittorrent.Service) gin.HandlerFunc {
 	if config.Get().ChooseStreamAutoMovie {
@@ -221,3 +373,50 @@ func PlayShow(s *bittorrent.Service) gin.HandlerFunc {
 	}
 	return ShowEpisodeRun("links", s)
 }
+
+// ImportIMDb imports IMDb public list, or exported CSV file, to the library or Trakt watchlist
+func ImportIMDb(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	list := ctx.Query("list")
+	if list == "" {
+		if list = xbmc.Keyboard("", "LOCALIZE[30714]"); list == "" {
+			return
+		}
+	}
+
+	result, err := library.ImportIMDbList(list, ctx.DefaultQuery("target", library.ImportToLibrary))
+	importDone(ctx, result, err)
+}
+
+// ImportTVDB imports TVDB account favorites to the library or Trakt watchlist
+func ImportTVDB(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	account := ctx.Query("account")
+	if account == "" {
+		if account = xbmc.Keyboard("", "LOCALIZE[30715]"); account == "" {
+			return
+		}
+	}
+
+	result, err := library.ImportTVDBFavorites(account, ctx.DefaultQuery("target", library.ImportToLibrary))
+	importDone(ctx, result, err)
+}
+
+func importDone(ctx *gin.Context, result *library.ImportResult, err error) {
+	if err != nil {
+		log.Warningf("Import failed: %s", err)
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		ctx.String(200, err.Error())
+		return
+	}
+
+	log.Noticef("Import finished: %d added, %d skipped, %d not found", result.Added, result.Skipped, result.NotFound)
+	xbmc.Notify("projectx", fmt.Sprintf("LOCALIZE[30713];;%d;;%d;;%d", result.Added, result.Skipped, result.NotFound), config.AddonIcon())
+
+	if result.Added > 0 && ctx.DefaultQuery("target", library.ImportToLibrary) == library.ImportToLibrary && config.Get().LibraryUpdate == 0 {
+		xbmc.VideoLibraryScan()
+	}
+	ctx.String(200, "")
+}
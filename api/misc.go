@@ -53,6 +53,10 @@ func Settings(ctx *gin.Context) {
 	addon := ctx.Params.ByName("addon")
 	if addon == "" {
 		addon = "plugin.video.projectx"
+	} else if addon == "search" {
+		// Router does not allow static route next to :addon parameter
+		SettingsSearch(ctx)
+		return
 	}
 
 	xbmc.AddonSettings(addon)
@@ -71,6 +75,7 @@ func Status(ctx *gin.Context) {
 [B]LOCALIZE[30395]:[/B] %s
 [B]LOCALIZE[30396]:[/B] %d
 [B]LOCALIZE[30488]:[/B] %d
+[B]LOCALIZE[30821]:[/B] %s
 
 [COLOR pink][B]LOCALIZE[30399]:[/B][/COLOR]
     [B]LOCALIZE[30397]:[/B] %s
@@ -99,8 +104,8 @@ func Status(ctx *gin.Context) {
 	debugBundleAddress := fmt.Sprintf("http://%s:%d/debug/bundle", ip, port)
 	infoAddress := fmt.Sprintf("http://%s:%d/info", ip, port)
 
-	appSize := fileSize(filepath.Join(config.Get().Info.Profile, database.GetStorm().GetFilename()))
-	cacheSize := fileSize(filepath.Join(config.Get().Info.Profile, database.GetCache().GetFilename()))
+	appSize := fileSize(database.GetStorm().GetPath())
+	cacheSize := fileSize(database.GetCache().GetPath())
 
 	torrentsCount, _ := database.GetStormDB().Count(&database.TorrentAssignMetadata{})
 	queriesCount, _ := database.GetStormDB().Count(&database.QueryHistory{})
@@ -112,6 +117,7 @@ func Status(ctx *gin.Context) {
 		ip,
 		port,
 		proxy.ProxyPort,
+		resourcesProfile(),
 
 		webAddress,
 		infoAddress,
@@ -127,10 +133,38 @@ func Status(ctx *gin.Context) {
 		deletedShowsCount,
 	)
 
+	if stats, err := database.GetCache().Stats(); err == nil {
+		text += fmt.Sprintf("\n[COLOR pink][B]LOCALIZE[30718]:[/B][/COLOR]\n    [B]LOCALIZE[30719]:[/B] %d (%s)\n",
+			stats.FreePages, humanize.Bytes(uint64(stats.FreeBytes)))
+		for _, b := range stats.Buckets {
+			text += fmt.Sprintf("    [B]%s:[/B] %s (%d)\n", b.Name, humanize.Bytes(uint64(b.Bytes)), b.Keys)
+		}
+	}
+
 	xbmc.DialogText(title, string(text))
 	ctx.String(200, "")
 }
 
+// DatabaseStatus returns per-bucket usage of the cache database
+func DatabaseStatus(ctx *gin.Context) {
+	stats, err := database.GetCache().Stats()
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, stats)
+}
+
+// resourcesProfile describes profile of the device, which search and torrent limits are scaled by
+func resourcesProfile() string {
+	r := util.GetResources()
+	if r.Memory == 0 {
+		return fmt.Sprintf("%s (%d CPU)", util.ProfileNames[r.Profile], r.Cores)
+	}
+	return fmt.Sprintf("%s (%d CPU, %s)", util.ProfileNames[r.Profile], r.Cores, humanize.IBytes(r.Memory))
+}
+
 func fileSize(path string) string {
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/parental"
 	"github.com/projectx13/projectx/providers"
 	"github.com/projectx13/projectx/scrape"
 	"github.com/projectx13/projectx/tmdb"
@@ -74,19 +76,33 @@ func MoviesIndex(ctx *gin.Context) {
 		{Label: "LOCALIZE[30250]", Path: URLForXBMC("/movies/trakt/anticipated"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
 		{Label: "LOCALIZE[30251]", Path: URLForXBMC("/movies/trakt/boxoffice"), Thumbnail: config.AddonResource("img", "box_office.png")},
 
+		{Label: "LOCALIZE[30726]", Path: URLForXBMC("/movies/trending/day"), Thumbnail: config.AddonResource("img", "trending.png")},
+		{Label: "LOCALIZE[30727]", Path: URLForXBMC("/movies/trending/week"), Thumbnail: config.AddonResource("img", "trending.png")},
+		{Label: "LOCALIZE[30740]", Path: URLForXBMC("/movies/discover/build"), Thumbnail: config.AddonResource("img", "search.png")},
 		{Label: "LOCALIZE[30210]", Path: URLForXBMC("/movies/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
 		{Label: "LOCALIZE[30211]", Path: URLForXBMC("/movies/top"), Thumbnail: config.AddonResource("img", "top_rated.png")},
 		{Label: "LOCALIZE[30212]", Path: URLForXBMC("/movies/mostvoted"), Thumbnail: config.AddonResource("img", "most_voted.png")},
 		{Label: "LOCALIZE[30236]", Path: URLForXBMC("/movies/recent"), Thumbnail: config.AddonResource("img", "clock.png")},
+		{Label: "LOCALIZE[30788]", Path: URLForXBMC("/movies/upcoming"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
+		{Label: "LOCALIZE[30808]", Path: URLForXBMC("/movies/recommendations"), Thumbnail: config.AddonResource("img", "movies.png")},
 		{Label: "LOCALIZE[30213]", Path: URLForXBMC("/movies/imdb250"), Thumbnail: config.AddonResource("img", "imdb.png")},
 		{Label: "LOCALIZE[30289]", Path: URLForXBMC("/movies/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
 		{Label: "LOCALIZE[30373]", Path: URLForXBMC("/movies/languages"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "LOCALIZE[30732]", Path: URLForXBMC("/movies/providers"), Thumbnail: config.AddonResource("img", "movies.png")},
 		{Label: "LOCALIZE[30374]", Path: URLForXBMC("/movies/countries"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "LOCALIZE[30722]", Path: URLForXBMC("/movies/years"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "LOCALIZE[30723]", Path: URLForXBMC("/movies/universes"), Thumbnail: config.AddonResource("img", "movies.png")},
 
 		{Label: "LOCALIZE[30361]", Path: URLForXBMC("/movies/trakt/history"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
 
 		{Label: "LOCALIZE[30517]", Path: URLForXBMC("/movies/library"), Thumbnail: config.AddonResource("img", "movies.png")},
 	}
+	if config.Get().WatchProviders != "" {
+		items = append(items, &xbmc.ListItem{Label: "LOCALIZE[30733]", Path: URLForXBMC("/movies/popular/provider/%s", config.Get().WatchProviders), Thumbnail: config.AddonResource("img", "movies.png")})
+	}
+	if tmdb.ListsAuthorized() {
+		items = append(items, &xbmc.ListItem{Label: "LOCALIZE[30795]", Path: URLForXBMC("/movies/tmdb/lists/"), Thumbnail: config.AddonResource("img", "movies.png")})
+	}
 	for _, item := range items {
 		item.ContextMenu = [][]string{
 			{"LOCALIZE[30142]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/menus_movies"))},
@@ -148,6 +164,24 @@ func MovieLanguages(ctx *gin.Context) {
 	ctx.JSON(200, xbmc.NewView("menus_movies_languages", filterListItems(items)))
 }
 
+// MovieProviders lists streaming services, available in the watch region
+func MovieProviders(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0)
+	for _, provider := range tmdb.GetWatchProviders("movie", config.Get().WatchRegion, config.Get().Language) {
+		items = append(items, &xbmc.ListItem{
+			Label:     provider.Name,
+			Path:      URLForXBMC("/movies/popular/provider/%d", provider.ID),
+			Thumbnail: tmdb.ArtURL(provider.LogoPath, tmdb.ArtLogo),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/menus_movies_providers"))},
+			},
+		})
+	}
+	ctx.JSON(200, xbmc.NewView("menus_movies_providers", filterListItems(items)))
+}
+
 // MovieCountries ...
 func MovieCountries(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -281,6 +315,13 @@ func CalendarMovies(ctx *gin.Context) {
 func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, query string) {
 	defer perf.ScopeTimer()()
 
+	items := movieListItems(ctx, movies, page, total, query)
+	ctx.JSON(200, xbmc.NewView("movies", sortListItems(ctx, filterListItems(items))))
+}
+
+// movieListItems returns list items of movies with context menus, and next page item,
+// when page is positive and there are more results
+func movieListItems(ctx *gin.Context, movies tmdb.Movies, page int, total int, query string) xbmc.ListItems {
 	hasNextPage := 0
 	if page > 0 {
 		if page*config.Get().ResultsPerPage < total {
@@ -289,12 +330,19 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 	}
 
 	items := make(xbmc.ListItems, 0, len(movies)+hasNextPage)
+	// Watched counts of collections, several movies of the page could be from one collection
+	collectionLabels := map[int]string{}
 
 	for _, movie := range movies {
-		if movie == nil {
+		if movie == nil || !parental.AllowMovie(movie) {
 			continue
 		}
 		item := movie.ToListItem()
+		if region, ok := regionOverride(ctx); ok {
+			item.Info.MPAA = movie.CertificationLabel(region)
+			item.Info.Premiered = movie.RegionReleaseDate(region)
+			item.Info.Date = item.Info.Premiered
+		}
 
 		thisURL := URLForXBMC("/movie/%d/", movie.ID) + "%s/%s"
 		contextLabel := playLabel
@@ -310,6 +358,7 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 
 		libraryActions := [][]string{
 			{contextLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextURL)},
+			{"LOCALIZE[30724]", fmt.Sprintf("XBMC.PlayMedia(%s)", URLQuery(item.Path, "audio", "true"))},
 		}
 		if library.IsDuplicateMovie(tmdbID) || library.IsAddedToLibrary(tmdbID, library.MovieType) {
 			libraryActions = append(libraryActions, []string{"LOCALIZE[30283]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/movie/add/%d?force=true", movie.ID))})
@@ -331,10 +380,27 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 		item.ContextMenu = [][]string{
 			watchlistAction,
 			collectionAction,
+			{"LOCALIZE[30779]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movie/%d/similar", movie.ID))},
 			{"LOCALIZE[30034]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/movies"))},
 		}
+		item.ContextMenu = append(item.ContextMenu, tmdbListActions("movie", movie.ID)...)
 		item.ContextMenu = append(libraryActions, item.ContextMenu...)
 
+		if movie.BelongsToCollection != nil {
+			collectionID := movie.BelongsToCollection.ID
+			label, ok := collectionLabels[collectionID]
+			if !ok {
+				label = "LOCALIZE[30734]"
+				if collection := tmdb.GetCollection(collectionID, config.Get().Language); collection != nil {
+					if watched, total := collection.WatchedCount(); total > 0 {
+						label = fmt.Sprintf("LOCALIZE[30770];;%d/%d", watched, total)
+					}
+				}
+				collectionLabels[collectionID] = label
+			}
+			item.ContextMenu = append(item.ContextMenu, []string{label, fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movies/collection/%d", collectionID))})
+		}
+
 		if config.Get().Platform.Kodi < 17 {
 			item.ContextMenu = append(item.ContextMenu,
 				[]string{"LOCALIZE[30203]", "XBMC.Action(Info)"},
@@ -350,6 +416,13 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 		nextPath := URLForXBMC(fmt.Sprintf("%s?page=%d", path, page+1))
 		if query != "" {
 			nextPath = URLForXBMC(fmt.Sprintf("%s?q=%s&page=%d", path, query, page+1))
+		} else if path == "/movies/discover" {
+			// Discover filters are kept in query
+			values := ctx.Request.URL.Query()
+			values.Set("page", strconv.Itoa(page+1))
+			nextPath = URLForXBMC("%s?%s", path, values.Encode())
+		} else if region, ok := regionOverride(ctx); ok {
+			nextPath = URLForXBMC(fmt.Sprintf("%s?page=%d&content_region=%s", path, page+1, region))
 		}
 		next := &xbmc.ListItem{
 			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
@@ -358,7 +431,7 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 		}
 		items = append(items, next)
 	}
-	ctx.JSON(200, xbmc.NewView("movies", filterListItems(items)))
+	return items
 }
 
 // AutoscrapedMovies ...
@@ -392,6 +465,7 @@ func PopularMovies(ctx *gin.Context) {
 	p.Genre = ctx.Params.ByName("genre")
 	p.Language = ctx.Params.ByName("language")
 	p.Country = ctx.Params.ByName("country")
+	p.Providers = ctx.Params.ByName("provider")
 	if p.Genre == "0" {
 		p.Genre = ""
 	}
@@ -401,6 +475,169 @@ func PopularMovies(ctx *gin.Context) {
 	renderMovies(ctx, movies, page, total, "")
 }
 
+// TrendingMovies ...
+func TrendingMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	window := ctx.Params.ByName("window")
+	if window != "week" {
+		window = "day"
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.GetTrendingMovies(window, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// MoviesByKeyword lists popular movies with TMDB keyword
+func MoviesByKeyword(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	keywordID, _ := strconv.Atoi(ctx.Params.ByName("keywordId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.MoviesByKeywords([]int{keywordID}, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// SimilarMovies lists movies, which share keywords with the movie
+func SimilarMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.SimilarMoviesByKeywords(tmdbID, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// RecommendedMovies lists TMDB recommendations for recently watched movies, merged into one list,
+// each movie is marked with the watched movie it is recommended for
+func RecommendedMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	recent, watched := watchedMovieIDs()
+	recommendations := tmdb.RecommendMovies(recent, watched)
+
+	perPage := config.Get().ResultsPerPage
+	ids := make([]int, 0, perPage)
+	because := map[int]int{}
+	for i := (page - 1) * perPage; i >= 0 && i < len(recommendations) && len(ids) < perPage; i++ {
+		ids = append(ids, recommendations[i].ID)
+		because[recommendations[i].ID] = recommendations[i].Because
+	}
+
+	language := config.Get().Language
+	movies := tmdb.GetMovies(ids, language)
+	items := movieListItems(ctx, movies, page, len(recommendations), "")
+	for _, m := range movies {
+		if m == nil || m.IMDBId == "" {
+			continue
+		}
+		source := tmdb.GetMovie(because[m.ID], language)
+		if source == nil {
+			continue
+		}
+		for _, item := range items {
+			if item.Info != nil && item.Info.IMDBNumber == m.IMDBId {
+				if item.Properties == nil {
+					item.Properties = map[string]string{}
+				}
+				item.Properties["because_watched"] = source.Title
+			}
+		}
+	}
+	ctx.JSON(200, xbmc.NewView("movies", sortListItems(ctx, filterListItems(items))))
+}
+
+// watchedMovieIDs returns TMDB IDs of watched movies, recently watched first, from Trakt history
+// and Kodi library, and all of them as a set
+func watchedMovieIDs() (recent []int, watched map[int]bool) {
+	watched = map[int]bool{}
+	add := func(id int) {
+		if id != 0 && !watched[id] {
+			watched[id] = true
+			recent = append(recent, id)
+		}
+	}
+
+	if config.Get().TraktToken != "" {
+		movies, err := trakt.WatchedMovies(false)
+		if err != nil {
+			log.Warningf("Could not get watched movies from Trakt: %s", err)
+		}
+		for _, m := range movies {
+			if m != nil && m.Movie != nil && m.Movie.IDs != nil {
+				add(m.Movie.IDs.TMDB)
+			}
+		}
+	}
+	for _, id := range library.GetWatchedMovies() {
+		add(id)
+	}
+	return
+}
+
+// MovieCollection lists all movies of TMDB collection, in release order
+func MovieCollection(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	collectionID, _ := strconv.Atoi(ctx.Params.ByName("collectionId"))
+	collection := tmdb.GetCollection(collectionID, config.Get().Language)
+	if collection == nil {
+		ctx.Error(errors.New("Unable to find collection"))
+		return
+	}
+
+	ids := make([]int, 0, len(collection.Parts))
+	for _, part := range collection.Parts {
+		ids = append(ids, part.ID)
+	}
+
+	watchedActions := [][]string{}
+	if watched, total := collection.WatchedCount(); watched < total {
+		watchedActions = append(watchedActions, []string{"LOCALIZE[30771]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movies/collection/%d/watched", collection.ID))})
+	} else if watched > 0 {
+		watchedActions = append(watchedActions, []string{"LOCALIZE[30772]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movies/collection/%d/unwatched", collection.ID))})
+	}
+
+	items := movieListItems(ctx, tmdb.GetMovies(ids, config.Get().Language), -1, 0, "")
+	for i, item := range items {
+		item.ContextMenu = append(item.ContextMenu, watchedActions...)
+		// Movies of the collection are grouped by Kodi into a movie set
+		item.Info.Set = collection.Name
+		item.Info.SetID = collection.ID
+		item.Info.SortTitle = fmt.Sprintf("%03d %s", i+1, item.Info.Title)
+		if item.Art != nil && collection.PosterPath != "" {
+			item.Art.SetPoster = tmdb.ArtURL(collection.PosterPath, tmdb.ArtPoster)
+		}
+		if item.Art != nil && collection.BackdropPath != "" {
+			item.Art.SetFanArt = tmdb.ArtURL(collection.BackdropPath, tmdb.ArtFanart)
+		}
+	}
+
+	ctx.JSON(200, xbmc.NewView("movies", filterListItems(items)))
+}
+
+// MovieCollectionWatched marks released movies of TMDB collection as watched or unwatched
+func MovieCollectionWatched(watched bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		collectionID, _ := strconv.Atoi(ctx.Params.ByName("collectionId"))
+		collection := tmdb.GetCollection(collectionID, config.Get().Language)
+		if collection == nil {
+			ctx.Error(errors.New("Unable to find collection"))
+			return
+		}
+
+		if err := library.SetMoviesWatched(collection.ReleasedParts(), watched); err != nil {
+			ctx.Error(fmt.Errorf("Unable to set watched state of collection %d: %s", collectionID, err))
+			return
+		}
+
+		xbmc.Refresh()
+		ctx.String(200, "")
+	}
+}
+
 // RecentMovies ...
 func RecentMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -501,6 +738,7 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		tmdbID := ctx.Params.ByName("tmdbId")
 		external := ctx.Query("external")
 		doresume := ctx.DefaultQuery("doresume", "true")
+		audio := ctx.DefaultQuery("audio", "false")
 
 		runAction := "/play"
 		if action == "download" {
@@ -508,7 +746,7 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		}
 
 		movie := tmdb.GetMovieByID(tmdbID, config.Get().Language)
-		if movie == nil {
+		if movie == nil || parentalBlocked(parental.AllowMovie(movie)) {
 			return
 		}
 
@@ -518,7 +756,8 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"doresume", doresume,
 				"resume", existingTorrent.InfoHash(),
 				"tmdb", tmdbID,
-				"type", "movie")
+				"type", "movie",
+				"audio", audio)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -532,7 +771,8 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"doresume", doresume,
 				"uri", torrent.URI,
 				"tmdb", tmdbID,
-				"type", "movie")
+				"type", "movie",
+				"audio", audio)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -555,6 +795,10 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		// Bitrate, needed for streaming, is compared with connection speed
+		duration := movie.Runtime * 60
+		speed := bittorrent.ConnectionSpeed()
+
 		choices := make([]string, 0, len(torrents))
 		for _, torrent := range torrents {
 			resolution := ""
@@ -566,6 +810,9 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.Size != "" {
 				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
 			}
+			if hint := bandwidthHint(torrent, duration, speed); hint != "" {
+				info = append(info, hint)
+			}
 			if torrent.RipType > 0 {
 				info = append(info, bittorrent.Rips[torrent.RipType])
 			}
@@ -575,6 +822,9 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.AudioCodec > 0 {
 				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
 			}
+			if torrent.IsStream() {
+				info = append(info, strings.ToUpper(torrent.StreamType))
+			}
 			if torrent.Provider != "" {
 				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
 			}
@@ -610,7 +860,11 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"uri", torrents[choice].URI,
 				"doresume", doresume,
 				"tmdb", tmdbID,
-				"type", "movie")
+				"type", "movie",
+				"audio", audio)
+			if torrents[choice].IsStream() {
+				rURL = streamPlayURL(torrents[choice])
+			}
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
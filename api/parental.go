@@ -0,0 +1,70 @@
+package api
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/parental"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// parentalProtectedRoutes could turn parental control off, so they need PIN
+var parentalProtectedRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/settings/`),
+}
+
+// ParentalGuard asks for PIN before opening settings, when parental control is active
+func ParentalGuard() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !parental.IsActive() {
+			return
+		}
+
+		path := ctx.Request.URL.Path
+		for _, re := range parentalProtectedRoutes {
+			if re.MatchString(path) && !requestParentalUnlock() {
+				log.Infof("Blocked %s by parental control", path)
+				ctx.AbortWithStatus(403)
+				return
+			}
+		}
+	}
+}
+
+// ParentalUnlock asks for PIN and turns parental control off for a while
+func ParentalUnlock(ctx *gin.Context) {
+	if requestParentalUnlock() {
+		xbmc.Notify("projectx", "LOCALIZE[30785]", config.AddonIcon())
+		xbmc.Refresh()
+	}
+	ctx.String(200, "")
+}
+
+// ParentalLock turns parental control back on
+func ParentalLock(ctx *gin.Context) {
+	parental.Lock()
+	xbmc.Notify("projectx", "LOCALIZE[30786]", config.AddonIcon())
+	xbmc.Refresh()
+	ctx.String(200, "")
+}
+
+func requestParentalUnlock() bool {
+	pin := xbmc.Keyboard("", "LOCALIZE[30781]", true)
+	if pin == "" {
+		return false
+	} else if !parental.Unlock(pin) {
+		xbmc.Notify("projectx", "LOCALIZE[30782]", config.AddonIcon())
+		return false
+	}
+	return true
+}
+
+// parentalBlocked notifies that item could not be played, when it is not allowed
+func parentalBlocked(allowed bool) bool {
+	if !allowed {
+		xbmc.Notify("projectx", "LOCALIZE[30780]", config.AddonIcon())
+	}
+	return !allowed
+}
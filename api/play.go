@@ -49,6 +49,7 @@ func Play(s *bittorrent.Service) gin.HandlerFunc {
 		season := ctx.Query("season")
 		episode := ctx.Query("episode")
 		background := ctx.DefaultQuery("background", "false")
+		audio := ctx.DefaultQuery("audio", "false")
 
 		if uri == "" && resume == "" {
 			return
@@ -81,6 +82,7 @@ func Play(s *bittorrent.Service) gin.HandlerFunc {
 			Episode:           episodeNumber,
 			Query:             query,
 			Background:        background == "true",
+			AudioOnly:         audio == "true",
 		}
 
 		player := bittorrent.NewPlayer(s, params)
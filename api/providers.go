@@ -9,6 +9,8 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/providers"
 	"github.com/projectx13/projectx/xbmc"
 )
 
@@ -98,6 +100,15 @@ func ProviderList(ctx *gin.Context) {
 		items = append(items, item)
 	}
 
+	items = append(items, &xbmc.ListItem{
+		Label:      "LOCALIZE[30791]",
+		Path:       URLForXBMC("/providers/coverage"),
+		IsPlayable: false,
+		ContextMenu: [][]string{
+			{"LOCALIZE[30792]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/providers/coverage/clear"))},
+		},
+	})
+
 	ctx.JSON(200, xbmc.NewView("", items))
 }
 
@@ -160,6 +171,24 @@ func ProvidersEnableAll(ctx *gin.Context) {
 	ctx.String(200, "")
 }
 
+// ProvidersCoverage shows report of searches without results, with suggestions which providers or filters are responsible
+func ProvidersCoverage(ctx *gin.Context) {
+	report := providers.CoverageReport()
+	if len(report) == 0 {
+		xbmc.Notify("projectx", "LOCALIZE[30793]", config.AddonIcon())
+	} else {
+		xbmc.DialogText("LOCALIZE[30791]", strings.Join(report, "\n"))
+	}
+	ctx.String(200, "")
+}
+
+// ProvidersCoverageClear forgets failed searches
+func ProvidersCoverageClear(ctx *gin.Context) {
+	database.GetStorm().ClearFailedSearches()
+	xbmc.Notify("projectx", "LOCALIZE[30794]", config.AddonIcon())
+	ctx.String(200, "")
+}
+
 // ProvidersDisableAll ...
 func ProvidersDisableAll(ctx *gin.Context) {
 	providers := getProviders()
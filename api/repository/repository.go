@@ -18,6 +18,7 @@ import (
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/proxy"
 	"github.com/projectx13/projectx/xbmc"
 	"github.com/gin-gonic/gin"
@@ -124,6 +125,8 @@ const (
 	githubLatestReleaseURL  = "https://api.github.com/repos/%s/%s/releases/latest"
 
 	releaseChangelog = "[B]%s[/B] - %s\n%s\n\n"
+
+	changelogExpiration = 60 * 60
 )
 
 var (
@@ -360,6 +363,20 @@ func writeChangelog(user string, repository string) error {
 }
 
 func addonChangelog(ctx *gin.Context, user string, repository string) {
-	changelog := fetchChangelog(user, repository)
-	ctx.String(200, changelog)
+	key := fmt.Sprintf("repository.changelog.%s.%s", user, repository)
+	reader, err := database.GetCache().AsReader(database.CommonBucket, key)
+	if err != nil {
+		changelog := fetchChangelog(user, repository)
+		if err := database.GetCache().SetCachedBlob(database.CommonBucket, changelogExpiration, key, []byte(changelog)); err != nil {
+			log.Warningf("Could not save changelog of %s: %s", repository, err)
+			ctx.String(200, changelog)
+			return
+		}
+		if reader, err = database.GetCache().AsReader(database.CommonBucket, key); err != nil {
+			ctx.AbortWithError(500, err)
+			return
+		}
+	}
+
+	http.ServeContent(ctx.Writer, ctx.Request, "changelog.txt", time.Time{}, reader)
 }
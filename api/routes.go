@@ -19,7 +19,9 @@ var log = logging.MustGetLogger("api")
 func Routes(s *bittorrent.Service) *gin.Engine {
 	r := gin.New()
 	r.Use(gin.Recovery())
-	r.Use(gin.LoggerWithWriter(gin.DefaultWriter, "/torrents/list", "/notification"))
+	r.Use(gin.LoggerWithWriter(gin.DefaultWriter, "/torrents/list", "/notification", "/ws"))
+	r.Use(GuestGuard())
+	r.Use(ParentalGuard())
 
 	gin.SetMode(gin.ReleaseMode)
 
@@ -30,6 +32,10 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	r.GET("/donate", Donate)
 	r.GET("/settings/:addon", Settings)
 	r.GET("/status", Status)
+	r.GET("/status/database", DatabaseStatus)
+	r.GET("/parental/unlock", ParentalUnlock)
+	r.GET("/parental/lock", ParentalLock)
+	r.GET("/ws", Events)
 
 	history := r.Group("/history")
 	{
@@ -43,6 +49,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		search.GET("", Search(s))
 		search.GET("/remove", SearchRemove)
 		search.GET("/clear", SearchClear)
+		search.GET("/cancel", SearchCancel)
 		search.GET("/infolabels/:tmdbId", InfoLabelsSearch(s))
 	}
 
@@ -56,6 +63,45 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		web.StaticFile("/favicon.ico", filepath.Join(config.Get().Info.Path, "resources", "web", "favicon.ico"))
 	}
 
+	// Transmission clients are using POST requests to /transmission/rpc
+	r.POST("/transmission/rpc", TransmissionRPC(s))
+
+	external := r.Group("/external/v1")
+	{
+		external.GET("/movie/:tmdbId", ExternalPlay("movie"))
+		external.GET("/episode/:tmdbId/:season/:episode", ExternalPlay("episode"))
+		external.GET("/uri", ExternalPlay("uri"))
+		external.POST("/play", ExternalPlayJSON)
+		external.GET("/status", ExternalStatus)
+	}
+
+	apiV2 := r.Group("/api/v2")
+	apiV2.Use(V2Headers)
+	{
+		apiV2.OPTIONS("/*path", V2Preflight)
+		apiV2.GET("/status", V2Status(s))
+
+		apiV2.GET("/movies/popular", V2PopularMovies)
+		apiV2.GET("/movies/search", V2SearchMovies)
+		apiV2.GET("/movie/:tmdbId", V2Movie)
+
+		apiV2.GET("/shows/popular", V2PopularShows)
+		apiV2.GET("/shows/search", V2SearchShows)
+		apiV2.GET("/show/:tmdbId", V2Show)
+		apiV2.GET("/show/:tmdbId/season/:season", V2Season)
+
+		apiV2.GET("/torrents", V2Torrents(s))
+		apiV2.POST("/torrents", V2AddTorrent(s))
+		apiV2.GET("/torrents/:torrentId", V2Torrent(s))
+		apiV2.POST("/torrents/:torrentId/pause", V2PauseTorrent(s))
+		apiV2.POST("/torrents/:torrentId/resume", V2ResumeTorrent(s))
+		apiV2.DELETE("/torrents/:torrentId", V2RemoveTorrent(s))
+
+		apiV2.GET("/library/jobs", V2LibraryJobs)
+
+		apiV2.POST("/play", ExternalPlayJSON)
+	}
+
 	torrents := r.Group("/torrents")
 	{
 		torrents.GET("/", ListTorrents(s))
@@ -66,6 +112,14 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		torrents.GET("/pause/:torrentId", PauseTorrent(s))
 		torrents.GET("/resume/:torrentId", ResumeTorrent(s))
 		torrents.GET("/delete/:torrentId", RemoveTorrent(s))
+		torrents.GET("/why/:torrentId", WhyTorrent(s))
+		torrents.GET("/reannounce/:torrentId", ReannounceTorrent(s))
+		torrents.GET("/recheck/:torrentId", RecheckTorrent(s))
+		torrents.GET("/episodes/:torrentId", AssignEpisodeFile(s))
+		torrents.GET("/import", ImportTorrents(s))
+		torrents.GET("/superseed/:torrentId", SuperSeedTorrent(s))
+		torrents.GET("/uploadslots/:torrentId", UploadSlotsTorrent(s))
+		torrents.GET("/passkey", RotatePasskey(s))
 		torrents.GET("/downloadall/:torrentId", DownloadAllTorrent(s))
 		torrents.GET("/undownloadall/:torrentId", UnDownloadAllTorrent(s))
 		torrents.GET("/selectfile/:torrentId", SelectFileTorrent(s, true))
@@ -73,6 +127,8 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 
 		// Web UI json
 		torrents.GET("/list", ListTorrentsWeb(s))
+		torrents.GET("/peers/:torrentId", TorrentPeers(s))
+		torrents.GET("/ratio", TorrentsRatio(s))
 	}
 
 	movies := r.Group("/movies")
@@ -84,6 +140,12 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movies.GET("/popular/genre/:genre", PopularMovies)
 		movies.GET("/popular/language/:language", PopularMovies)
 		movies.GET("/popular/country/:country", PopularMovies)
+		movies.GET("/popular/provider/:provider", PopularMovies)
+		movies.GET("/trending/:window", TrendingMovies)
+		movies.GET("/discover", DiscoverMovies)
+		movies.GET("/discover/build", DiscoverMoviesBuild)
+		movies.GET("/upcoming", UpcomingMovies)
+		movies.GET("/recommendations", RecommendedMovies)
 		movies.GET("/recent", RecentMovies)
 		movies.GET("/recent/genre/:genre", RecentMovies)
 		movies.GET("/recent/language/:language", RecentMovies)
@@ -94,7 +156,19 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movies.GET("/genres", MovieGenres)
 		movies.GET("/languages", MovieLanguages)
 		movies.GET("/countries", MovieCountries)
+		movies.GET("/providers", MovieProviders)
+		movies.GET("/years", MovieDecades)
+		movies.GET("/years/:decade", MovieYears)
+		movies.GET("/year/:year", MoviesByYear)
+		movies.GET("/keyword/:keywordId", MoviesByKeyword)
+		movies.GET("/universes", MovieUniverses)
+		movies.GET("/universes/:universeId", MovieUniverse)
+		movies.GET("/collection/:collectionId", MovieCollection)
+		movies.GET("/collection/:collectionId/watched", MovieCollectionWatched(true))
+		movies.GET("/collection/:collectionId/unwatched", MovieCollectionWatched(false))
 		movies.GET("/library", MovieLibrary)
+		movies.GET("/tmdb/lists/", MoviesTMDBLists)
+		movies.GET("/tmdb/lists/:listId", TMDBListMovies)
 
 		trakt := movies.Group("/trakt")
 		{
@@ -144,6 +218,9 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movie.GET("/:tmdbId/watchlist/remove", RemoveMovieFromWatchlist)
 		movie.GET("/:tmdbId/collection/add", AddMovieToCollection)
 		movie.GET("/:tmdbId/collection/remove", RemoveMovieFromCollection)
+		movie.GET("/:tmdbId/tmdblist/add", AddMovieToTMDBList)
+		movie.GET("/:tmdbId/tmdblist/remove", RemoveMovieFromTMDBList)
+		movie.GET("/:tmdbId/similar", SimilarMovies)
 	}
 
 	shows := r.Group("/shows")
@@ -154,6 +231,8 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		shows.GET("/popular/genre/:genre", PopularShows)
 		shows.GET("/popular/language/:language", PopularShows)
 		shows.GET("/popular/country/:country", PopularShows)
+		shows.GET("/popular/provider/:provider", PopularShows)
+		shows.GET("/trending/:window", TrendingShows)
 		shows.GET("/recent/shows", RecentShows)
 		shows.GET("/recent/shows/genre/:genre", RecentShows)
 		shows.GET("/recent/shows/language/:language", RecentShows)
@@ -162,12 +241,21 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		shows.GET("/recent/episodes/genre/:genre", RecentEpisodes)
 		shows.GET("/recent/episodes/language/:language", RecentEpisodes)
 		shows.GET("/recent/episodes/country/:country", RecentEpisodes)
+		shows.GET("/airing", AiringShows)
+		shows.GET("/airing/today", AiringTodayShows)
 		shows.GET("/top", TopRatedShows)
 		shows.GET("/mostvoted", TVMostVoted)
 		shows.GET("/genres", TVGenres)
 		shows.GET("/languages", TVLanguages)
 		shows.GET("/countries", TVCountries)
+		shows.GET("/providers", TVProviders)
+		shows.GET("/years", TVDecades)
+		shows.GET("/years/:decade", TVYears)
+		shows.GET("/year/:year", ShowsByYear)
+		shows.GET("/keyword/:keywordId", ShowsByKeyword)
 		shows.GET("/library", TVLibrary)
+		shows.GET("/tmdb/lists/", TVTMDBLists)
+		shows.GET("/tmdb/lists/:listId", TMDBListShows)
 
 		trakt := shows.Group("/trakt")
 		{
@@ -204,6 +292,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	show := r.Group("/show")
 	{
 		show.GET("/:showId/seasons", ShowSeasons)
+		show.GET("/:showId/episodes", ShowAllEpisodes)
 		show.GET("/:showId/season/:season/download", ShowSeasonRun("download", s))
 		show.GET("/:showId/season/:season/download/*ident", ShowSeasonRun("download", s))
 		show.GET("/:showId/season/:season/links", ShowSeasonRun("links", s))
@@ -226,6 +315,11 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		show.GET("/:showId/watchlist/remove", RemoveShowFromWatchlist)
 		show.GET("/:showId/collection/add", AddShowToCollection)
 		show.GET("/:showId/collection/remove", RemoveShowFromCollection)
+		show.GET("/:showId/tmdblist/add", AddShowToTMDBList)
+		show.GET("/:showId/tmdblist/remove", RemoveShowFromTMDBList)
+		show.GET("/:showId/ordering", ShowEpisodeOrdering)
+		show.GET("/:showId/artwork", ShowArtwork)
+		show.GET("/:showId/similar", SimilarShows)
 	}
 	// TODO
 	// episode := r.Group("/episode")
@@ -245,6 +339,19 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		library.GET("/show/play/:showId/:season/:episode", PlayShow(s))
 
 		library.GET("/update", UpdateLibrary)
+		library.GET("/jobs", LibraryJobs)
+
+		library.GET("/import/imdb", ImportIMDb)
+		library.GET("/import/tvdb", ImportTVDB)
+
+		library.GET("/removed", RemovedItems)
+		library.GET("/removed/restore/:media/:tmdbId", RemovedItemRestore)
+		library.GET("/removed/clear", RemovedItemsClear)
+
+		library.GET("/failed", FailedItems)
+		library.GET("/failed/retry", FailedItemsRetry)
+		library.GET("/failed/retry/:media/:tmdbId", FailedItemRetry)
+		library.GET("/failed/dismiss/:media/:tmdbId", FailedItemDismiss)
 
 		// DEPRECATED
 		library.GET("/play/movie/:tmdbId", PlayMovie(s))
@@ -273,6 +380,8 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	{
 		allproviders.GET("/enable", ProvidersEnableAll)
 		allproviders.GET("/disable", ProvidersDisableAll)
+		allproviders.GET("/coverage", ProvidersCoverage)
+		allproviders.GET("/coverage/clear", ProvidersCoverageClear)
 	}
 
 	repo := r.Group("/repository")
@@ -288,12 +397,16 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		trakt.GET("/update", UpdateTrakt)
 	}
 
+	r.GET("/tmdb/authorize", AuthorizeTMDB)
+
 	r.GET("/setviewmode/:content_type", SetViewMode)
 
 	r.GET("/subtitles", SubtitlesIndex(s))
 	r.GET("/subtitle/:id", SubtitleGet)
+	r.POST("/subtitles/upload", SubtitleUpload(s))
 
 	r.GET("/play", Play(s))
+	r.GET("/playstream", PlayStream)
 	r.GET("/play/*ident", Play(s))
 	r.Any("/playuri", PlayURI(s))
 	r.Any("/playuri/*ident", PlayURI(s))
@@ -321,6 +434,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 
 		cmd.GET("/select_interface/:type", SelectNetworkInterface)
 		cmd.GET("/select_strm_language", SelectStrmLanguage)
+		cmd.GET("/compact_database", CompactDatabase)
 
 		database := cmd.Group("/database")
 		{
@@ -329,6 +443,9 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 			database.GET("/clear_torrent_history", ClearDatabaseTorrentHistory)
 			database.GET("/clear_search_history", ClearDatabaseSearchHistory)
 			database.GET("/clear_database", ClearDatabase)
+			database.GET("/export", ExportDatabase)
+			database.POST("/import", ImportDatabase)
+			database.GET("/restore", RestoreDatabase)
 		}
 
 		cache := cmd.Group("/cache")
@@ -336,6 +453,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 			cache.GET("/clear_tmdb", ClearCacheTMDB)
 			cache.GET("/clear_trakt", ClearCacheTrakt)
 			cache.GET("/clear_cache", ClearCache)
+			cache.GET("/invalidate", InvalidateCache)
 		}
 	}
 
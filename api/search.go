@@ -96,6 +96,9 @@ func Search(s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.AudioCodec > 0 {
 				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
 			}
+			if torrent.IsStream() {
+				info = append(info, strings.ToUpper(torrent.StreamType))
+			}
 			if torrent.Provider != "" {
 				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
 			}
@@ -125,6 +128,11 @@ func Search(s *bittorrent.Service) gin.HandlerFunc {
 		}
 
 		if choice >= 0 {
+			if torrents[choice].IsStream() {
+				xbmc.PlayURLWithTimeout(streamPlayURL(torrents[choice]))
+				return
+			}
+
 			AddToTorrentsMap(fakeTmdbID, torrents[choice])
 
 			xbmc.PlayURLWithTimeout(URLQuery(
@@ -258,3 +266,10 @@ func searchHistoryGetHTTPUrl(historyType string, query string) string {
 
 	return URLQuery(URLForHTTP(urlPrefix+"/search"), "q", query)
 }
+
+// SearchCancel stops running provider searches, it is called
+// by the plugin, when user dismisses the search dialog
+func SearchCancel(ctx *gin.Context) {
+	providers.CancelSearches()
+	ctx.String(200, "")
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// settingsEntry is a setting from settings.xml with its position in settings dialog
+type settingsEntry struct {
+	ID            string
+	Label         string
+	Category      string
+	CategoryIndex int
+	ControlIndex  int
+}
+
+// Title returns label of the setting with its category
+func (e *settingsEntry) Title() string {
+	return fmt.Sprintf("%s: %s", e.Category, e.Label)
+}
+
+// matches checks that each word of the query is in label, category or ID of the setting
+func (e *settingsEntry) matches(words []string) bool {
+	text := strings.ToLower(strings.Join([]string{e.Label, e.Category, strings.Replace(e.ID, "_", " ", -1)}, " "))
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// SettingsSearch finds settings by keywords, like "/settings/search?q=download path",
+// and opens settings dialog at the chosen setting. Setting could be opened directly
+// by its ID, like "/settings/search?id=download_path", to be used as a deep link.
+func SettingsSearch(ctx *gin.Context) {
+	ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+
+	entries, err := loadSettingsEntries()
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to read settings: %s", err))
+		return
+	}
+
+	if id := ctx.Query("id"); id != "" {
+		for _, e := range entries {
+			if e.ID == id {
+				openSettingsEntry(e)
+				ctx.String(200, "")
+				return
+			}
+		}
+		ctx.String(404, "Setting %s not found", id)
+		return
+	}
+
+	query := ctx.Query("q")
+	if query == "" {
+		if query = xbmc.Keyboard("", "LOCALIZE[30768]"); query == "" {
+			ctx.String(200, "")
+			return
+		}
+	}
+
+	matches := searchSettings(entries, query)
+	switch len(matches) {
+	case 0:
+		xbmc.Notify("projectx", "LOCALIZE[30769]", config.AddonIcon())
+	case 1:
+		openSettingsEntry(matches[0])
+	default:
+		titles := make([]string, 0, len(matches))
+		for _, e := range matches {
+			titles = append(titles, e.Title())
+		}
+		if choice := xbmc.ListDialog("LOCALIZE[30768]", titles...); choice >= 0 && choice < len(matches) {
+			openSettingsEntry(matches[choice])
+		}
+	}
+
+	ctx.String(200, "")
+}
+
+func searchSettings(entries []*settingsEntry, query string) []*settingsEntry {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	ret := []*settingsEntry{}
+	for _, e := range entries {
+		if e.matches(words) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+func openSettingsEntry(e *settingsEntry) {
+	log.Infof("Opening setting %s at category %d, control %d", e.ID, e.CategoryIndex, e.ControlIndex)
+	xbmc.AddonSettingsFocus("plugin.video.projectx", e.CategoryIndex, e.ControlIndex)
+}
+
+// loadSettingsEntries reads settings of the add-on, counting controls of each category,
+// as they are shown in settings dialog. Hidden settings and separators are not focusable.
+func loadSettingsEntries() ([]*settingsEntry, error) {
+	if config.Get().Info == nil {
+		return nil, errors.New("add-on info is not available")
+	}
+
+	f, err := os.Open(filepath.Join(config.Get().Info.Path, "resources", "settings.xml"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []*settingsEntry{}
+	category := ""
+	categoryIndex := -1
+	controlIndex := 0
+
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "category":
+			categoryIndex++
+			controlIndex = 0
+			category = settingsLabel(xmlAttr(element, "label"))
+		case "setting":
+			settingType := xmlAttr(element, "type")
+			if categoryIndex < 0 || settingType == "sep" || settingType == "lsep" || xmlAttr(element, "visible") == "false" {
+				continue
+			}
+
+			if id := xmlAttr(element, "id"); id != "" {
+				entries = append(entries, &settingsEntry{
+					ID:            id,
+					Label:         settingsLabel(xmlAttr(element, "label")),
+					Category:      category,
+					CategoryIndex: categoryIndex,
+					ControlIndex:  controlIndex,
+				})
+			}
+			controlIndex++
+		}
+	}
+
+	return entries, nil
+}
+
+// settingsLabel resolves label, given as string ID
+func settingsLabel(label string) string {
+	if id, err := strconv.Atoi(label); err == nil {
+		if str := xbmc.LocalizedString(id); str != "" {
+			return str
+		}
+	}
+	return label
+}
+
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
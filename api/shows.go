@@ -14,6 +14,7 @@ import (
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/parental"
 	"github.com/projectx13/projectx/providers"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/trakt"
@@ -42,11 +43,17 @@ func TVIndex(ctx *gin.Context) {
 
 		{Label: "LOCALIZE[30238]", Path: URLForXBMC("/shows/recent/episodes"), Thumbnail: config.AddonResource("img", "fresh.png")},
 		{Label: "LOCALIZE[30237]", Path: URLForXBMC("/shows/recent/shows"), Thumbnail: config.AddonResource("img", "clock.png")},
+		{Label: "LOCALIZE[30789]", Path: URLForXBMC("/shows/airing"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
+		{Label: "LOCALIZE[30790]", Path: URLForXBMC("/shows/airing/today"), Thumbnail: config.AddonResource("img", "fresh.png")},
+		{Label: "LOCALIZE[30726]", Path: URLForXBMC("/shows/trending/day"), Thumbnail: config.AddonResource("img", "trending.png")},
+		{Label: "LOCALIZE[30727]", Path: URLForXBMC("/shows/trending/week"), Thumbnail: config.AddonResource("img", "trending.png")},
 		{Label: "LOCALIZE[30210]", Path: URLForXBMC("/shows/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
 		{Label: "LOCALIZE[30211]", Path: URLForXBMC("/shows/top"), Thumbnail: config.AddonResource("img", "top_rated.png")},
 		{Label: "LOCALIZE[30212]", Path: URLForXBMC("/shows/mostvoted"), Thumbnail: config.AddonResource("img", "most_voted.png")},
 		{Label: "LOCALIZE[30289]", Path: URLForXBMC("/shows/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
 		{Label: "LOCALIZE[30373]", Path: URLForXBMC("/shows/languages"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
+		{Label: "LOCALIZE[30722]", Path: URLForXBMC("/shows/years"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
+		{Label: "LOCALIZE[30732]", Path: URLForXBMC("/shows/providers"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
 		// Note: Search by countries is implemented, but TMDB does not support it yet,
 		// so we are not showing this. When there is an endpoint - we can enable
 		// and modify the URL params to /discover endpoint
@@ -56,6 +63,12 @@ func TVIndex(ctx *gin.Context) {
 
 		{Label: "LOCALIZE[30517]", Path: URLForXBMC("/shows/library"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
 	}
+	if config.Get().WatchProviders != "" {
+		items = append(items, &xbmc.ListItem{Label: "LOCALIZE[30733]", Path: URLForXBMC("/shows/popular/provider/%s", config.Get().WatchProviders), Thumbnail: config.AddonResource("img", "genre_tv.png")})
+	}
+	if tmdb.ListsAuthorized() {
+		items = append(items, &xbmc.ListItem{Label: "LOCALIZE[30795]", Path: URLForXBMC("/shows/tmdb/lists/"), Thumbnail: config.AddonResource("img", "genre_tv.png")})
+	}
 	for _, item := range items {
 		item.ContextMenu = [][]string{
 			{"LOCALIZE[30143]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/menus_tvshows"))},
@@ -100,6 +113,24 @@ func TVGenres(ctx *gin.Context) {
 	ctx.JSON(200, xbmc.NewView("menus_tvshows_genres", filterListItems(items)))
 }
 
+// TVProviders lists streaming services, available in the watch region
+func TVProviders(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0)
+	for _, provider := range tmdb.GetWatchProviders("tv", config.Get().WatchRegion, config.Get().Language) {
+		items = append(items, &xbmc.ListItem{
+			Label:     provider.Name,
+			Path:      URLForXBMC("/shows/popular/provider/%d", provider.ID),
+			Thumbnail: tmdb.ArtURL(provider.LogoPath, tmdb.ArtLogo),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/menus_tvshows_providers"))},
+			},
+		})
+	}
+	ctx.JSON(200, xbmc.NewView("menus_tvshows_providers", filterListItems(items)))
+}
+
 // TVLanguages ...
 func TVLanguages(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -216,6 +247,13 @@ func CalendarShows(ctx *gin.Context) {
 }
 
 func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query string) {
+	items := showListItems(ctx, shows, page, total, query)
+	ctx.JSON(200, xbmc.NewView("tvshows", sortListItems(ctx, filterListItems(items))))
+}
+
+// showListItems returns list items of shows with context menus, and next page item,
+// when page is positive and there are more results
+func showListItems(ctx *gin.Context, shows tmdb.Shows, page int, total int, query string) xbmc.ListItems {
 	hasNextPage := 0
 	if page > 0 {
 		if page*config.Get().ResultsPerPage < total {
@@ -226,11 +264,14 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 	items := make(xbmc.ListItems, 0, len(shows)+hasNextPage)
 
 	for _, show := range shows {
-		if show == nil {
+		if show == nil || !parental.AllowShow(show) {
 			continue
 		}
 		item := show.ToListItem()
 		item.Path = URLForXBMC("/show/%d/seasons", show.ID)
+		if region, ok := regionOverride(ctx); ok {
+			item.Info.MPAA = show.CertificationLabel(region)
+		}
 
 		tmdbID := strconv.Itoa(show.ID)
 		libraryActions := [][]string{}
@@ -254,8 +295,12 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 		item.ContextMenu = [][]string{
 			watchlistAction,
 			collectionAction,
+			{"LOCALIZE[30765]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/ordering", show.ID))},
+			{"LOCALIZE[30809]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/artwork", show.ID))},
+			{"LOCALIZE[30779]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/show/%d/similar", show.ID))},
 			{"LOCALIZE[30035]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/tvshows"))},
 		}
+		item.ContextMenu = append(item.ContextMenu, tmdbListActions("show", show.ID)...)
 		item.ContextMenu = append(libraryActions, item.ContextMenu...)
 
 		if config.Get().Platform.Kodi < 17 {
@@ -271,6 +316,8 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 		nextPath := URLForXBMC(fmt.Sprintf("%s?page=%d", path, page+1))
 		if query != "" {
 			nextPath = URLForXBMC(fmt.Sprintf("%s?q=%s&page=%d", path, query, page+1))
+		} else if region, ok := regionOverride(ctx); ok {
+			nextPath = URLForXBMC(fmt.Sprintf("%s?page=%d&content_region=%s", path, page+1, region))
 		}
 		next := &xbmc.ListItem{
 			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
@@ -279,7 +326,27 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 		}
 		items = append(items, next)
 	}
-	ctx.JSON(200, xbmc.NewView("tvshows", filterListItems(items)))
+	return items
+}
+
+// ShowsByKeyword lists popular shows with TMDB keyword
+func ShowsByKeyword(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	keywordID, _ := strconv.Atoi(ctx.Params.ByName("keywordId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.ShowsByKeywords([]int{keywordID}, config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
+
+// SimilarShows lists shows, which share keywords with the show
+func SimilarShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.SimilarShowsByKeywords(showID, config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
 }
 
 // PopularShows ...
@@ -290,6 +357,7 @@ func PopularShows(ctx *gin.Context) {
 	p.Genre = ctx.Params.ByName("genre")
 	p.Language = ctx.Params.ByName("language")
 	p.Country = ctx.Params.ByName("country")
+	p.Providers = ctx.Params.ByName("provider")
 	if p.Genre == "0" {
 		p.Genre = ""
 	}
@@ -299,6 +367,20 @@ func PopularShows(ctx *gin.Context) {
 	renderShows(ctx, shows, page, total, "")
 }
 
+// TrendingShows ...
+func TrendingShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	window := ctx.Params.ByName("window")
+	if window != "week" {
+		window = "day"
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.GetTrendingShows(window, config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
+
 // RecentShows ...
 func RecentShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -386,6 +468,11 @@ func ShowSeasons(ctx *gin.Context) {
 		ctx.Error(errors.New("Unable to find show"))
 		return
 	}
+	tmdb.PrefetchSeasons(show, config.Get().Language)
+
+	if ctx.Query("list") != "seasons" && openShowAtNextEpisode(ctx, show) {
+		return
+	}
 
 	items := show.Seasons.ToListItems(show)
 	reversedItems := make(xbmc.ListItems, 0)
@@ -425,7 +512,7 @@ func ShowSeasons(ctx *gin.Context) {
 
 	// xbmc.ListItems always returns false to Less() so that order is unchanged
 
-	ctx.JSON(200, xbmc.NewView("seasons", filterListItems(reversedItems)))
+	ctx.JSON(200, xbmc.NewView("seasons", sortListItems(ctx, filterListItems(reversedItems))))
 }
 
 // ShowEpisodes ...
@@ -459,12 +546,107 @@ func ShowEpisodes(ctx *gin.Context) {
 		}
 	}
 
+	episodes, err := showEpisodeItems(show, seasonsToShow, language)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, xbmc.NewView("episodes", sortListItems(ctx, filterListItems(episodes))))
+}
+
+// ShowAllEpisodes lists episodes of all seasons as one flat list, split into pages,
+// only seasons, needed for the page, are fetched
+func ShowAllEpisodes(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	language := config.Get().Language
+
+	show := tmdb.GetShow(showID, language)
+	if show == nil {
+		ctx.Error(errors.New("Unable to find show"))
+		return
+	}
+
+	seasons := make(tmdb.SeasonList, 0, len(show.Seasons))
+	for _, s := range show.Seasons {
+		if s != nil && s.EpisodeCount > 0 {
+			seasons = append(seasons, s)
+		}
+	}
+	// Specials go after regular seasons
+	sort.Slice(seasons, func(i, j int) bool {
+		if (seasons[i].Season == 0) != (seasons[j].Season == 0) {
+			return seasons[j].Season == 0
+		}
+		return seasons[i].Season < seasons[j].Season
+	})
+
+	perPage := config.Get().ResultsPerPage
+	start := (page - 1) * perPage
+	end := start + perPage
+
+	seasonsToShow := []int{}
+	offset := 0
+	skipped := 0
+	total := 0
+	for _, s := range seasons {
+		if offset+s.EpisodeCount > start && offset < end {
+			seasonsToShow = append(seasonsToShow, s.Season)
+		} else if offset+s.EpisodeCount <= start {
+			skipped += s.EpisodeCount
+		}
+		offset += s.EpisodeCount
+		total += s.EpisodeCount
+	}
+
+	episodes, err := showEpisodeItems(show, seasonsToShow, language)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Episode counts of seasons can differ from listed episodes, like with hidden unaired episodes
+	from := start - skipped
+	if from > len(episodes) {
+		from = len(episodes)
+	}
+	to := from + perPage
+	if to > len(episodes) {
+		to = len(episodes)
+	}
+	items := episodes[from:to]
+
+	for _, item := range items {
+		item.Label = fmt.Sprintf("S%02dE%02d. %s", item.Info.Season, item.Info.Episode, item.Label)
+	}
+
+	if end < total {
+		items = append(items, &xbmc.ListItem{
+			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
+			Path:      URLForXBMC(fmt.Sprintf("%s?page=%d", ctx.Request.URL.Path, page+1)),
+			Thumbnail: config.AddonResource("img", "nextpage.png"),
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("episodes", sortListItems(ctx, filterListItems(items))))
+}
+
+// showEpisodeItems returns list items of episodes of selected seasons
+func showEpisodeItems(show *tmdb.Show, seasonsToShow []int, language string) (xbmc.ListItems, error) {
+	ordering := tmdb.GetEpisodeOrdering(show.ID, language)
+
 	episodes := make(xbmc.ListItems, 0)
 	for _, seasonNumber := range seasonsToShow {
-		season := tmdb.GetSeason(showID, seasonNumber, language, len(show.Seasons))
+		season := tmdb.GetSeason(show.ID, seasonNumber, language, len(show.Seasons))
 		if season == nil {
-			ctx.Error(errors.New("Unable to find season"))
-			return
+			return nil, errors.New("Unable to find season")
 		}
 
 		items := season.Episodes.ToListItems(show, season)
@@ -487,6 +669,7 @@ func ShowEpisodes(ctx *gin.Context) {
 			if config.Get().Platform.Kodi < 17 {
 				item.ContextMenu = [][]string{
 					{contextLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextURL)},
+					{"LOCALIZE[30724]", fmt.Sprintf("XBMC.PlayMedia(%s)", URLQuery(item.Path, "audio", "true"))},
 					{"LOCALIZE[30203]", "XBMC.Action(Info)"},
 					{"LOCALIZE[30268]", "XBMC.Action(ToggleWatched)"},
 					{"LOCALIZE[30037]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/episodes"))},
@@ -494,16 +677,128 @@ func ShowEpisodes(ctx *gin.Context) {
 			} else {
 				item.ContextMenu = [][]string{
 					{contextLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextURL)},
+					{"LOCALIZE[30724]", fmt.Sprintf("XBMC.PlayMedia(%s)", URLQuery(item.Path, "audio", "true"))},
 					{"LOCALIZE[30037]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/episodes"))},
 				}
 			}
 			item.IsPlayable = true
+
+			// Paths keep aired numbers, only shown numbers follow chosen ordering
+			if s, e, ok := ordering.Number(seasonNumber, item.Info.Episode); ok {
+				item.Info.Season = s
+				item.Info.Episode = e
+			}
 		}
 
 		episodes = append(episodes, items...)
 	}
 
-	ctx.JSON(200, xbmc.NewView("episodes", filterListItems(episodes)))
+	return episodes, nil
+}
+
+// ShowEpisodeOrdering lets user choose TMDB episode group, used to number episodes of the show
+func ShowEpisodeOrdering(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	groups := tmdb.GetEpisodeGroups(showID, config.Get().Language)
+	if len(groups) == 0 {
+		xbmc.Notify("projectx", "LOCALIZE[30766]", config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	current := database.GetStorm().GetEpisodeOrdering(showID)
+	items := []string{"LOCALIZE[30767]"}
+	for _, g := range groups {
+		label := fmt.Sprintf("%s (%d)", g.Name, g.EpisodeCount)
+		if g.ID == current {
+			label = "[B]" + label + "[/B]"
+		}
+		items = append(items, label)
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30765]", items...)
+	if choice < 0 || choice >= len(items) {
+		ctx.String(200, "")
+		return
+	}
+
+	groupID := ""
+	if choice > 0 {
+		groupID = groups[choice-1].ID
+	}
+	if err := database.GetStorm().SetEpisodeOrdering(showID, groupID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	xbmc.Refresh()
+	ctx.String(200, "")
+}
+
+// ShowArtwork lets user choose poster, fanart and logo of the show from TMDB images
+func ShowArtwork(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+	defer ctx.String(200, "")
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	images := tmdb.GetShowImages(showID)
+	if images == nil {
+		xbmc.Notify("projectx", "LOCALIZE[30814]", config.AddonIcon())
+		return
+	}
+
+	kind := xbmc.ListDialog("LOCALIZE[30809]", "LOCALIZE[30810]", "LOCALIZE[30811]", "LOCALIZE[30812]", "LOCALIZE[30813]")
+	if kind < 0 {
+		return
+	}
+
+	artwork := database.GetStorm().GetArtwork(showID)
+	if artwork == nil {
+		artwork = &database.Artwork{ShowID: showID}
+	}
+
+	if kind == 3 {
+		artwork = &database.Artwork{ShowID: showID}
+	} else {
+		language := config.Get().Language
+		options := [][]*tmdb.Image{images.PosterImages(language), images.BackdropImages(language), images.LogoImages(language)}[kind]
+		if len(options) == 0 {
+			xbmc.Notify("projectx", "LOCALIZE[30814]", config.AddonIcon())
+			return
+		}
+
+		labels := make([]string, 0, len(options))
+		for i, img := range options {
+			lang := strings.ToUpper(img.Iso639_1)
+			if lang == "" {
+				lang = "-"
+			}
+			labels = append(labels, fmt.Sprintf("%d. [%s] %dx%d, %.1f", i+1, lang, img.Width, img.Height, img.VoteAverage))
+		}
+		choice := xbmc.ListDialog("LOCALIZE[30809]", labels...)
+		if choice < 0 || choice >= len(options) {
+			return
+		}
+
+		switch kind {
+		case 0:
+			artwork.Poster = options[choice].FilePath
+		case 1:
+			artwork.FanArt = options[choice].FilePath
+		case 2:
+			artwork.ClearLogo = options[choice].FilePath
+		}
+	}
+
+	if err := database.GetStorm().SetArtwork(artwork); err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		return
+	}
+
+	library.ClearPageCache()
+	xbmc.Refresh()
 }
 
 func showSeasonLinks(showID int, seasonNumber int) ([]*bittorrent.TorrentFile, error) {
@@ -564,6 +859,8 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		if show == nil {
 			ctx.Error(errors.New("Unable to find show"))
 			return
+		} else if parentalBlocked(parental.AllowShow(show)) {
+			return
 		}
 
 		season := tmdb.GetSeason(showID, seasonNumber, config.Get().Language, len(show.Seasons))
@@ -630,6 +927,10 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		// Bitrate, needed for streaming, is compared with connection speed
+		duration := season.Duration(show)
+		speed := bittorrent.ConnectionSpeed()
+
 		choices := make([]string, 0, len(torrents))
 		for _, torrent := range torrents {
 			resolution := ""
@@ -641,6 +942,9 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.Size != "" {
 				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
 			}
+			if hint := bandwidthHint(torrent, duration, speed); hint != "" {
+				info = append(info, hint)
+			}
 			if torrent.RipType > 0 {
 				info = append(info, bittorrent.Rips[torrent.RipType])
 			}
@@ -650,6 +954,9 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.AudioCodec > 0 {
 				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
 			}
+			if torrent.IsStream() {
+				info = append(info, strings.ToUpper(torrent.StreamType))
+			}
 			if torrent.Provider != "" {
 				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
 			}
@@ -688,6 +995,9 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"show", tmdbID,
 				"season", ctx.Params.ByName("season"),
 				"type", "episode")
+			if torrents[choice].IsStream() {
+				rURL = streamPlayURL(torrents[choice])
+			}
 
 			if external != "" {
 				xbmc.PlayURL(rURL)
@@ -741,6 +1051,7 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
 		external := ctx.Query("external")
 		doresume := ctx.DefaultQuery("doresume", "true")
+		audio := ctx.DefaultQuery("audio", "false")
 
 		runAction := "/play"
 		if action == "download" {
@@ -751,6 +1062,8 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		if show == nil {
 			ctx.Error(errors.New("Unable to find show"))
 			return
+		} else if parentalBlocked(parental.AllowShow(show)) {
+			return
 		}
 
 		episode := tmdb.GetEpisode(showID, seasonNumber, episodeNumber, config.Get().Language)
@@ -770,7 +1083,8 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"show", tmdbID,
 				"season", ctx.Params.ByName("season"),
 				"episode", ctx.Params.ByName("episode"),
-				"type", "episode")
+				"type", "episode",
+				"audio", audio)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -787,7 +1101,8 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"show", tmdbID,
 				"season", ctx.Params.ByName("season"),
 				"episode", ctx.Params.ByName("episode"),
-				"type", "episode")
+				"type", "episode",
+				"audio", audio)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -816,6 +1131,10 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		// Bitrate, needed for streaming, is compared with connection speed
+		duration := episode.Duration(show)
+		speed := bittorrent.ConnectionSpeed()
+
 		choices := make([]string, 0, len(torrents))
 		for _, torrent := range torrents {
 			resolution := ""
@@ -827,6 +1146,12 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.Size != "" {
 				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
 			}
+			// Size of season packs is not the size of the episode, so bitrate is not estimated for them
+			if torrent.MatchesEpisode(seasonNumber, episodeNumber) {
+				if hint := bandwidthHint(torrent, duration, speed); hint != "" {
+					info = append(info, hint)
+				}
+			}
 			if torrent.RipType > 0 {
 				info = append(info, bittorrent.Rips[torrent.RipType])
 			}
@@ -836,6 +1161,9 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			if torrent.AudioCodec > 0 {
 				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
 			}
+			if torrent.IsStream() {
+				info = append(info, strings.ToUpper(torrent.StreamType))
+			}
 			if torrent.Provider != "" {
 				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
 			}
@@ -874,7 +1202,11 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"show", tmdbID,
 				"season", ctx.Params.ByName("season"),
 				"episode", ctx.Params.ByName("episode"),
-				"type", "episode")
+				"type", "episode",
+				"audio", audio)
+			if torrents[choice].IsStream() {
+				rURL = streamPlayURL(torrents[choice])
+			}
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
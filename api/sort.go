@@ -0,0 +1,144 @@
+package api
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	sortDefault   = "default"
+	sortTitle     = "title"
+	sortYear      = "year"
+	sortRating    = "rating"
+	sortDateAdded = "dateadded"
+	sortLastAired = "lastaired"
+	sortPremiered = "premiered"
+	sortCountry   = "country"
+	sortLanguage  = "language"
+	sortStudio    = "studio"
+	sortStatus    = "status"
+	sortRandom    = "random"
+
+	sortPreferenceExpiration = 365 * 24 * 60 * 60
+)
+
+var sortOrders = map[string]func(a, b *xbmc.ListItem) bool{
+	sortTitle: func(a, b *xbmc.ListItem) bool {
+		return strings.ToLower(itemSortTitle(a)) < strings.ToLower(itemSortTitle(b))
+	},
+	sortYear: func(a, b *xbmc.ListItem) bool {
+		return a.Info.Year > b.Info.Year
+	},
+	sortRating: func(a, b *xbmc.ListItem) bool {
+		return a.Info.Rating > b.Info.Rating
+	},
+	sortDateAdded: func(a, b *xbmc.ListItem) bool {
+		return itemDateAdded(a) > itemDateAdded(b)
+	},
+	sortLastAired: func(a, b *xbmc.ListItem) bool {
+		return itemAired(a) > itemAired(b)
+	},
+	sortPremiered: func(a, b *xbmc.ListItem) bool {
+		return a.Info.Premiered > b.Info.Premiered
+	},
+	sortCountry: func(a, b *xbmc.ListItem) bool {
+		return lessKnown(a.Info.Country, b.Info.Country)
+	},
+	sortLanguage: func(a, b *xbmc.ListItem) bool {
+		return lessKnown(a.Properties["original_language"], b.Properties["original_language"])
+	},
+	sortStudio: func(a, b *xbmc.ListItem) bool {
+		return lessKnown(strings.ToLower(a.Info.Studio), strings.ToLower(b.Info.Studio))
+	},
+	sortStatus: func(a, b *xbmc.ListItem) bool {
+		return lessKnown(a.Info.Status, b.Info.Status)
+	},
+}
+
+// lessKnown compares values alphabetically, items without value go last
+func lessKnown(a, b string) bool {
+	if a == "" || b == "" {
+		return a != "" && b == ""
+	}
+	return a < b
+}
+
+func itemSortTitle(i *xbmc.ListItem) string {
+	if i.Info.SortTitle != "" {
+		return i.Info.SortTitle
+	} else if i.Info.Title != "" {
+		return i.Info.Title
+	}
+	return i.Label
+}
+
+func itemDateAdded(i *xbmc.ListItem) string {
+	if i.Info.DateAdded != "" {
+		return i.Info.DateAdded
+	}
+	return i.Info.Date
+}
+
+func itemAired(i *xbmc.ListItem) string {
+	if i.Info.Aired != "" {
+		return i.Info.Aired
+	}
+	return i.Info.Premiered
+}
+
+func sortPreferenceKey(ctx *gin.Context) string {
+	return "list.sort." + ctx.Request.URL.Path
+}
+
+// listSortOrder returns sort order from "sort" query parameter, which is remembered
+// for the list, or previously selected order for the list
+func listSortOrder(ctx *gin.Context) string {
+	key := sortPreferenceKey(ctx)
+	order := strings.ToLower(ctx.Query("sort"))
+	if order == sortDefault {
+		database.GetCache().Delete(database.CommonBucket, key)
+		return ""
+	} else if _, ok := sortOrders[order]; ok || order == sortRandom {
+		database.GetCache().SetCached(database.CommonBucket, sortPreferenceExpiration, key, order)
+		return order
+	}
+
+	order, _ = database.GetCache().GetCached(database.CommonBucket, key)
+	return order
+}
+
+// sortListItems sorts media items of the list with selected sort order,
+// other items, like next page, are kept at the end in original order.
+// Watched items are then arranged with selected view mode.
+func sortListItems(ctx *gin.Context, items xbmc.ListItems) xbmc.ListItems {
+	order := listSortOrder(ctx)
+	if order == "" {
+		return viewListItems(ctx, items)
+	}
+
+	media := make(xbmc.ListItems, 0, len(items))
+	others := xbmc.ListItems{}
+	for _, i := range items {
+		if i.Info != nil && i.Info.Mediatype != "" {
+			media = append(media, i)
+		} else {
+			others = append(others, i)
+		}
+	}
+
+	if order == sortRandom {
+		rand.Shuffle(len(media), media.Swap)
+	} else if less, ok := sortOrders[order]; ok {
+		sort.SliceStable(media, func(i, j int) bool {
+			return less(media[i], media[j])
+		})
+	}
+
+	return viewListItems(ctx, append(media, others...))
+}
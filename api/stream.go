@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// streamPlayURL returns URL to play the stream, which provider returned instead of torrent
+func streamPlayURL(t *bittorrent.TorrentFile) string {
+	return URLQuery(URLForXBMC("/playstream"),
+		"uri", t.URI,
+		"stream_type", t.StreamType,
+		"headers", t.EncodedHeaders(),
+		"license_key", t.LicenseKey)
+}
+
+// PlayStream hands the stream from provider to Kodi. HLS and video files are resolved
+// with redirect, protected streams and DASH are opened with inputstream.adaptive properties.
+func PlayStream(ctx *gin.Context) {
+	t := &bittorrent.TorrentFile{
+		URI:        ctx.Query("uri"),
+		StreamType: ctx.Query("stream_type"),
+		LicenseKey: ctx.Query("license_key"),
+	}
+	if headers, err := url.ParseQuery(ctx.Query("headers")); err == nil && len(headers) > 0 {
+		t.Headers = map[string]string{}
+		for k := range headers {
+			t.Headers[k] = headers.Get(k)
+		}
+	}
+
+	if t.URI == "" || !t.IsStream() {
+		ctx.String(400, "Missing stream URI")
+		return
+	}
+
+	log.Infof("Playing %s stream: %s", t.StreamType, t.URI)
+	if !t.NeedsInputStream() {
+		ctx.Redirect(302, t.StreamURL())
+		return
+	}
+
+	item := &xbmc.ListItem{
+		Path:       t.URI,
+		IsPlayable: true,
+		Properties: t.StreamProperties(),
+	}
+	xbmc.PlayURLWithListItem(t.URI, item)
+	ctx.String(200, "")
+}
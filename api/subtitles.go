@@ -1,7 +1,9 @@
 package api
 
 import (
+	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -17,6 +19,9 @@ import (
 
 var subLog = logging.MustGetLogger("subtitles")
 
+// subtitlesUploadLimit is much bigger, than any subtitles file, it keeps uploads from filling the disk
+const subtitlesUploadLimit = 5 << 20
+
 // SubtitlesIndex ...
 func SubtitlesIndex(s *bittorrent.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -88,3 +93,57 @@ func SubtitleGet(ctx *gin.Context) {
 		{Label: file, Path: outFile.Name()},
 	}))
 }
+
+// SubtitleUpload attaches uploaded subtitles file to the playing video and turns it on,
+// for subtitles, which are not found by providers, like "curl -F file=@movie.srt http://host:port/subtitles/upload".
+// Browser uploads are accepted from the same origins, as JSON API requests.
+func SubtitleUpload(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if origin := ctx.GetHeader("Origin"); origin != "" {
+			if !v2OriginAllowed(origin, ctx.Request.Host) {
+				subLog.Warningf("Blocked subtitles upload from %s", origin)
+				ctx.String(403, "Origin %s is not allowed", origin)
+				return
+			}
+			ctx.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			ctx.Writer.Header().Add("Vary", "Origin")
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, subtitlesUploadLimit)
+
+		file, header, err := ctx.Request.FormFile("file")
+		if err != nil {
+			ctx.String(400, "Missing subtitles file: %s", err)
+			return
+		}
+		defer file.Close()
+
+		name := filepath.Base(header.Filename)
+		if !util.IsSubtitlesExt(filepath.Ext(name)) {
+			ctx.String(400, "Unsupported subtitles file %s", name)
+			return
+		} else if xbmc.PlayerGetActive() < 0 {
+			ctx.String(404, "No video is playing")
+			return
+		}
+
+		_, path, err := osdb.SaveSubtitles(name, file)
+		if err != nil {
+			subLog.Errorf("Could not save uploaded subtitles: %s", err)
+			ctx.String(500, err.Error())
+			return
+		}
+		if player := s.GetActivePlayer(); player != nil {
+			player.AddSubtitles(path)
+		}
+
+		subLog.Infof("Adding uploaded subtitles %s", path)
+		if err := xbmc.PlayerAddSubtitles(path); err != nil {
+			subLog.Errorf("Could not add uploaded subtitles: %s", err)
+			ctx.String(500, err.Error())
+			return
+		}
+
+		xbmc.Notify("projectx", "LOCALIZE[30787]", config.AddonIcon())
+		ctx.String(200, "")
+	}
+}
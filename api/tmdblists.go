@@ -0,0 +1,161 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// AuthorizeTMDB asks user to approve access to TMDB lists
+func AuthorizeTMDB(ctx *gin.Context) {
+	if err := tmdb.AuthorizeLists(true); err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+	}
+	ctx.String(200, "")
+}
+
+// MoviesTMDBLists ...
+func MoviesTMDBLists(ctx *gin.Context) {
+	renderTMDBLists(ctx, "movie", "/movies/tmdb/lists/%d", &MovieMenu, "menus_movies")
+}
+
+// TVTMDBLists ...
+func TVTMDBLists(ctx *gin.Context) {
+	renderTMDBLists(ctx, "shows", "/shows/tmdb/lists/%d", &TVMenu, "menus_tvshows")
+}
+
+func renderTMDBLists(ctx *gin.Context, menuType string, linkFormat string, menu *Menu, view string) {
+	defer perf.ScopeTimer()()
+
+	lists, err := tmdb.UserLists()
+	if err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+	}
+
+	sort.Slice(lists, func(i int, j int) bool {
+		return lists[i].Name < lists[j].Name
+	})
+
+	items := xbmc.ListItems{}
+	for _, list := range lists {
+		link := URLForXBMC(linkFormat, list.ID)
+		menuItem := []string{"LOCALIZE[30520]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/menu/%s/add", menuType), "name", list.Name, "link", link))}
+		if menu.Contains(addAction, &MenuItem{Name: list.Name, Link: link}) {
+			menuItem = []string{"LOCALIZE[30521]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/menu/%s/remove", menuType), "name", list.Name, "link", link))}
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     list.Name,
+			Path:      link,
+			Thumbnail: config.AddonResource("img", "movies.png"),
+			ContextMenu: [][]string{
+				menuItem,
+			},
+		})
+	}
+	ctx.JSON(200, xbmc.NewView(view, filterListItems(items)))
+}
+
+// TMDBListMovies ...
+func TMDBListMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.ListMovies(ctx.Params.ByName("listId"), config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// TMDBListShows ...
+func TMDBListShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.ListShows(ctx.Params.ByName("listId"), config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
+
+// AddMovieToTMDBList ...
+func AddMovieToTMDBList(ctx *gin.Context) {
+	changeTMDBList(ctx, "tmdbId", "movie", true)
+}
+
+// RemoveMovieFromTMDBList ...
+func RemoveMovieFromTMDBList(ctx *gin.Context) {
+	changeTMDBList(ctx, "tmdbId", "movie", false)
+}
+
+// AddShowToTMDBList ...
+func AddShowToTMDBList(ctx *gin.Context) {
+	changeTMDBList(ctx, "showId", "tv", true)
+}
+
+// RemoveShowFromTMDBList ...
+func RemoveShowFromTMDBList(ctx *gin.Context) {
+	changeTMDBList(ctx, "showId", "tv", false)
+}
+
+// changeTMDBList asks which of user's lists to change, and adds or removes the item
+func changeTMDBList(ctx *gin.Context, param string, mediaType string, add bool) {
+	defer perf.ScopeTimer()()
+	defer ctx.String(200, "")
+
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName(param))
+	lists, err := tmdb.UserLists()
+	if err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		return
+	} else if len(lists) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(lists))
+	for _, l := range lists {
+		names = append(names, l.Name)
+	}
+	title := "LOCALIZE[30796]"
+	if !add {
+		title = "LOCALIZE[30797]"
+	}
+	choice := xbmc.ListDialog(title, names...)
+	if choice < 0 {
+		return
+	}
+
+	listID := strconv.Itoa(lists[choice].ID)
+	if add {
+		err = tmdb.AddToList(listID, mediaType, tmdbID)
+	} else {
+		err = tmdb.RemoveFromList(listID, mediaType, tmdbID)
+	}
+	if err != nil {
+		xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if add {
+		xbmc.Notify("projectx", "LOCALIZE[30800]", config.AddonIcon())
+	} else {
+		xbmc.Notify("projectx", "LOCALIZE[30801]", config.AddonIcon())
+	}
+	library.ClearPageCache()
+}
+
+// tmdbListActions returns context menu actions to change user's TMDB lists, if they are authorized
+func tmdbListActions(mediaPath string, tmdbID int) [][]string {
+	if !tmdb.ListsAuthorized() {
+		return nil
+	}
+
+	return [][]string{
+		{"LOCALIZE[30796]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/%s/%d/tmdblist/add", mediaPath, tmdbID))},
+		{"LOCALIZE[30797]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/%s/%d/tmdblist/remove", mediaPath, tmdbID))},
+	}
+}
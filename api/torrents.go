@@ -22,6 +22,8 @@ import (
 	"github.com/projectx13/projectx/bittorrent"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/providers"
+	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/util"
 	"github.com/projectx13/projectx/xbmc"
 )
@@ -49,6 +51,7 @@ type TorrentsWeb struct {
 	SeedersTotal  int     `json:"seeders_total"`
 	Peers         int     `json:"peers"`
 	PeersTotal    int     `json:"peers_total"`
+	TrackerError  string  `json:"tracker_error"`
 }
 
 // AddToTorrentsMap ...
@@ -175,8 +178,13 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 		defer perf.ScopeTimer()()
 
 		items := make(xbmc.ListItems, 0, len(s.GetTorrents()))
+		importItem := &xbmc.ListItem{
+			Label:      "LOCALIZE[30804]",
+			Path:       URLForXBMC("/torrents/import"),
+			IsPlayable: false,
+		}
 		if len(s.GetTorrents()) == 0 {
-			ctx.JSON(200, xbmc.NewView("", items))
+			ctx.JSON(200, xbmc.NewView("", append(items, importItem)))
 			return
 		}
 
@@ -224,8 +232,16 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 
 			playURL := t.GetPlayURL("")
 
+			label := fmt.Sprintf("%.2f%% - [COLOR %s]%s[/COLOR] - %s", progress, color, status, torrentName)
+			if trackerError := t.TrackerError(); trackerError != "" {
+				label += fmt.Sprintf(" - [COLOR red]%s[/COLOR]", trackerError)
+			}
+			if corrupt := t.CorruptPieces(); corrupt > 0 {
+				label += fmt.Sprintf(" - [COLOR red]%d corrupt pieces[/COLOR]", corrupt)
+			}
+
 			item := xbmc.ListItem{
-				Label: fmt.Sprintf("%.2f%% - [COLOR %s]%s[/COLOR] - %s", progress, color, status, torrentName),
+				Label: label,
 				Path:  playURL,
 				Info: &xbmc.ListItemInfo{
 					Title: torrentName,
@@ -238,12 +254,31 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 				{"LOCALIZE[30232]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/delete/%s", t.InfoHash()))},
 				{"LOCALIZE[30276]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/delete/%s?files=true", t.InfoHash()))},
 				{"LOCALIZE[30308]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/move/%s", t.InfoHash()))},
+				{"LOCALIZE[30716]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/why/%s", t.InfoHash()))},
+				{"LOCALIZE[30763]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/reannounce/%s", t.InfoHash()))},
 				sessionAction,
 			}
 
+			if progress >= 100 {
+				if t.IsSuperSeeding() {
+					item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30729]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/superseed/%s?enable=false", t.InfoHash()))})
+				} else {
+					item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30728]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/superseed/%s?enable=true", t.InfoHash()))})
+				}
+				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30730]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/uploadslots/%s", t.InfoHash()))})
+			}
+
+			if t.IsPrivate() && len(bittorrent.GetTrackerRules()) > 0 {
+				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30735]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/passkey"))})
+			}
+
 			if !t.IsMemoryStorage() {
+				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30803]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/recheck/%s", t.InfoHash()))})
 				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30573]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/selectfile/%s", t.InfoHash()))})
 				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30612]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/downloadfile/%s", t.InfoHash()))})
+				if dbItem := t.GetDBItem(); dbItem != nil && dbItem.ShowID != 0 && len(t.GetFiles()) > 1 {
+					item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30815]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/episodes/%s", t.InfoHash()))})
+				}
 
 				if t.HasAvailableFiles() {
 					item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30531]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/downloadall/%s", t.InfoHash()))})
@@ -256,7 +291,7 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 			items = append(items, &item)
 		}
 
-		ctx.JSON(200, xbmc.NewView("", items))
+		ctx.JSON(200, xbmc.NewView("", append(items, importItem)))
 	}
 }
 
@@ -337,6 +372,7 @@ func ListTorrentsWeb(s *bittorrent.Service) gin.HandlerFunc {
 				SeedersTotal:  seedersTotal,
 				Peers:         peers,
 				PeersTotal:    peersTotal,
+				TrackerError:  t.TrackerError(),
 			}
 			torrents = append(torrents, ti)
 		}
@@ -346,6 +382,33 @@ func ListTorrentsWeb(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// TorrentPeers returns connected peers of the torrent with country and client breakdown
+func TorrentPeers(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.JSON(200, torrent.GetPeerStats())
+	}
+}
+
+// TorrentsRatio returns ratio and seeding obligations of torrents from private trackers
+func TorrentsRatio(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.JSON(200, s.GetRatioStats())
+	}
+}
+
 // PauseSession ...
 func PauseSession(s *bittorrent.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -425,6 +488,46 @@ func AddTorrent(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// ImportTorrents adopts torrents of another client, which have all files downloaded
+func ImportTorrents(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+		defer ctx.String(200, "")
+
+		dir := ctx.Query("path")
+		if dir == "" {
+			if dir = xbmc.Keyboard("", "LOCALIZE[30805]"); dir == "" {
+				return
+			}
+		}
+
+		found, err := bittorrent.FindClientTorrents(dir)
+		if err != nil {
+			xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+			return
+		} else if len(found) == 0 {
+			xbmc.Notify("projectx", "LOCALIZE[30806]", config.AddonIcon())
+			return
+		}
+
+		if !xbmc.DialogConfirm("LOCALIZE[30804]", fmt.Sprintf("Found %d torrents with complete files in %s. Import them?", len(found), dir)) {
+			return
+		}
+
+		imported := 0
+		for _, ct := range found {
+			if _, err := s.ImportTorrent(ct); err != nil {
+				torrentsLog.Warningf("Cannot import %s: %s", ct.Name, err)
+				continue
+			}
+			imported++
+		}
+
+		xbmc.Notify("projectx", fmt.Sprintf("LOCALIZE[30807];;%d/%d", imported, len(found)), config.AddonIcon())
+		xbmc.Refresh()
+	}
+}
+
 // ResumeTorrent ...
 func ResumeTorrent(s *bittorrent.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -486,6 +589,243 @@ func PauseTorrent(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// SuperSeedTorrent ...
+func SuperSeedTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		torrent.SetSuperSeeding(ctx.DefaultQuery("enable", "true") == "true")
+
+		xbmc.Refresh()
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
+// UploadSlotsTorrent sets number of upload slots of the torrent,
+// selected in a dialog or passed in "slots" parameter
+func UploadSlotsTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		slots, err := strconv.Atoi(ctx.Query("slots"))
+		if err != nil {
+			choices := []int{-1, 1, 2, 4, 8, 16}
+			items := make([]string, 0, len(choices))
+			for _, c := range choices {
+				if c < 0 {
+					items = append(items, "LOCALIZE[30731]")
+				} else {
+					items = append(items, strconv.Itoa(c))
+				}
+			}
+
+			choice := xbmc.ListDialog("LOCALIZE[30730]", items...)
+			if choice < 0 || choice >= len(choices) {
+				ctx.String(200, "")
+				return
+			}
+			slots = choices[choice]
+		}
+
+		torrent.SetUploadSlots(slots)
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
+// ReannounceTorrent forces announce of the torrent to trackers and DHT
+func ReannounceTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to reannounce torrent with index %s", torrentID))
+			return
+		}
+
+		if !torrent.Reannounce() {
+			xbmc.Notify("projectx", "LOCALIZE[30764]", config.AddonIcon())
+		}
+
+		xbmc.Refresh()
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
+// RecheckTorrent verifies hashes of downloaded pieces, corrupt pieces are downloaded again
+func RecheckTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to recheck torrent with index %s", torrentID))
+			return
+		}
+
+		torrent.ForceRecheck()
+
+		xbmc.Refresh()
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
+// AssignEpisodeFile lets user choose, which episode is in the file of season pack,
+// when automatic matching fails. Assignments are kept for the rest of the pack.
+func AssignEpisodeFile(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+		defer ctx.String(200, "")
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to assign files of torrent with index %s", torrentID))
+			return
+		}
+
+		item := torrent.GetDBItem()
+		if item == nil || item.ShowID == 0 {
+			xbmc.Notify("projectx", "LOCALIZE[30816]", config.AddonIcon())
+			return
+		}
+		show := tmdb.GetShow(item.ShowID, config.Get().Language)
+		if show == nil {
+			return
+		}
+
+		choices, _, err := torrent.GetCandidateFiles(nil)
+		if err != nil || len(choices) == 0 {
+			return
+		}
+
+		assigned := map[string]string{}
+		for _, f := range database.GetStorm().GetEpisodeFiles(torrent.InfoHash()) {
+			assigned[f.Path] = fmt.Sprintf("S%02dE%02d", f.Season, f.Episode)
+		}
+		labels := make([]string, 0, len(choices))
+		for _, c := range choices {
+			label := c.DisplayName
+			if episode, ok := assigned[c.Path]; ok {
+				label = fmt.Sprintf("[B]%s[/B] %s", episode, label)
+			}
+			labels = append(labels, label)
+		}
+		choice := xbmc.ListDialog("LOCALIZE[30815]", labels...)
+		if choice < 0 || choice >= len(choices) {
+			return
+		}
+		path := choices[choice].Path
+
+		seasons := make([]*tmdb.Season, 0, len(show.Seasons))
+		seasonLabels := []string{"LOCALIZE[30817]"}
+		for _, season := range show.Seasons {
+			if season != nil && season.EpisodeCount > 0 {
+				seasons = append(seasons, season)
+				seasonLabels = append(seasonLabels, season.Name)
+			}
+		}
+		seasonChoice := xbmc.ListDialog("LOCALIZE[30815]", seasonLabels...)
+		if seasonChoice < 0 {
+			return
+		} else if seasonChoice == 0 {
+			database.GetStorm().SetEpisodeFile(torrent.InfoHash(), 0, 0, path)
+			return
+		}
+
+		seasonNumber := seasons[seasonChoice-1].Season
+		season := tmdb.GetSeason(item.ShowID, seasonNumber, config.Get().Language, len(show.Seasons))
+		if season == nil || len(season.Episodes) == 0 {
+			return
+		}
+		episodeLabels := make([]string, 0, len(season.Episodes))
+		for _, e := range season.Episodes {
+			episodeLabels = append(episodeLabels, fmt.Sprintf("S%02dE%02d %s", e.SeasonNumber, e.EpisodeNumber, e.Name))
+		}
+		episodeChoice := xbmc.ListDialog("LOCALIZE[30815]", episodeLabels...)
+		if episodeChoice < 0 || episodeChoice >= len(season.Episodes) {
+			return
+		}
+
+		episode := season.Episodes[episodeChoice]
+		if err := database.GetStorm().SetEpisodeFile(torrent.InfoHash(), seasonNumber, episode.EpisodeNumber, path); err != nil {
+			xbmc.Notify("projectx", err.Error(), config.AddonIcon())
+			return
+		}
+		torrentsLog.Infof("Assigned %s to S%02dE%02d of %s", path, seasonNumber, episode.EpisodeNumber, show.Name)
+	}
+}
+
+// RotatePasskey replaces passkey of private tracker in all torrents
+func RotatePasskey(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		host := ctx.Query("host")
+		if host == "" {
+			hosts := []string{}
+			for _, r := range bittorrent.GetTrackerRules() {
+				if r.Passkey != "" {
+					hosts = append(hosts, r.Host)
+				}
+			}
+			if len(hosts) == 0 {
+				xbmc.Notify("projectx", "LOCALIZE[30736]", config.AddonIcon())
+				ctx.String(200, "")
+				return
+			}
+
+			choice := xbmc.ListDialog("LOCALIZE[30735]", hosts...)
+			if choice < 0 || choice >= len(hosts) {
+				ctx.String(200, "")
+				return
+			}
+			host = hosts[choice]
+		}
+
+		passkey := ctx.Query("passkey")
+		if passkey == "" {
+			if passkey = xbmc.Keyboard("", "LOCALIZE[30737]"); passkey == "" {
+				ctx.String(200, "")
+				return
+			}
+		}
+
+		updated, err := s.RotatePasskey(host, passkey)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to save passkey for %s: %s", host, err))
+			return
+		}
+
+		log.Infof("Updated passkey of %s in %d torrents", host, updated)
+		xbmc.Notify("projectx", xbmc.Localizef(30738, updated), config.AddonIcon())
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
 // RemoveTorrent ...
 func RemoveTorrent(s *bittorrent.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -498,6 +838,11 @@ func RemoveTorrent(s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		if torrent.IsHitAndRun() && !xbmc.DialogConfirm("projectx", "LOCALIZE[30739]") {
+			ctx.String(200, "")
+			return
+		}
+
 		s.RemoveTorrent(torrent, true, deleteFiles != "", false)
 
 		xbmc.Refresh()
@@ -641,3 +986,26 @@ func saveTorrentFile(file multipart.File, header *multipart.FileHeader) (string,
 
 	return path, nil
 }
+
+// WhyTorrent shows why torrent was picked by auto-selection
+func WhyTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		reason := providers.GetSelectionReason(torrent.InfoHash())
+		if len(reason) == 0 {
+			xbmc.Notify("projectx", "LOCALIZE[30717]", config.AddonIcon())
+		} else {
+			xbmc.DialogText("LOCALIZE[30716]", strings.Join(reason, "\n"))
+		}
+
+		ctx.String(200, "")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/parental"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/trakt"
 	"github.com/projectx13/projectx/util"
@@ -252,6 +253,9 @@ func RemoveMovieFromWatchlist(ctx *gin.Context) {
 		xbmc.Notify("projectx", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("projectx", "Movie removed from watchlist", config.AddonIcon())
+		if id, err := strconv.Atoi(tmdbID); err == nil {
+			database.GetStorm().AddTombstone(id, library.MovieType, "")
+		}
 		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.movies"))
 		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.movies.watchlist"))
 		if ctx != nil {
@@ -294,6 +298,9 @@ func RemoveShowFromWatchlist(ctx *gin.Context) {
 		xbmc.Notify("projectx", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("projectx", "Show removed from watchlist", config.AddonIcon())
+		if id, err := strconv.Atoi(tmdbID); err == nil {
+			database.GetStorm().AddTombstone(id, library.ShowType, "")
+		}
 		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.shows"))
 		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.shows.watchlist"))
 		if ctx != nil {
@@ -430,7 +437,7 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 		wg.Add(1)
 		go func(movieListing *trakt.Movies, index int) {
 			defer wg.Done()
-			if movieListing == nil || movieListing.Movie == nil {
+			if movieListing == nil || movieListing.Movie == nil || !parental.AllowMovieID(movieListing.Movie.IDs.TMDB) {
 				return
 			}
 
@@ -495,6 +502,13 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 	}
 	wg.Wait()
 
+	// Items, hidden by parental control, are left empty
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i] == nil {
+			items = append(items[:i], items[i+1:]...)
+		}
+	}
+
 	if page >= 0 && hasNextPage > 0 {
 		path := ctx.Request.URL.Path
 		nextpage := &xbmc.ListItem{
@@ -504,7 +518,7 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 		}
 		items = append(items, nextpage)
 	}
-	ctx.JSON(200, xbmc.NewView("movies", items))
+	ctx.JSON(200, xbmc.NewView("movies", sortListItems(ctx, items)))
 }
 
 // TraktPopularMovies ...
@@ -693,7 +707,7 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 	items := make(xbmc.ListItems, 0, len(shows)+hasNextPage)
 
 	for _, showListing := range shows {
-		if showListing == nil || showListing.Show == nil {
+		if showListing == nil || showListing.Show == nil || !parental.AllowShowID(showListing.Show.IDs.TMDB) {
 			continue
 		}
 
@@ -745,7 +759,7 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 		}
 		items = append(items, nextpage)
 	}
-	ctx.JSON(200, xbmc.NewView("tvshows", items))
+	ctx.JSON(200, xbmc.NewView("tvshows", sortListItems(ctx, items)))
 }
 
 // TraktPopularShows ...
@@ -1012,7 +1026,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 		go func(i int, movieListing *trakt.CalendarMovie) {
 			defer wg.Done()
 
-			if movieListing == nil || movieListing.Movie == nil {
+			if movieListing == nil || movieListing.Movie == nil || !parental.AllowMovieID(movieListing.Movie.IDs.TMDB) {
 				return
 			}
 
@@ -1111,7 +1125,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 		}
 		items = append(items, nextpage)
 	}
-	ctx.JSON(200, xbmc.NewView("movies", items))
+	ctx.JSON(200, xbmc.NewView("movies", sortListItems(ctx, items)))
 }
 
 func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total int, page int) {
@@ -1155,7 +1169,7 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 	for i, s := range shows {
 		go func(i int, showListing *trakt.CalendarShow) {
 			defer wg.Done()
-			if showListing == nil || showListing.Episode == nil {
+			if showListing == nil || showListing.Episode == nil || !parental.AllowShowID(showListing.Show.IDs.TMDB) {
 				return
 			}
 
@@ -1287,7 +1301,7 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 		}
 		items = append(items, nextpage)
 	}
-	ctx.JSON(200, xbmc.NewView("tvshows", items))
+	ctx.JSON(200, xbmc.NewView("tvshows", sortListItems(ctx, items)))
 }
 
 func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total int, page int) {
@@ -1309,6 +1323,8 @@ func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total in
 			defer wg.Done()
 			if showListing == nil && showListing.Episode == nil {
 				return
+			} else if !parental.AllowShowID(showListing.Show.IDs.TMDB) {
+				return
 			}
 
 			epi := showListing.Episode
@@ -1435,7 +1451,7 @@ func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total in
 		})
 	}
 
-	ctx.JSON(200, xbmc.NewView("tvshows", items))
+	ctx.JSON(200, xbmc.NewView("tvshows", sortListItems(ctx, items)))
 }
 
 // SelectTraktUserList ...
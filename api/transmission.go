@@ -0,0 +1,359 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	lt "github.com/projectxorg/libtorrent-go"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+	"github.com/op/go-logging"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/util"
+)
+
+const (
+	transmissionSessionHeader = "X-Transmission-Session-Id"
+	transmissionRPCVersion    = 15
+
+	transmissionStopped      = 0
+	transmissionCheck        = 2
+	transmissionDownloadWait = 3
+	transmissionDownload     = 4
+	transmissionSeed         = 6
+)
+
+var (
+	transmissionLog = logging.MustGetLogger("transmission")
+
+	transmissionSessionID string
+	transmissionIDs       = map[string]int{}
+	transmissionLastID    = 0
+	transmissionMu        sync.Mutex
+
+	transmissionStatuses = map[string]int{
+		bittorrent.StatusStrings[bittorrent.StatusQueued]:      transmissionDownloadWait,
+		bittorrent.StatusStrings[bittorrent.StatusChecking]:    transmissionCheck,
+		bittorrent.StatusStrings[bittorrent.StatusFinding]:     transmissionDownloadWait,
+		bittorrent.StatusStrings[bittorrent.StatusDownloading]: transmissionDownload,
+		bittorrent.StatusStrings[bittorrent.StatusFinished]:    transmissionStopped,
+		bittorrent.StatusStrings[bittorrent.StatusSeeding]:     transmissionSeed,
+		bittorrent.StatusStrings[bittorrent.StatusAllocating]:  transmissionCheck,
+		bittorrent.StatusStrings[bittorrent.StatusStalled]:     transmissionDownload,
+		bittorrent.StatusStrings[bittorrent.StatusPaused]:      transmissionStopped,
+		bittorrent.StatusStrings[bittorrent.StatusBuffering]:   transmissionDownload,
+		bittorrent.StatusStrings[bittorrent.StatusPlaying]:     transmissionDownload,
+	}
+)
+
+// TransmissionRequest is a Transmission RPC request
+type TransmissionRequest struct {
+	Method    string                 `json:"method"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Tag       interface{}            `json:"tag,omitempty"`
+}
+
+// TransmissionResponse is a Transmission RPC response
+type TransmissionResponse struct {
+	Result    string                 `json:"result"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Tag       interface{}            `json:"tag,omitempty"`
+}
+
+func init() {
+	b := make([]byte, 24)
+	rand.Read(b)
+	transmissionSessionID = hex.EncodeToString(b)
+}
+
+// TransmissionRPC handles Transmission RPC protocol requests, so that
+// remote control apps for Transmission can manage projectx torrents
+func TransmissionRPC(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		ctx.Writer.Header().Set(transmissionSessionHeader, transmissionSessionID)
+		if ctx.Request.Header.Get(transmissionSessionHeader) != transmissionSessionID {
+			ctx.String(409, "<h1>409: Conflict</h1><p>Invalid session id.</p>")
+			return
+		}
+
+		var req TransmissionRequest
+		if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+			ctx.JSON(400, TransmissionResponse{Result: err.Error()})
+			return
+		}
+		if req.Arguments == nil {
+			req.Arguments = map[string]interface{}{}
+		}
+
+		args, err := transmissionDispatch(s, &req)
+		resp := TransmissionResponse{
+			Result:    "success",
+			Arguments: args,
+			Tag:       req.Tag,
+		}
+		if err != nil {
+			transmissionLog.Warningf("Request %s failed: %s", req.Method, err)
+			resp.Result = err.Error()
+		}
+		if resp.Arguments == nil {
+			resp.Arguments = map[string]interface{}{}
+		}
+
+		ctx.JSON(200, resp)
+	}
+}
+
+func transmissionDispatch(s *bittorrent.Service, req *TransmissionRequest) (map[string]interface{}, error) {
+	if s.Closer.IsSet() {
+		return nil, fmt.Errorf("service is closing")
+	}
+
+	switch req.Method {
+	case "session-get":
+		return map[string]interface{}{
+			"version":             fmt.Sprintf("2.94 (projectx %s)", util.GetVersion()),
+			"rpc-version":         transmissionRPCVersion,
+			"rpc-version-minimum": 1,
+			"download-dir":        config.Get().DownloadPath,
+			"speed-limit-down":    config.Get().DownloadRateLimit / 1024,
+			"speed-limit-up":      config.Get().UploadRateLimit / 1024,
+		}, nil
+	case "session-set":
+		return nil, nil
+	case "session-stats":
+		return transmissionSessionStats(s), nil
+	case "torrent-get":
+		return transmissionTorrentGet(s, req.Arguments), nil
+	case "torrent-start", "torrent-start-now":
+		for _, t := range transmissionSelect(s, req.Arguments) {
+			t.Resume()
+		}
+		return nil, nil
+	case "torrent-stop":
+		for _, t := range transmissionSelect(s, req.Arguments) {
+			t.Pause()
+		}
+		return nil, nil
+	case "torrent-remove":
+		deleteFiles, _ := req.Arguments["delete-local-data"].(bool)
+		for _, t := range transmissionSelect(s, req.Arguments) {
+			s.RemoveTorrent(t, true, deleteFiles, false)
+		}
+		return nil, nil
+	case "torrent-add":
+		return transmissionTorrentAdd(s, req.Arguments)
+	}
+
+	return nil, fmt.Errorf("method %s is not supported", req.Method)
+}
+
+func transmissionID(infoHash string) int {
+	transmissionMu.Lock()
+	defer transmissionMu.Unlock()
+
+	if id, ok := transmissionIDs[infoHash]; ok {
+		return id
+	}
+
+	transmissionLastID++
+	transmissionIDs[infoHash] = transmissionLastID
+	return transmissionLastID
+}
+
+// transmissionSelect returns torrents, matching "ids" argument,
+// which can be a single id, list of ids or hashes, or missing for all torrents
+func transmissionSelect(s *bittorrent.Service, args map[string]interface{}) []*bittorrent.Torrent {
+	torrents := s.GetTorrents()
+	ids, ok := args["ids"]
+	if !ok || ids == "recently-active" {
+		return torrents
+	}
+
+	wanted := map[string]bool{}
+	var add func(v interface{})
+	add = func(v interface{}) {
+		switch id := v.(type) {
+		case float64:
+			wanted[fmt.Sprintf("%d", int(id))] = true
+		case string:
+			wanted[strings.ToLower(id)] = true
+		case []interface{}:
+			for _, i := range id {
+				add(i)
+			}
+		}
+	}
+	add(ids)
+
+	ret := []*bittorrent.Torrent{}
+	for _, t := range torrents {
+		if wanted[t.InfoHash()] || wanted[fmt.Sprintf("%d", transmissionID(t.InfoHash()))] {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+func transmissionSessionStats(s *bittorrent.Service) map[string]interface{} {
+	active, paused, down, up := 0, 0, 0, 0
+	for _, t := range s.GetTorrents() {
+		if t.GetPaused() {
+			paused++
+		} else {
+			active++
+		}
+
+		d, u := t.GetSpeeds()
+		down += d
+		up += u
+	}
+
+	return map[string]interface{}{
+		"activeTorrentCount": active,
+		"pausedTorrentCount": paused,
+		"torrentCount":       active + paused,
+		"downloadSpeed":      down,
+		"uploadSpeed":        up,
+	}
+}
+
+func transmissionTorrentGet(s *bittorrent.Service, args map[string]interface{}) map[string]interface{} {
+	fields := map[string]bool{}
+	if list, ok := args["fields"].([]interface{}); ok {
+		for _, f := range list {
+			if name, ok := f.(string); ok {
+				fields[name] = true
+			}
+		}
+	}
+
+	torrents := []map[string]interface{}{}
+	for _, t := range transmissionSelect(s, args) {
+		th := t.GetHandle()
+		if th == nil || !th.IsValid() {
+			continue
+		}
+
+		item := transmissionTorrentInfo(t, th)
+		if len(fields) > 0 {
+			for k := range item {
+				if !fields[k] {
+					delete(item, k)
+				}
+			}
+		}
+		torrents = append(torrents, item)
+	}
+
+	return map[string]interface{}{
+		"torrents": torrents,
+	}
+}
+
+func transmissionTorrentInfo(t *bittorrent.Torrent, th lt.TorrentHandle) map[string]interface{} {
+	ts := th.Status(uint(lt.WrappedTorrentHandleQueryName) | uint(lt.WrappedTorrentHandleQuerySavePath))
+	defer lt.DeleteTorrentStatus(ts)
+
+	progress := float64(ts.GetProgress())
+	wanted := ts.GetTotalWanted()
+	left := wanted - ts.GetTotalWantedDone()
+	downloadRate := ts.GetDownloadPayloadRate()
+
+	ratio := float64(0)
+	if downloaded := ts.GetAllTimeDownload(); downloaded > 0 {
+		ratio = float64(ts.GetAllTimeUpload()) / float64(downloaded)
+	}
+
+	eta := -1
+	if downloadRate > 0 {
+		eta = int(left / int64(downloadRate))
+	} else if left == 0 {
+		eta = 0
+	}
+
+	status, ok := transmissionStatuses[t.GetStateString()]
+	if !ok {
+		status = transmissionStopped
+	}
+
+	seeders, _, peers, _ := t.GetConnections()
+
+	return map[string]interface{}{
+		"id":                 transmissionID(t.InfoHash()),
+		"hashString":         t.InfoHash(),
+		"name":               ts.GetName(),
+		"status":             status,
+		"percentDone":        progress,
+		"totalSize":          t.Length(),
+		"sizeWhenDone":       wanted,
+		"leftUntilDone":      left,
+		"isFinished":         progress >= 1,
+		"rateDownload":       downloadRate,
+		"rateUpload":         ts.GetUploadPayloadRate(),
+		"uploadRatio":        ratio,
+		"uploadedEver":       ts.GetAllTimeUpload(),
+		"downloadedEver":     ts.GetAllTimeDownload(),
+		"eta":                eta,
+		"peersConnected":     seeders + peers,
+		"peersSendingToUs":   seeders,
+		"peersGettingFromUs": peers,
+		"addedDate":          t.GetAddedTime().Unix(),
+		"downloadDir":        ts.GetSavePath(),
+		"error":              0,
+		"errorString":        "",
+		"queuePosition":      0,
+	}
+}
+
+func transmissionTorrentAdd(s *bittorrent.Service, args map[string]interface{}) (map[string]interface{}, error) {
+	uri, _ := args["filename"].(string)
+	if metainfo, ok := args["metainfo"].(string); ok && metainfo != "" {
+		b, err := base64.StdEncoding.DecodeString(metainfo)
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha1.Sum(b)
+		uri = filepath.Join(config.Get().TorrentsPath, hex.EncodeToString(sum[:])+".torrent")
+		log.Debugf("Saving incoming torrent file to: %s", uri)
+		if err := ioutil.WriteFile(uri, b, 0644); err != nil {
+			return nil, fmt.Errorf("Could not write file content: %s", err)
+		}
+	}
+
+	if uri == "" {
+		return nil, fmt.Errorf("missing torrent filename or metainfo")
+	}
+
+	paused, _ := args["paused"].(bool)
+	t, err := s.AddTorrent(uri, paused, config.Get().DownloadStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	database.GetStorm().UpdateBTItem(t.InfoHash(), 0, "", []string{}, t.Name(), 0, 0, 0)
+	t.DownloadAllFiles()
+	t.SaveDBFiles()
+
+	return map[string]interface{}{
+		"torrent-added": map[string]interface{}{
+			"id":         transmissionID(t.InfoHash()),
+			"hashString": t.InfoHash(),
+			"name":       t.Name(),
+		},
+	}, nil
+}
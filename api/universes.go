@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// universesFileName is a file in the profile folder, which replaces bundled universes,
+// so lists can be updated without a new release
+const universesFileName = "universes.json"
+
+// Universe is a curated list of movies from several collections,
+// in the order they should be watched
+type Universe struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Movies []int  `json:"movies"`
+}
+
+// defaultUniverses are bundled universes, movies are TMDB ids
+const defaultUniverses = `[
+	{
+		"id": "mcu-timeline",
+		"name": "Marvel Cinematic Universe (timeline order)",
+		"movies": [1771, 299537, 1726, 10138, 1724, 10195, 24428, 76338, 68721, 100402, 118340, 283995, 99861, 102899, 271110, 497698, 284054, 315635, 284052, 284053, 363088, 299536, 299534, 429617]
+	},
+	{
+		"id": "star-wars-machete",
+		"name": "Star Wars (machete order)",
+		"movies": [11, 1891, 1894, 1895, 1892]
+	}
+]`
+
+var (
+	universesMu      sync.Mutex
+	universes        []*Universe
+	universesModTime time.Time
+)
+
+// getUniverses returns universes from the profile file, if it exists, or bundled ones,
+// file is read again when it is changed
+func getUniverses() []*Universe {
+	universesMu.Lock()
+	defer universesMu.Unlock()
+
+	path := filepath.Join(config.Get().ProfilePath, universesFileName)
+	if stat, err := os.Stat(path); err == nil {
+		if universes != nil && stat.ModTime().Equal(universesModTime) {
+			return universes
+		}
+
+		if data, err := ioutil.ReadFile(path); err != nil {
+			log.Warningf("Could not read %s: %s", path, err)
+		} else if list, err := parseUniverses(data); err != nil {
+			log.Warningf("Could not parse %s: %s", path, err)
+		} else {
+			universes = list
+			universesModTime = stat.ModTime()
+			return universes
+		}
+	} else if !universesModTime.IsZero() {
+		// Profile file is removed, so go back to bundled universes
+		universes = nil
+		universesModTime = time.Time{}
+	}
+
+	if universes == nil {
+		universes, _ = parseUniverses([]byte(defaultUniverses))
+	}
+	return universes
+}
+
+func parseUniverses(data []byte) ([]*Universe, error) {
+	list := []*Universe{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, u := range list {
+		if u.ID == "" || len(u.Movies) == 0 {
+			return nil, fmt.Errorf("universe %q should have id and movies", u.Name)
+		}
+	}
+	return list, nil
+}
+
+func getUniverse(id string) *Universe {
+	for _, u := range getUniverses() {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// MovieUniverses ...
+func MovieUniverses(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0)
+	for _, u := range getUniverses() {
+		items = append(items, &xbmc.ListItem{
+			Label:     u.Name,
+			Path:      URLForXBMC("/movies/universes/%s", u.ID),
+			Thumbnail: config.AddonResource("img", "movies.png"),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/menus_movies_universes"))},
+			},
+		})
+	}
+	ctx.JSON(200, xbmc.NewView("menus_movies_universes", filterListItems(items)))
+}
+
+// MovieUniverse lists movies of the universe in watching order,
+// which is kept in track number and sort title, so Kodi sorting does not break it
+func MovieUniverse(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	universe := getUniverse(ctx.Params.ByName("universeId"))
+	if universe == nil {
+		ctx.Error(errors.New("Unable to find universe"))
+		return
+	}
+
+	movies := tmdb.GetMovies(universe.Movies, config.Get().Language)
+	items := movieListItems(ctx, movies, -1, 0, "")
+	for i, item := range items {
+		item.Info.TrackNumber = i + 1
+		item.Info.SortTitle = fmt.Sprintf("%03d %s", i+1, item.Info.Title)
+	}
+
+	ctx.JSON(200, xbmc.NewView("movies", filterListItems(items)))
+}
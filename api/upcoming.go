@@ -0,0 +1,127 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/parental"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// UpcomingMovies lists movies, which are released soon in user's country, ordered by release date
+func UpcomingMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	country := contentRegion(ctx)
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.UpcomingMovies(country, config.Get().Language, page)
+
+	// Items are matched with dates by index, so skipped movies are removed beforehand
+	upcoming := make(tmdb.Movies, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && parental.AllowMovie(m) {
+			upcoming = append(upcoming, m)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return earlierDate(upcoming[i].RegionReleaseDate(country), upcoming[j].RegionReleaseDate(country))
+	})
+	dates := make([]string, len(upcoming))
+	for i, m := range upcoming {
+		dates[i] = m.RegionReleaseDate(country)
+	}
+
+	items := movieListItems(ctx, upcoming, page, total, "")
+	datedListItems(items, dates)
+	ctx.JSON(200, xbmc.NewView("movies", sortListItems(ctx, filterListItems(items))))
+}
+
+// AiringShows lists shows, which have episodes airing this week, ordered by air date of the next episode
+func AiringShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.OnTheAirShows(config.Get().Language, page)
+
+	airing := make(tmdb.Shows, 0, len(shows))
+	for _, s := range shows {
+		if s != nil && parental.AllowShow(s) {
+			airing = append(airing, s)
+		}
+	}
+	sort.SliceStable(airing, func(i, j int) bool {
+		return earlierDate(airing[i].NextAirDate(), airing[j].NextAirDate())
+	})
+	dates := make([]string, len(airing))
+	for i, s := range airing {
+		dates[i] = s.NextAirDate()
+	}
+
+	items := showListItems(ctx, airing, page, total, "")
+	datedListItems(items, dates)
+	ctx.JSON(200, xbmc.NewView("tvshows", sortListItems(ctx, filterListItems(items))))
+}
+
+// AiringTodayShows lists shows, which have episodes airing today
+func AiringTodayShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.AiringTodayShows(config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
+
+// earlierDate compares dates in "2006-01-02" format, unknown dates go last
+func earlierDate(a, b string) bool {
+	if a == "" || b == "" {
+		return a != "" && b == ""
+	}
+	return a < b
+}
+
+// datedListItems prefixes labels of the first items with their dates, formatted like Trakt calendars
+// contentRegion returns region of release dates and age ratings for the listing,
+// "content_region" query parameter overrides the one from settings
+func contentRegion(ctx *gin.Context) string {
+	if region, ok := regionOverride(ctx); ok {
+		return region
+	}
+	return tmdb.CertificationCountry()
+}
+
+// regionOverride returns region, passed to the listing in "content_region" query parameter, like "DE".
+// It is not "region", which is watch region of discover filters.
+func regionOverride(ctx *gin.Context) (string, bool) {
+	region := strings.ToUpper(strings.TrimSpace(ctx.Query("content_region")))
+	return region, len(region) == 2
+}
+
+func datedListItems(items xbmc.ListItems, dates []string) {
+	colorDate := config.Get().TraktCalendarsColorDate
+	colorShow := config.Get().TraktCalendarsColorShow
+	dateFormat := getCalendarsDateFormat()
+
+	for i, date := range dates {
+		if i >= len(items) {
+			break
+		}
+
+		aired, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+
+		item := items[i]
+		item.Label = fmt.Sprintf(`[COLOR %s]%s[/COLOR] | [B][COLOR %s]%s[/COLOR][/B]`,
+			colorDate, aired.Format(dateFormat), colorShow, item.Label)
+		item.Info.Title = item.Label
+	}
+}
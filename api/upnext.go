@@ -0,0 +1,130 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/playcount"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	// showOpenSeasons always shows list of seasons
+	showOpenSeasons = iota
+	// showOpenNextSeason shows episodes of the season with next unwatched episode
+	showOpenNextSeason
+	// showOpenUpNext shows unwatched episodes, starting from the next one
+	showOpenUpNext
+)
+
+// upNextSeasons is how many seasons, starting with the next unwatched episode,
+// are included into "up next" list
+const upNextSeasons = 2
+
+// nextUnwatchedSeasons returns numbers of aired seasons, starting with the first one,
+// which is not fully watched, specials are skipped
+func nextUnwatchedSeasons(show *tmdb.Show) []int {
+	now := util.UTCBod()
+
+	seasons := []int{}
+	for _, s := range show.Seasons {
+		if s == nil || s.Season <= 0 || s.EpisodeCount == 0 {
+			continue
+		}
+		if firstAired, err := time.Parse("2006-01-02", s.AirDate); err != nil || !firstAired.Before(now) {
+			continue
+		}
+		seasons = append(seasons, s.Season)
+	}
+	sort.Ints(seasons)
+
+	for i, season := range seasons {
+		if !playcount.GetWatchedSeasonByTMDB(show.ID, season) {
+			return seasons[i:]
+		}
+	}
+	return nil
+}
+
+// openShowAtNextEpisode renders episodes, where user can continue watching the show,
+// according to show_open_mode setting. Returns false, when seasons list should be shown,
+// because the mode is disabled or there is nothing left to watch.
+func openShowAtNextEpisode(ctx *gin.Context, show *tmdb.Show) bool {
+	mode := config.Get().ShowOpenMode
+	if mode != showOpenNextSeason && mode != showOpenUpNext {
+		return false
+	}
+
+	seasons := nextUnwatchedSeasons(show)
+	if len(seasons) == 0 {
+		return false
+	}
+	// Nothing is watched yet, so start from the beginning
+	if seasons[0] == firstSeason(show) && !hasWatchedEpisodes(show, seasons[0]) {
+		return false
+	}
+
+	if mode == showOpenNextSeason {
+		seasons = seasons[:1]
+	} else if len(seasons) > upNextSeasons {
+		seasons = seasons[:upNextSeasons]
+	}
+
+	items, err := showEpisodeItems(show, seasons, config.Get().Language)
+	if err != nil {
+		log.Warningf("Could not list next episodes of show %d: %s", show.ID, err)
+		return false
+	}
+
+	if mode == showOpenUpNext {
+		unwatched := make(xbmc.ListItems, 0, len(items))
+		for _, item := range items {
+			if item.Info == nil || item.Info.PlayCount == 0 {
+				unwatched = append(unwatched, item)
+			}
+		}
+		items = unwatched
+	}
+
+	seasonsItem := &xbmc.ListItem{
+		Label: "LOCALIZE[30721]",
+		Path:  URLForXBMC("/show/%d/seasons?list=seasons", show.ID),
+		ContextMenu: [][]string{
+			{"LOCALIZE[30036]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/seasons"))},
+		},
+	}
+
+	ctx.JSON(200, xbmc.NewView("episodes", append(xbmc.ListItems{seasonsItem}, filterListItems(items)...)))
+	return true
+}
+
+// firstSeason returns the first regular season of the show
+func firstSeason(show *tmdb.Show) int {
+	first := 0
+	for _, s := range show.Seasons {
+		if s != nil && s.Season > 0 && (first == 0 || s.Season < first) {
+			first = s.Season
+		}
+	}
+	return first
+}
+
+func hasWatchedEpisodes(show *tmdb.Show, season int) bool {
+	for _, s := range show.Seasons {
+		if s == nil || s.Season != season {
+			continue
+		}
+		for episode := 1; episode <= s.EpisodeCount; episode++ {
+			if playcount.GetWatchedEpisodeByTMDB(show.ID, season, episode) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,615 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	lt "github.com/projectxorg/libtorrent-go"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/library"
+	"github.com/projectx13/projectx/parental"
+	"github.com/projectx13/projectx/playcount"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// JSON API v2 is for clients other than Kodi, like web dashboards and mobile remotes.
+// Unlike the rest of routes, it returns plain JSON with stable schemas instead of Kodi views.
+//
+//	GET    /api/v2/status
+//	GET    /api/v2/movies/popular?page=1
+//	GET    /api/v2/movies/search?q=<query>&page=1
+//	GET    /api/v2/movie/<tmdb id>
+//	GET    /api/v2/shows/popular?page=1
+//	GET    /api/v2/shows/search?q=<query>&page=1
+//	GET    /api/v2/show/<tmdb id>
+//	GET    /api/v2/show/<tmdb id>/season/<season>
+//	GET    /api/v2/torrents
+//	POST   /api/v2/torrents                 {"uri": "magnet:?xt=...", "all": false}
+//	GET    /api/v2/torrents/<infohash>
+//	POST   /api/v2/torrents/<infohash>/pause
+//	POST   /api/v2/torrents/<infohash>/resume
+//	DELETE /api/v2/torrents/<infohash>?files=true
+//	GET    /api/v2/library/jobs
+//	POST   /api/v2/play                     same request as /external/v1/play
+//
+// Lists are {"page": 1, "total_results": 100, "results": [...]}, errors are {"error": "<message>"}.
+// Sizes are in bytes, rates in bytes per second, progress in percents, dates are YYYY-MM-DD,
+// times are Unix timestamps.
+// Optional "content_region" query parameter selects region of certifications and release dates.
+
+// MovieV2 is a movie of JSON API v2
+type MovieV2 struct {
+	ID            int      `json:"id"`
+	IMDBID        string   `json:"imdb_id"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title"`
+	Year          int      `json:"year"`
+	Premiered     string   `json:"premiered"`
+	Overview      string   `json:"overview"`
+	Runtime       int      `json:"runtime"`
+	Rating        float32  `json:"rating"`
+	Votes         int      `json:"votes"`
+	Genres        []string `json:"genres"`
+	Certification string   `json:"certification"`
+	Poster        string   `json:"poster"`
+	Fanart        string   `json:"fanart"`
+	Watched       bool     `json:"watched"`
+	InLibrary     bool     `json:"in_library"`
+}
+
+// ShowV2 is a TV show of JSON API v2, seasons are filled only for a single show
+type ShowV2 struct {
+	ID            int         `json:"id"`
+	IMDBID        string      `json:"imdb_id"`
+	TVDBID        int         `json:"tvdb_id"`
+	Name          string      `json:"name"`
+	OriginalName  string      `json:"original_name"`
+	Year          int         `json:"year"`
+	Premiered     string      `json:"premiered"`
+	Overview      string      `json:"overview"`
+	Status        string      `json:"status"`
+	Rating        float32     `json:"rating"`
+	Votes         int         `json:"votes"`
+	Genres        []string    `json:"genres"`
+	Certification string      `json:"certification"`
+	Poster        string      `json:"poster"`
+	Fanart        string      `json:"fanart"`
+	SeasonsCount  int         `json:"seasons_count"`
+	EpisodesCount int         `json:"episodes_count"`
+	Watched       bool        `json:"watched"`
+	InLibrary     bool        `json:"in_library"`
+	Seasons       []*SeasonV2 `json:"seasons,omitempty"`
+}
+
+// SeasonV2 is a season of JSON API v2, episodes are filled only for a single season
+type SeasonV2 struct {
+	Season        int          `json:"season"`
+	Name          string       `json:"name"`
+	AirDate       string       `json:"air_date"`
+	EpisodesCount int          `json:"episodes_count"`
+	Poster        string       `json:"poster"`
+	Watched       bool         `json:"watched"`
+	Episodes      []*EpisodeV2 `json:"episodes,omitempty"`
+}
+
+// EpisodeV2 is an episode of JSON API v2
+type EpisodeV2 struct {
+	Season   int     `json:"season"`
+	Episode  int     `json:"episode"`
+	Name     string  `json:"name"`
+	AirDate  string  `json:"air_date"`
+	Overview string  `json:"overview"`
+	Runtime  int     `json:"runtime"`
+	Rating   float32 `json:"rating"`
+	Votes    int     `json:"votes"`
+	Still    string  `json:"still"`
+	Watched  bool    `json:"watched"`
+}
+
+// TorrentV2 is a torrent of JSON API v2, files are filled only for a single torrent
+type TorrentV2 struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Size         int64            `json:"size"`
+	Status       string           `json:"status"`
+	Paused       bool             `json:"paused"`
+	Progress     float64          `json:"progress"`
+	DownloadRate int              `json:"download_rate"`
+	UploadRate   int              `json:"upload_rate"`
+	Seeders      int              `json:"seeders"`
+	SeedersTotal int              `json:"seeders_total"`
+	Peers        int              `json:"peers"`
+	PeersTotal   int              `json:"peers_total"`
+	TrackerError string           `json:"tracker_error"`
+	Files        []*TorrentFileV2 `json:"files,omitempty"`
+}
+
+// TorrentFileV2 is a file of the torrent of JSON API v2
+type TorrentFileV2 struct {
+	Index    int    `json:"index"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Selected bool   `json:"selected"`
+}
+
+// LibraryJobV2 is an addition of a movie or a show to the library of JSON API v2
+type LibraryJobV2 struct {
+	ID       int    `json:"id"`
+	Type     string `json:"type"`
+	TmdbID   string `json:"tmdb_id"`
+	Title    string `json:"title"`
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+	Created  int64  `json:"created"`
+	Finished int64  `json:"finished,omitempty"`
+}
+
+// ListV2 is a page of results of JSON API v2
+type ListV2 struct {
+	Page         int         `json:"page"`
+	TotalResults int         `json:"total_results"`
+	Results      interface{} `json:"results"`
+}
+
+// AddTorrentV2Request is a JSON request to add a torrent
+type AddTorrentV2Request struct {
+	URI string `json:"uri"`
+	All bool   `json:"all"`
+}
+
+// V2Headers allows requests of JSON API v2 from web pages of origins, configured in settings.
+// Browsers send Origin with cross-origin requests, so pages of other origins are rejected,
+// otherwise any opened web page could control torrents. Apps and scripts do not send Origin.
+func V2Headers(ctx *gin.Context) {
+	origin := ctx.GetHeader("Origin")
+	if origin == "" {
+		return
+	}
+	if !v2OriginAllowed(origin, ctx.Request.Host) {
+		log.Warningf("Blocked JSON API request %s %s from %s", ctx.Request.Method, ctx.Request.URL.Path, origin)
+		v2Error(ctx, 403, "origin %s is not allowed", origin)
+		return
+	}
+
+	ctx.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+	ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+	ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	ctx.Writer.Header().Add("Vary", "Origin")
+}
+
+// V2Preflight answers CORS preflight requests of JSON API v2
+func V2Preflight(ctx *gin.Context) {
+	ctx.Status(204)
+}
+
+// v2OriginAllowed checks whether the origin is the host itself, like for the web UI, or is allowed in settings
+func v2OriginAllowed(origin string, host string) bool {
+	if u, err := url.Parse(origin); err == nil && u.Host == host {
+		return true
+	}
+
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	for _, allowed := range config.Get().APIAllowedOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func v2Error(ctx *gin.Context, code int, format string, args ...interface{}) {
+	ctx.AbortWithStatusJSON(code, gin.H{"error": fmt.Sprintf(format, args...)})
+}
+
+func v2Page(ctx *gin.Context) int {
+	if page := strToInt(ctx.Query("page"), 1); page > 0 {
+		return page
+	}
+	return 1
+}
+
+func v2Image(path string, art int) string {
+	if path == "" {
+		return ""
+	}
+	return tmdb.ArtURL(path, art)
+}
+
+func v2Genres(genres []*tmdb.IDName) []string {
+	ret := make([]string, 0, len(genres))
+	for _, g := range genres {
+		if g != nil {
+			ret = append(ret, g.Name)
+		}
+	}
+	return ret
+}
+
+func v2Movie(movie *tmdb.Movie, region string) *MovieV2 {
+	tmdbID := strconv.Itoa(movie.ID)
+	return &MovieV2{
+		ID:            movie.ID,
+		IMDBID:        movie.IMDBId,
+		Title:         movie.Title,
+		OriginalTitle: movie.OriginalTitle,
+		Year:          movie.Year(),
+		Premiered:     movie.RegionReleaseDate(region),
+		Overview:      movie.Overview,
+		Runtime:       movie.Runtime,
+		Rating:        movie.VoteAverage,
+		Votes:         movie.VoteCount,
+		Genres:        v2Genres(movie.Genres),
+		Certification: movie.Certification(region),
+		Poster:        v2Image(movie.PosterPath, tmdb.ArtPoster),
+		Fanart:        v2Image(movie.BackdropPath, tmdb.ArtFanart),
+		Watched:       bool(playcount.GetWatchedMovieByTMDB(movie.ID)),
+		InLibrary:     library.IsAddedToLibrary(tmdbID, library.MovieType),
+	}
+}
+
+func v2Show(show *tmdb.Show, region string) *ShowV2 {
+	ret := &ShowV2{
+		ID:            show.ID,
+		Name:          show.Name,
+		OriginalName:  show.OriginalName,
+		Premiered:     show.FirstAirDate,
+		Overview:      show.Overview,
+		Status:        show.Status,
+		Rating:        show.VoteAverage,
+		Votes:         show.VoteCount,
+		Genres:        v2Genres(show.Genres),
+		Certification: show.Certification(region),
+		Poster:        v2Image(show.PosterPath, tmdb.ArtPoster),
+		Fanart:        v2Image(show.BackdropPath, tmdb.ArtFanart),
+		SeasonsCount:  show.NumberOfSeasons,
+		EpisodesCount: show.NumberOfEpisodes,
+		Watched:       bool(playcount.GetWatchedShowByTMDB(show.ID)),
+		InLibrary:     library.IsAddedToLibrary(strconv.Itoa(show.ID), library.ShowType),
+	}
+	ret.Year, _ = strconv.Atoi(strings.Split(show.FirstAirDate, "-")[0])
+	if show.ExternalIDs != nil {
+		ret.IMDBID = show.ExternalIDs.IMDBId
+		ret.TVDBID = util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
+	}
+	return ret
+}
+
+func v2Season(show *tmdb.Show, season *tmdb.Season) *SeasonV2 {
+	return &SeasonV2{
+		Season:        season.Season,
+		Name:          season.Name,
+		AirDate:       season.AirDate,
+		EpisodesCount: season.EpisodeCount,
+		Poster:        v2Image(season.Poster, tmdb.ArtPoster),
+		Watched:       bool(playcount.GetWatchedSeasonByTMDB(show.ID, season.Season)),
+	}
+}
+
+func v2Episode(show *tmdb.Show, episode *tmdb.Episode) *EpisodeV2 {
+	return &EpisodeV2{
+		Season:   episode.SeasonNumber,
+		Episode:  episode.EpisodeNumber,
+		Name:     episode.Name,
+		AirDate:  episode.AirDate,
+		Overview: episode.Overview,
+		Runtime:  episode.Runtime,
+		Rating:   episode.VoteAverage,
+		Votes:    episode.VoteCount,
+		Still:    v2Image(episode.StillPath, tmdb.ArtThumb),
+		Watched:  bool(playcount.GetWatchedEpisodeByTMDB(show.ID, episode.SeasonNumber, episode.EpisodeNumber)),
+	}
+}
+
+func v2Movies(ctx *gin.Context, movies tmdb.Movies, page int, total int) {
+	region := contentRegion(ctx)
+	results := make([]*MovieV2, 0, len(movies))
+	for _, movie := range movies {
+		if movie == nil || !parental.AllowMovie(movie) {
+			continue
+		}
+		results = append(results, v2Movie(movie, region))
+	}
+	ctx.JSON(200, ListV2{Page: page, TotalResults: total, Results: results})
+}
+
+func v2Shows(ctx *gin.Context, shows tmdb.Shows, page int, total int) {
+	region := contentRegion(ctx)
+	results := make([]*ShowV2, 0, len(shows))
+	for _, show := range shows {
+		if show == nil || !parental.AllowShow(show) {
+			continue
+		}
+		results = append(results, v2Show(show, region))
+	}
+	ctx.JSON(200, ListV2{Page: page, TotalResults: total, Results: results})
+}
+
+// V2Status returns version, player and torrents state
+func V2Status(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(200, gin.H{
+			"version":  util.GetVersion(),
+			"playing":  xbmc.PlayerIsPlaying(),
+			"file":     xbmc.PlayerGetPlayingFile(),
+			"torrents": len(s.GetTorrents()),
+			"profile":  util.ProfileNames[util.GetResources().Profile],
+		})
+	}
+}
+
+// V2PopularMovies returns popular movies
+func V2PopularMovies(ctx *gin.Context) {
+	page := v2Page(ctx)
+	movies, total := tmdb.PopularMovies(tmdb.DiscoverFilters{}, config.Get().Language, page)
+	v2Movies(ctx, movies, page, total)
+}
+
+// V2SearchMovies returns movies, found by the "q" query
+func V2SearchMovies(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		v2Error(ctx, 400, "q is required")
+		return
+	}
+
+	page := v2Page(ctx)
+	movies, total := tmdb.SearchMovies(query, config.Get().Language, page)
+	v2Movies(ctx, movies, page, total)
+}
+
+// V2Movie returns the movie
+func V2Movie(ctx *gin.Context) {
+	tmdbID := ctx.Params.ByName("tmdbId")
+	movie := tmdb.GetMovieByID(tmdbID, config.Get().Language)
+	if movie == nil {
+		v2Error(ctx, 404, "movie %s is not found", tmdbID)
+		return
+	} else if !parental.AllowMovie(movie) {
+		v2Error(ctx, 403, "movie %s is blocked by parental control", tmdbID)
+		return
+	}
+
+	ctx.JSON(200, v2Movie(movie, contentRegion(ctx)))
+}
+
+// V2PopularShows returns popular shows
+func V2PopularShows(ctx *gin.Context) {
+	page := v2Page(ctx)
+	shows, total := tmdb.PopularShows(tmdb.DiscoverFilters{}, config.Get().Language, page)
+	v2Shows(ctx, shows, page, total)
+}
+
+// V2SearchShows returns shows, found by the "q" query
+func V2SearchShows(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		v2Error(ctx, 400, "q is required")
+		return
+	}
+
+	page := v2Page(ctx)
+	shows, total := tmdb.SearchShows(query, config.Get().Language, page)
+	v2Shows(ctx, shows, page, total)
+}
+
+// v2GetShow returns the show of "tmdbId" parameter, or aborts with an error
+func v2GetShow(ctx *gin.Context) *tmdb.Show {
+	tmdbID := ctx.Params.ByName("tmdbId")
+	show := tmdb.GetShowByID(tmdbID, config.Get().Language)
+	if show == nil {
+		v2Error(ctx, 404, "show %s is not found", tmdbID)
+		return nil
+	} else if !parental.AllowShow(show) {
+		v2Error(ctx, 403, "show %s is blocked by parental control", tmdbID)
+		return nil
+	}
+	return show
+}
+
+// V2Show returns the show with its seasons
+func V2Show(ctx *gin.Context) {
+	show := v2GetShow(ctx)
+	if show == nil {
+		return
+	}
+
+	ret := v2Show(show, contentRegion(ctx))
+	ret.Seasons = make([]*SeasonV2, 0, len(show.Seasons))
+	for _, season := range show.Seasons {
+		if season != nil {
+			ret.Seasons = append(ret.Seasons, v2Season(show, season))
+		}
+	}
+	ctx.JSON(200, ret)
+}
+
+// V2Season returns the season of the show with its episodes
+func V2Season(ctx *gin.Context) {
+	show := v2GetShow(ctx)
+	if show == nil {
+		return
+	}
+
+	seasonNumber := strToInt(ctx.Params.ByName("season"), -1)
+	season := tmdb.GetSeason(show.ID, seasonNumber, config.Get().Language, len(show.Seasons))
+	if seasonNumber < 0 || season == nil {
+		v2Error(ctx, 404, "season %s is not found", ctx.Params.ByName("season"))
+		return
+	}
+
+	ret := v2Season(show, season)
+	ret.Episodes = make([]*EpisodeV2, 0, len(season.Episodes))
+	for _, episode := range season.Episodes {
+		if episode != nil {
+			ret.Episodes = append(ret.Episodes, v2Episode(show, episode))
+		}
+	}
+	ctx.JSON(200, ret)
+}
+
+func v2Torrent(t *bittorrent.Torrent, withFiles bool) *TorrentV2 {
+	ret := &TorrentV2{
+		ID:           t.InfoHash(),
+		Name:         t.Name(),
+		Size:         t.Length(),
+		Status:       t.GetStateString(),
+		Paused:       t.IsPaused,
+		Progress:     t.GetProgress(),
+		TrackerError: t.TrackerError(),
+	}
+	ret.Seeders, ret.SeedersTotal, ret.Peers, ret.PeersTotal = t.GetConnections()
+
+	if th := t.GetHandle(); th != nil && th.IsValid() {
+		torrentStatus := th.Status()
+		ret.DownloadRate = torrentStatus.GetDownloadPayloadRate()
+		ret.UploadRate = torrentStatus.GetUploadPayloadRate()
+		lt.DeleteTorrentStatus(torrentStatus)
+	}
+
+	if withFiles {
+		ret.Files = make([]*TorrentFileV2, 0, len(t.GetFiles()))
+		for _, f := range t.GetFiles() {
+			ret.Files = append(ret.Files, &TorrentFileV2{
+				Index:    f.Index,
+				Path:     f.Path,
+				Size:     f.Size,
+				Selected: f.Selected,
+			})
+		}
+	}
+	return ret
+}
+
+// V2Torrents returns active torrents
+func V2Torrents(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		torrents := s.GetTorrents()
+		results := make([]*TorrentV2, 0, len(torrents))
+		for _, t := range torrents {
+			if t != nil && t.HasMetadata() {
+				results = append(results, v2Torrent(t, false))
+			}
+		}
+		ctx.JSON(200, ListV2{Page: 1, TotalResults: len(results), Results: results})
+	}
+}
+
+// v2GetTorrent returns the torrent of "torrentId" parameter, or aborts with an error
+func v2GetTorrent(ctx *gin.Context, s *bittorrent.Service) *bittorrent.Torrent {
+	torrentID := ctx.Params.ByName("torrentId")
+	t, err := GetTorrentFromParam(s, torrentID)
+	if err != nil {
+		v2Error(ctx, 404, "torrent %s is not found", torrentID)
+		return nil
+	}
+	return t
+}
+
+// V2Torrent returns the torrent with its files
+func V2Torrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if t := v2GetTorrent(ctx, s); t != nil {
+			ctx.JSON(200, v2Torrent(t, true))
+		}
+	}
+}
+
+// V2AddTorrent adds the torrent, and downloads its biggest file, or all files
+func V2AddTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req AddTorrentV2Request
+		if err := ctx.BindJSON(&req); err != nil {
+			v2Error(ctx, 400, "%s", err)
+			return
+		} else if req.URI == "" {
+			v2Error(ctx, 400, "uri is required")
+			return
+		}
+
+		torrentsLog.Infof("Adding torrent from %s", req.URI)
+		t, err := s.AddTorrent(req.URI, false, config.Get().DownloadStorage)
+		if err != nil {
+			v2Error(ctx, 400, "%s", err)
+			return
+		}
+
+		// Create initial BTItem entry
+		database.GetStorm().UpdateBTItem(t.InfoHash(), 0, "", []string{}, t.Name(), 0, 0, 0)
+
+		if req.All {
+			t.DownloadAllFiles()
+		} else if file, _, err := t.ChooseFile(nil); err == nil && file != nil {
+			t.DownloadFile(file)
+		}
+		t.SaveDBFiles()
+
+		ctx.JSON(200, v2Torrent(t, true))
+	}
+}
+
+// V2PauseTorrent pauses the torrent
+func V2PauseTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if t := v2GetTorrent(ctx, s); t != nil {
+			t.Pause()
+			ctx.JSON(200, v2Torrent(t, false))
+		}
+	}
+}
+
+// V2ResumeTorrent resumes the torrent
+func V2ResumeTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if t := v2GetTorrent(ctx, s); t != nil {
+			t.Resume()
+			ctx.JSON(200, v2Torrent(t, false))
+		}
+	}
+}
+
+// V2RemoveTorrent removes the torrent, and its files, when "files" query is set
+func V2RemoveTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		t := v2GetTorrent(ctx, s)
+		if t == nil {
+			return
+		}
+
+		deleteFiles, _ := strconv.ParseBool(ctx.DefaultQuery("files", "false"))
+		s.RemoveTorrent(t, true, deleteFiles, false)
+		ctx.JSON(200, gin.H{"result": "success"})
+	}
+}
+
+// V2LibraryJobs returns queued, running and recently finished additions to the library
+func V2LibraryJobs(ctx *gin.Context) {
+	results := []*LibraryJobV2{}
+	for _, j := range library.Jobs() {
+		job := &LibraryJobV2{
+			ID:      j.ID,
+			Type:    movieType,
+			TmdbID:  j.TmdbID,
+			Title:   j.Title,
+			State:   library.JobStateNames[j.State],
+			Error:   j.Error,
+			Created: j.Created.Unix(),
+		}
+		if j.MediaType == library.ShowType {
+			job.Type = showType
+		}
+		if !j.Active() {
+			job.Finished = j.Finished.Unix()
+		}
+		results = append(results, job)
+	}
+	ctx.JSON(200, ListV2{Page: 1, TotalResults: len(results), Results: results})
+}
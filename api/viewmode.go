@@ -0,0 +1,72 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	viewAll            = "all"
+	viewUnwatchedFirst = "unwatchedfirst"
+	viewHideWatched    = "hidewatched"
+)
+
+func viewModePreferenceKey(ctx *gin.Context) string {
+	return "list.view." + ctx.Request.URL.Path
+}
+
+// listViewMode returns view mode from "view" query parameter, which is remembered
+// for the list, or previously selected view mode for the list
+func listViewMode(ctx *gin.Context) string {
+	key := viewModePreferenceKey(ctx)
+	mode := strings.ToLower(ctx.Query("view"))
+	if mode == viewAll {
+		database.GetCache().Delete(database.CommonBucket, key)
+		return ""
+	} else if mode == viewUnwatchedFirst || mode == viewHideWatched {
+		database.GetCache().SetCached(database.CommonBucket, sortPreferenceExpiration, key, mode)
+		return mode
+	}
+
+	mode, _ = database.GetCache().GetCached(database.CommonBucket, key)
+	return mode
+}
+
+func isWatchedItem(i *xbmc.ListItem) bool {
+	return i.Info != nil && i.Info.Mediatype != "" && i.Info.PlayCount > 0
+}
+
+// viewListItems moves watched items to the bottom of the list, or removes them,
+// according to selected view mode, order of other items is kept
+func viewListItems(ctx *gin.Context, items xbmc.ListItems) xbmc.ListItems {
+	mode := listViewMode(ctx)
+	if mode != viewUnwatchedFirst && mode != viewHideWatched {
+		return items
+	}
+
+	ret := make(xbmc.ListItems, 0, len(items))
+	watched := xbmc.ListItems{}
+	for _, i := range items {
+		if !isWatchedItem(i) {
+			ret = append(ret, i)
+		} else if mode == viewUnwatchedFirst {
+			watched = append(watched, i)
+		}
+	}
+	if len(watched) == 0 {
+		return ret
+	}
+
+	// Keep next page and other service items at the end
+	media := len(ret)
+	for media > 0 && (ret[media-1].Info == nil || ret[media-1].Info.Mediatype == "") {
+		media--
+	}
+
+	others := append(xbmc.ListItems{}, ret[media:]...)
+	return append(append(ret[:media], watched...), others...)
+}
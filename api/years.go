@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// firstDecade is the earliest decade, offered for browsing
+const firstDecade = 1900
+
+// decadeItems returns list of decades, starting with the current one
+func decadeItems(kind string, view string) xbmc.ListItems {
+	items := make(xbmc.ListItems, 0)
+	for decade := time.Now().Year() / 10 * 10; decade >= firstDecade; decade -= 10 {
+		items = append(items, &xbmc.ListItem{
+			Label: fmt.Sprintf("%ds", decade),
+			Path:  URLForXBMC("/%s/years/%d", kind, decade),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/%s", view))},
+			},
+		})
+	}
+	return items
+}
+
+// yearItems returns list of years of the decade, latest first, future years are skipped
+func yearItems(kind string, view string, decade int) xbmc.ListItems {
+	items := make(xbmc.ListItems, 0)
+	for year := decade + 9; year >= decade; year-- {
+		if year > time.Now().Year() {
+			continue
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label: strconv.Itoa(year),
+			Path:  URLForXBMC("/%s/year/%d", kind, year),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/%s", view))},
+			},
+		})
+	}
+	return items
+}
+
+// MovieDecades ...
+func MovieDecades(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.JSON(200, xbmc.NewView("menus_movies_years", decadeItems("movies", "menus_movies_years")))
+}
+
+// MovieYears ...
+func MovieYears(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	decade, _ := strconv.Atoi(ctx.Params.ByName("decade"))
+	ctx.JSON(200, xbmc.NewView("menus_movies_years", yearItems("movies", "menus_movies_years", decade/10*10)))
+}
+
+// MoviesByYear ...
+func MoviesByYear(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	year, _ := strconv.Atoi(ctx.Params.ByName("year"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.MoviesByYear(year, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// TVDecades ...
+func TVDecades(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.JSON(200, xbmc.NewView("menus_tvshows_years", decadeItems("shows", "menus_tvshows_years")))
+}
+
+// TVYears ...
+func TVYears(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	decade, _ := strconv.Atoi(ctx.Params.ByName("decade"))
+	ctx.JSON(200, xbmc.NewView("menus_tvshows_years", yearItems("shows", "menus_tvshows_years", decade/10*10)))
+}
+
+// ShowsByYear ...
+func ShowsByYear(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	year, _ := strconv.Atoi(ctx.Params.ByName("year"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.ShowsByYear(year, config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
@@ -0,0 +1,71 @@
+package bittorrent
+
+import (
+	"sort"
+	"time"
+
+	"github.com/projectx13/projectx/config"
+)
+
+// Reannounce forces announce to all trackers and DHT. Private torrents are not
+// announced more often than min_announce_interval, returns false in that case.
+func (t *Torrent) Reannounce() bool {
+	if t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 {
+		return false
+	}
+
+	t.reannounceMu.Lock()
+	defer t.reannounceMu.Unlock()
+
+	isPrivate := t.IsPrivate()
+	if isPrivate && time.Since(t.lastReannounce) < time.Duration(minAnnounceInterval())*time.Second {
+		log.Infof("Skipping reannounce of private torrent %s, last one was at %s", t.Name(), t.lastReannounce.Format(time.Stamp))
+		return false
+	}
+
+	log.Infof("Forcing reannounce of %s", t.Name())
+	t.lastReannounce = time.Now()
+	t.th.ForceReannounce()
+	if !isPrivate && !config.Get().DisableDHT {
+		t.th.ForceDhtAnnounce()
+	}
+	return true
+}
+
+// TrackerErrors returns last errors of trackers, failing to announce, by tracker URL
+func (t *Torrent) TrackerErrors() map[string]string {
+	ret := map[string]string{}
+	t.trackerErrors.Range(func(u, e interface{}) bool {
+		ret[u.(string)] = e.(string)
+		return true
+	})
+	return ret
+}
+
+// TrackerError returns error of the first failing tracker, like "unregistered torrent",
+// or empty string if all trackers are fine
+func (t *Torrent) TrackerError() string {
+	errors := t.TrackerErrors()
+	if len(errors) == 0 {
+		return ""
+	}
+
+	urls := make([]string, 0, len(errors))
+	for u := range errors {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	return errors[urls[0]]
+}
+
+// onTrackerError keeps error message, so it is shown in torrents list,
+// libtorrent retries the tracker itself, with tracker_backoff
+func (t *Torrent) onTrackerError(trackerURL string, message string, timesInRow int) {
+	if message == "" {
+		message = "unknown error"
+	}
+	t.trackerErrors.Store(trackerURL, message)
+
+	log.Debugf("Tracker %s of %s failed %d times in a row: %s", trackerURL, t.Name(), timesInRow, message)
+}
@@ -0,0 +1,68 @@
+package bittorrent
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+)
+
+const (
+	bandwidthPeakKey = "bittorrent.bandwidth.peak"
+	// bandwidthPeakDuration is how long the peak is kept, so it follows changes of the connection
+	bandwidthPeakDuration = 60 * 60 * 24 * 30
+)
+
+var (
+	bandwidthMu     sync.Mutex
+	bandwidthPeak   int64
+	bandwidthLoaded bool
+)
+
+// ConnectionSpeed returns speed of the connection in bytes per second, configured in settings,
+// or the highest download rate of all torrents, seen in last month, or 0, when it is not known yet
+func ConnectionSpeed() int64 {
+	if mbits := config.Get().ConnectionSpeed; mbits > 0 {
+		return int64(mbits) * 1000 * 1000 / 8
+	}
+
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	loadBandwidthPeak()
+	return bandwidthPeak
+}
+
+// recordDownloadRate remembers total download rate, when it is a new peak
+func recordDownloadRate(rate int64) {
+	bandwidthMu.Lock()
+	defer bandwidthMu.Unlock()
+
+	loadBandwidthPeak()
+	// Small increases are not stored, so the database is not written on every tick, while rate grows
+	if rate <= bandwidthPeak+bandwidthPeak/10 {
+		return
+	}
+
+	bandwidthPeak = rate
+	if db := database.GetCache(); db != nil {
+		db.SetCached(database.CommonBucket, bandwidthPeakDuration, bandwidthPeakKey, strconv.FormatInt(rate, 10))
+	}
+}
+
+func loadBandwidthPeak() {
+	if bandwidthLoaded {
+		return
+	}
+
+	db := database.GetCache()
+	if db == nil {
+		return
+	}
+	bandwidthLoaded = true
+
+	if value, err := db.GetCached(database.CommonBucket, bandwidthPeakKey); err == nil && value != "" {
+		bandwidthPeak, _ = strconv.ParseInt(value, 10, 64)
+	}
+}
@@ -0,0 +1,83 @@
+package bittorrent
+
+import (
+	"time"
+)
+
+// boostFactor multiplies connection limits while boost is active
+const boostFactor = 4
+
+func (s *Service) connectionsLimit() int {
+	if s.config.ConnectionsLimit > 0 {
+		return s.config.ConnectionsLimit
+	}
+	return getPlatformSpecificConnectionLimit()
+}
+
+func (s *Service) connectionSpeed() int {
+	if s.config.ConnTrackerLimitAuto || s.config.ConnTrackerLimit == 0 {
+		return 500
+	}
+	return s.config.ConnTrackerLimit
+}
+
+// Boost raises connection limits and disables upload for the first seconds of a new stream,
+// so buffering is not slowed down by saturated upload on asymmetric connections.
+// Normal limits are restored after boost_duration seconds.
+func (s *Service) Boost(t *Torrent) {
+	if s.config.BoostDuration <= 0 || t == nil || t.th == nil || t.th.Swigcptr() == 0 {
+		return
+	}
+
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+
+	if s.boostTimer != nil {
+		s.boostTimer.Stop()
+		s.restoreTorrentConnections(s.boostTorrent)
+	}
+
+	log.Infof("Boosting connections of %s for %d seconds", t.Name(), s.config.BoostDuration)
+
+	settings := s.PackSettings
+	settings.SetInt("connections_limit", s.connectionsLimit()*boostFactor)
+	settings.SetInt("connection_speed", s.connectionSpeed()*boostFactor)
+	// Zero means unlimited, so minimal limit is used to stop uploading
+	settings.SetInt("upload_rate_limit", 1)
+	s.Session.ApplySettings(settings)
+
+	t.th.SetMaxConnections(getPlatformSpecificConnectionLimit() * boostFactor)
+
+	s.boostTorrent = t
+	s.boostTimer = time.AfterFunc(time.Duration(s.config.BoostDuration)*time.Second, s.stopBoost)
+}
+
+func (s *Service) stopBoost() {
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+
+	if s.Closer.IsSet() {
+		return
+	}
+
+	log.Info("Boost finished, restoring connection limits")
+
+	settings := s.PackSettings
+	settings.SetInt("connections_limit", s.connectionsLimit())
+	settings.SetInt("connection_speed", s.connectionSpeed())
+	_, uploadLimit := s.rateLimits()
+	settings.SetInt("upload_rate_limit", uploadLimit)
+	s.Session.ApplySettings(settings)
+
+	s.restoreTorrentConnections(s.boostTorrent)
+
+	s.boostTorrent = nil
+	s.boostTimer = nil
+}
+
+func (s *Service) restoreTorrentConnections(t *Torrent) {
+	if t == nil || t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 {
+		return
+	}
+	t.th.SetMaxConnections(getPlatformSpecificConnectionLimit())
+}
@@ -0,0 +1,238 @@
+package bittorrent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	lt "github.com/projectxorg/libtorrent-go"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	geoIPFileName = "geoip-country.csv.gz"
+	// Free country database from db-ip.com, updated monthly
+	geoIPURL            = "https://download.db-ip.com/free/dbip-country-lite-%s.csv.gz"
+	geoIPUpdateInterval = 30 * 24 * time.Hour
+)
+
+var geoIPClient = &http.Client{
+	Timeout: 5 * time.Minute,
+}
+
+type geoIPRange struct {
+	start   net.IP
+	end     net.IP
+	country string
+}
+
+// GeoIP resolves country of peer address using ranges from GeoIP database
+type GeoIP struct {
+	mu     sync.RWMutex
+	ranges []geoIPRange
+}
+
+var (
+	geoIP         = &GeoIP{}
+	geoIPUpdateMu sync.Mutex
+)
+
+// IsLoaded returns whether GeoIP database is loaded
+func (g *GeoIP) IsLoaded() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return len(g.ranges) > 0
+}
+
+// Country returns ISO code of the country for the address, or empty string if it is unknown
+func (g *GeoIP) Country(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return ""
+	}
+	ip = ip.To16()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	// First range, which starts after the address, previous one could contain it
+	i := sort.Search(len(g.ranges), func(i int) bool {
+		return bytes.Compare(g.ranges[i].start, ip) > 0
+	})
+	if i == 0 {
+		return ""
+	}
+	if r := g.ranges[i-1]; bytes.Compare(ip, r.end) <= 0 {
+		return r.country
+	}
+	return ""
+}
+
+// countryRanges returns ranges, which belong to any of the countries
+func (g *GeoIP) countryRanges(countries []string) []geoIPRange {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	ret := []geoIPRange{}
+	for _, r := range g.ranges {
+		for _, c := range countries {
+			if r.country == c {
+				ret = append(ret, r)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func (g *GeoIP) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	ranges := []geoIPRange{}
+	reader := csv.NewReader(gz)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		} else if len(record) < 3 {
+			continue
+		}
+
+		start, end := net.ParseIP(record[0]), net.ParseIP(record[1])
+		if start == nil || end == nil {
+			continue
+		}
+		ranges = append(ranges, geoIPRange{start: start.To16(), end: end.To16(), country: record[2]})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start, ranges[j].start) < 0
+	})
+
+	g.mu.Lock()
+	g.ranges = ranges
+	g.mu.Unlock()
+
+	log.Infof("Loaded %d GeoIP ranges from %s", len(ranges), path)
+	return nil
+}
+
+func downloadGeoIP(path string) error {
+	url := fmt.Sprintf(geoIPURL, time.Now().Format("2006-01"))
+	log.Infof("Downloading GeoIP database from %s", url)
+
+	resp, err := geoIPClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Bad status downloading %s: %d", url, resp.StatusCode)
+	}
+
+	// Download into temporary file, so broken download does not replace working database
+	tmpPath := path + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	out.Close()
+
+	return os.Rename(tmpPath, path)
+}
+
+// geoIPUpdater keeps GeoIP database in the profile folder updated
+func (s *Service) geoIPUpdater() {
+	s.updateGeoIP()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	closing := s.Closer.C()
+	for {
+		select {
+		case <-ticker.C:
+			s.updateGeoIP()
+		case <-closing:
+			return
+		}
+	}
+}
+
+// updateGeoIP downloads GeoIP database, if it is missing or outdated, loads it
+// and applies country filter
+func (s *Service) updateGeoIP() {
+	geoIPUpdateMu.Lock()
+	defer geoIPUpdateMu.Unlock()
+
+	if s.config.GeoIPEnabled || len(s.config.BlockedCountries) > 0 {
+		path := filepath.Join(config.Get().ProfilePath, geoIPFileName)
+
+		stat, err := os.Stat(path)
+		if err != nil || time.Since(stat.ModTime()) > geoIPUpdateInterval {
+			if err := downloadGeoIP(path); err != nil {
+				log.Warningf("Could not download GeoIP database: %s", err)
+			} else if err := geoIP.load(path); err != nil {
+				log.Warningf("Could not load GeoIP database: %s", err)
+			}
+		}
+
+		if !geoIP.IsLoaded() {
+			if err := geoIP.load(path); err != nil {
+				log.Warningf("Could not load GeoIP database: %s", err)
+			}
+		}
+	}
+
+	s.applyCountryFilter()
+}
+
+// applyCountryFilter blocks peers from countries, selected in blocked_countries setting
+func (s *Service) applyCountryFilter() {
+	filter := lt.NewIpFilter()
+	defer lt.DeleteIpFilter(filter)
+
+	if len(s.config.BlockedCountries) > 0 {
+		if !geoIP.IsLoaded() {
+			log.Warning("GeoIP database is not loaded yet, peers are not blocked by country")
+			return
+		}
+
+		ranges := geoIP.countryRanges(s.config.BlockedCountries)
+		for _, r := range ranges {
+			filter.AddRule(lt.AddressFromString(r.start.String()), lt.AddressFromString(r.end.String()), uint(lt.IpFilterBlocked))
+		}
+		log.Infof("Blocking %d address ranges of countries: %v", len(ranges), s.config.BlockedCountries)
+	}
+
+	s.Session.SetIpFilter(filter)
+}
@@ -0,0 +1,210 @@
+package bittorrent
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeebo/bencode"
+
+	"github.com/projectx13/projectx/database"
+)
+
+// ClientTorrent is a torrent, found in session of another client
+type ClientTorrent struct {
+	Name        string
+	InfoHash    string
+	TorrentFile string
+	SavePath    string
+	Files       []*ClientTorrentFile
+}
+
+// ClientTorrentFile is a file of the torrent, with path relative to save path
+type ClientTorrentFile struct {
+	Path string
+	Size int64
+}
+
+type clientMetaInfo struct {
+	Info bencode.RawMessage `bencode:"info"`
+}
+
+type clientInfoDict struct {
+	Name   string `bencode:"name"`
+	Length int64  `bencode:"length"`
+	Files  []struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	} `bencode:"files"`
+}
+
+// qBittorrent keeps its save path in own key, libtorrent's key is used by older versions
+type qbittorrentResume struct {
+	QBtSavePath string `bencode:"qBt-savePath"`
+	SavePath    string `bencode:"save_path"`
+}
+
+type transmissionResume struct {
+	Destination string `bencode:"destination"`
+}
+
+// FindClientTorrents scans session of another client, qBittorrent's BT_backup folder
+// or Transmission's config folder, and returns torrents with all files in place
+func FindClientTorrents(dir string) ([]*ClientTorrent, error) {
+	if _, err := os.Stat(filepath.Join(dir, "resume")); err == nil {
+		return findTransmissionTorrents(dir)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.fastresume"))
+	if err != nil {
+		return nil, err
+	} else if len(matches) == 0 {
+		return nil, errors.New("No qBittorrent or Transmission session found")
+	}
+
+	ret := []*ClientTorrent{}
+	for _, resumeFile := range matches {
+		var resume qbittorrentResume
+		if err := decodeBencodeFile(resumeFile, &resume); err != nil {
+			log.Warningf("Cannot read qBittorrent resume data %s: %s", resumeFile, err)
+			continue
+		}
+
+		savePath := resume.QBtSavePath
+		if savePath == "" {
+			savePath = resume.SavePath
+		}
+		torrentFile := strings.TrimSuffix(resumeFile, ".fastresume") + ".torrent"
+		if ct := readClientTorrent(torrentFile, savePath); ct != nil {
+			ret = append(ret, ct)
+		}
+	}
+	return ret, nil
+}
+
+func findTransmissionTorrents(dir string) ([]*ClientTorrent, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "resume", "*.resume"))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*ClientTorrent{}
+	for _, resumeFile := range matches {
+		var resume transmissionResume
+		if err := decodeBencodeFile(resumeFile, &resume); err != nil {
+			log.Warningf("Cannot read Transmission resume data %s: %s", resumeFile, err)
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(resumeFile), ".resume")
+		torrentFile := filepath.Join(dir, "torrents", name+".torrent")
+		if ct := readClientTorrent(torrentFile, resume.Destination); ct != nil {
+			ret = append(ret, ct)
+		}
+	}
+	return ret, nil
+}
+
+// readClientTorrent reads files of the torrent, and returns nil, if any of them is missing
+func readClientTorrent(torrentFile string, savePath string) *ClientTorrent {
+	if savePath == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(torrentFile)
+	if err != nil {
+		log.Warningf("Cannot read torrent file %s: %s", torrentFile, err)
+		return nil
+	}
+
+	var meta clientMetaInfo
+	var info clientInfoDict
+	if err := bencode.DecodeBytes(data, &meta); err != nil || len(meta.Info) == 0 {
+		log.Warningf("Cannot decode torrent file %s: %v", torrentFile, err)
+		return nil
+	} else if err := bencode.DecodeBytes(meta.Info, &info); err != nil {
+		log.Warningf("Cannot decode torrent info of %s: %s", torrentFile, err)
+		return nil
+	}
+
+	hash := sha1.Sum(meta.Info)
+	ct := &ClientTorrent{
+		Name:        info.Name,
+		InfoHash:    hex.EncodeToString(hash[:]),
+		TorrentFile: torrentFile,
+		SavePath:    savePath,
+	}
+	if len(info.Files) == 0 {
+		ct.Files = append(ct.Files, &ClientTorrentFile{Path: info.Name, Size: info.Length})
+	}
+	for _, f := range info.Files {
+		ct.Files = append(ct.Files, &ClientTorrentFile{
+			Path: filepath.Join(append([]string{info.Name}, f.Path...)...),
+			Size: f.Length,
+		})
+	}
+
+	for _, f := range ct.Files {
+		if fi, err := os.Stat(filepath.Join(savePath, f.Path)); err != nil || fi.Size() != f.Size {
+			log.Debugf("Skipping %s, file %s is missing or incomplete", ct.Name, f.Path)
+			return nil
+		}
+	}
+	return ct
+}
+
+func decodeBencodeFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return bencode.DecodeBytes(data, v)
+}
+
+// ImportTorrent puts files of the torrent from another client into save path,
+// and adds the torrent to the session. Files are hard-linked, so the other client keeps seeding them,
+// or moved, if the filesystem has no hard links. Libtorrent checks files, when the torrent is added,
+// so nothing is downloaded again.
+func (s *Service) ImportTorrent(ct *ClientTorrent) (*Torrent, error) {
+	if s.GetTorrentByHash(ct.InfoHash) != nil {
+		return nil, fmt.Errorf("Torrent %s is already in the session", ct.Name)
+	}
+
+	// Torrent is added to stream cache, if it is set, and moved to download path with other finished torrents
+	savePath := s.savePath(StorageFile)
+	if filepath.Clean(ct.SavePath) != filepath.Clean(savePath) {
+		for _, f := range ct.Files {
+			src := filepath.Join(ct.SavePath, f.Path)
+			dst := filepath.Join(savePath, f.Path)
+			if _, err := os.Stat(dst); err == nil {
+				continue
+			}
+
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.Link(src, dst); err != nil {
+				log.Infof("Cannot link %s, moving it instead: %s", src, err)
+				if err := os.Rename(src, dst); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	log.Infof("Importing torrent %s from %s", ct.Name, ct.SavePath)
+	t, err := s.AddTorrent(ct.TorrentFile, false, StorageFile)
+	if err != nil {
+		return nil, err
+	}
+
+	database.GetStorm().UpdateBTItem(t.InfoHash(), 0, "", []string{}, t.Name(), 0, 0, 0)
+	t.DownloadAllFiles()
+	t.SaveDBFiles()
+	return t, nil
+}
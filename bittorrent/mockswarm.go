@@ -0,0 +1,55 @@
+package bittorrent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectx13/projectx/mock"
+	"github.com/projectx13/projectx/util"
+)
+
+// mockSwarmTorrent returns fixture torrent file of the magnet in mock mode,
+// so the torrent is added with metadata, which otherwise comes from peers
+func mockSwarmTorrent(uri string) string {
+	if !mock.Enabled() || !strings.HasPrefix(uri, "magnet:") {
+		return uri
+	}
+
+	if path := mock.SwarmTorrent(NewTorrentFile(uri).InfoHash); path != "" {
+		log.Infof("Using fixture torrent %s for %s", path, uri)
+		return path
+	}
+	return uri
+}
+
+// mockSwarmSeed puts fixture files of the torrent into save path in mock mode.
+// Libtorrent checks them after adding, so the torrent is complete without any peers,
+// and playback is the same on every run.
+func mockSwarmSeed(infoHash string, savePath string) {
+	if !mock.Enabled() {
+		return
+	}
+	src := mock.SwarmFiles(infoHash)
+	if src == "" {
+		return
+	}
+
+	log.Infof("Seeding %s from fixture files in %s", infoHash, src)
+	filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		rel, _ := filepath.Rel(src, path)
+		dst := filepath.Join(savePath, rel)
+		if _, err := os.Stat(dst); err == nil {
+			return nil
+		}
+		os.MkdirAll(filepath.Dir(dst), 0755)
+		if err := util.CopyFile(path, dst, true); err != nil {
+			log.Warningf("Could not copy fixture file %s: %s", path, err)
+		}
+		return nil
+	})
+}
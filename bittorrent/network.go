@@ -0,0 +1,166 @@
+package bittorrent
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Types of the network, which is used for the default route
+const (
+	NetworkUnknown = iota
+	NetworkWired
+	NetworkWiFi
+	NetworkCellular
+)
+
+// NetworkNames are names of network types for logs
+var NetworkNames = []string{"Unknown", "Wired", "WiFi", "Cellular"}
+
+const networkCheckInterval = 30 * time.Second
+
+// Android names mobile data interfaces by modem vendor, VPN interfaces are not classified,
+// since it is not known what they are running over
+var (
+	cellularPrefixes = []string{"rmnet", "ccmni", "pdp", "wwan", "seth", "v4-rmnet", "rev_rmnet", "clat"}
+	wifiPrefixes     = []string{"wlan", "wifi", "swlan", "ap"}
+	wiredPrefixes    = []string{"eth", "en"}
+)
+
+// networkType is the last detected type, shared with providers, which skip 4K results on metered networks
+var networkType int32
+
+// IsMeteredNetwork returns true, when default route goes over mobile data
+func IsMeteredNetwork() bool {
+	return atomic.LoadInt32(&networkType) == NetworkCellular
+}
+
+// DetectNetwork returns type of the network, which has the default route, by interface name
+func DetectNetwork() int {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return NetworkUnknown
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Default route has zero destination and mask
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		return networkByInterface(fields[0])
+	}
+	return NetworkUnknown
+}
+
+func networkByInterface(name string) int {
+	hasPrefix := func(prefixes []string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasPrefix(cellularPrefixes):
+		return NetworkCellular
+	case hasPrefix(wifiPrefixes):
+		return NetworkWiFi
+	case hasPrefix(wiredPrefixes):
+		return NetworkWired
+	}
+	return NetworkUnknown
+}
+
+// networkWatcher follows network changes on Android, and applies metered network policies,
+// when device switches to mobile data
+func (s *Service) networkWatcher() {
+	if s.config.Platform == nil || s.config.Platform.OS != "android" {
+		return
+	}
+
+	ticker := time.NewTicker(networkCheckInterval)
+	defer ticker.Stop()
+
+	closing := s.Closer.C()
+	for {
+		s.checkNetwork()
+
+		select {
+		case <-ticker.C:
+		case <-closing:
+			return
+		}
+	}
+}
+
+func (s *Service) checkNetwork() {
+	current := int32(DetectNetwork())
+	previous := atomic.SwapInt32(&networkType, current)
+	if current != previous {
+		log.Infof("Network changed from %s to %s", NetworkNames[previous], NetworkNames[current])
+		s.RestoreLimits()
+		if current != NetworkCellular {
+			s.resumeMeteredPaused()
+		}
+	}
+
+	if current == NetworkCellular && s.config.MeteredPauseSeeding {
+		s.pauseSeeding()
+	}
+}
+
+// pauseSeeding pauses finished torrents, which are not played, and remembers them
+// to resume after device is back on unmetered network
+func (s *Service) pauseSeeding() {
+	s.meteredMu.Lock()
+	defer s.meteredMu.Unlock()
+
+	for _, t := range s.q.All() {
+		if t.IsMemoryStorage() || t.IsPlaying || t.GetPaused() || t.GetRealProgress() < 100 {
+			continue
+		}
+
+		log.Infof("Pausing seeding of %s on metered network", t.Name())
+		t.Pause()
+		s.meteredPaused[t.InfoHash()] = true
+	}
+}
+
+func (s *Service) resumeMeteredPaused() {
+	s.meteredMu.Lock()
+	defer s.meteredMu.Unlock()
+
+	for infoHash := range s.meteredPaused {
+		if t := s.GetTorrentByHash(infoHash); t != nil && t.GetPaused() {
+			log.Infof("Resuming seeding of %s on unmetered network", t.Name())
+			t.Resume()
+		}
+	}
+	s.meteredPaused = map[string]bool{}
+}
+
+// rateLimits returns download and upload limits for current network,
+// metered network limits are used, when they are lower than general ones
+func (s *Service) rateLimits() (download int, upload int) {
+	download, upload = s.config.DownloadRateLimit, s.config.UploadRateLimit
+	if !IsMeteredNetwork() {
+		return
+	}
+
+	lower := func(limit int, metered int) int {
+		if metered > 0 && (limit == 0 || metered < limit) {
+			return metered
+		}
+		return limit
+	}
+	download = lower(download, s.config.MeteredDownloadRateLimit)
+	upload = lower(upload, s.config.MeteredUploadRateLimit)
+	return
+}
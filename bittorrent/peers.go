@@ -0,0 +1,110 @@
+package bittorrent
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	lt "github.com/projectxorg/libtorrent-go"
+)
+
+// clientVersionRegexp cuts version from client name, like "qBittorrent 4.2.5"
+var clientVersionRegexp = regexp.MustCompile(`[\s/]+v?\d[\w.\-]*$`)
+
+// PeerInfo describes connected peer
+type PeerInfo struct {
+	IP           string  `json:"ip"`
+	Country      string  `json:"country"`
+	Client       string  `json:"client"`
+	DownloadRate int     `json:"download_rate"`
+	UploadRate   int     `json:"upload_rate"`
+	Progress     float64 `json:"progress"`
+}
+
+// PeerCount is a number of peers, grouped by country or client
+type PeerCount struct {
+	Name  string `json:"name"`
+	Peers int    `json:"peers"`
+}
+
+// PeerStats contains connected peers with breakdown by country and client
+type PeerStats struct {
+	Peers     []*PeerInfo  `json:"peers"`
+	Countries []*PeerCount `json:"countries"`
+	Clients   []*PeerCount `json:"clients"`
+}
+
+// GetPeerStats returns connected peers of the torrent,
+// countries are resolved only when GeoIP database is loaded
+func (t *Torrent) GetPeerStats() *PeerStats {
+	stats := &PeerStats{
+		Peers:     []*PeerInfo{},
+		Countries: []*PeerCount{},
+		Clients:   []*PeerCount{},
+	}
+	if t.th == nil || t.th.Swigcptr() == 0 || !t.th.IsValid() {
+		return stats
+	}
+
+	vectorPeers := lt.NewStdVectorPeerInfo()
+	defer lt.DeleteStdVectorPeerInfo(vectorPeers)
+	t.th.GetPeerInfo(vectorPeers)
+
+	countries := map[string]int{}
+	clients := map[string]int{}
+	for i := 0; i < int(vectorPeers.Size()); i++ {
+		p := vectorPeers.Get(i)
+
+		ip := p.GetIp().Address().ToString()
+		peer := &PeerInfo{
+			IP:           ip,
+			Country:      geoIP.Country(ip),
+			Client:       p.GetClient(),
+			DownloadRate: p.GetPayloadDownSpeed(),
+			UploadRate:   p.GetPayloadUpSpeed(),
+			Progress:     float64(p.GetProgress()) * 100,
+		}
+		stats.Peers = append(stats.Peers, peer)
+
+		countries[peer.Country]++
+		clients[clientName(peer.Client)]++
+	}
+
+	sort.Slice(stats.Peers, func(i, j int) bool {
+		return stats.Peers[i].DownloadRate > stats.Peers[j].DownloadRate
+	})
+
+	if geoIP.IsLoaded() {
+		stats.Countries = peerCounts(countries)
+	}
+	stats.Clients = peerCounts(clients)
+
+	return stats
+}
+
+// clientName returns client name without version
+func clientName(client string) string {
+	if client = strings.TrimSpace(clientVersionRegexp.ReplaceAllString(client, "")); client == "" {
+		return "Unknown"
+	}
+	return client
+}
+
+// peerCounts converts counters into list, sorted by number of peers
+func peerCounts(m map[string]int) []*PeerCount {
+	ret := make([]*PeerCount, 0, len(m))
+	for name, count := range m {
+		if name == "" {
+			name = "Unknown"
+		}
+		ret = append(ret, &PeerCount{Name: name, Peers: count})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Peers != ret[j].Peers {
+			return ret[i].Peers > ret[j].Peers
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
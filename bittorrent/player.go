@@ -25,6 +25,7 @@ import (
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/diskusage"
+	"github.com/projectx13/projectx/events"
 	"github.com/projectx13/projectx/library"
 	"github.com/projectx13/projectx/osdb"
 	"github.com/projectx13/projectx/tmdb"
@@ -37,6 +38,14 @@ import (
 const (
 	episodeMatchRegex       = `(?i)(^|\W|_)(S0*%[1]d\W?E?0*%[2]d|0*%[1]dx0*%[2]d)(\W|_)`
 	singleEpisodeMatchRegex = `(?i)(^|\W|_)(E0*%[1]d|0*%[1]d)(\W|_)`
+
+	// audioOnlyBufferDivider reduces start buffer for audio-only playback
+	audioOnlyBufferDivider = 4
+
+	// subtitlesHashChunk is size of head and tail of the file, which OpenSubtitles hash is calculated from
+	subtitlesHashChunk = 65536
+	// subtitlesChoicesLimit is how many subtitles are offered, when none is loaded automatically
+	subtitlesChoicesLimit = 20
 )
 
 // Player ...
@@ -66,6 +75,9 @@ type Player struct {
 	diskStatus *diskusage.DiskStatus
 	closer     util.Event
 	closed     bool
+
+	pausedSince time.Time
+	idleStopped bool
 }
 
 // PlayerParams ...
@@ -78,6 +90,7 @@ type PlayerParams struct {
 	DoneAudio         bool
 	DoneSubtitles     bool
 	Background        bool
+	AudioOnly         bool
 	KodiPosition      int
 	WatchedProgress   int
 	WatchedTime       float64
@@ -232,6 +245,7 @@ func (btp *Player) Buffer() error {
 		btp.t.IsBufferingFinished = true
 	} else {
 		btp.t.IsBuffering = true
+		btp.s.Boost(btp.t)
 	}
 
 	buffered, done := btp.bufferEvents.Listen()
@@ -352,7 +366,7 @@ func (btp *Player) processMetadata() {
 	log.Info("Setting piece priorities")
 
 	if !btp.p.Background {
-		go btp.t.Buffer(btp.chosenFile, btp.p.ResumeHash == "")
+		go btp.t.Buffer(btp.chosenFile, btp.p.ResumeHash == "", btp.startBufferSize())
 	}
 }
 
@@ -531,10 +545,12 @@ func (btp *Player) updateBufferDialog() (bool, error) {
 		if btp.dialogProgress != nil {
 			btp.dialogProgress.Update(int(progress), line1, line2, line3)
 		}
+		events.Publish(events.Buffering, btp.playbackEvent(progress))
 
 		if btp.t.IsRarArchive && progress >= 100 {
-			archivePath := filepath.Join(btp.s.config.DownloadPath, btp.chosenFile.Path)
-			destPath := filepath.Join(btp.s.config.DownloadPath, filepath.Dir(btp.chosenFile.Path), "extracted")
+			savePath := btp.t.SavePath()
+			archivePath := filepath.Join(savePath, btp.chosenFile.Path)
+			destPath := filepath.Join(savePath, filepath.Dir(btp.chosenFile.Path), "extracted")
 
 			if _, err := os.Stat(destPath); err == nil {
 				btp.findExtracted(destPath)
@@ -595,6 +611,7 @@ func (btp *Player) updateBufferDialog() (bool, error) {
 		if btp.dialogProgress != nil {
 			btp.dialogProgress.Update(int(btp.t.BufferProgress), line1, line2, line3)
 		}
+		events.Publish(events.Buffering, btp.playbackEvent(btp.t.BufferProgress))
 		if !btp.t.IsBuffering && btp.t.HasMetadata() && btp.t.GetState() != StatusChecking {
 			btp.bufferEvents.Signal()
 			btp.setRateLimiting(true)
@@ -686,6 +703,8 @@ playbackWaitLoop:
 	}
 
 	btp.t.IsPlaying = true
+	go btp.watchdog()
+	events.Publish(events.PlaybackStarted, btp.playbackEvent(float64(btp.p.WatchedProgress)))
 
 playbackLoop:
 	for {
@@ -703,6 +722,8 @@ playbackLoop:
 					trakt.Scrobble("start", btp.p.ContentType, btp.p.TMDBId, btp.p.WatchedTime, btp.p.VideoDuration)
 				}
 			} else if xbmc.PlayerIsPaused() {
+				btp.checkIdle()
+
 				if btp.overlayStatusEnabled == true {
 					status := btp.t.GetStatus()
 					defer lt.DeleteTorrentStatus(status)
@@ -722,6 +743,8 @@ playbackLoop:
 					}
 				}
 			} else {
+				btp.resumeIdle()
+
 				if overlayStatusActive == true {
 					btp.overlayStatus.Hide()
 					overlayStatusActive = false
@@ -735,6 +758,10 @@ playbackLoop:
 			}
 
 			btp.p.WatchedProgress = int(btp.p.WatchedTime / btp.p.VideoDuration * 100)
+			if playing {
+				// Writes are coalesced in the queue, so resume point survives crashes without disk churn
+				btp.SaveStoredResume()
+			}
 
 			if btp.next.f != nil && !btp.next.started && btp.isReadyForNextFile() {
 				btp.startNextFile()
@@ -743,6 +770,8 @@ playbackLoop:
 	}
 
 	log.Info("Stopped playback")
+	events.Publish(events.PlaybackStopped, btp.playbackEvent(float64(btp.p.WatchedProgress)))
+	btp.resumeIdle()
 	btp.SaveStoredResume()
 	btp.setRateLimiting(false)
 	go func() {
@@ -765,6 +794,64 @@ playbackLoop:
 	}
 }
 
+// playbackEvent returns data of buffering and playback events with the progress in percents
+func (btp *Player) playbackEvent(progress float64) *events.Playback {
+	ev := &events.Playback{
+		TorrentID:   btp.t.InfoHash(),
+		ContentType: btp.p.ContentType,
+		TMDBID:      btp.p.TMDBId,
+		Season:      btp.p.Season,
+		Episode:     btp.p.Episode,
+		Progress:    progress,
+		WatchedTime: btp.p.WatchedTime,
+		Duration:    btp.p.VideoDuration,
+	}
+	if btp.chosenFile != nil {
+		ev.File = btp.chosenFile.Path
+	}
+	return ev
+}
+
+// checkIdle stops the torrent, when playback is paused for idle_timeout minutes
+// and Kodi is idle or shows screensaver, so paused stream does not take bandwidth
+func (btp *Player) checkIdle() {
+	if btp.pausedSince.IsZero() {
+		btp.pausedSince = time.Now()
+	}
+
+	timeout := time.Duration(config.Get().IdleTimeout) * time.Minute
+	if btp.idleStopped || timeout <= 0 || time.Since(btp.pausedSince) < timeout || btp.t.IsPaused {
+		return
+	}
+
+	idleCondition := fmt.Sprintf("System.IdleTime(%d)", int(timeout.Seconds()))
+	conditions := xbmc.InfoBooleans("System.ScreenSaverActive", idleCondition)
+	if !conditions["System.ScreenSaverActive"] && !conditions[idleCondition] {
+		return
+	}
+
+	log.Infof("Kodi is idle with paused playback for %s, stopping torrent %s", timeout, btp.t.Name())
+	btp.SaveStoredResume()
+	btp.t.Pause()
+	if btp.t.th != nil && btp.t.th.Swigcptr() != 0 {
+		btp.t.th.SaveResumeData(1)
+	}
+	btp.idleStopped = true
+}
+
+// resumeIdle starts the torrent, stopped by checkIdle, when playback is resumed
+func (btp *Player) resumeIdle() {
+	btp.pausedSince = time.Time{}
+	if !btp.idleStopped {
+		return
+	}
+
+	log.Infof("Playback is resumed, starting torrent %s", btp.t.Name())
+	btp.idleStopped = false
+	btp.t.Resume()
+	btp.t.Reannounce()
+}
+
 func (btp *Player) isReadyForNextFile() bool {
 	if btp.t.IsMemoryStorage() {
 		ra := btp.t.GetReadaheadSize()
@@ -988,7 +1075,7 @@ func (btp *Player) startNextFile() {
 	}
 
 	btp.next.started = true
-	go btp.t.Buffer(btp.next.f, false)
+	go btp.t.Buffer(btp.next.f, false, btp.startBufferSize())
 }
 
 func (btp *Player) findNextFile() {
@@ -1032,8 +1119,7 @@ func (btp *Player) findNextFile() {
 
 	btp.t.HasNextFile = true
 
-	startBufferSize := btp.s.GetBufferSize()
-	_, _, _, preBufferSize := btp.t.getBufferSize(btp.next.f.Offset, 0, startBufferSize)
+	_, _, _, preBufferSize := btp.t.getBufferSize(btp.next.f.Offset, 0, btp.startBufferSize())
 	_, _, _, postBufferSize := btp.t.getBufferSize(btp.next.f.Offset, btp.next.f.Size-int64(config.Get().EndBufferSize), int64(config.Get().EndBufferSize))
 
 	btp.next.bufferSize = preBufferSize + postBufferSize
@@ -1070,9 +1156,19 @@ func (btp *Player) InitAudio() {
 	btp.p.DoneAudio = true
 }
 
+// startBufferSize returns size of the buffer, needed to start playback,
+// audio needs much lower bitrate, so it starts with a fraction of the buffer
+func (btp *Player) startBufferSize() int64 {
+	size := btp.s.GetBufferSize()
+	if btp.p.AudioOnly {
+		size /= audioOnlyBufferDivider
+	}
+	return size
+}
+
 // InitSubtitles ...
 func (btp *Player) InitSubtitles() {
-	if btp.p.DoneSubtitles {
+	if btp.p.DoneSubtitles || btp.p.AudioOnly {
 		return
 	}
 
@@ -1087,9 +1183,18 @@ func (btp *Player) InitSubtitles() {
 	btp.p.DoneSubtitles = true
 }
 
-// DownloadSubtitles ...
+// DownloadSubtitles loads subtitles, which are likely in sync with the file, like ones matched by hash.
+// When none of them is trusted enough, subtitles are chosen by user, instead of loading out of sync ones silently.
 func (btp *Player) DownloadSubtitles() {
-	payloads, preferredLanguage := osdb.GetPayloads("", []string{"English"}, xbmc.SettingsGetSettingValue("locale.subtitlelanguage"), btp.p.ShowID, xbmc.PlayerGetPlayingFile())
+	playingFile := xbmc.PlayerGetPlayingFile()
+	payloads, preferredLanguage := osdb.GetPayloads("", []string{"English"}, xbmc.SettingsGetSettingValue("locale.subtitlelanguage"), btp.p.ShowID, playingFile)
+	if len(payloads) == 0 {
+		return
+	}
+	languages := payloads[0].Languages
+	if hash, size := btp.subtitlesHash(); hash != "" {
+		payloads = append([]osdb.SearchPayload{{Hash: hash, Size: size, Languages: languages}}, payloads...)
+	}
 	log.Infof("Subtitles payload auto: %#v; %s", payloads, preferredLanguage)
 
 	results, err := osdb.DoSearch(payloads, preferredLanguage)
@@ -1097,12 +1202,31 @@ func (btp *Player) DownloadSubtitles() {
 		return
 	}
 
-	btp.subtitlesLoaded = []string{}
-	for i, sub := range results {
-		if i+1 > config.Get().OSDBAutoLoadCount {
+	fileName := playingFile
+	if btp.chosenFile != nil {
+		fileName = btp.chosenFile.Path
+	}
+	results.SortByConfidence(fileName, strings.Split(languages, ","))
+
+	chosen := osdb.Subtitles{}
+	threshold := config.Get().OSDBAutoLoadConfidence
+	for _, sub := range results {
+		if len(chosen) >= config.Get().OSDBAutoLoadCount {
 			break
 		}
+		if confidence := sub.Confidence(fileName); confidence >= threshold {
+			log.Infof("Subtitles %s are matched by %s with confidence %d%%", sub.SubFileName, sub.MatchedBy, confidence)
+			chosen = append(chosen, sub)
+		}
+	}
+	if len(chosen) == 0 {
+		if sub := btp.chooseSubtitles(results, fileName); sub != nil {
+			chosen = append(chosen, *sub)
+		}
+	}
 
+	btp.subtitlesLoaded = []string{}
+	for _, sub := range chosen {
 		subPath := sub.SubFileName[:len(sub.SubFileName)-3] + sub.IDSubtitleFile + ".srt"
 		_, path, err := osdb.DoDownload(subPath, sub.SubDownloadLink)
 		if err != nil {
@@ -1120,6 +1244,59 @@ func (btp *Player) DownloadSubtitles() {
 	}
 }
 
+// chooseSubtitles asks user to choose subtitles, when none of them is trusted enough to be loaded
+func (btp *Player) chooseSubtitles(results osdb.Subtitles, fileName string) *osdb.Subtitle {
+	if len(results) > subtitlesChoicesLimit {
+		results = results[:subtitlesChoicesLimit]
+	}
+
+	choices := make([]string, 0, len(results))
+	for _, sub := range results {
+		choices = append(choices, fmt.Sprintf("[B]%d%%[/B] %s - %s", sub.Confidence(fileName), sub.LanguageName, sub.SubFileName))
+	}
+
+	if choice := xbmc.ListDialog("LOCALIZE[30828]", choices...); choice >= 0 && choice < len(results) {
+		return &results[choice]
+	}
+	return nil
+}
+
+// subtitlesHash returns OpenSubtitles hash and size of the chosen file, when its head and tail are downloaded.
+// Subtitles, matched by hash, were uploaded for this file, so they are in sync.
+func (btp *Player) subtitlesHash() (string, int64) {
+	f := btp.chosenFile
+	if f == nil || btp.t.IsMemoryStorage() || f.Size < 2*subtitlesHashChunk {
+		return "", 0
+	}
+
+	for _, off := range []int64{f.Offset, f.Offset + f.Size - subtitlesHashChunk} {
+		begin, end := btp.t.byteRegionPieces(off, subtitlesHashChunk)
+		for piece := begin; piece <= end; piece++ {
+			if !btp.t.hasPiece(piece) {
+				return "", 0
+			}
+		}
+	}
+
+	file, err := os.Open(filepath.Join(btp.t.SavePath(), f.Path))
+	if err != nil {
+		return "", 0
+	}
+	defer file.Close()
+
+	hash, err := osdb.Hash(file, f.Size)
+	if err != nil {
+		log.Warningf("Could not get subtitles hash of %s: %s", f.Path, err)
+		return "", 0
+	}
+	return hash, f.Size
+}
+
+// AddSubtitles remembers subtitles file, added during playback, to delete it with downloaded ones
+func (btp *Player) AddSubtitles(path string) {
+	btp.subtitlesLoaded = append(btp.subtitlesLoaded, path)
+}
+
 // SetSubtitles ...
 func (btp *Player) SetSubtitles() {
 	filePath := btp.chosenFile.Path
@@ -1169,9 +1346,9 @@ func (btp *Player) SaveStoredResume() {
 	if btp.p.StoredResume.Total == 0 || btp.p.StoredResume.Position == 0 {
 		return
 	} else if btp.IsWatched() || btp.p.StoredResume.Position < 180 {
-		database.GetCache().Delete(database.CommonBucket, key)
+		database.GetCache().QueueDelete(database.CommonBucket, key)
 	} else {
-		database.GetCache().SetCachedObject(database.CommonBucket, storedResumeExpiration, key, btp.p.StoredResume)
+		database.GetCache().QueueSetCachedObject(database.CommonBucket, storedResumeExpiration, key, btp.p.StoredResume)
 	}
 }
 
@@ -0,0 +1,208 @@
+package bittorrent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lt "github.com/projectxorg/libtorrent-go"
+
+	"github.com/projectx13/projectx/config"
+)
+
+// privateTrackersFileName is a file in the profile folder with rules of private trackers
+const privateTrackersFileName = "trackers.json"
+
+// defaultMinAnnounceInterval is used, when there are no rules for private trackers
+const defaultMinAnnounceInterval = 30
+
+// TrackerRule describes private tracker, torrents announcing to it are treated as private,
+// even without private flag in metadata
+type TrackerRule struct {
+	Host string `json:"host"`
+	// MinAnnounceInterval in seconds, tracker could ban clients announcing more often
+	MinAnnounceInterval int `json:"min_announce_interval,omitempty"`
+	// Passkey is a part of announce URL, used to replace it in all torrents, when it is changed
+	Passkey string `json:"passkey,omitempty"`
+	// MinRatio and MinSeedTime (in hours) are seeding obligations, torrent removed
+	// before reaching any of them is counted as hit-and-run
+	MinRatio    float64 `json:"min_ratio,omitempty"`
+	MinSeedTime int     `json:"min_seed_time,omitempty"`
+}
+
+// HasObligations returns whether tracker requires seeding after download
+func (r *TrackerRule) HasObligations() bool {
+	return r.MinRatio > 0 || r.MinSeedTime > 0
+}
+
+var (
+	trackerRulesMu      sync.Mutex
+	trackerRules        []*TrackerRule
+	trackerRulesModTime time.Time
+)
+
+// GetTrackerRules returns rules from the profile file, file is read again when it is changed
+func GetTrackerRules() []*TrackerRule {
+	trackerRulesMu.Lock()
+	defer trackerRulesMu.Unlock()
+
+	path := filepath.Join(config.Get().ProfilePath, privateTrackersFileName)
+	stat, err := os.Stat(path)
+	if err != nil {
+		trackerRules = nil
+		trackerRulesModTime = time.Time{}
+		return trackerRules
+	} else if stat.ModTime().Equal(trackerRulesModTime) {
+		return trackerRules
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warningf("Could not read %s: %s", path, err)
+		return trackerRules
+	}
+
+	rules := []*TrackerRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		log.Warningf("Could not parse %s: %s", path, err)
+		return trackerRules
+	}
+
+	trackerRules = rules
+	trackerRulesModTime = stat.ModTime()
+	return trackerRules
+}
+
+func saveTrackerRules(rules []*TrackerRule) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(config.Get().ProfilePath, privateTrackersFileName)
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// trackerRule returns rule for the announce URL, or nil if tracker is not private
+func trackerRule(trackerURL string) *TrackerRule {
+	u, err := url.Parse(trackerURL)
+	if err != nil {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, r := range GetTrackerRules() {
+		if rh := strings.ToLower(r.Host); host == rh || strings.HasSuffix(host, "."+rh) {
+			return r
+		}
+	}
+	return nil
+}
+
+// IsPrivateTracker returns whether announce URL belongs to private tracker from trackers.json
+func IsPrivateTracker(trackerURL string) bool {
+	return trackerRule(trackerURL) != nil
+}
+
+// minAnnounceInterval returns the biggest interval, required by private trackers.
+// Libtorrent has only session-wide setting, so the strictest tracker wins.
+func minAnnounceInterval() int {
+	interval := defaultMinAnnounceInterval
+	for _, r := range GetTrackerRules() {
+		if r.MinAnnounceInterval > interval {
+			interval = r.MinAnnounceInterval
+		}
+	}
+	return interval
+}
+
+// trackerURLs returns announce URLs of the torrent
+func (t *Torrent) trackerURLs() []string {
+	ret := []string{}
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return ret
+	}
+
+	trackers := t.th.Trackers()
+	defer lt.DeleteStdVectorAnnounceEntry(trackers)
+
+	for i := 0; i < int(trackers.Size()); i++ {
+		ret = append(ret, trackers.Get(i).GetUrl())
+	}
+	return ret
+}
+
+// trackerRule returns rule of the first private tracker of the torrent
+func (t *Torrent) trackerRule() *TrackerRule {
+	for _, u := range t.trackerURLs() {
+		if r := trackerRule(u); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// IsPrivate returns whether torrent has private flag or announces to private tracker.
+// Libtorrent disables DHT, PEX and LSD for torrents with private flag by itself,
+// here we also stop forced announces and adding public trackers.
+func (t *Torrent) IsPrivate() bool {
+	if t.ti != nil && t.ti.Swigcptr() != 0 && t.ti.Priv() {
+		return true
+	}
+
+	return t.trackerRule() != nil
+}
+
+// RotatePasskey replaces passkey of private tracker in announce URLs of all torrents
+// and saves new passkey to trackers.json. Returns number of updated torrents.
+func (s *Service) RotatePasskey(host string, passkey string) (int, error) {
+	rules := GetTrackerRules()
+
+	var rule *TrackerRule
+	for _, r := range rules {
+		if r.Host == host {
+			rule = r
+			break
+		}
+	}
+	if rule == nil || rule.Passkey == "" || passkey == "" || rule.Passkey == passkey {
+		return 0, nil
+	}
+
+	updated := 0
+	for _, t := range s.q.All() {
+		if t == nil || t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 {
+			continue
+		}
+
+		urls := t.trackerURLs()
+		changed := false
+		for i, u := range urls {
+			if trackerRule(u) == rule && strings.Contains(u, rule.Passkey) {
+				urls[i] = strings.Replace(u, rule.Passkey, passkey, -1)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		entries := lt.NewStdVectorAnnounceEntry()
+		for _, u := range urls {
+			entries.Add(lt.NewAnnounceEntry(u))
+		}
+		t.th.ReplaceTrackers(entries)
+		lt.DeleteStdVectorAnnounceEntry(entries)
+
+		log.Infof("Updated passkey of %s in torrent %s", host, t.InfoHash())
+		updated++
+	}
+
+	rule.Passkey = passkey
+	return updated, saveTrackerRules(rules)
+}
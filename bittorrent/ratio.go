@@ -0,0 +1,127 @@
+package bittorrent
+
+import (
+	"sort"
+
+	lt "github.com/projectxorg/libtorrent-go"
+)
+
+// RatioInfo describes seeding progress of the torrent against obligations of private tracker
+type RatioInfo struct {
+	InfoHash    string  `json:"info_hash"`
+	Name        string  `json:"name"`
+	Tracker     string  `json:"tracker"`
+	Uploaded    int64   `json:"uploaded"`
+	Downloaded  int64   `json:"downloaded"`
+	Ratio       float64 `json:"ratio"`
+	SeedingTime int     `json:"seeding_time"`
+	MinRatio    float64 `json:"min_ratio"`
+	MinSeedTime int     `json:"min_seed_time"`
+	Satisfied   bool    `json:"satisfied"`
+}
+
+// TrackerRatio summarizes torrents of one private tracker
+type TrackerRatio struct {
+	Tracker    string       `json:"tracker"`
+	Uploaded   int64        `json:"uploaded"`
+	Downloaded int64        `json:"downloaded"`
+	Ratio      float64      `json:"ratio"`
+	HitAndRuns int          `json:"hit_and_runs"`
+	Torrents   []*RatioInfo `json:"torrents"`
+}
+
+// GetRatioInfo returns seeding progress of the torrent,
+// or nil if its tracker has no seeding obligations
+func (t *Torrent) GetRatioInfo() *RatioInfo {
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return nil
+	}
+
+	rule := t.trackerRule()
+	if rule == nil || !rule.HasObligations() {
+		return nil
+	}
+
+	ts := t.th.Status()
+	defer lt.DeleteTorrentStatus(ts)
+
+	info := &RatioInfo{
+		InfoHash:    t.InfoHash(),
+		Name:        t.Name(),
+		Tracker:     rule.Host,
+		Uploaded:    ts.GetAllTimeUpload(),
+		Downloaded:  ts.GetAllTimeDownload(),
+		SeedingTime: ts.GetSeedingTime(),
+		MinRatio:    rule.MinRatio,
+		MinSeedTime: rule.MinSeedTime * 3600,
+	}
+	if info.Downloaded > 0 {
+		info.Ratio = float64(info.Uploaded) / float64(info.Downloaded)
+	}
+
+	// Nothing downloaded yet means nothing to give back
+	info.Satisfied = info.Downloaded == 0 ||
+		(info.MinRatio > 0 && info.Ratio >= info.MinRatio) ||
+		(info.MinSeedTime > 0 && info.SeedingTime >= info.MinSeedTime)
+
+	return info
+}
+
+// IsHitAndRun returns whether removing or stopping the torrent now
+// would break seeding obligations of private tracker
+func (t *Torrent) IsHitAndRun() bool {
+	info := t.GetRatioInfo()
+	return info != nil && !info.Satisfied
+}
+
+// GetRatioStats returns torrents with seeding obligations, grouped by tracker
+func (s *Service) GetRatioStats() []*TrackerRatio {
+	trackers := map[string]*TrackerRatio{}
+	for _, t := range s.q.All() {
+		if t == nil || t.Closer.IsSet() {
+			continue
+		}
+
+		info := t.GetRatioInfo()
+		if info == nil {
+			continue
+		}
+
+		tr, ok := trackers[info.Tracker]
+		if !ok {
+			tr = &TrackerRatio{
+				Tracker:  info.Tracker,
+				Torrents: []*RatioInfo{},
+			}
+			trackers[info.Tracker] = tr
+		}
+
+		tr.Torrents = append(tr.Torrents, info)
+		tr.Uploaded += info.Uploaded
+		tr.Downloaded += info.Downloaded
+		if !info.Satisfied {
+			tr.HitAndRuns++
+		}
+	}
+
+	ret := make([]*TrackerRatio, 0, len(trackers))
+	for _, tr := range trackers {
+		if tr.Downloaded > 0 {
+			tr.Ratio = float64(tr.Uploaded) / float64(tr.Downloaded)
+		}
+
+		// Torrents, which are closer to hit-and-run, go first
+		sort.Slice(tr.Torrents, func(i, j int) bool {
+			if tr.Torrents[i].Satisfied != tr.Torrents[j].Satisfied {
+				return !tr.Torrents[i].Satisfied
+			}
+			return tr.Torrents[i].Ratio < tr.Torrents[j].Ratio
+		})
+		ret = append(ret, tr)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Tracker < ret[j].Tracker
+	})
+	return ret
+}
@@ -0,0 +1,90 @@
+package bittorrent
+
+import (
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// States of verification of completed torrent, before its files are moved
+const (
+	verifyNone = iota
+	verifyChecking
+	verifyDone
+)
+
+// ForceRecheck verifies hashes of all downloaded pieces. Pieces, failing the check,
+// are downloaded again, so the torrent is resumed, if it was paused.
+func (t *Torrent) ForceRecheck() bool {
+	if t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 || t.IsMemoryStorage() {
+		return false
+	}
+
+	log.Infof("Forcing recheck of %s", t.Name())
+	t.th.ForceRecheck()
+	if t.GetPaused() {
+		t.Resume()
+	}
+	return true
+}
+
+// IsChecking returns true, while libtorrent checks hashes of the torrent
+func (t *Torrent) IsChecking() bool {
+	state := t.GetState()
+	return state == StatusQueued || state == StatusChecking
+}
+
+// CorruptPieces returns number of pieces, which failed last verification of completed torrent
+func (t *Torrent) CorruptPieces() int {
+	return t.corruptPieces
+}
+
+// missingPieces counts pieces of the files, which are not downloaded or failed the hash check
+func (t *Torrent) missingPieces(paths []string) (missing int) {
+	for _, p := range paths {
+		f := t.GetFileByPath(p)
+		if f == nil {
+			continue
+		}
+		for piece := f.PieceStart; piece <= f.PieceEnd; piece++ {
+			if !t.hasPiece(piece) {
+				missing++
+			}
+		}
+	}
+	return
+}
+
+// verifyCompleted rechecks completed torrent once, before its files are moved to completed folder,
+// and returns true, when files are verified. Corrupt pieces are downloaded again,
+// and the torrent is verified again, when it is seeded next time.
+func (s *Service) verifyCompleted(t *Torrent, paths []string) bool {
+	switch t.verifyState {
+	case verifyNone:
+		log.Infof("Verifying %s before moving files to completed folder", t.Name())
+		t.verifyState = verifyChecking
+		if !t.ForceRecheck() {
+			t.verifyState = verifyNone
+		}
+		return false
+
+	case verifyChecking:
+		if t.IsChecking() {
+			return false
+		}
+
+		t.corruptPieces = t.missingPieces(paths)
+		if t.corruptPieces == 0 {
+			log.Infof("Files of %s are verified", t.Name())
+			t.verifyState = verifyDone
+			return true
+		}
+
+		log.Warningf("%s has %d corrupt pieces, downloading them again", t.Name(), t.corruptPieces)
+		xbmc.Notify("projectx", "LOCALIZE[30802];;"+t.Name(), config.AddonIcon())
+		t.verifyState = verifyNone
+		t.Resume()
+		return false
+	}
+
+	return true
+}
@@ -32,7 +32,10 @@ import (
 	"github.com/projectx13/projectx/broadcast"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/digest"
 	"github.com/projectx13/projectx/diskusage"
+	"github.com/projectx13/projectx/events"
+	"github.com/projectx13/projectx/mock"
 	"github.com/projectx13/projectx/proxy"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/util"
@@ -70,6 +73,13 @@ type Service struct {
 	alertsBroadcaster *broadcast.Broadcaster
 	Closer            util.Event
 	isShutdown        bool
+
+	boostMu      sync.Mutex
+	boostTimer   *time.Timer
+	boostTorrent *Torrent
+
+	meteredMu     sync.Mutex
+	meteredPaused map[string]bool
 }
 
 type activeTorrent struct {
@@ -92,6 +102,8 @@ func NewService() *Service {
 		SpaceChecked: map[string]bool{},
 		Players:      map[string]*Player{},
 
+		meteredPaused: map[string]bool{},
+
 		alertsBroadcaster: broadcast.NewBroadcaster(),
 	}
 
@@ -116,6 +128,8 @@ func NewService() *Service {
 
 	go s.loadTorrentFiles()
 	go s.downloadProgress()
+	go s.geoIPUpdater()
+	go s.networkWatcher()
 
 	return s
 }
@@ -163,6 +177,8 @@ func (s *Service) Reconfigure() {
 
 	s.startServices()
 	s.loadTorrentFiles()
+
+	go s.updateGeoIP()
 }
 
 func (s *Service) configure() {
@@ -219,11 +235,15 @@ func (s *Service) configure() {
 	// Intervals and Timeouts
 	settings.SetInt("auto_scrape_interval", 1200)
 	settings.SetInt("auto_scrape_min_interval", 900)
-	settings.SetInt("min_announce_interval", 30)
+	settings.SetInt("min_announce_interval", minAnnounceInterval())
 	settings.SetInt("dht_announce_interval", 60)
 	// settings.SetInt("peer_connect_timeout", 5)
 	// settings.SetInt("request_timeout", 2)
 	settings.SetInt("stop_tracker_timeout", 1)
+	// Percentage, by which retry interval of failing tracker grows after each failure
+	if s.config.TrackerBackoff > 0 {
+		settings.SetInt("tracker_backoff", s.config.TrackerBackoff)
+	}
 
 	// Ratios
 	settings.SetInt("seed_time_limit", 0)
@@ -264,17 +284,8 @@ func (s *Service) configure() {
 		settings.SetInt("max_queued_disk_bytes", s.config.DiskCacheSize)
 	}
 
-	if s.config.ConnectionsLimit > 0 {
-		settings.SetInt("connections_limit", s.config.ConnectionsLimit)
-	} else {
-		settings.SetInt("connections_limit", getPlatformSpecificConnectionLimit())
-	}
-
-	if s.config.ConnTrackerLimitAuto || s.config.ConnTrackerLimit == 0 {
-		settings.SetInt("connection_speed", 500)
-	} else {
-		settings.SetInt("connection_speed", s.config.ConnTrackerLimit)
-	}
+	settings.SetInt("connections_limit", s.connectionsLimit())
+	settings.SetInt("connection_speed", s.connectionSpeed())
 
 	if s.config.LimitAfterBuffering == false {
 		if s.config.DownloadRateLimit > 0 {
@@ -490,6 +501,11 @@ func (s *Service) configure() {
 }
 
 func (s *Service) startServices() {
+	if mock.Enabled() {
+		log.Info("Peer discovery is disabled in mock mode")
+		return
+	}
+
 	log.Info("Starting LSD...")
 	s.PackSettings.SetBool("enable_lsd", true)
 
@@ -575,9 +591,10 @@ func (s *Service) checkAvailableSpace(t *Torrent) bool {
 		return true
 	}
 
-	diskStatus, err := diskusage.DiskUsage(config.Get().DownloadPath)
+	savePath := t.SavePath()
+	diskStatus, err := diskusage.DiskUsage(savePath)
 	if err != nil {
-		log.Warningf("Unable to retrieve the free space for %s, continuing anyway...", config.Get().DownloadPath)
+		log.Warningf("Unable to retrieve the free space for %s, continuing anyway...", savePath)
 		return false
 	}
 
@@ -626,6 +643,11 @@ func (s *Service) AddTorrent(uri string, paused bool, downloadStorage int) (*Tor
 
 	log.Infof("Adding torrent from %s", uri)
 
+	// Fixture files of the fake swarm could be only on disk
+	if mock.Enabled() && downloadStorage == StorageMemory {
+		downloadStorage = StorageFile
+	}
+
 	if downloadStorage != StorageMemory && s.config.DownloadPath == "." {
 		log.Warningf("Cannot add torrent since download path is not set")
 		xbmc.Notify("projectx", "LOCALIZE[30113]", config.AddonIcon())
@@ -653,6 +675,8 @@ func (s *Service) AddTorrent(uri string, paused bool, downloadStorage int) (*Tor
 		}
 	}
 
+	uri = mockSwarmTorrent(uri)
+
 	if strings.HasPrefix(uri, "magnet:") {
 		// Remove all spaces in magnet
 		uri = strings.Replace(uri, " ", "", -1)
@@ -695,8 +719,10 @@ func (s *Service) AddTorrent(uri string, paused bool, downloadStorage int) (*Tor
 		infoHash = hex.EncodeToString([]byte(shaHash))
 	}
 
-	log.Infof("Setting save path to %s", s.config.DownloadPath)
-	torrentParams.SetSavePath(s.config.DownloadPath)
+	savePath := s.savePath(downloadStorage)
+	log.Infof("Setting save path to %s", savePath)
+	torrentParams.SetSavePath(savePath)
+	mockSwarmSeed(infoHash, savePath)
 
 	skipPriorities := false
 	if downloadStorage != StorageMemory {
@@ -763,6 +789,7 @@ func (s *Service) AddTorrent(uri string, paused bool, downloadStorage int) (*Tor
 
 	go t.Watch()
 
+	events.Publish(events.TorrentAdded, &events.Torrent{ID: t.InfoHash(), Name: t.Name()})
 	return t, nil
 }
 
@@ -795,6 +822,12 @@ func (s *Service) RemoveTorrent(t *Torrent, forceDrop, forceDelete, isWatched bo
 		keepDownloading = true
 	}
 
+	// Keep seeding after playback, private tracker would count removal as hit-and-run
+	if !keepDownloading && !forceDrop && !t.IsMemoryStorage() && t.IsHitAndRun() {
+		log.Warningf("Torrent %s did not meet seeding obligations yet, keeping it", t.Name())
+		keepDownloading = true
+	}
+
 	keepSetting := configKeepFilesPlaying
 	if isWatched {
 		keepSetting = configKeepFilesFinished
@@ -844,6 +877,7 @@ func (s *Service) RemoveTorrent(t *Torrent, forceDrop, forceDelete, isWatched bo
 		s.q.Delete(t)
 
 		t.Drop(deleteAnswer)
+		events.Publish(events.TorrentRemoved, &events.Torrent{ID: t.InfoHash(), Name: t.Name()})
 	}
 
 	return true
@@ -990,6 +1024,14 @@ func (s *Service) alertsConsumer() {
 					for _, t := range s.q.All() {
 						if t.th != nil && ta.GetHandle().Equal(t.th) {
 							t.trackers.Store(ta.TrackerUrl(), ta.GetNumPeers())
+							t.trackerErrors.Delete(ta.TrackerUrl())
+						}
+					}
+				case lt.TrackerErrorAlertAlertType:
+					ta := lt.SwigcptrTrackerErrorAlert(alertPtr)
+					for _, t := range s.q.All() {
+						if t.th != nil && ta.GetHandle().Equal(t.th) {
+							t.onTrackerError(ta.TrackerUrl(), ta.ErrorMessage(), ta.GetTimesInRow())
 						}
 					}
 				case lt.DhtReplyAlertAlertType:
@@ -1114,6 +1156,9 @@ func (s *Service) loadTorrentFiles() {
 	}
 
 	s.cleanStaleFiles(s.config.DownloadPath, ".parts")
+	if s.config.StreamCachePath != "" {
+		s.cleanStaleFiles(s.config.StreamCachePath, ".parts")
+	}
 	s.cleanStaleFiles(s.config.TorrentsPath, ".fastresume")
 }
 
@@ -1146,6 +1191,8 @@ func (s *Service) downloadProgress() {
 
 	pathChecked := make(map[string]bool)
 	warnedMissing := make(map[string]bool)
+	// Torrents, seen downloading, to notify once they finish
+	downloading := make(map[string]bool)
 
 	showNext := 0
 	for {
@@ -1207,6 +1254,27 @@ func (s *Service) downloadProgress() {
 				torrentName := ts.GetName()
 				progress := int(float64(ts.GetProgress()) * 100)
 
+				if events.HasListeners() {
+					events.Publish(events.TorrentProgress, &events.Torrent{
+						ID:           infoHash,
+						Name:         torrentName,
+						Status:       status,
+						Progress:     float64(ts.GetProgress()) * 100,
+						DownloadRate: int(downloadRate),
+						UploadRate:   int(uploadRate),
+					})
+				}
+
+				if progress < 100 {
+					downloading[infoHash] = true
+				} else if downloading[infoHash] {
+					delete(downloading, infoHash)
+					events.Publish(events.TorrentCompleted, &events.Torrent{ID: infoHash, Name: torrentName, Progress: 100})
+					if !t.IsMemoryStorage() && !t.IsPlaying {
+						digest.Notify("LOCALIZE[30819];;" + torrentName)
+					}
+				}
+
 				if progress < 100 && !isPaused {
 					activeTorrents = append(activeTorrents, &activeTorrent{
 						torrentName:  torrentName,
@@ -1224,9 +1292,19 @@ func (s *Service) downloadProgress() {
 					seedingTime = finishedTime
 				}
 
-				if !t.IsMemoryStorage() && s.config.SeedTimeLimit > 0 {
+				// Seeding limits do not stop torrents, which did not meet private tracker obligations yet
+				isObligated := t.IsHitAndRun()
+				// Paused torrent is not checked, so it is kept running, while verified before moving
+				isVerifying := t.verifyState == verifyChecking
+
+				// Finished downloads leave stream cache for download path
+				if progress == 100 && !isVerifying {
+					s.migrateStorage(t)
+				}
+
+				if !t.IsMemoryStorage() && !isObligated && s.config.SeedTimeLimit > 0 {
 					if seedingTime >= s.config.SeedTimeLimit {
-						if !isPaused {
+						if !isPaused && !isVerifying {
 							log.Warningf("Seeding time limit reached, pausing %s", torrentName)
 							torrentHandle.AutoManaged(false)
 							torrentHandle.Pause(1)
@@ -1235,14 +1313,14 @@ func (s *Service) downloadProgress() {
 						status = "Seeded"
 					}
 				}
-				if !t.IsMemoryStorage() && s.config.SeedTimeRatioLimit > 0 {
+				if !t.IsMemoryStorage() && !isObligated && s.config.SeedTimeRatioLimit > 0 {
 					timeRatio := 0
 					downloadTime := ts.GetActiveTime() - seedingTime
 					if downloadTime > 1 {
 						timeRatio = seedingTime * 100 / downloadTime
 					}
 					if timeRatio >= s.config.SeedTimeRatioLimit {
-						if !isPaused {
+						if !isPaused && !isVerifying {
 							log.Warningf("Seeding time ratio reached, pausing %s", torrentName)
 							torrentHandle.AutoManaged(false)
 							torrentHandle.Pause(1)
@@ -1251,14 +1329,14 @@ func (s *Service) downloadProgress() {
 						status = "Seeded"
 					}
 				}
-				if !t.IsMemoryStorage() && s.config.ShareRatioLimit > 0 {
+				if !t.IsMemoryStorage() && !isObligated && s.config.ShareRatioLimit > 0 {
 					ratio := int64(0)
 					allTimeDownload := ts.GetAllTimeDownload()
 					if allTimeDownload > 0 {
 						ratio = ts.GetAllTimeUpload() * 100 / allTimeDownload
 					}
 					if ratio >= int64(s.config.ShareRatioLimit) {
-						if !isPaused {
+						if !isPaused && !isVerifying {
 							log.Warningf("Share ratio reached, pausing %s", torrentName)
 							torrentHandle.AutoManaged(false)
 							torrentHandle.Pause(1)
@@ -1275,7 +1353,7 @@ func (s *Service) downloadProgress() {
 				//
 				// Handle moving completed downloads
 				//
-				if t.IsMemoryStorage() || !s.config.CompletedMove || status != "Seeded" || s.anyPlayerIsPlaying() {
+				if t.IsMemoryStorage() || !s.config.CompletedMove || status != "Seeded" || s.anyPlayerIsPlaying() || t.IsMigrating() {
 					continue
 				}
 				if xbmc.PlayerIsPlaying() {
@@ -1298,6 +1376,9 @@ func (s *Service) downloadProgress() {
 						log.Error(errMsg)
 						return errors.New(errMsg)
 					}
+					if s.config.CompletedVerify && !s.verifyCompleted(t, item.Files) {
+						return nil
+					}
 					log.Warning(torrentName, "finished seeding, moving files...")
 
 					// Check paths are valid and writable, and only once
@@ -1321,12 +1402,15 @@ func (s *Service) downloadProgress() {
 						}
 					}
 
+					// Files could be in stream cache, if they were not moved to download path yet
+					savePath := t.SavePath()
+
 					log.Info("Removing the torrent without deleting files after Completed move ...")
 					t := s.GetTorrentByHash(infoHash)
 					s.RemoveTorrent(t, false, false, false)
 
 					// Delete leftover .parts file if any
-					partsFile := filepath.Join(config.Get().DownloadPath, fmt.Sprintf(".%s.parts", infoHash))
+					partsFile := filepath.Join(savePath, fmt.Sprintf(".%s.parts", infoHash))
 					os.Remove(partsFile)
 
 					// Delete fast resume data
@@ -1363,7 +1447,7 @@ func (s *Service) downloadProgress() {
 						extracted := ""
 						re := regexp.MustCompile(`(?i).*\.rar$`)
 						if re.MatchString(fileName) {
-							extractedPath := filepath.Join(s.config.DownloadPath, filepath.Dir(filePath), "extracted")
+							extractedPath := filepath.Join(savePath, filepath.Dir(filePath), "extracted")
 							files, err := ioutil.ReadDir(extractedPath)
 							if err != nil {
 								return err
@@ -1408,7 +1492,7 @@ func (s *Service) downloadProgress() {
 
 						go func() {
 							log.Infof("Moving %s to %s", fileName, dstPath)
-							srcPath := filepath.Join(s.config.DownloadPath, filePath)
+							srcPath := filepath.Join(savePath, filePath)
 							if dst, err := util.Move(srcPath, dstPath); err != nil {
 								log.Error(err)
 							} else {
@@ -1417,12 +1501,13 @@ func (s *Service) downloadProgress() {
 									os.RemoveAll(filepath.Dir(srcPath))
 									if extracted != "" {
 										parentPath := filepath.Clean(filepath.Join(filepath.Dir(srcPath), ".."))
-										if parentPath != "." && parentPath != s.config.DownloadPath {
+										if parentPath != "." && parentPath != savePath {
 											os.RemoveAll(parentPath)
 										}
 									}
 								}
 								log.Warning(fileName, "moved to", dst)
+								digest.Notify("LOCALIZE[30820];;" + fileName)
 
 								log.Infof("Marking %s for removal from library and database...", torrentName)
 								database.GetStorm().UpdateBTItemStatus(infoHash, Remove)
@@ -1433,6 +1518,8 @@ func (s *Service) downloadProgress() {
 				}()
 			}
 
+			recordDownloadRate(int64(totalDownloadRate))
+
 			totalActive := len(activeTorrents)
 			if totalActive > 0 {
 				showProgress := totalProgress / totalActive
@@ -1482,9 +1569,10 @@ func (s *Service) SetUploadLimit(i int) {
 
 // RestoreLimits ...
 func (s *Service) RestoreLimits() {
-	if s.config.DownloadRateLimit > 0 {
-		s.SetDownloadLimit(s.config.DownloadRateLimit)
-		log.Infof("Rate limiting download to %s", humanize.Bytes(uint64(s.config.DownloadRateLimit)))
+	downloadLimit, uploadLimit := s.rateLimits()
+	if downloadLimit > 0 {
+		s.SetDownloadLimit(downloadLimit)
+		log.Infof("Rate limiting download to %s", humanize.Bytes(uint64(downloadLimit)))
 	} else {
 		s.SetDownloadLimit(0)
 	}
@@ -1493,9 +1581,9 @@ func (s *Service) RestoreLimits() {
 	// 	s.SetUploadLimit(1)
 	// 	log.Infof("Rate limiting upload to %d byte, due to disabled upload", 1)
 	// } else if s.config.UploadRateLimit > 0 {
-	if s.config.UploadRateLimit > 0 {
-		s.SetUploadLimit(s.config.UploadRateLimit)
-		log.Infof("Rate limiting upload to %s", humanize.Bytes(uint64(s.config.UploadRateLimit)))
+	if uploadLimit > 0 {
+		s.SetUploadLimit(uploadLimit)
+		log.Infof("Rate limiting upload to %s", humanize.Bytes(uint64(uploadLimit)))
 	} else {
 		s.SetUploadLimit(0)
 	}
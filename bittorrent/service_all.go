@@ -2,7 +2,11 @@
 
 package bittorrent
 
-// Nothing to do on regular devices
+import (
+	"github.com/projectx13/projectx/util"
+)
+
+// Regular devices get connections limit of their resource profile
 func getPlatformSpecificConnectionLimit() int {
-	return 75
+	return util.GetResources().ConnectionsLimit
 }
@@ -4,6 +4,8 @@ package bittorrent
 
 import (
 	"runtime"
+
+	"github.com/projectx13/projectx/util"
 )
 
 const (
@@ -20,5 +22,5 @@ func getPlatformSpecificConnectionLimit() int {
 		return maxSingleCoreConnections
 	}
 
-	return 75
+	return util.Min(75, util.GetResources().ConnectionsLimit)
 }
@@ -0,0 +1,101 @@
+package bittorrent
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Stream types, which providers could return instead of torrents
+const (
+	// StreamHLS is HLS playlist
+	StreamHLS = "hls"
+	// StreamDASH is MPEG-DASH manifest
+	StreamDASH = "dash"
+	// StreamDirect is a video file, which Kodi plays by itself
+	StreamDirect = "direct"
+)
+
+const widevineLicenseType = "com.widevine.alpha"
+
+// IsStream checks whether provider returned direct stream instead of torrent
+func (t *TorrentFile) IsStream() bool {
+	return t.StreamType != ""
+}
+
+// NeedsInputStream checks whether the stream could not be played without inputstream.adaptive,
+// Kodi plays HLS and video files by itself, unless they are protected
+func (t *TorrentFile) NeedsInputStream() bool {
+	return t.StreamType == StreamDASH || t.LicenseKey != ""
+}
+
+// StreamURL returns stream URL with headers, in the format, which Kodi uses for HTTP requests
+func (t *TorrentFile) StreamURL() string {
+	if len(t.Headers) == 0 {
+		return t.URI
+	}
+	return t.URI + "|" + t.EncodedHeaders()
+}
+
+// StreamProperties returns list item properties to play the stream with inputstream.adaptive
+func (t *TorrentFile) StreamProperties() map[string]string {
+	if !t.NeedsInputStream() {
+		return nil
+	}
+
+	manifestType := "mpd"
+	if t.StreamType == StreamHLS {
+		manifestType = "hls"
+	}
+
+	props := map[string]string{
+		// "inputstreamaddon" is used by Kodi 18, "inputstream" by later versions
+		"inputstreamaddon":                   "inputstream.adaptive",
+		"inputstream":                        "inputstream.adaptive",
+		"inputstream.adaptive.manifest_type": manifestType,
+	}
+	if len(t.Headers) > 0 {
+		props["inputstream.adaptive.stream_headers"] = t.EncodedHeaders()
+	}
+	if t.LicenseKey != "" {
+		props["inputstream.adaptive.license_type"] = widevineLicenseType
+		props["inputstream.adaptive.license_key"] = t.LicenseKey
+	}
+	return props
+}
+
+// detectStreamType normalizes stream type from provider, or detects it by playlist extension
+func (t *TorrentFile) detectStreamType() {
+	switch strings.ToLower(t.StreamType) {
+	case StreamHLS, "m3u8":
+		t.StreamType = StreamHLS
+		return
+	case StreamDASH, "mpd":
+		t.StreamType = StreamDASH
+		return
+	case "":
+	default:
+		t.StreamType = StreamDirect
+		return
+	}
+
+	u, err := url.Parse(t.URI)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".m3u8":
+		t.StreamType = StreamHLS
+	case ".mpd":
+		t.StreamType = StreamDASH
+	}
+}
+
+// EncodedHeaders returns stream headers, encoded like URL query
+func (t *TorrentFile) EncodedHeaders() string {
+	headers := url.Values{}
+	for k, v := range t.Headers {
+		headers.Set(k, v)
+	}
+	return headers.Encode()
+}
@@ -0,0 +1,70 @@
+package bittorrent
+
+import (
+	"path/filepath"
+
+	lt "github.com/projectxorg/libtorrent-go"
+)
+
+// savePath returns path for new torrents, stream cache path on a faster device, if it is set,
+// and download path otherwise
+func (s *Service) savePath(downloadStorage int) string {
+	if downloadStorage != StorageMemory && s.config.StreamCachePath != "" {
+		return s.config.StreamCachePath
+	}
+	return s.config.DownloadPath
+}
+
+// SavePath returns path, where files of the torrent are saved now.
+// Libtorrent changes it only after files are moved, so it is safe to read files from it.
+func (t *Torrent) SavePath() string {
+	if t.th == nil || t.th.Swigcptr() == 0 || t.IsMemoryStorage() {
+		return t.Service.config.DownloadPath
+	}
+
+	status := t.th.Status(uint(lt.WrappedTorrentHandleQuerySavePath))
+	defer lt.DeleteTorrentStatus(status)
+
+	if path := status.GetSavePath(); path != "" {
+		return path
+	}
+	return t.Service.config.DownloadPath
+}
+
+// IsCached returns true, if files of the torrent are in stream cache path,
+// and are not moved to download path yet
+func (t *Torrent) IsCached() bool {
+	cachePath := t.Service.config.StreamCachePath
+	if cachePath == "" || t.IsMemoryStorage() {
+		return false
+	}
+	return filepath.Clean(t.SavePath()) == filepath.Clean(cachePath)
+}
+
+// IsMigrating returns true, while files of the torrent are moved from stream cache path to download path
+func (t *Torrent) IsMigrating() bool {
+	if t.migrating && filepath.Clean(t.SavePath()) == filepath.Clean(t.Service.config.DownloadPath) {
+		log.Infof("Files of %s are moved to %s", t.Name(), t.Service.config.DownloadPath)
+		t.migrating = false
+	}
+	return t.migrating
+}
+
+// migrateStorage moves files of the finished torrent from stream cache path to download path.
+// Files are moved by libtorrent, which keeps seeding them, so it is started only when nothing is played.
+func (s *Service) migrateStorage(t *Torrent) {
+	if t.migrating || !t.IsCached() || s.anyPlayerIsPlaying() {
+		return
+	}
+
+	t.muReaders.Lock()
+	readers := len(t.readers)
+	t.muReaders.Unlock()
+	if readers > 0 {
+		return
+	}
+
+	log.Infof("Moving files of %s from stream cache to %s", t.Name(), s.config.DownloadPath)
+	t.migrating = true
+	t.th.MoveStorage(s.config.DownloadPath)
+}
@@ -54,6 +54,12 @@ type Torrent struct {
 	reservedPieces     []int
 	lastPrioritization string
 	trackers           sync.Map
+	trackerErrors      sync.Map
+	lastReannounce     time.Time
+	reannounceMu       sync.Mutex
+	verifyState        int
+	migrating          bool
+	corruptPieces      int
 
 	awaitingPieces *roaring.Bitmap
 	demandPieces   *roaring.Bitmap
@@ -332,7 +338,8 @@ func (t *Torrent) bufferFinishedEvent() {
 // Kodi sends two requests, one for onecoming file read handler,
 // another for a piece of file from the end (probably to get codec descriptors and so on)
 // We set it as post-buffer and include in required buffer pieces array.
-func (t *Torrent) Buffer(file *File, isStartup bool) {
+// Start buffer size is selected by the player, it is smaller for audio-only playback.
+func (t *Torrent) Buffer(file *File, isStartup bool, startBufferSize int64) {
 	if file == nil {
 		t.bufferFinishedEvent()
 		return
@@ -346,7 +353,6 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 
 	t.startBufferTicker()
 
-	startBufferSize := t.Service.GetBufferSize()
 	preBufferStart, preBufferEnd, preBufferOffset, preBufferSize := t.getBufferSize(file.Offset, 0, startBufferSize)
 	postBufferStart, postBufferEnd, postBufferOffset, postBufferSize := t.getBufferSize(file.Offset, file.Size-int64(config.Get().EndBufferSize), int64(config.Get().EndBufferSize))
 
@@ -486,10 +492,12 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 		t.Resume()
 	}
 
-	// Force reannounce for trackers
-	t.th.ForceReannounce()
-	if !config.Get().DisableDHT {
-		t.th.ForceDhtAnnounce()
+	// Force reannounce for trackers, private trackers could ban for announcing too often
+	if !t.IsPrivate() {
+		t.th.ForceReannounce()
+		if !config.Get().DisableDHT {
+			t.th.ForceDhtAnnounce()
+		}
 	}
 
 	// As long as file storage has many enabled pieces, we make sure buffer pieces are sent immediately
@@ -1425,7 +1433,7 @@ func (t *Torrent) onMetadataReceived() {
 	// Reset fastResumeFile
 	infoHash := t.InfoHash()
 	t.fastResumeFile = filepath.Join(t.Service.config.TorrentsPath, fmt.Sprintf("%s.fastresume", infoHash))
-	t.partsFile = filepath.Join(t.Service.config.DownloadPath, fmt.Sprintf(".%s.parts", infoHash))
+	t.partsFile = filepath.Join(t.SavePath(), fmt.Sprintf(".%s.parts", infoHash))
 
 	go func() {
 		// After metadata is fetched for a torrent, we should
@@ -1516,8 +1524,62 @@ func (t *Torrent) GetPaused() bool {
 	return ts.GetPaused()
 }
 
+// IsSuperSeeding ...
+func (t *Torrent) IsSuperSeeding() bool {
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return false
+	}
+
+	ts := t.th.Status()
+	defer lt.DeleteTorrentStatus(ts)
+
+	return ts.GetSuperSeeding()
+}
+
+// SetSuperSeeding toggles super-seeding, where each peer gets only pieces,
+// which are not available from other peers, so the initial seed spreads faster
+func (t *Torrent) SetSuperSeeding(enable bool) {
+	if t.Closer.IsSet() {
+		return
+	}
+
+	log.Infof("Setting super-seeding of torrent %s to %v", t.InfoHash(), enable)
+	t.th.SuperSeeding(enable)
+}
+
+// GetUploadSlots ...
+func (t *Torrent) GetUploadSlots() int {
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return -1
+	}
+
+	return t.th.MaxUploads()
+}
+
+// SetUploadSlots limits number of peers, which torrent uploads to at once, -1 means unlimited
+func (t *Torrent) SetUploadSlots(slots int) {
+	if t.Closer.IsSet() {
+		return
+	}
+
+	log.Infof("Setting upload slots of torrent %s to %d", t.InfoHash(), slots)
+	t.th.SetMaxUploads(slots)
+}
+
+// GetEpisodeFile returns file, which is assigned by the user to the episode
+func (t *Torrent) GetEpisodeFile(season, episode int) *File {
+	if path := database.GetStorm().GetEpisodeFile(t.InfoHash(), season, episode); path != "" {
+		return t.GetFileByPath(path)
+	}
+	return nil
+}
+
 // GetNextEpisodeFile ...
 func (t *Torrent) GetNextEpisodeFile(season, episode int) *File {
+	if f := t.GetEpisodeFile(season, episode); f != nil {
+		return f
+	}
+
 	re := regexp.MustCompile(fmt.Sprintf(episodeMatchRegex, season, episode))
 	for _, choice := range t.files {
 		if re.MatchString(choice.Path) {
@@ -1688,6 +1750,15 @@ func (t *Torrent) ChooseFile(btp *Player) (*File, int, error) {
 		}
 
 		if btp != nil && btp.p.Season > 0 && btp.p.FileIndex < 0 {
+			// File, assigned by the user, is used over automatic matching
+			if f := t.GetEpisodeFile(btp.p.Season, btp.p.Episode); f != nil {
+				for index, choice := range choices {
+					if files[choice.Index] == f {
+						return f, index, nil
+					}
+				}
+			}
+
 			// In episode search we are using smart-match to store found episodes
 			//   in the torrent history table
 			go btp.smartMatch(choices)
@@ -1887,6 +1958,10 @@ func (t *Torrent) TorrentInfo(w io.Writer) {
 		fmt.Fprintf(w, "        %s: %d peers\n", t, p)
 		return true
 	})
+	t.trackerErrors.Range(func(t, e interface{}) bool {
+		fmt.Fprintf(w, "        %s: %s\n", t, e)
+		return true
+	})
 	fmt.Fprint(w, "\n")
 
 	// TODO: Do we need pieces into?
@@ -55,6 +55,11 @@ type TorrentFile struct {
 	RipType     int    `json:"rip_type"`
 	SceneRating int    `json:"scene_rating"`
 
+	// Hybrid providers could return direct streams instead of torrents
+	StreamType string            `json:"stream_type,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	LicenseKey string            `json:"license_key,omitempty"`
+
 	hasResolved bool
 }
 
@@ -235,6 +240,19 @@ func (t *TorrentFile) MarshalJSON() ([]byte, error) {
 	return b, nil
 }
 
+// Update copies exported fields from other torrent, keeping resolve state
+func (t *TorrentFile) Update(other *TorrentFile) {
+	hasResolved := t.hasResolved
+	*t = *other
+	t.hasResolved = hasResolved
+}
+
+// MatchesEpisode checks whether torrent name points to the episode, so it is not a season pack
+func (t *TorrentFile) MatchesEpisode(season int, episode int) bool {
+	re := regexp.MustCompile(fmt.Sprintf(episodeMatchRegex, season, episode))
+	return re.MatchString(t.Title) || re.MatchString(t.Name)
+}
+
 // IsMagnet ...
 func (t *TorrentFile) IsMagnet() bool {
 	return strings.HasPrefix(t.URI, "magnet:")
@@ -294,6 +312,7 @@ func NewTorrentFile(uri string) *TorrentFile {
 }
 
 func (t *TorrentFile) initialize() {
+	t.detectStreamType()
 	if t.IsMagnet() {
 		t.initializeFromMagnet()
 	}
@@ -348,6 +367,16 @@ func (t *TorrentFile) initializeFromMagnet() {
 	}
 }
 
+// HasPrivateTracker returns whether torrent announces to private tracker from trackers.json
+func (t *TorrentFile) HasPrivateTracker() bool {
+	for _, tracker := range t.Trackers {
+		if IsPrivateTracker(tracker) {
+			return true
+		}
+	}
+	return false
+}
+
 // Magnet ...
 func (t *TorrentFile) Magnet() {
 	if t.hasResolved == false {
@@ -363,7 +392,7 @@ func (t *TorrentFile) Magnet() {
 			}
 		}
 	}
-	if config.Get().MagnetTrackers == magnetEnricherAdd {
+	if config.Get().MagnetTrackers == magnetEnricherAdd && !t.HasPrivateTracker() {
 		for _, tracker := range DefaultTrackers {
 			if !util.StringSliceContains(t.Trackers, tracker) {
 				params.Add("tr", tracker)
@@ -481,6 +510,13 @@ func (t *TorrentFile) Download() ([]byte, error) {
 		}
 	}
 
+	// Torrent files come from providers, so they share provider traffic limit,
+	// requests through internal proxy take the slot in the proxy
+	if !config.Get().InternalProxyEnabled {
+		util.ProviderPool.Acquire()
+		defer util.ProviderPool.Release()
+	}
+
 	resp, err := proxy.GetClient().Do(req)
 	if err != nil {
 		return nil, err
@@ -508,7 +544,7 @@ func (t *TorrentFile) Download() ([]byte, error) {
 
 // Resolve ...
 func (t *TorrentFile) Resolve() error {
-	if t.IsMagnet() {
+	if t.IsMagnet() || t.IsStream() {
 		t.hasResolved = true
 		return nil
 	}
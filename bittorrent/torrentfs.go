@@ -78,7 +78,7 @@ func (tfs *TorrentFS) Open(uname string) (http.File, error) {
 				log.Noticef("%s belongs to torrent %s", name, t.Name())
 
 				if !t.IsMemoryStorage() {
-					file, err = os.Open(filepath.Join(string(tfs.Dir), name))
+					file, err = os.Open(filepath.Join(t.SavePath(), name))
 					if err != nil {
 						return nil, err
 					}
@@ -0,0 +1,73 @@
+package bittorrent
+
+import (
+	"time"
+
+	"github.com/projectx13/projectx/trakt"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	watchdogInterval = 10 * time.Second
+	watchdogTimeout  = 5 * time.Second
+	// watchdogMisses is a number of failed heartbeats in a row, after which player is considered dead
+	watchdogMisses = 6
+)
+
+// watchdog checks that Kodi player is alive during playback. If Kodi was killed
+// or the add-on hangs, playback loop could wait forever, so the stream is cleaned up here.
+func (btp *Player) watchdog() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	closing := btp.closer.C()
+	misses := 0
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			if btp.p.Background {
+				return
+			}
+
+			if playing, err := xbmc.PlayerHeartbeat(watchdogTimeout); err == nil && playing {
+				misses = 0
+				continue
+			} else if err != nil {
+				log.Warningf("Player heartbeat failed: %s", err)
+			}
+
+			misses++
+			if misses >= watchdogMisses {
+				btp.onDeadPlayer()
+				return
+			}
+		}
+	}
+}
+
+// onDeadPlayer finishes playback without Kodi: stores resume point,
+// stops scrobbling and removes the torrent, as if playback was stopped
+func (btp *Player) onDeadPlayer() {
+	if btp.IsClosed() || btp.t == nil {
+		return
+	}
+
+	log.Warningf("Player did not respond for %s, cleaning up %s", watchdogInterval*watchdogMisses, btp.t.Name())
+
+	btp.t.IsPlaying = false
+	btp.SaveStoredResume()
+	btp.setRateLimiting(false)
+
+	if btp.scrobble {
+		btp.scrobble = false
+		if btp.p.TraktScrobbled {
+			trakt.Scrobble("stop", btp.p.ContentType, btp.p.TMDBId, btp.p.WatchedTime, btp.p.VideoDuration)
+		}
+	}
+
+	// Nobody is going to play next file
+	btp.t.HasNextFile = false
+	btp.Close()
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"bytes"
 	"errors"
+	"reflect"
 	"strconv"
 	"sync"
 	"time"
@@ -49,7 +50,7 @@ var dbStore *DBStore
 // NewDBStore Returns instance of BoltDB backed cache store
 func NewDBStore() *DBStore {
 	if dbStore == nil {
-		dbStore = &DBStore{database.GetCache()}
+		dbStore = &DBStore{database.GetCacheWithMemory()}
 	}
 
 	return dbStore
@@ -57,6 +58,7 @@ func NewDBStore() *DBStore {
 
 // Set ...
 func (c *DBStore) Set(key string, value interface{}, expires time.Duration) (err error) {
+	key = versionedKey(key)
 	item := DBStoreItem{
 		Key:   key,
 		Value: value,
@@ -74,7 +76,9 @@ func (c *DBStore) Set(key string, value interface{}, expires time.Duration) (err
 		return err
 	}
 
-	return c.db.SetBytes(database.CommonBucket, key, append([]byte(strconv.FormatInt(time.Now().UTC().Add(expires).Unix(), 10)), b...))
+	expires = database.CapExpiration(database.CommonBucket, key, expires)
+	// msgpack encoded item is a map, so it never starts with compression marker
+	return c.db.SetBytes(database.CommonBucket, key, append([]byte(strconv.FormatInt(util.Now().Add(expires).Unix(), 10)), database.EncodeCacheValue(b)...))
 }
 
 // Add ...
@@ -89,6 +93,7 @@ func (c *DBStore) Replace(key string, value interface{}, expires time.Duration)
 
 // Get ...
 func (c *DBStore) Get(key string, value interface{}) (err error) {
+	key = versionedKey(key)
 	data, errGet := c.db.GetBytes(database.CommonBucket, key)
 	if errGet != nil {
 		return errGet
@@ -96,6 +101,11 @@ func (c *DBStore) Get(key string, value interface{}) (err error) {
 		return errors.New("data is empty")
 	}
 
+	return c.decode(key, data, value)
+}
+
+// decode unmarshals stored item into value, expired items are removed
+func (c *DBStore) decode(key string, data []byte, value interface{}) (err error) {
 	// Recover from unmarshal errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -103,28 +113,67 @@ func (c *DBStore) Get(key string, value interface{}) (err error) {
 		}
 	}()
 
-	if err != nil {
-		return err
-	}
-
 	item := DBStoreItem{
 		Value: value,
 	}
-	if expires, _ := database.ParseCacheItem(data); expires > 0 && expires < util.NowInt64() {
-		go c.db.Delete(database.CommonBucket, key)
+	if expires := database.ParseCacheExpiration(data); expires > 0 && expires < util.NowInt64() {
+		// Clock could be wrong until it is verified, so the item is kept for cleanup
+		if util.IsClockVerified() {
+			go c.db.Delete(database.CommonBucket, key)
+		}
 		return errors.New("key is expired")
 	}
 
-	if errDecode := msgpack.Unmarshal(data[10:], &item); errDecode != nil {
+	payload, err := database.DecodeCacheValue(data[10:])
+	if err != nil {
+		return err
+	}
+
+	if errDecode := msgpack.Unmarshal(payload, &item); errDecode != nil {
 		return errDecode
 	}
 
 	return nil
 }
 
+// BatchGet reads values of the keys in one transaction and decodes them concurrently.
+// Results should be a pointer to a slice, which is resized to the number of keys,
+// found tells which elements were read from the cache.
+func (c *DBStore) BatchGet(keys []string, results interface{}) (found []bool, err error) {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return nil, errors.New("results should be a pointer to a slice")
+	}
+	slice = slice.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), len(keys), len(keys)))
+
+	found = make([]bool, len(keys))
+	keys = versionedKeys(keys)
+	values, err := c.db.GetBytesMulti(database.CommonBucket, keys)
+	if err != nil {
+		return found, err
+	}
+
+	var wg sync.WaitGroup
+	for i, data := range values {
+		if len(data) <= 10 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, data []byte) {
+			defer wg.Done()
+			found[i] = c.decode(keys[i], data, slice.Index(i).Addr().Interface()) == nil
+		}(i, data)
+	}
+	wg.Wait()
+
+	return found, nil
+}
+
 // Delete ...
 func (c *DBStore) Delete(key string) error {
-	return c.db.Delete(database.CommonBucket, key)
+	return c.db.Delete(database.CommonBucket, versionedKey(key))
 }
 
 // Increment ...
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path"
 	"time"
+
+	"github.com/projectx13/projectx/util"
 )
 
 // FileStore ...
@@ -46,7 +48,7 @@ func (c *FileStore) Set(key string, value interface{}, expires time.Duration) er
 	item := fileStoreItem{
 		Key:     key,
 		Value:   value,
-		Expires: time.Now().UTC().Add(expires),
+		Expires: util.Now().Add(expires),
 	}
 
 	return json.NewEncoder(gzWriter).Encode(item)
@@ -90,7 +92,7 @@ func (c *FileStore) Get(key string, value interface{}) error {
 	if err = json.NewDecoder(gzReader).Decode(&item); err != nil {
 		return err
 	}
-	if item.Expires.Before(time.Now().UTC()) {
+	if item.Expires.Before(util.Now()) {
 		return errors.New("key is expired")
 	}
 	return nil
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"errors"
+
+	"github.com/projectx13/projectx/database"
+)
+
+var errEmptyPrefix = errors.New("cache: prefix should not be empty")
+
+// InvalidateNamespace removes entries, which keys start with the prefix, from all cache buckets,
+// like "com.tmdb.show.1234." to refresh metadata of a single show
+func InvalidateNamespace(prefix string) (int, error) {
+	if prefix == "" {
+		return 0, errEmptyPrefix
+	}
+
+	// DBStore reads through in-memory layer, so entries are deleted through it,
+	// otherwise they would be served from memory and written back to the disk
+	db := database.GetCacheWithMemory()
+	if db == nil {
+		return 0, errors.New("cache: database is not initialized")
+	}
+
+	removed := 0
+	for _, bucket := range database.CacheBuckets {
+		removed += db.DeleteWithPrefix(bucket, []byte(prefix))
+	}
+
+	log.Infof("Invalidated %d cache entries with prefix %s", removed, prefix)
+	return removed, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/projectx13/projectx/database"
+)
+
+// namespaceVersionDuration keeps stored namespace versions practically forever
+const namespaceVersionDuration = 60 * 60 * 24 * 365 * 10
+
+// namespaceVersions are versions of cached values by key prefix.
+// Version is bumped, when structure of cached values changes, like new fields of TMDB types,
+// so values, cached by previous versions, are not read anymore, and are removed on start.
+// Version 1 keeps keys as is.
+var namespaceVersions = []struct {
+	Prefix  string
+	Version int
+}{
+	{"com.tmdb.", 1},
+	{"com.trakt.", 1},
+	{"com.tvdb.", 1},
+	{"com.fanart.", 1},
+	{"com.imdb.", 1},
+	{"com.anilist.", 1},
+	{pageCachePrefix + ".", 1},
+}
+
+// versionedKey returns key with version of its namespace, so entries of different versions never clash
+func versionedKey(key string) string {
+	for _, ns := range namespaceVersions {
+		if strings.HasPrefix(key, ns.Prefix) {
+			if ns.Version > 1 {
+				return fmt.Sprintf("%s#v%d", key, ns.Version)
+			}
+			return key
+		}
+	}
+	return key
+}
+
+func versionedKeys(keys []string) []string {
+	ret := make([]string, len(keys))
+	for i, key := range keys {
+		ret[i] = versionedKey(key)
+	}
+	return ret
+}
+
+// UpgradeNamespaces removes entries of namespaces, which versions were bumped since previous start,
+// so only affected entries are fetched again, instead of wiping the whole cache
+func UpgradeNamespaces() {
+	db := database.GetCache()
+	if db == nil {
+		return
+	}
+
+	for _, ns := range namespaceVersions {
+		key := "cache.namespace.version." + ns.Prefix
+
+		stored := 1
+		if value, err := db.GetCached(database.CommonBucket, key); err == nil && value != "" {
+			stored, _ = strconv.Atoi(value)
+		}
+		if stored == ns.Version {
+			continue
+		}
+
+		log.Noticef("Cache namespace %s is upgraded from version %d to %d", ns.Prefix, stored, ns.Version)
+		if _, err := InvalidateNamespace(ns.Prefix); err != nil {
+			log.Warningf("Could not invalidate cache namespace %s: %s", ns.Prefix, err)
+			continue
+		}
+		db.SetCached(database.CommonBucket, namespaceVersionDuration, key, strconv.Itoa(ns.Version))
+	}
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/projectx13/projectx/util"
+)
+
+// refreshItem wraps cached value with time, until which the value is fresh,
+// value itself is kept longer, to be returned while it is refreshed
+type refreshItem struct {
+	Fresh     int64       `json:"fresh"`
+	Value     interface{} `json:"value"`
+	Validator Validator   `json:"validator"`
+}
+
+// Validator is a version of the value at its source, like HTTP ETag, kept with the cached value,
+// so that refresh could confirm, that the value is not changed, instead of downloading it again
+type Validator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// RevalidateFunc fills value, validator is a version of the cached value, or empty, when there is none,
+// and is updated with a version of the new value. Returned notModified means, that cached value is current,
+// and value is not filled.
+type RevalidateFunc func(value interface{}, validator *Validator) (notModified bool, err error)
+
+var (
+	errEmptyRefresh = errors.New("refresh returned empty value")
+
+	refreshing   = map[string]struct{}{}
+	refreshingMu sync.Mutex
+)
+
+// GetWithRefresh reads value from the cache, stale value is returned immediately,
+// and refresh is called in background to update the cache.
+// When there is no value, refresh is called to fill the value and result is cached.
+// Refresh gets pointer of the same type as value, and should fill it.
+// Values are kept for twice of maxAge, if bucket policy allows that.
+func (c *DBStore) GetWithRefresh(key string, value interface{}, maxAge time.Duration, refresh func(value interface{}) error) error {
+	return c.GetWithRevalidate(key, value, maxAge, func(value interface{}, _ *Validator) (bool, error) {
+		return false, refresh(value)
+	})
+}
+
+// GetWithRevalidate works like GetWithRefresh, but background refresh gets validator
+// of the cached value, and could keep the value, if it is not changed at the source.
+func (c *DBStore) GetWithRevalidate(key string, value interface{}, maxAge time.Duration, revalidate RevalidateFunc) error {
+	item := refreshItem{Value: value}
+	// Values, cached without refresh time, are treated as missing
+	if err := c.Get(key, &item); err == nil && item.Fresh > 0 {
+		if item.Fresh < util.NowInt64() {
+			go c.refresh(key, reflect.TypeOf(value).Elem(), maxAge, revalidate)
+		}
+		return nil
+	}
+
+	validator := Validator{}
+	if notModified, err := revalidate(value, &validator); err != nil {
+		return err
+	} else if notModified || isNil(value) {
+		// There is no cached value, which could be confirmed
+		return errEmptyRefresh
+	}
+
+	return c.setFresh(key, value, validator, maxAge)
+}
+
+func (c *DBStore) setFresh(key string, value interface{}, validator Validator, maxAge time.Duration) error {
+	item := refreshItem{
+		Fresh:     util.Now().Add(maxAge).Unix(),
+		Value:     value,
+		Validator: validator,
+	}
+	return c.Set(key, item, 2*maxAge)
+}
+
+// refresh fills new value in background, only one refresh runs for each key.
+// Cached value is read again, so it could be kept, when it is not modified.
+func (c *DBStore) refresh(key string, valueType reflect.Type, maxAge time.Duration, revalidate RevalidateFunc) {
+	refreshingMu.Lock()
+	if _, ok := refreshing[key]; ok {
+		refreshingMu.Unlock()
+		return
+	}
+	refreshing[key] = struct{}{}
+	refreshingMu.Unlock()
+
+	defer func() {
+		refreshingMu.Lock()
+		delete(refreshing, key)
+		refreshingMu.Unlock()
+	}()
+
+	stale := refreshItem{Value: reflect.New(valueType).Interface()}
+	if err := c.Get(key, &stale); err != nil || isNil(stale.Value) {
+		stale.Validator = Validator{}
+	}
+
+	// New value is decoded into empty one, so fields of the stale value do not stay there
+	value := reflect.New(valueType).Interface()
+	validator := stale.Validator
+	notModified, err := revalidate(value, &validator)
+	if err != nil {
+		log.Warningf("Could not refresh cached value of %s: %s", key, err)
+		return
+	} else if notModified && stale.Validator != (Validator{}) {
+		value = stale.Value
+	} else if isNil(value) {
+		log.Warningf("Could not refresh cached value of %s: %s", key, errEmptyRefresh)
+		return
+	}
+
+	if err := c.setFresh(key, value, validator, maxAge); err != nil {
+		log.Warningf("Could not save refreshed value of %s: %s", key, err)
+	}
+}
+
+// isNil checks if pointer to a pointer, filled by refresh, is still empty
+func isNil(value interface{}) bool {
+	v := reflect.ValueOf(value).Elem()
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
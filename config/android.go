@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	contentScheme            = "content://"
+	externalStorageAuthority = "com.android.externalstorage.documents"
+	androidPrimaryStorage    = "/storage/emulated/0"
+)
+
+var androidAppStorageRegexp = regexp.MustCompile(`^(.*/Android/data/[^/]+/files)/`)
+
+// IsContentURI checks if path is an Android Storage Access Framework URI
+func IsContentURI(path string) bool {
+	return strings.HasPrefix(strings.ToLower(path), contentScheme)
+}
+
+// ResolveContentURI translates SAF tree/document URI of external storage provider,
+// like content://com.android.externalstorage.documents/tree/primary%3AMovies,
+// to the filesystem path, so it can be used for downloads and free space checks.
+// Daemon has no access to Android APIs, so URIs, which are not available
+// as filesystem paths, are reported as unsupported.
+func ResolveContentURI(path string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("Could not parse content URI %s: %s", path, err)
+	}
+	if u.Host != externalStorageAuthority {
+		return "", fmt.Errorf("Content URI %s is provided by %s, only local storage folders are supported", path, u.Host)
+	}
+
+	// Path looks like /tree/<volume>:<relative path>[/document/<volume>:<relative path>],
+	// with slashes in relative path being escaped
+	parts := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("Content URI %s does not point to a folder", path)
+	}
+	documentID, err := url.PathUnescape(parts[len(parts)-1])
+	if err != nil {
+		return "", fmt.Errorf("Could not parse content URI %s: %s", path, err)
+	}
+
+	idx := strings.Index(documentID, ":")
+	if idx < 0 {
+		return "", fmt.Errorf("Content URI %s does not point to a folder", path)
+	}
+
+	volume, relative := documentID[:idx], documentID[idx+1:]
+	root := androidPrimaryStorage
+	if volume != "primary" {
+		root = filepath.Join("/storage", volume)
+	}
+
+	resolved := filepath.Join(root, filepath.FromSlash(relative))
+	if _, err := os.Stat(resolved); err != nil {
+		return "", fmt.Errorf("Content URI %s is not available as %s: %s", path, resolved, err)
+	}
+
+	return resolved, nil
+}
+
+// AndroidAppStoragePath returns app-specific external folder, which is
+// always writable by Kodi, even with scoped storage restrictions
+func AndroidAppStoragePath(profile string) string {
+	matches := androidAppStorageRegexp.FindStringSubmatch(profile + "/")
+	if len(matches) < 2 {
+		return ""
+	}
+
+	path := filepath.Join(matches[1], "projectx_downloads")
+	if err := os.MkdirAll(path, 0777); err != nil {
+		log.Warningf("Could not create app storage folder at %s: %s", path, err)
+		return ""
+	}
+
+	return path
+}
@@ -30,8 +30,11 @@ const maxMemorySize = 300 * 1024 * 1024
 // Configuration ...
 type Configuration struct {
 	DownloadPath               string
+	StreamCachePath            string
 	TorrentsPath               string
 	LibraryPath                string
+	CachePath                  string
+	LibraryDBPath              string
 	Info                       *xbmc.AddonInfo
 	Platform                   *xbmc.Platform
 	Language                   string
@@ -45,7 +48,32 @@ type Configuration struct {
 	KeepFilesFinished          int
 	UseTorrentHistory          bool
 	TorrentHistorySize         int
+	DatabaseEngine             int
+	CacheMemorySize            int
+	CacheCompression           bool
+	BackupGenerations          int
+	BackupInterval             int
+	RemoteBackupType           int
+	RemoteBackupURL            string
+	RemoteBackupUser           string
+	RemoteBackupPassword       string
+	RemoteBackupInterval       int
+	RemoteBackupRetention      int
+	SyncURL                    string
+	SyncUser                   string
+	SyncPassword               string
+	GuestMode                  bool
+	ParentalControl            bool
+	ParentalPIN                string
+	ParentalMovieRating        string
+	ParentalShowRating         string
+	ParentalBlockUnrated       bool
+	ResultsPostProcessCommand  string
 	UseFanartTv                bool
+	ImageQualityPoster         int
+	ImageQualityFanart         int
+	ImageQualityThumb          int
+	ImageQualityStill          int
 	DisableBgProgress          bool
 	DisableBgProgressPlayback  bool
 	ForceUseTrakt              bool
@@ -55,7 +83,10 @@ type Configuration struct {
 	CacheSearchDuration        int
 	ShowFilesWatched           bool
 	ResultsPerPage             int
+	ConnectionSpeed            int
 	GreetingEnabled            bool
+	NotificationDigest         bool
+	NotificationDigestHour     int
 	EnableOverlayStatus        bool
 	SilentStreamStart          bool
 	AutoYesEnabled             bool
@@ -66,6 +97,7 @@ type Configuration struct {
 	ForceLinkType              bool
 	UseOriginalTitle           bool
 	UseAnimeEnTitle            bool
+	AnimeMetadata              bool
 	UseLowestReleaseDate       bool
 	AddSpecials                bool
 	AddEpisodeNumbers          bool
@@ -73,6 +105,8 @@ type Configuration struct {
 	ShowUnairedEpisodes        bool
 	ShowSeasonsAll             bool
 	ShowSeasonsOrder           int
+	ShowOpenMode               int
+	CastMembersLimit           int
 	SmartEpisodeStart          bool
 	SmartEpisodeMatch          bool
 	SmartEpisodeChoose         bool
@@ -81,6 +115,7 @@ type Configuration struct {
 	LibrarySyncPlaybackEnabled bool
 	LibraryUpdate              int
 	StrmLanguage               string
+	SecondLanguage             string
 	LibraryNFOMovies           bool
 	LibraryNFOShows            bool
 	PlaybackPercent            int
@@ -95,6 +130,7 @@ type Configuration struct {
 	MinCandidateSize           int64
 	MinCandidateShowSize       int64
 	BufferTimeout              int
+	IdleTimeout                int
 	BufferSize                 int
 	EndBufferSize              int
 	KodiBufferSize             int
@@ -104,8 +140,17 @@ type Configuration struct {
 	AutoloadTorrentsPaused     bool
 	LimitAfterBuffering        bool
 	ConnectionsLimit           int
+	BoostDuration              int
+	GeoIPEnabled               bool
+	BlockedCountries           []string
+	APIAllowedOrigins          []string
+	ContentRegion              string
+	WatchRegion                string
+	WatchProviders             string
 	ConnTrackerLimit           int
 	ConnTrackerLimitAuto       bool
+	MetadataConnectionsLimit   int
+	ProviderConnectionsLimit   int
 	SessionSave                int
 
 	SeedForever        bool
@@ -113,6 +158,11 @@ type Configuration struct {
 	SeedTimeRatioLimit int
 	SeedTimeLimit      int
 
+	MeteredPauseSeeding      bool
+	MeteredDownloadRateLimit int
+	MeteredUploadRateLimit   int
+	MeteredBlock4K           bool
+
 	DisableUpload            bool
 	DisableDHT               bool
 	DisableTCP               bool
@@ -134,6 +184,7 @@ type Configuration struct {
 	LibtorrentProfile        int
 	MagnetTrackers           int
 	MagnetResolveTimeout     int
+	TrackerBackoff           int
 	Scrobble                 bool
 
 	AutoScrapeEnabled        bool
@@ -195,12 +246,17 @@ type Configuration struct {
 	StoreResumeAction int
 	TMDBApiKey        string
 
+	TMDBReadAccessToken string
+	TMDBAccessToken     string
+	TMDBAccountID       string
+
 	OSDBUser               string
 	OSDBPass               string
 	OSDBLanguage           string
 	OSDBAutoLanguage       bool
 	OSDBAutoLoad           bool
 	OSDBAutoLoadCount      int
+	OSDBAutoLoadConfidence int
 	OSDBAutoLoadDelete     bool
 	OSDBAutoLoadSkipExists bool
 	OSDBIncludedEnabled    bool
@@ -236,6 +292,7 @@ type Configuration struct {
 	ProxyUseDownload bool
 
 	CompletedMove       bool
+	CompletedVerify     bool
 	CompletedMoviesPath string
 	CompletedShowsPath  string
 
@@ -272,6 +329,9 @@ var (
 
 		LocalHost string `help:"local host, default is '0.0.0.0'"`
 		LocalPort int    `help:"local port, default is '65220'"`
+
+		MockPath   string `help:"fixtures directory, which replaces network services, also set by PROJECTX_MOCK environment variable"`
+		MockRecord bool   `help:"record missing fixtures from network in mock mode"`
 	}{
 		RemoteHost: "127.0.0.1",
 		RemotePort: 65221,
@@ -297,6 +357,9 @@ func Reload() *Configuration {
 	xbmc.XBMCJSONRPCHosts = []string{net.JoinHostPort(Args.RemoteHost, "9090")}
 	xbmc.XBMCExJSONRPCHosts = []string{net.JoinHostPort(Args.RemoteHost, strconv.Itoa(Args.RemotePort))}
 
+	// Kodi language could be changed, so strings are requested again
+	xbmc.ResetLocalizedStrings()
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Warningf("Addon settings not properly set, opening settings window: %#v", r)
@@ -397,13 +460,32 @@ func Reload() *Configuration {
 			settingsWarning = "LOCALIZE[30113]"
 			panic(settingsWarning)
 		} else if err := IsWritablePath(downloadPath); err != nil {
-			log.Errorf("Cannot write to download location '%s': %#v", downloadPath, err)
-			settingsWarning = err.Error()
-			panic(settingsWarning)
+			// With scoped storage on Android we can only write to app-specific folder,
+			// unsupported content URI is not replaced, as user has chosen that folder explicitly
+			if appStorage := AndroidAppStoragePath(info.Profile); platform.OS == "android" && !IsContentURI(downloadPath) && appStorage != "" && IsWritablePath(appStorage) == nil {
+				log.Warningf("Cannot write to download location '%s': %#v, using app storage at '%s'", downloadPath, err, appStorage)
+				xbmc.Notify("projectx", "LOCALIZE[30705]", AddonIcon())
+				downloadPath = appStorage
+			} else {
+				log.Errorf("Cannot write to download location '%s': %#v", downloadPath, err)
+				settingsWarning = err.Error()
+				panic(settingsWarning)
+			}
 		}
 	}
 	log.Infof("Using download path: %s", downloadPath)
 
+	// Streaming buffer could be kept on a faster device, finished downloads are moved to download path
+	streamCachePath := TranslatePath(xbmc.GetSettingString("stream_cache_path"))
+	if streamCachePath == "." || downloadStorage == 1 || filepath.Clean(streamCachePath) == filepath.Clean(downloadPath) {
+		streamCachePath = ""
+	} else if err := IsWritablePath(streamCachePath); err != nil {
+		log.Warningf("Cannot write to stream cache location '%s': %#v, using download path", streamCachePath, err)
+		streamCachePath = ""
+	} else {
+		log.Infof("Using stream cache path: %s", streamCachePath)
+	}
+
 	if libraryPath == "." {
 		log.Errorf("Cannot use library location '%s'", libraryPath)
 		settingsWarning = "LOCALIZE[30220]"
@@ -438,6 +520,10 @@ func Reload() *Configuration {
 	}
 	log.Infof("Using torrents path: %s", torrentsPath)
 
+	cachePath := databaseLocation("cache_path", info.Profile)
+	libraryDBPath := databaseLocation("library_db_path", info.Profile)
+	log.Infof("Using database paths: Cache = %s , Library = %s", cachePath, libraryDBPath)
+
 	xbmcSettings := xbmc.GetAllSettings()
 	settings := make(map[string]interface{})
 	for _, setting := range xbmcSettings {
@@ -474,8 +560,11 @@ func Reload() *Configuration {
 
 	newConfig := Configuration{
 		DownloadPath:               downloadPath,
+		StreamCachePath:            streamCachePath,
 		LibraryPath:                libraryPath,
 		TorrentsPath:               torrentsPath,
+		CachePath:                  cachePath,
+		LibraryDBPath:              libraryDBPath,
 		Info:                       info,
 		Platform:                   platform,
 		Language:                   xbmc.GetLanguageISO639_1(),
@@ -494,6 +583,7 @@ func Reload() *Configuration {
 		MinCandidateSize:           int64(settings["min_candidate_size"].(int) * 1024 * 1024),
 		MinCandidateShowSize:       int64(settings["min_candidate_show_size"].(int) * 1024 * 1024),
 		BufferTimeout:              settings["buffer_timeout"].(int),
+		IdleTimeout:                settings["idle_timeout"].(int),
 		BufferSize:                 settings["buffer_size"].(int) * 1024 * 1024,
 		EndBufferSize:              settings["end_buffer_size"].(int) * 1024 * 1024,
 		UploadRateLimit:            settings["max_upload_rate"].(int) * 1024,
@@ -507,7 +597,32 @@ func Reload() *Configuration {
 		KeepFilesFinished:          settings["keep_files_finished"].(int),
 		UseTorrentHistory:          settings["use_torrent_history"].(bool),
 		TorrentHistorySize:         settings["torrent_history_size"].(int),
+		DatabaseEngine:             settings["database_engine"].(int),
+		CacheMemorySize:            settings["cache_memory_size"].(int),
+		CacheCompression:           settings["cache_compression"].(bool),
+		BackupGenerations:          settings["backup_generations"].(int),
+		BackupInterval:             settings["backup_interval"].(int),
+		RemoteBackupType:           settings["remote_backup_type"].(int),
+		RemoteBackupURL:            settings["remote_backup_url"].(string),
+		RemoteBackupUser:           settings["remote_backup_user"].(string),
+		RemoteBackupPassword:       settings["remote_backup_password"].(string),
+		RemoteBackupInterval:       settings["remote_backup_interval"].(int),
+		RemoteBackupRetention:      settings["remote_backup_retention"].(int),
+		SyncURL:                    settings["sync_url"].(string),
+		SyncUser:                   settings["sync_user"].(string),
+		SyncPassword:               settings["sync_password"].(string),
+		GuestMode:                  settings["guest_mode"].(bool),
+		ParentalControl:            settings["parental_control"].(bool),
+		ParentalPIN:                settings["parental_pin"].(string),
+		ParentalMovieRating:        settings["parental_movie_rating"].(string),
+		ParentalShowRating:         settings["parental_show_rating"].(string),
+		ParentalBlockUnrated:       settings["parental_block_unrated"].(bool),
+		ResultsPostProcessCommand:  settings["results_postprocess_command"].(string),
 		UseFanartTv:                settings["use_fanart_tv"].(bool),
+		ImageQualityPoster:         settings["image_quality_poster"].(int),
+		ImageQualityFanart:         settings["image_quality_fanart"].(int),
+		ImageQualityThumb:          settings["image_quality_thumb"].(int),
+		ImageQualityStill:          settings["image_quality_still"].(int),
 		DisableBgProgress:          settings["disable_bg_progress"].(bool),
 		DisableBgProgressPlayback:  settings["disable_bg_progress_playback"].(bool),
 		ForceUseTrakt:              settings["force_use_trakt"].(bool),
@@ -516,8 +631,11 @@ func Reload() *Configuration {
 		UseCacheTorrents:           settings["use_cache_torrents"].(bool),
 		CacheSearchDuration:        settings["cache_search_duration"].(int),
 		ResultsPerPage:             settings["results_per_page"].(int),
+		ConnectionSpeed:            settings["connection_speed"].(int),
 		ShowFilesWatched:           settings["show_files_watched"].(bool),
 		GreetingEnabled:            settings["greeting_enabled"].(bool),
+		NotificationDigest:         settings["notification_digest"].(bool),
+		NotificationDigestHour:     settings["notification_digest_hour"].(int),
 		EnableOverlayStatus:        settings["enable_overlay_status"].(bool),
 		SilentStreamStart:          settings["silent_stream_start"].(bool),
 		AutoYesEnabled:             settings["autoyes_enabled"].(bool),
@@ -528,6 +646,7 @@ func Reload() *Configuration {
 		ForceLinkType:              settings["force_link_type"].(bool),
 		UseOriginalTitle:           settings["use_original_title"].(bool),
 		UseAnimeEnTitle:            settings["use_anime_en_title"].(bool),
+		AnimeMetadata:              settings["anime_metadata"].(bool),
 		UseLowestReleaseDate:       settings["use_lowest_release_date"].(bool),
 		AddSpecials:                settings["add_specials"].(bool),
 		AddEpisodeNumbers:          settings["add_episode_numbers"].(bool),
@@ -535,6 +654,8 @@ func Reload() *Configuration {
 		ShowUnairedEpisodes:        settings["unaired_episodes"].(bool),
 		ShowSeasonsAll:             settings["seasons_all"].(bool),
 		ShowSeasonsOrder:           settings["seasons_order"].(int),
+		ShowOpenMode:               settings["show_open_mode"].(int),
+		CastMembersLimit:           settings["cast_members_limit"].(int),
 		PlaybackPercent:            settings["playback_percent"].(int),
 		SmartEpisodeStart:          settings["smart_episode_start"].(bool),
 		SmartEpisodeMatch:          settings["smart_episode_match"].(bool),
@@ -544,12 +665,17 @@ func Reload() *Configuration {
 		LibrarySyncPlaybackEnabled: settings["library_sync_playback_enabled"].(bool),
 		LibraryUpdate:              settings["library_update"].(int),
 		StrmLanguage:               settings["strm_language"].(string),
+		SecondLanguage:             settings["second_language"].(string),
 		LibraryNFOMovies:           settings["library_nfo_movies"].(bool),
 		LibraryNFOShows:            settings["library_nfo_shows"].(bool),
 		SeedForever:                settings["seed_forever"].(bool),
 		ShareRatioLimit:            settings["share_ratio_limit"].(int),
 		SeedTimeRatioLimit:         settings["seed_time_ratio_limit"].(int),
 		SeedTimeLimit:              settings["seed_time_limit"].(int) * 3600,
+		MeteredPauseSeeding:        settings["metered_pause_seeding"].(bool),
+		MeteredDownloadRateLimit:   settings["metered_max_download_rate"].(int) * 1024,
+		MeteredUploadRateLimit:     settings["metered_max_upload_rate"].(int) * 1024,
+		MeteredBlock4K:             settings["metered_block_4k"].(bool),
 		DisableUpload:              settings["disable_upload"].(bool),
 		DisableDHT:                 settings["disable_dht"].(bool),
 		DisableTCP:                 settings["disable_tcp"].(bool),
@@ -571,9 +697,14 @@ func Reload() *Configuration {
 		LibtorrentProfile:          settings["libtorrent_profile"].(int),
 		MagnetTrackers:             settings["magnet_trackers"].(int),
 		MagnetResolveTimeout:       settings["magnet_resolve_timeout"].(int),
+		TrackerBackoff:             settings["tracker_backoff"].(int),
 		ConnectionsLimit:           settings["connections_limit"].(int),
+		BoostDuration:              settings["boost_duration"].(int),
+		GeoIPEnabled:               settings["geoip_enabled"].(bool),
 		ConnTrackerLimit:           settings["conntracker_limit"].(int),
 		ConnTrackerLimitAuto:       settings["conntracker_limit_auto"].(bool),
+		MetadataConnectionsLimit:   settings["metadata_connections_limit"].(int),
+		ProviderConnectionsLimit:   settings["provider_connections_limit"].(int),
 		SessionSave:                settings["session_save"].(int),
 		Scrobble:                   settings["trakt_scrobble"].(bool),
 
@@ -636,12 +767,17 @@ func Reload() *Configuration {
 		StoreResumeAction: settings["store_resume_action"].(int),
 		TMDBApiKey:        settings["tmdb_api_key"].(string),
 
+		TMDBReadAccessToken: settings["tmdb_read_access_token"].(string),
+		TMDBAccessToken:     settings["tmdb_access_token"].(string),
+		TMDBAccountID:       settings["tmdb_account_id"].(string),
+
 		OSDBUser:               settings["osdb_user"].(string),
 		OSDBPass:               settings["osdb_pass"].(string),
 		OSDBLanguage:           settings["osdb_language"].(string),
 		OSDBAutoLanguage:       settings["osdb_auto_language"].(bool),
 		OSDBAutoLoad:           settings["osdb_auto_load"].(bool),
 		OSDBAutoLoadCount:      settings["osdb_auto_load_count"].(int),
+		OSDBAutoLoadConfidence: settings["osdb_auto_load_confidence"].(int),
 		OSDBAutoLoadDelete:     settings["osdb_auto_load_delete"].(bool),
 		OSDBAutoLoadSkipExists: settings["osdb_auto_load_skipexists"].(bool),
 		OSDBIncludedEnabled:    settings["osdb_included_enabled"].(bool),
@@ -676,6 +812,7 @@ func Reload() *Configuration {
 		ProxyUseDownload: settings["use_proxy_download"].(bool),
 
 		CompletedMove:       settings["completed_move"].(bool),
+		CompletedVerify:     settings["completed_verify"].(bool),
 		CompletedMoviesPath: settings["completed_movies_path"].(string),
 		CompletedShowsPath:  settings["completed_shows_path"].(string),
 
@@ -726,6 +863,12 @@ func Reload() *Configuration {
 		}
 	}
 
+	// Passwords and tokens, moved out of settings, are read from encrypted storage
+	lock.RLock()
+	resolver := credentialResolver
+	lock.RUnlock()
+	newConfig.resolveCredentials(resolver)
+
 	// Set default Trakt Frequency
 	if newConfig.TraktToken != "" && newConfig.TraktSyncFrequencyMin == 0 {
 		newConfig.TraktSyncFrequencyMin = 5
@@ -736,16 +879,22 @@ func Reload() *Configuration {
 		newConfig.OSDBLanguage = newConfig.Language
 	}
 
-	// Collect proxy settings
-	if newConfig.ProxyEnabled && newConfig.ProxyHost != "" {
-		newConfig.ProxyURL = proxyTypes[newConfig.ProxyType] + "://"
-		if newConfig.ProxyLogin != "" || newConfig.ProxyPassword != "" {
-			newConfig.ProxyURL += newConfig.ProxyLogin + ":" + newConfig.ProxyPassword + "@"
+	// Completed paths are used as directories with trailing separator
+	if newConfig.CompletedMove {
+		for _, p := range []*string{&newConfig.CompletedMoviesPath, &newConfig.CompletedShowsPath} {
+			if IsNetworkPath(*p) {
+				if resolved, err := ResolveNetworkPath(*p); err == nil {
+					*p = resolved + string(os.PathSeparator)
+				} else {
+					log.Warningf("Cannot use completed location: %s", err)
+				}
+			}
 		}
-
-		newConfig.ProxyURL += newConfig.ProxyHost + ":" + strconv.Itoa(newConfig.ProxyPort)
 	}
 
+	// Collect proxy settings
+	newConfig.collectProxyURL()
+
 	// Reading Kodi's advancedsettings file for MemorySize variable to avoid waiting for playback
 	// after projectx's buffer is finished.
 	newConfig.KodiBufferSize = getKodiBufferSize()
@@ -769,6 +918,35 @@ func Reload() *Configuration {
 		newConfig.StrmLanguage = newConfig.Language
 	}
 
+	// Second language for metadata, used when there is no translation to the interface language
+	if tokens := strings.Split(newConfig.SecondLanguage, " | "); len(tokens) == 2 {
+		newConfig.SecondLanguage = tokens[1]
+	} else {
+		newConfig.SecondLanguage = ""
+	}
+
+	// Region of release dates and age ratings, derived from interface language, when it is not set
+	newConfig.ContentRegion = strings.ToUpper(strings.TrimSpace(settings["content_region"].(string)))
+	newConfig.WatchRegion = strings.ToUpper(strings.TrimSpace(settings["watch_region"].(string)))
+	if newConfig.WatchRegion == "" {
+		newConfig.WatchRegion = newConfig.ContentRegion
+	}
+	if newConfig.WatchRegion == "" {
+		newConfig.WatchRegion = "US"
+	}
+	// Provider ids are passed to TMDB as "any of", like "8|337"
+	newConfig.WatchProviders = strings.Join(strings.FieldsFunc(settings["watch_providers"].(string), func(r rune) bool { return r == ',' || r == ' ' || r == '|' }), "|")
+
+	// Country codes are separated with commas or spaces, like "US, GB"
+	for _, code := range strings.FieldsFunc(settings["blocked_countries"].(string), func(r rune) bool { return r == ',' || r == ' ' }) {
+		newConfig.BlockedCountries = append(newConfig.BlockedCountries, strings.ToUpper(code))
+	}
+
+	// Origins of web pages, allowed to use JSON API v2, like "http://192.168.1.10:8080"
+	for _, origin := range strings.FieldsFunc(settings["api_allowed_origins"].(string), func(r rune) bool { return r == ',' || r == ' ' }) {
+		newConfig.APIAllowedOrigins = append(newConfig.APIAllowedOrigins, strings.TrimSuffix(strings.ToLower(origin), "/"))
+	}
+
 	if newConfig.SessionSave == 0 {
 		newConfig.SessionSave = 10
 	}
@@ -783,6 +961,8 @@ func Reload() *Configuration {
 		xbmc.DialogAutoclose = 1200
 	}
 
+	guardGuestMode(config, &newConfig)
+
 	lock.Lock()
 	config = &newConfig
 	lock.Unlock()
@@ -826,16 +1006,61 @@ func TranslatePath(path string) string {
 		return pathDir
 	}
 
-	// Do not translate nfs/smb path
-	// if strings.HasPrefix(path, "nfs:") || strings.HasPrefix(path, "smb:") {
-	// 	if !strings.HasSuffix(path, "/") {
-	// 		path += "/"
-	// 	}
-	// 	return path
-	// }
+	if IsContentURI(path) {
+		resolved, err := ResolveContentURI(path)
+		if err != nil {
+			log.Warning(err)
+			xbmc.Notify("projectx", fmt.Sprintf("LOCALIZE[30831];;%s", path), AddonIcon())
+			return path
+		}
+
+		log.Infof("Using %s as OS location for %s", resolved, path)
+		return resolved
+	}
+
+	// Network paths are replaced with OS mounts, if we can find them,
+	// otherwise we keep them as is to show proper error while validating the path
+	if IsNetworkPath(path) {
+		resolved, err := ResolveNetworkPath(path)
+		if err != nil {
+			log.Warning(err)
+			return path
+		}
+
+		log.Infof("Using %s as OS location for %s", resolved, path)
+		return resolved
+	}
+
 	return filepath.Dir(xbmc.TranslatePath(path))
 }
 
+// databaseLocation returns folder for database files from the setting,
+// profile folder is used if setting is empty or the folder cannot be used
+func databaseLocation(setting string, profile string) string {
+	path := xbmc.GetSettingString(setting)
+	if path == "" {
+		return profile
+	}
+
+	path = TranslatePath(path)
+	if IsNetworkPath(path) || IsContentURI(path) {
+		log.Warningf("Database location '%s' should be a local folder, using profile folder", path)
+		xbmc.Notify("projectx", "LOCALIZE[30725]", AddonIcon())
+		return profile
+	}
+	if err := os.MkdirAll(path, 0777); err != nil {
+		log.Warningf("Could not create database location '%s': %#v, using profile folder", path, err)
+		xbmc.Notify("projectx", "LOCALIZE[30725]", AddonIcon())
+		return profile
+	}
+	if err := IsWritablePath(path); err != nil {
+		log.Warningf("Cannot write to database location '%s': %#v, using profile folder", path, err)
+		xbmc.Notify("projectx", "LOCALIZE[30725]", AddonIcon())
+		return profile
+	}
+	return path
+}
+
 // PathExists returns whether path exists in OS
 func PathExists(path string) bool {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -850,9 +1075,19 @@ func IsWritablePath(path string) error {
 	if path == "." {
 		return errors.New("Path not set")
 	}
-	// TODO: Review this after test evidences come
-	if strings.HasPrefix(path, "nfs") || strings.HasPrefix(path, "smb") {
-		return fmt.Errorf("Network paths are not supported, change %s to a locally mounted path by the OS", path)
+	if IsNetworkPath(path) {
+		resolved, err := ResolveNetworkPath(path)
+		if err != nil {
+			return err
+		}
+		path = resolved
+	}
+	if IsContentURI(path) {
+		resolved, err := ResolveContentURI(path)
+		if err != nil {
+			return err
+		}
+		path = resolved
 	}
 	if p, err := os.Stat(path); err != nil || !p.IsDir() {
 		if err != nil {
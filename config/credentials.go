@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strconv"
+)
+
+// credentialResolver moves passwords and tokens, entered in settings, into encrypted storage,
+// and returns them from there, it is set by database package, when the storage is opened
+var credentialResolver func(setting string, value string) string
+
+// credentials returns settings with passwords and tokens, and fields, where they are read to
+func (c *Configuration) credentials() map[string]*string {
+	return map[string]*string{
+		"trakt_token":            &c.TraktToken,
+		"trakt_refresh_token":    &c.TraktRefreshToken,
+		"tmdb_access_token":      &c.TMDBAccessToken,
+		"tmdb_read_access_token": &c.TMDBReadAccessToken,
+		"osdb_pass":              &c.OSDBPass,
+		"proxy_password":         &c.ProxyPassword,
+		"remote_backup_password": &c.RemoteBackupPassword,
+		"sync_password":          &c.SyncPassword,
+	}
+}
+
+// SetCredentialResolver sets the function, which keeps passwords and tokens in encrypted storage,
+// and applies it to the current configuration, as storage is opened after configuration is loaded
+func SetCredentialResolver(resolver func(setting string, value string) string) {
+	lock.Lock()
+	credentialResolver = resolver
+	newConfig := *config
+	lock.Unlock()
+
+	// Resolver could change settings, which reloads configuration, so it is not called under the lock
+	newConfig.resolveCredentials(resolver)
+
+	lock.Lock()
+	config = &newConfig
+	lock.Unlock()
+}
+
+func (c *Configuration) resolveCredentials(resolver func(setting string, value string) string) {
+	if resolver == nil {
+		return
+	}
+
+	for setting, field := range c.credentials() {
+		*field = resolver(setting, *field)
+	}
+	c.collectProxyURL()
+}
+
+// collectProxyURL builds proxy URL with credentials from proxy settings
+func (c *Configuration) collectProxyURL() {
+	c.ProxyURL = ""
+	if !c.ProxyEnabled || c.ProxyHost == "" {
+		return
+	}
+
+	c.ProxyURL = proxyTypes[c.ProxyType] + "://"
+	if c.ProxyLogin != "" || c.ProxyPassword != "" {
+		c.ProxyURL += c.ProxyLogin + ":" + c.ProxyPassword + "@"
+	}
+	c.ProxyURL += c.ProxyHost + ":" + strconv.Itoa(c.ProxyPort)
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// guardGuestMode keeps guest mode on, when it is turned off in addon settings without PIN.
+// Addon settings could be opened from Kodi directly, so guest mode could not rely on blocking /settings/ routes only.
+// Parental control PIN protects guest mode, it could not be changed, while guest mode is on, to keep it protected.
+func guardGuestMode(oldConfig, newConfig *Configuration) {
+	icon := filepath.Join(newConfig.Info.Path, "icon.png")
+
+	if newConfig.GuestMode && newConfig.ParentalPIN == "" && (oldConfig == nil || !oldConfig.GuestMode) {
+		// Guest mode without PIN would be turned off by anybody
+		log.Warning("Guest mode could not be enabled without parental control PIN")
+		xbmc.Notify("projectx", "LOCALIZE[30829]", icon)
+		xbmc.SetSetting("guest_mode", false)
+		newConfig.GuestMode = false
+		return
+	}
+
+	if oldConfig == nil || !oldConfig.GuestMode || oldConfig.ParentalPIN == "" {
+		return
+	} else if newConfig.GuestMode && newConfig.ParentalPIN == oldConfig.ParentalPIN {
+		return
+	}
+
+	if pin := xbmc.Keyboard("", "LOCALIZE[30781]", true); pin != "" && pin == oldConfig.ParentalPIN {
+		log.Info("Guest mode settings changed with PIN")
+		return
+	}
+
+	log.Warning("Guest mode settings changed without PIN, restoring them")
+	xbmc.Notify("projectx", "LOCALIZE[30830]", icon)
+	xbmc.SetSetting("guest_mode", true)
+	xbmc.SetSetting("parental_pin", oldConfig.ParentalPIN)
+	newConfig.GuestMode = true
+	newConfig.ParentalPIN = oldConfig.ParentalPIN
+}
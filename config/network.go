@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var networkSchemes = []string{"smb://", "nfs://"}
+
+// IsNetworkPath checks if path is a Kodi VFS network path, like smb:// or nfs://
+func IsNetworkPath(path string) bool {
+	lower := strings.ToLower(path)
+	for _, s := range networkSchemes {
+		if strings.HasPrefix(lower, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveNetworkPath tries to find OS-level location for Kodi VFS network path.
+// On Windows SMB shares are accessed through UNC paths, on other systems
+// we are looking for the share in the list of mounted filesystems.
+func ResolveNetworkPath(path string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("Could not parse network path %s: %s", path, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	remote := "/" + strings.Trim(u.Path, "/")
+	share := strings.SplitN(strings.Trim(u.Path, "/"), "/", 2)[0]
+	if u.Hostname() == "" || share == "" {
+		return "", fmt.Errorf("Network path %s should contain host and share", path)
+	}
+
+	if runtime.GOOS == "windows" && scheme == "smb" {
+		return `\\` + u.Hostname() + filepath.FromSlash(remote), nil
+	}
+
+	if mount := findNetworkMount(scheme, strings.ToLower(u.Hostname()), remote); mount != "" {
+		return mount, nil
+	}
+
+	return "", fmt.Errorf("Network path %s is not mounted by the OS, mount //%s/%s and select the mounted folder instead", path, u.Hostname(), share)
+}
+
+// findNetworkMount looks for a local folder, matching remote path, in /proc/mounts,
+// or in /Volumes on macOS, where shares are mounted by the share name.
+func findNetworkMount(scheme, host, remote string) string {
+	if runtime.GOOS == "darwin" {
+		mount := filepath.Join("/Volumes", filepath.FromSlash(remote))
+		if PathExists(mount) {
+			return mount
+		}
+		return ""
+	}
+
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	lowerRemote := strings.ToLower(remote)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		source := strings.ToLower(strings.ReplaceAll(fields[0], `\040`, " "))
+		mountpoint := strings.ReplaceAll(fields[1], `\040`, " ")
+		fsType := fields[2]
+
+		export := ""
+		switch {
+		case scheme == "smb" && (fsType == "cifs" || fsType == "smb3" || fsType == "smbfs"):
+			export = strings.TrimPrefix(source, "//"+host)
+		case scheme == "nfs" && strings.HasPrefix(fsType, "nfs"):
+			export = strings.TrimPrefix(source, host+":")
+		}
+		if export == "" || export == source {
+			continue
+		}
+
+		export = "/" + strings.Trim(export, "/")
+		if lowerRemote == export || strings.HasPrefix(lowerRemote, export+"/") || export == "/" {
+			return filepath.Join(mountpoint, filepath.FromSlash(remote[len(strings.TrimSuffix(export, "/")):]))
+		}
+	}
+
+	return ""
+}
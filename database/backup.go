@@ -0,0 +1,196 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	backupTimeFormat         = "20060102-150405"
+	defaultBackupGenerations = 5
+	defaultBackupInterval    = 2 * time.Hour
+	pendingRestoreSuffix     = ".restore"
+	checksumSuffix           = ".sha256"
+)
+
+var backupGenerationRegexp = regexp.MustCompile(`-\d{8}-\d{6}$`)
+
+// BackupInterval returns time between database backups, selected in the settings
+func BackupInterval() time.Duration {
+	if hours := config.Get().BackupInterval; hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return defaultBackupInterval
+}
+
+func backupGenerationsCount() int {
+	if count := config.Get().BackupGenerations; count > 0 {
+		return count
+	}
+	return defaultBackupGenerations
+}
+
+// newBackupGeneration returns timestamped path for the new backup,
+// like storm-backup-20200601-150405.db for storm-backup.db
+func newBackupGeneration(backupPath string) string {
+	ext := filepath.Ext(backupPath)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(backupPath, ext), time.Now().Format(backupTimeFormat), ext)
+}
+
+// BackupGenerations returns existing backups of the database, newest first.
+// Backup from older versions, without timestamp, goes last.
+func BackupGenerations(backupPath string) []string {
+	ext := filepath.Ext(backupPath)
+	base := strings.TrimSuffix(backupPath, ext)
+
+	matches, _ := filepath.Glob(base + "-*" + ext)
+	ret := []string{}
+	for _, m := range matches {
+		if backupGenerationRegexp.MatchString(strings.TrimSuffix(m, ext)) {
+			ret = append(ret, m)
+		}
+	}
+	// Timestamp suffix makes lexical order chronological
+	sort.Sort(sort.Reverse(sort.StringSlice(ret)))
+
+	if _, err := os.Stat(backupPath); err == nil {
+		ret = append(ret, backupPath)
+	}
+	return ret
+}
+
+// LatestBackup returns the newest backup of the database, or backupPath if there are none
+func LatestBackup(backupPath string) string {
+	if generations := BackupGenerations(backupPath); len(generations) > 0 {
+		return generations[0]
+	}
+	return backupPath
+}
+
+// BackupTime returns creation time of the backup generation, parsed from the file name
+func BackupTime(path string) time.Time {
+	name := strings.TrimSuffix(path, filepath.Ext(path))
+	if match := backupGenerationRegexp.FindString(name); match != "" {
+		if t, err := time.ParseInLocation(backupTimeFormat, match[1:], time.Local); err == nil {
+			return t
+		}
+	}
+
+	if fi, err := os.Stat(path); err == nil {
+		return fi.ModTime()
+	}
+	return time.Time{}
+}
+
+// rotateBackup saves new backup generation with create function
+// and removes generations, exceeding the count, selected in the settings
+func rotateBackup(backupPath string, create func(path string) error) (string, error) {
+	path := newBackupGeneration(backupPath)
+	if err := create(path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	// Partially written backup should not replace good data on restore
+	if err := checkFile(path); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("backup is inconsistent: %s", err)
+	}
+	if err := writeChecksum(path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	keep := backupGenerationsCount()
+	for i, old := range BackupGenerations(backupPath) {
+		if i < keep || old == backupPath {
+			continue
+		}
+
+		log.Debugf("Removing old database backup %s", old)
+		if err := os.Remove(old); err != nil {
+			log.Warningf("Could not remove old backup %s: %s", old, err)
+		}
+		os.Remove(old + checksumSuffix)
+	}
+
+	return path, nil
+}
+
+// ScheduleRestore marks backup to be restored on the next start,
+// as opened database file can't be replaced
+func ScheduleRestore(databasePath string, backupPath string) error {
+	return ioutil.WriteFile(databasePath+pendingRestoreSuffix, []byte(backupPath), 0600)
+}
+
+// applyPendingRestore restores backup, selected with ScheduleRestore
+func applyPendingRestore(databasePath string) {
+	markerPath := databasePath + pendingRestoreSuffix
+	b, err := ioutil.ReadFile(markerPath)
+	if err != nil {
+		return
+	}
+	os.Remove(markerPath)
+
+	backupPath := strings.TrimSpace(string(b))
+	if err := checkFile(backupPath); err != nil {
+		log.Warningf("Not restoring backup %s, it is corrupted: %s", backupPath, err)
+		return
+	}
+
+	RestoreBackup(databasePath, backupPath)
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeChecksum saves SHA-256 of the backup next to it
+func writeChecksum(path string) error {
+	sum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path+checksumSuffix, []byte(sum), 0600)
+}
+
+// verifyChecksum compares backup with saved SHA-256,
+// backups from older versions, without checksum, are accepted
+func verifyChecksum(path string) error {
+	b, err := ioutil.ReadFile(path + checksumSuffix)
+	if os.IsNotExist(err) {
+		log.Debugf("Backup %s has no checksum", path)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	} else if expected := strings.TrimSpace(string(b)); sum != expected {
+		return fmt.Errorf("checksum mismatch, expected %s, got %s", expected, sum)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/projectx13/projectx/util"
+)
+
+const (
+	// Large values are split into chunks, so that reading a part of the value,
+	// like a range request from Kodi, does not load the whole value into memory
+	blobChunkSize = 256 * 1024
+	blobMarker    = "\x00blob|"
+)
+
+var (
+	errBlobNotFound = errors.New("Blob not found")
+	errBlobExpired  = errors.New("Blob expired")
+	errBlobSeek     = errors.New("Invalid seek position")
+)
+
+// DBReader reads stored value, large values are read chunk by chunk
+type DBReader struct {
+	database *BoltDatabase
+	bucket   []byte
+	key      string
+	cached   bool
+
+	size   int64
+	chunks int
+	offset int64
+
+	chunkIndex int
+	chunk      []byte
+}
+
+func blobChunkKey(key string, index int) []byte {
+	return []byte(fmt.Sprintf("%s\x00%06d", key, index))
+}
+
+func blobHeader(size int, chunks int) []byte {
+	return []byte(fmt.Sprintf("%s%d|%d", blobMarker, size, chunks))
+}
+
+// parseBlobHeader returns size and number of chunks, ok is false for plain values
+func parseBlobHeader(value []byte) (size int64, chunks int, ok bool) {
+	if !bytes.HasPrefix(value, []byte(blobMarker)) {
+		return 0, 0, false
+	}
+
+	parts := bytes.SplitN(value[len(blobMarker):], []byte("|"), 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(string(parts[0]), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	chunks, err = strconv.Atoi(string(parts[1]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return size, chunks, true
+}
+
+// SetBlob saves the value, splitting it into chunks, if it is large
+func (d *BoltDatabase) SetBlob(bucket []byte, key string, value []byte) error {
+	return d.setBlob(bucket, key, value, func(v []byte) []byte { return v })
+}
+
+// SetCachedBlob saves the value, expiring in selected seconds,
+// all chunks have the same expiration, so they are removed by cache cleanup together
+func (d *BoltDatabase) SetCachedBlob(bucket []byte, seconds int, key string, value []byte) error {
+	return d.setBlob(bucket, key, value, func(v []byte) []byte {
+		return cacheItem(bucket, seconds, key, v)
+	})
+}
+
+func (d *BoltDatabase) setBlob(bucket []byte, key string, value []byte, wrap func([]byte) []byte) error {
+	d.unqueue(bucket, key)
+
+	parts := [][]byte{}
+	if len(value) > blobChunkSize {
+		for offset := 0; offset < len(value); offset += blobChunkSize {
+			end := offset + blobChunkSize
+			if end > len(value) {
+				end = len(value)
+			}
+			parts = append(parts, value[offset:end])
+		}
+	}
+
+	return d.store.Update(func(tx StoreTx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		if err := deleteBlobChunks(b, bucket, key); err != nil {
+			return err
+		}
+
+		if len(parts) == 0 {
+			item := wrap(value)
+			d.indexExpiry(bucket, key, item)
+			return b.Put([]byte(key), item)
+		}
+
+		for i, part := range parts {
+			chunk := wrap(part)
+			d.indexExpiry(bucket, string(blobChunkKey(key, i)), chunk)
+			if err := b.Put(blobChunkKey(key, i), chunk); err != nil {
+				return err
+			}
+		}
+
+		item := wrap(blobHeader(len(value), len(parts)))
+		d.indexExpiry(bucket, key, item)
+		return b.Put([]byte(key), item)
+	})
+}
+
+// deleteBlobChunks removes chunks of previously stored value of the key
+func deleteBlobChunks(b StoreBucket, bucket []byte, key string) error {
+	header := b.Get([]byte(key))
+	if header == nil {
+		return nil
+	}
+	if isCacheBucket(bucket) {
+		_, header = ParseCacheItem(header)
+	}
+
+	_, chunks, ok := parseBlobHeader(header)
+	if !ok {
+		return nil
+	}
+	for i := 0; i < chunks; i++ {
+		if err := b.Delete(blobChunkKey(key, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBlob removes the value with all its chunks
+func (d *BoltDatabase) DeleteBlob(bucket []byte, key string) error {
+	d.unqueue(bucket, key)
+
+	return d.store.Update(func(tx StoreTx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		if err := deleteBlobChunks(b, bucket, key); err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// AsReader returns a reader of stored value, which can be used with http.ServeContent,
+// values from cache buckets should be saved with SetCachedBlob
+func (d *BoltDatabase) AsReader(bucket []byte, key string) (*DBReader, error) {
+	r := &DBReader{
+		database:   d,
+		bucket:     bucket,
+		key:        key,
+		cached:     isCacheBucket(bucket),
+		chunkIndex: -1,
+	}
+
+	value, err := r.get([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if size, chunks, ok := parseBlobHeader(value); ok {
+		r.size = size
+		r.chunks = chunks
+	} else {
+		r.size = int64(len(value))
+		r.chunks = 1
+		r.chunkIndex = 0
+		r.chunk = value
+	}
+
+	return r, nil
+}
+
+// get reads the value, unwrapping cache item in cache buckets
+func (r *DBReader) get(key []byte) ([]byte, error) {
+	value, err := r.database.GetBytes(r.bucket, string(key))
+	if err != nil {
+		return nil, err
+	} else if value == nil {
+		return nil, errBlobNotFound
+	}
+
+	if !r.cached {
+		return value, nil
+	}
+
+	expire, v := ParseCacheItem(value)
+	if expire == 0 || expire < util.NowInt64() {
+		return nil, errBlobExpired
+	}
+	return v, nil
+}
+
+// Size returns size of the value
+func (r *DBReader) Size() int64 {
+	return r.size
+}
+
+// Read ...
+func (r *DBReader) Read(p []byte) (n int, err error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	index := int(r.offset / blobChunkSize)
+	if r.chunks == 1 {
+		index = 0
+	}
+	if index != r.chunkIndex {
+		chunk, err := r.get(blobChunkKey(r.key, index))
+		if err != nil {
+			return 0, err
+		}
+		r.chunkIndex = index
+		r.chunk = chunk
+	}
+
+	start := r.offset - int64(index)*blobChunkSize
+	if start >= int64(len(r.chunk)) {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	n = copy(p, r.chunk[start:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// Seek ...
+func (r *DBReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errBlobSeek
+	}
+
+	if offset < 0 {
+		return 0, errBlobSeek
+	}
+	r.offset = offset
+	return offset, nil
+}
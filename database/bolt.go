@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/anacrolix/missinggo/perf"
@@ -21,51 +23,85 @@ import (
 
 // InitCacheDB ...
 func InitCacheDB(conf *config.Configuration) (*BoltDatabase, error) {
-	db, err := CreateBoltDB(conf, cacheFileName, backupCacheFileName)
-	if err != nil || db == nil {
+	store, err := CreateStore(conf, cacheFileName, backupCacheFileName)
+	if err != nil || store == nil {
 		return nil, errors.New("database not created")
 	}
+	fileName, backupFileName := storeFileNames(conf.DatabaseEngine, cacheFileName, backupCacheFileName)
+
+	expiry := newExpiryIndex(store)
+
+	cacheMemoryDatabase = nil
+	if conf.CacheMemorySize > 0 {
+		memory := newMemoryStore(store, conf.CacheMemorySize)
+		store = &memoryView{memoryStore: memory}
+
+		cacheMemoryDatabase = &BoltDatabase{
+			store:          instrumentStore(&memoryView{memoryStore: memory, writeBack: true}),
+			expiry:         expiry,
+			quit:           make(chan struct{}, 2),
+			dir:            databaseDir(conf, fileName),
+			fileName:       fileName,
+			backupFileName: backupFileName,
+		}
+	}
 
+	store = instrumentStore(store)
 	cacheDatabase = &BoltDatabase{
-		db:             db,
+		store:          store,
+		queue:          newWriteQueue(store),
+		expiry:         expiry,
 		quit:           make(chan struct{}, 2),
-		fileName:       cacheFileName,
-		backupFileName: backupCacheFileName,
+		dir:            databaseDir(conf, fileName),
+		fileName:       fileName,
+		backupFileName: backupFileName,
+	}
+	if cacheMemoryDatabase == nil {
+		cacheMemoryDatabase = cacheDatabase
 	}
 
-	for _, bucket := range CacheBuckets {
+	for _, bucket := range append([][]byte{SecretBucket, SyncBucket, ExpiryIndexBucket}, CacheBuckets...) {
 		if err = cacheDatabase.CheckBucket(bucket); err != nil {
 			xbmc.Notify("projectx", err.Error(), config.AddonIcon())
 			log.Error(err)
 			return cacheDatabase, err
 		}
 	}
+	// Passwords and tokens are moved from settings into SecretBucket
+	config.SetCredentialResolver(credential)
 
 	return cacheDatabase, nil
 }
 
 // CreateBoltDB ...
 func CreateBoltDB(conf *config.Configuration, fileName string, backupFileName string) (*bolt.DB, error) {
-	databasePath := filepath.Join(conf.Info.Profile, fileName)
-	backupPath := filepath.Join(conf.Info.Profile, backupFileName)
+	relocateDatabase(conf, fileName, backupFileName)
+
+	dir := databaseDir(conf, fileName)
+	databasePath := filepath.Join(dir, fileName)
+	backupPath := filepath.Join(dir, backupFileName)
+
+	CheckIntegrity(databasePath, backupPath)
 
 	defer func() {
 		if r := recover(); r != nil {
 			log.Errorf("Got critical error while creating Bolt: %v", r)
-			RestoreBackup(databasePath, backupPath)
+			RestoreBackup(databasePath, LatestBackup(backupPath))
 			os.Exit(1)
 		}
 	}()
 
-	db, err := bolt.Open(databasePath, 0600, &bolt.Options{
-		ReadOnly: false,
-		Timeout:  15 * time.Second,
-	})
+	path, err := openPath(databasePath)
+	if err != nil {
+		log.Warningf("Could not prepare database at %s: %#v", databasePath, err)
+		return nil, err
+	}
+
+	db, err := openBoltFile(path)
 	if err != nil {
-		log.Warningf("Could not open database at %s: %#v", databasePath, err)
+		log.Warningf("Could not open database at %s: %#v", path, err)
 		return nil, err
 	}
-	db.NoSync = true
 
 	return db, nil
 }
@@ -80,60 +116,140 @@ func GetCache() *BoltDatabase {
 	return cacheDatabase
 }
 
+// GetCacheWithMemory returns Cache database with in-memory layer,
+// where writes are flushed to the disk in background
+func GetCacheWithMemory() *BoltDatabase {
+	return cacheMemoryDatabase
+}
+
 // GetFilename returns bolt filename
 func (d *BoltDatabase) GetFilename() string {
 	return d.fileName
 }
 
+// GetBackupFilename returns bolt backup filename
+func (d *BoltDatabase) GetBackupFilename() string {
+	return d.backupFileName
+}
+
+// GetPath returns location of bolt file
+func (d *BoltDatabase) GetPath() string {
+	return filepath.Join(d.dir, d.fileName)
+}
+
+// GetBackupPath returns location of bolt backup file
+func (d *BoltDatabase) GetBackupPath() string {
+	return filepath.Join(d.dir, d.backupFileName)
+}
+
 // Close ...
 func (d *BoltDatabase) Close() {
 	log.Debug("Closing Bolt Database")
 	d.quit <- struct{}{}
-	d.db.Close()
+	if d.queue != nil {
+		if err := d.queue.Close(); err != nil {
+			log.Warningf("Could not flush write queue: %s", err)
+		}
+	}
+	if d.expiry != nil {
+		if err := d.expiry.Close(); err != nil {
+			log.Warningf("Could not flush expiry index: %s", err)
+		}
+	}
+	d.store.Close()
 }
 
 // CheckBucket ...
 func (d *BoltDatabase) CheckBucket(bucket []byte) error {
-	return d.db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucket)
-		return err
-	})
+	return d.store.CreateBucket(bucket)
 }
 
 // BucketExists checks if bucket already exists in the database
 func (d *BoltDatabase) BucketExists(bucket []byte) (res bool) {
-	d.db.View(func(tx *bolt.Tx) error {
-		res = tx.Bucket(bucket) != nil
-		return nil
-	})
+	buckets, _ := d.store.Buckets()
+	for _, b := range buckets {
+		if bytes.Equal(b, bucket) {
+			return true
+		}
+	}
 
 	return
 }
 
 // RecreateBucket ...
 func (d *BoltDatabase) RecreateBucket(bucket []byte) error {
-	return d.db.Update(func(tx *bolt.Tx) error {
-		errDrop := tx.DeleteBucket(bucket)
-		if errDrop != nil {
-			return errDrop
-		}
+	if err := d.store.DeleteBucket(bucket); err != nil {
+		return err
+	}
+
+	return d.store.CreateBucket(bucket)
+}
+
+// Compact rewrites database file to reclaim unused space and returns amount of reclaimed bytes
+func (d *BoltDatabase) Compact() (int64, error) {
+	defer perf.ScopeTimer()()
+
+	path := d.GetPath()
+	before, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("Compacting database at %s", path)
+	if err := d.store.Compact(); err != nil {
+		log.Warningf("Could not compact database at %s: %s", path, err)
+		return 0, err
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("Database at %s compacted from %d to %d bytes", path, before.Size(), after.Size())
+	return before.Size() - after.Size(), nil
+}
 
-		_, errCreate := tx.CreateBucketIfNotExists(bucket)
-		return errCreate
+// Stats returns usage of the database file, buckets are sorted by size, biggest first
+func (d *BoltDatabase) Stats() (*DatabaseStats, error) {
+	stats, err := d.store.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	stats.FileName = d.fileName
+	if fi, err := os.Stat(d.GetPath()); err == nil {
+		stats.FileSize = fi.Size()
+	}
+
+	sort.Slice(stats.Buckets, func(i, j int) bool {
+		return stats.Buckets[i].Bytes > stats.Buckets[j].Bytes
 	})
+	return stats, nil
 }
 
 // MaintenanceRefreshHandler ...
 func (d *BoltDatabase) MaintenanceRefreshHandler() {
-	backupPath := filepath.Join(config.Get().Info.Profile, d.backupFileName)
+	if IsReadOnly() {
+		return
+	}
+
+	backupPath := d.GetBackupPath()
 
 	d.CreateBackup(backupPath)
+	waitClock()
 	d.CacheCleanup()
 
-	tickerBackup := time.NewTicker(2 * time.Hour)
+	done := make(chan struct{})
+	for _, bucket := range CacheBuckets {
+		go d.expiryScheduler(bucket, done)
+	}
+
+	tickerBackup := time.NewTicker(BackupInterval())
 
 	defer tickerBackup.Stop()
 	defer close(d.quit)
+	defer close(done)
 
 	for {
 		select {
@@ -141,102 +257,109 @@ func (d *BoltDatabase) MaintenanceRefreshHandler() {
 			go func() {
 				d.CreateBackup(backupPath)
 			}()
-			// case <-tickerCache.C:
-			// 	go d.CacheCleanup()
 		case <-d.quit:
 			return
 		}
 	}
 }
 
-// RestoreBackup ...
-func RestoreBackup(databasePath string, backupPath string) {
+// RestoreBackup replaces database with the backup, if backup does not exist, database is removed,
+// so it starts from scratch. Returns an error, when backup was not restored.
+func RestoreBackup(databasePath string, backupPath string) error {
 	log.Warningf("Restoring backup from '%s' to '%s'", backupPath, databasePath)
 
+	_, errBackup := os.Stat(backupPath)
+	if errBackup == nil {
+		if err := verifyChecksum(backupPath); err != nil {
+			log.Warningf("Not restoring backup %s: %s", backupPath, err)
+			return err
+		}
+	}
+
 	// Remove existing library.db if needed
 	if _, err := os.Stat(databasePath); err == nil {
 		if err := os.Remove(databasePath); err != nil {
 			log.Warningf("Could not delete existing library file (%s): %s", databasePath, err)
-			return
+			return err
 		}
 	}
+	// Write-ahead log of SQLite database belongs to the removed file
+	os.Remove(databasePath + "-wal")
+	os.Remove(databasePath + "-shm")
 
-	// Restore backup if exists
-	if _, err := os.Stat(backupPath); err == nil {
-		errorMsg := fmt.Sprintf("Could not restore backup from '%s' to '%s': ", backupPath, databasePath)
+	if errBackup != nil {
+		return errBackup
+	}
 
-		srcFile, err := os.Open(backupPath)
-		if err != nil {
-			log.Warning(errorMsg, err)
-			return
-		}
-		defer srcFile.Close()
+	if err := copyFile(backupPath, databasePath); err != nil {
+		log.Warningf("Could not restore backup from '%s' to '%s': %s", backupPath, databasePath, err)
+		// Partially copied file is worse than empty database
+		os.Remove(databasePath)
+		return err
+	}
 
-		destFile, err := os.Create(databasePath)
-		if err != nil {
-			log.Warning(errorMsg, err)
-			return
-		}
-		defer destFile.Close()
+	log.Warningf("Restored backup to %s", databasePath)
+	return nil
+}
 
-		if _, err := io.Copy(destFile, srcFile); err != nil {
-			log.Warning(errorMsg, err)
-			return
-		}
+func copyFile(srcPath string, destPath string) error {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
 
-		if err := destFile.Sync(); err != nil {
-			log.Warning(errorMsg, err)
-			return
-		}
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
 
-		log.Warningf("Restored backup to %s", databasePath)
+	if _, err := io.Copy(destFile, srcFile); err != nil {
+		return err
 	}
+	return destFile.Sync()
 }
 
 // CreateBackup ...
 func (d *BoltDatabase) CreateBackup(backupPath string) {
-	d.db.View(func(tx *bolt.Tx) error {
-		tx.CopyFile(backupPath, 0600)
-		log.Debugf("Database backup saved at: %s", backupPath)
-		return nil
-	})
+	path, err := rotateBackup(backupPath, d.store.Backup)
+	if err != nil {
+		log.Warningf("Could not save database backup for %s: %s", backupPath, err)
+		return
+	}
+	log.Debugf("Database backup saved at: %s", path)
+
+	go PushRemoteBackup(path, d.fileName)
 }
 
 // CacheCleanup ...
 func (d *BoltDatabase) CacheCleanup() {
 	defer perf.ScopeTimer()()
 
-	now := util.NowInt64()
+	// Expiry index is used between full scans, see fullCleanupInterval
+	_, err := d.GetCached(CommonBucket, fullCleanupKey)
+	full := err != nil
 	for _, bucket := range CacheBuckets {
-		if !d.BucketExists(bucket) {
-			continue
+		if full {
+			d.fullCleanupBucket(bucket)
+		} else {
+			d.CleanupBucket(bucket)
 		}
+	}
 
-		toRemove := []string{}
-		d.ForEach(bucket, func(key []byte, value []byte) error {
-			expire, _ := ParseCacheItem(value)
-			if (expire > 0 && expire < now) || expire == 0 {
-				toRemove = append(toRemove, string(key))
-			}
-
-			return nil
-		})
-
-		if len(toRemove) > 0 {
-			log.Debugf("Removing %d invalidated items from cache", len(toRemove))
-			d.BatchDelete(bucket, toRemove)
-		}
+	if full {
+		d.SetCached(CommonBucket, fullCleanupInterval, fullCleanupKey, "1")
 	}
 }
 
-// DeleteWithPrefix ...
-func (d *BoltDatabase) DeleteWithPrefix(bucket []byte, prefix []byte) {
-	toRemove := []string{}
-	d.ForEach(bucket, func(key []byte, v []byte) error {
-		if bytes.HasPrefix(key, prefix) {
-			toRemove = append(toRemove, string(key))
-		}
+// DeleteWithPrefix removes keys, starting with the prefix, and returns number of removed keys
+func (d *BoltDatabase) DeleteWithPrefix(bucket []byte, prefix []byte) int {
+	d.unqueuePrefix(bucket, prefix)
 
+	toRemove := []string{}
+	d.store.Seek(bucket, prefix, func(key []byte, v []byte) error {
+		toRemove = append(toRemove, string(key))
 		return nil
 	})
 
@@ -244,6 +367,7 @@ func (d *BoltDatabase) DeleteWithPrefix(bucket []byte, prefix []byte) {
 		log.Debugf("Deleting %d items from cache", len(toRemove))
 		d.BatchDelete(bucket, toRemove)
 	}
+	return len(toRemove)
 }
 
 //
@@ -252,53 +376,98 @@ func (d *BoltDatabase) DeleteWithPrefix(bucket []byte, prefix []byte) {
 
 // Seek ...
 func (d *BoltDatabase) Seek(bucket []byte, prefix string, callback callBack) error {
-	return d.db.View(func(tx *bolt.Tx) error {
-		c := tx.Bucket(bucket).Cursor()
-		bytePrefix := []byte(prefix)
-		for k, v := c.Seek(bytePrefix); k != nil && bytes.HasPrefix(k, bytePrefix); k, v = c.Next() {
-			callback(k, v)
-		}
+	return d.store.Seek(bucket, []byte(prefix), func(k []byte, v []byte) error {
+		callback(k, v)
 		return nil
 	})
 }
 
+// SeekPage iterates over keys with the prefix, skipping first offset keys
+// and stopping after limit keys, limit <= 0 means no limit
+func (d *BoltDatabase) SeekPage(bucket []byte, prefix string, offset int, limit int, callback callBack) error {
+	return ignoreStop(d.store.Seek(bucket, []byte(prefix), pageCallback(offset, limit, callback)))
+}
+
+// SeekPageReverse is like SeekPage, but iterates in descending order of keys
+func (d *BoltDatabase) SeekPageReverse(bucket []byte, prefix string, offset int, limit int, callback callBack) error {
+	return ignoreStop(d.store.SeekReverse(bucket, []byte(prefix), pageCallback(offset, limit, callback)))
+}
+
+func pageCallback(offset int, limit int, callback callBack) callBackWithError {
+	index := 0
+	return func(k []byte, v []byte) error {
+		defer func() { index++ }()
+
+		if index < offset {
+			return nil
+		} else if limit > 0 && index >= offset+limit {
+			return errStopIteration
+		}
+
+		callback(k, v)
+		return nil
+	}
+}
+
+func ignoreStop(err error) error {
+	if err == errStopIteration {
+		return nil
+	}
+	return err
+}
+
 // ForEach ...
 func (d *BoltDatabase) ForEach(bucket []byte, callback callBackWithError) error {
-	return d.db.View(func(tx *bolt.Tx) error {
-		tx.Bucket(bucket).ForEach(callback)
-		return nil
-	})
+	return d.store.Seek(bucket, nil, callback)
 }
 
 //
 // Cache operations
 //
 
-// ParseCacheItem ...
+// ParseCacheExpiration returns expiration time of cached item, without decoding the value
+func ParseCacheExpiration(item []byte) int64 {
+	if len(item) < 10 {
+		return 0
+	}
+
+	expire, _ := strconv.ParseInt(string(item[0:10]), 10, 64)
+	return expire
+}
+
+// ParseCacheItem returns expiration time and the value of cached item,
+// items are stored as "<expiration>|<value>", or "<expiration>~<compressed value>"
 func ParseCacheItem(item []byte) (int64, []byte) {
 	if len(item) < 11 {
 		return 0, nil
 	}
 
 	expire, _ := strconv.ParseInt(string(item[0:10]), 10, 64)
+	if item[10] == CompressedMarker {
+		value, err := decompressValue(item[11:])
+		if err != nil {
+			log.Warningf("Could not decompress cached item: %s", err)
+			return 0, nil
+		}
+		return expire, value
+	}
+
 	return expire, item[11:]
 }
 
 // GetCachedBytes ...
 func (d *BoltDatabase) GetCachedBytes(bucket []byte, key string) (cacheValue []byte, err error) {
-	var value []byte
-	err = d.db.View(func(tx *bolt.Tx) error {
-		value = tx.Bucket(bucket).Get([]byte(key))
-		return nil
-	})
-
+	value, err := d.GetBytes(bucket, key)
 	if err != nil || len(value) == 0 {
 		return
 	}
 
 	expire, v := ParseCacheItem(value)
 	if expire > 0 && expire < util.NowInt64() {
-		d.Delete(bucket, key)
+		// Clock could be wrong until it is verified, so the item is kept for cleanup
+		if util.IsClockVerified() {
+			d.Delete(bucket, key)
+		}
 		return nil, errors.New("Key Expired")
 	} else if expire == 0 {
 		d.Delete(bucket, key)
@@ -344,25 +513,17 @@ func (d *BoltDatabase) GetCachedObject(bucket []byte, key string, item interface
 //
 
 // Has checks for existence of a key
-func (d *BoltDatabase) Has(bucket []byte, key string) (ret bool) {
-	d.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		ret = len(b.Get([]byte(key))) > 0
-		return nil
-	})
-
-	return
+func (d *BoltDatabase) Has(bucket []byte, key string) bool {
+	value, _ := d.GetBytes(bucket, key)
+	return len(value) > 0
 }
 
 // GetBytes ...
-func (d *BoltDatabase) GetBytes(bucket []byte, key string) (value []byte, err error) {
-	err = d.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		value = b.Get([]byte(key))
-		return nil
-	})
-
-	return
+func (d *BoltDatabase) GetBytes(bucket []byte, key string) ([]byte, error) {
+	if value, ok := d.queued(bucket, key); ok {
+		return value, nil
+	}
+	return d.store.Get(bucket, []byte(key))
 }
 
 // Get ...
@@ -390,12 +551,82 @@ func (d *BoltDatabase) GetObject(bucket []byte, key string, item interface{}) (e
 	return
 }
 
+// GetBytesMulti returns values of the keys, read in one transaction,
+// values of missing keys are nil
+func (d *BoltDatabase) GetBytesMulti(bucket []byte, keys []string) ([][]byte, error) {
+	bkeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		bkeys[i] = []byte(key)
+	}
+
+	values, err := d.store.GetMulti(bucket, bkeys)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if value, ok := d.queued(bucket, key); ok {
+			values[i] = value
+		}
+	}
+
+	return values, nil
+}
+
+// BatchGetObject reads objects of the keys in one transaction and unmarshals them concurrently.
+// Results should be a pointer to a slice, which is resized to the number of keys,
+// elements of missing or broken values are left empty.
+func (d *BoltDatabase) BatchGetObject(bucket []byte, keys []string, results interface{}) error {
+	defer perf.ScopeTimer()()
+
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.New("results should be a pointer to a slice")
+	}
+	slice = slice.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), len(keys), len(keys)))
+
+	values, err := d.GetBytesMulti(bucket, keys)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i, v := range values {
+		if len(v) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, v []byte) {
+			defer wg.Done()
+			if err := json.Unmarshal(v, slice.Index(i).Addr().Interface()); err != nil {
+				log.Warningf("Could not unmarshal object for key: '%s', in bucket '%s': %s", keys[i], bucket, err)
+			}
+		}(i, v)
+	}
+	wg.Wait()
+
+	return nil
+}
+
 // SetCachedBytes ...
 func (d *BoltDatabase) SetCachedBytes(bucket []byte, seconds int, key string, value []byte) error {
-	return d.db.Update(func(tx *bolt.Tx) error {
-		value = append([]byte(strconv.Itoa(util.NowPlusSecondsInt(seconds))+"|"), value...)
-		return tx.Bucket(bucket).Put([]byte(key), value)
-	})
+	d.unqueue(bucket, key)
+	item := cacheItem(bucket, seconds, key, value)
+	d.indexExpiry(bucket, key, item)
+	return d.store.Set(bucket, []byte(key), item)
+}
+
+// cacheItem prepends value with expiration time, capped by the bucket policy
+func cacheItem(bucket []byte, seconds int, key string, value []byte) []byte {
+	seconds = int(CapExpiration(bucket, key, time.Duration(seconds)*time.Second) / time.Second)
+	separator := "|"
+	if compressed, ok := compressValue(value); ok {
+		value = compressed
+		separator = string(CompressedMarker)
+	}
+
+	return append([]byte(strconv.Itoa(util.NowPlusSecondsInt(seconds))+separator), value...)
 }
 
 // SetCached ...
@@ -421,9 +652,9 @@ func (d *BoltDatabase) SetCachedObject(bucket []byte, seconds int, key string, i
 
 // SetBytes ...
 func (d *BoltDatabase) SetBytes(bucket []byte, key string, value []byte) error {
-	return d.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(bucket).Put([]byte(key), value)
-	})
+	d.unqueue(bucket, key)
+	d.indexExpiry(bucket, key, value)
+	return d.store.Set(bucket, []byte(key), value)
 }
 
 // Set ...
@@ -444,8 +675,10 @@ func (d *BoltDatabase) SetObject(bucket []byte, key string, item interface{}) er
 
 // BatchSet ...
 func (d *BoltDatabase) BatchSet(bucket []byte, objects map[string]string) error {
-	return d.db.Batch(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
+	for key := range objects {
+		d.unqueue(bucket, key)
+	}
+	return d.store.Batch(bucket, func(b StoreBucket) error {
 		for key, value := range objects {
 			if err := b.Put([]byte(key), []byte(value)); err != nil {
 				return err
@@ -457,8 +690,10 @@ func (d *BoltDatabase) BatchSet(bucket []byte, objects map[string]string) error
 
 // BatchSetBytes ...
 func (d *BoltDatabase) BatchSetBytes(bucket []byte, objects map[string][]byte) error {
-	return d.db.Batch(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
+	for key := range objects {
+		d.unqueue(bucket, key)
+	}
+	return d.store.Batch(bucket, func(b StoreBucket) error {
 		for key, value := range objects {
 			if err := b.Put([]byte(key), value); err != nil {
 				return err
@@ -484,15 +719,16 @@ func (d *BoltDatabase) BatchSetObject(bucket []byte, objects map[string]interfac
 
 // Delete ...
 func (d *BoltDatabase) Delete(bucket []byte, key string) error {
-	return d.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(bucket).Delete([]byte(key))
-	})
+	d.unqueue(bucket, key)
+	return d.store.Delete(bucket, []byte(key))
 }
 
 // BatchDelete ...
 func (d *BoltDatabase) BatchDelete(bucket []byte, keys []string) error {
-	return d.db.Batch(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
+	for _, key := range keys {
+		d.unqueue(bucket, key)
+	}
+	return d.store.Batch(bucket, func(b StoreBucket) error {
 		for _, key := range keys {
 			b.Delete([]byte(key))
 		}
@@ -511,7 +747,5 @@ func (d *BoltDatabase) AsWriter(bucket []byte, key string) *DBWriter {
 
 // Write ...
 func (w *DBWriter) Write(b []byte) (n int, err error) {
-	return len(b), w.database.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(w.bucket).Put(w.key, b)
-	})
+	return len(b), w.database.SetBlob(w.bucket, string(w.key), b)
 }
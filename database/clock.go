@@ -0,0 +1,58 @@
+package database
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+
+	"github.com/projectx13/projectx/util"
+)
+
+const (
+	clockLastSeenKey = "clock_last_seen"
+	// clockSaveInterval is how often current time is saved, to be restored after reboot
+	clockSaveInterval = 10 * time.Minute
+	// clockVerifyTimeout is how long cache cleanup waits for clock to be verified at startup
+	clockVerifyTimeout = time.Minute
+)
+
+// RestoreClock makes sure that current time is not earlier than the time, saved on the previous run,
+// so cache items do not stay forever on devices, which clock is reset on reboot
+func RestoreClock() {
+	var lastSeen int64
+	if err := stormDatabase.db.Get(metaBucket, clockLastSeenKey, &lastSeen); err != nil {
+		if err != storm.ErrNotFound {
+			log.Warningf("Could not read last known time: %s", err)
+		}
+		return
+	}
+
+	util.EnsureClockAfter(time.Unix(lastSeen, 0))
+}
+
+// saveClock stores current time, it is never moved back, if clock is not verified yet
+func saveClock() {
+	if IsReadOnly() {
+		return
+	}
+
+	now := util.NowInt64()
+	if !util.IsClockVerified() {
+		var lastSeen int64
+		if err := stormDatabase.db.Get(metaBucket, clockLastSeenKey, &lastSeen); err == nil && lastSeen > now {
+			return
+		}
+	}
+
+	if err := stormDatabase.db.Set(metaBucket, clockLastSeenKey, now); err != nil {
+		log.Warningf("Could not save last known time: %s", err)
+	}
+}
+
+// waitClock delays cache cleanup until clock is verified, to not remove all items,
+// when clock is set to the future, gives up after clockVerifyTimeout
+func waitClock() {
+	if !util.WaitClockVerified(clockVerifyTimeout) {
+		log.Warningf("Could not verify system clock in %s, cleaning cache with system time", clockVerifyTimeout)
+	}
+}
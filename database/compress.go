@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	// CompressedMarker is a header byte of zstd-compressed cache values,
+	// uncompressed values never start with it, so old entries keep working
+	CompressedMarker = '~'
+
+	// Smaller values are not worth compressing
+	compressMinSize = 512
+)
+
+var errZstdUnavailable = errors.New("zstd is not available")
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		var err error
+		if zstdEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest)); err != nil {
+			log.Warningf("Could not create zstd encoder: %s", err)
+		}
+		if zstdDecoder, err = zstd.NewReader(nil); err != nil {
+			log.Warningf("Could not create zstd decoder: %s", err)
+		}
+	})
+}
+
+// compressValue compresses value if compression is enabled in the settings
+// and it makes the value smaller, second return value tells if it was compressed
+func compressValue(value []byte) ([]byte, bool) {
+	if !config.Get().CacheCompression || len(value) < compressMinSize {
+		return value, false
+	}
+
+	initZstd()
+	if zstdEncoder == nil {
+		return value, false
+	}
+
+	compressed := zstdEncoder.EncodeAll(value, make([]byte, 0, len(value)/2))
+	if len(compressed) >= len(value) {
+		return value, false
+	}
+	return compressed, true
+}
+
+func decompressValue(value []byte) ([]byte, error) {
+	initZstd()
+	if zstdDecoder == nil {
+		return nil, errZstdUnavailable
+	}
+	return zstdDecoder.DecodeAll(value, nil)
+}
+
+// EncodeCacheValue compresses value, prepending it with CompressedMarker,
+// value is returned as is, if compression is disabled or not worth it.
+// Caller should make sure uncompressed values never start with CompressedMarker.
+func EncodeCacheValue(value []byte) []byte {
+	if compressed, ok := compressValue(value); ok {
+		return append([]byte{CompressedMarker}, compressed...)
+	}
+	return value
+}
+
+// DecodeCacheValue reverts EncodeCacheValue, uncompressed values are returned as is,
+// so it works with values stored before compression was enabled
+func DecodeCacheValue(value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != CompressedMarker {
+		return value, nil
+	}
+	return decompressValue(value[1:])
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/projectx13/projectx/util"
+)
+
+const (
+	expiryIndexFlushInterval = 1 * time.Minute
+	// Full scan is still needed to index items, stored before the index existed,
+	// or lost on crash before index was flushed, and to apply changed TTL policies
+	fullCleanupInterval = 7 * 24 * 60 * 60
+	fullCleanupKey      = "cache.full_cleanup"
+)
+
+var (
+	// ExpiryIndexBucket keeps keys of cache items, ordered by expiration time,
+	// so that cleanup scans only expired items, instead of the whole bucket
+	ExpiryIndexBucket = []byte("ExpiryIndex")
+
+	expirySeparator = []byte{0}
+)
+
+// expiryIndex collects index entries in memory, to not add a transaction
+// to every cache write, and flushes them periodically
+type expiryIndex struct {
+	store Store
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	quit    chan struct{}
+	once    sync.Once
+}
+
+func newExpiryIndex(store Store) *expiryIndex {
+	x := &expiryIndex{
+		store:   store,
+		pending: map[string]struct{}{},
+		quit:    make(chan struct{}),
+	}
+
+	go x.flusher()
+	return x
+}
+
+// expiryIndexKey is zero-padded expiration time, so that lexical order
+// is chronological, followed by bucket and key of the item
+func expiryIndexKey(expire int64, bucket []byte, key []byte) []byte {
+	ret := []byte(fmt.Sprintf("%010d", expire))
+	ret = append(ret, expirySeparator...)
+	ret = append(ret, bucket...)
+	ret = append(ret, expirySeparator...)
+	return append(ret, key...)
+}
+
+func parseExpiryIndexKey(k []byte) (expire int64, bucket []byte, key []byte, ok bool) {
+	parts := bytes.SplitN(k, expirySeparator, 3)
+	if len(parts) != 3 {
+		return 0, nil, nil, false
+	}
+
+	expire, err := strconv.ParseInt(string(parts[0]), 10, 64)
+	if err != nil {
+		return 0, nil, nil, false
+	}
+	return expire, parts[1], parts[2], true
+}
+
+func isCacheBucket(bucket []byte) bool {
+	for _, b := range CacheBuckets {
+		if bytes.Equal(b, bucket) {
+			return true
+		}
+	}
+	return false
+}
+
+func (x *expiryIndex) add(bucket []byte, key []byte, value []byte) {
+	if !isCacheBucket(bucket) {
+		return
+	}
+	expire := ParseCacheExpiration(value)
+	if expire <= 0 {
+		return
+	}
+
+	x.mu.Lock()
+	x.pending[string(expiryIndexKey(expire, bucket, key))] = struct{}{}
+	x.mu.Unlock()
+}
+
+func (x *expiryIndex) flusher() {
+	ticker := time.NewTicker(expiryIndexFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := x.Flush(); err != nil {
+				log.Warningf("Could not flush expiry index: %s", err)
+			}
+		case <-x.quit:
+			return
+		}
+	}
+}
+
+// Flush writes pending index entries
+func (x *expiryIndex) Flush() error {
+	if IsReadOnly() {
+		return nil
+	}
+
+	x.mu.Lock()
+	pending := x.pending
+	x.pending = map[string]struct{}{}
+	x.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return x.store.Batch(ExpiryIndexBucket, func(b StoreBucket) error {
+		for k := range pending {
+			if err := b.Put([]byte(k), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close stops the ticker and flushes pending entries
+func (x *expiryIndex) Close() error {
+	x.once.Do(func() {
+		close(x.quit)
+	})
+	return x.Flush()
+}
+
+// indexExpiry adds cache item to expiry index
+func (d *BoltDatabase) indexExpiry(bucket []byte, key string, value []byte) {
+	if d.expiry != nil {
+		d.expiry.add(bucket, []byte(key), value)
+	}
+}
+
+// CleanupBucket removes expired items from the bucket, using expiry index,
+// so only expired items are visited. Items, overwritten with later expiration,
+// are kept, only their outdated index entries are removed.
+func (d *BoltDatabase) CleanupBucket(bucket []byte) {
+	defer perf.ScopeTimer()()
+
+	if d.expiry == nil || !d.BucketExists(bucket) || !d.BucketExists(ExpiryIndexBucket) {
+		d.fullCleanupBucket(bucket)
+		return
+	}
+	if err := d.expiry.Flush(); err != nil {
+		log.Warningf("Could not flush expiry index: %s", err)
+	}
+
+	now := util.NowInt64()
+	entries := [][]byte{}
+	err := d.store.Seek(ExpiryIndexBucket, nil, func(k []byte, _ []byte) error {
+		expire, b, _, ok := parseExpiryIndexKey(k)
+		if ok && expire >= now {
+			return errStopIteration
+		}
+		if !ok || bytes.Equal(b, bucket) {
+			entries = append(entries, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil && err != errStopIteration {
+		log.Warningf("Could not read expiry index: %s", err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	removed := 0
+	for len(entries) > 0 {
+		size := cleanupChunkSize
+		if size > len(entries) {
+			size = len(entries)
+		}
+		chunk := entries[:size]
+		entries = entries[size:]
+
+		err := d.store.Update(func(tx StoreTx) error {
+			index := tx.Bucket(ExpiryIndexBucket)
+			items := tx.Bucket(bucket)
+			if index == nil || items == nil {
+				return errBucketNotFound
+			}
+
+			for _, k := range chunk {
+				if _, _, key, ok := parseExpiryIndexKey(k); ok {
+					if v := items.Get(key); v != nil && ParseCacheExpiration(v) < now {
+						if err := items.Delete(key); err != nil {
+							return err
+						}
+						removed++
+					}
+				}
+				if err := index.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Warningf("Could not cleanup cache bucket %s: %s", bucket, err)
+			return
+		}
+	}
+
+	if removed > 0 {
+		log.Debugf("Removed %d expired items from cache bucket %s", removed, bucket)
+	}
+}
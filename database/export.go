@@ -0,0 +1,229 @@
+package database
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/asdine/storm"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const importChunkSize = 1000
+
+// ExportItem is a single line of the database export,
+// Bucket holds the path of nested bucket names, starting from the root bucket
+type ExportItem struct {
+	Bucket []string `json:"bucket"`
+	Key    []byte   `json:"key"`
+	Value  []byte   `json:"value"`
+}
+
+// Export writes all buckets and keys as newline-delimited JSON
+func (d *BoltDatabase) Export(w io.Writer) error {
+	defer perf.ScopeTimer()()
+
+	buckets, err := d.store.Buckets()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, bucket := range buckets {
+		path := []string{string(bucket)}
+		err := d.store.Seek(bucket, nil, func(k []byte, v []byte) error {
+			return enc.Encode(ExportItem{Bucket: path, Key: k, Value: v})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import reads newline-delimited JSON, created by Export, and stores all the keys,
+// existing keys are overwritten. Returns number of imported keys.
+func (d *BoltDatabase) Import(r io.Reader) (int, error) {
+	defer perf.ScopeTimer()()
+
+	count := 0
+	chunk := map[string][]byte{}
+	var bucket []byte
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		err := d.store.Batch(bucket, func(b StoreBucket) error {
+			for k, v := range chunk {
+				if err := b.Put([]byte(k), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		count += len(chunk)
+		chunk = map[string][]byte{}
+		return err
+	}
+
+	err := decodeExport(r, func(item *ExportItem) error {
+		if len(item.Bucket) != 1 {
+			return fmt.Errorf("Nested buckets are not supported in %s", d.fileName)
+		}
+
+		if bucket == nil || string(bucket) != item.Bucket[0] || len(chunk) >= importChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			bucket = []byte(item.Bucket[0])
+			if err := d.store.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+
+		chunk[string(item.Key)] = item.Value
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	err = flush()
+	return count, err
+}
+
+// Export writes all buckets, including storm's nested index buckets,
+// and keys as newline-delimited JSON
+func (d *StormDatabase) Export(w io.Writer) error {
+	defer perf.ScopeTimer()()
+
+	enc := json.NewEncoder(w)
+	var walk func(b *bolt.Bucket, path []string) error
+	walk = func(b *bolt.Bucket, path []string) error {
+		return b.ForEach(func(k []byte, v []byte) error {
+			if v == nil {
+				return walk(b.Bucket(k), append(path[:len(path):len(path)], string(k)))
+			}
+			return enc.Encode(ExportItem{Bucket: path, Key: k, Value: v})
+		})
+	}
+
+	return d.db.Bolt.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return walk(b, []string{string(name)})
+		})
+	})
+}
+
+// stormTypes are structs, which storm keeps in buckets, named after them.
+// They are imported with Save, so storm updates their indexes for the merged records.
+var stormTypes = map[string]func() interface{}{
+	"BTItem":                func() interface{} { return &BTItem{} },
+	"LibraryItem":           func() interface{} { return &LibraryItem{} },
+	"LibraryTombstone":      func() interface{} { return &LibraryTombstone{} },
+	"LibraryFailure":        func() interface{} { return &LibraryFailure{} },
+	"FailedSearch":          func() interface{} { return &FailedSearch{} },
+	"EpisodeOrdering":       func() interface{} { return &EpisodeOrdering{} },
+	"EpisodeFile":           func() interface{} { return &EpisodeFile{} },
+	"DigestMessage":         func() interface{} { return &DigestMessage{} },
+	"Artwork":               func() interface{} { return &Artwork{} },
+	"ExternalIDs":           func() interface{} { return &ExternalIDs{} },
+	"QueryHistory":          func() interface{} { return &QueryHistory{} },
+	"TorrentAssignMetadata": func() interface{} { return &TorrentAssignMetadata{} },
+	"TorrentAssignItem":     func() interface{} { return &TorrentAssignItem{} },
+	"TorrentHistory":        func() interface{} { return &TorrentHistory{} },
+}
+
+// Import reads newline-delimited JSON, created by Export, and stores all the records,
+// existing records are overwritten. Records of storm structs are saved with storm,
+// and their exported index buckets are skipped, as merging raw index entries
+// into existing database would point them to missing or duplicate records.
+// Returns number of imported keys.
+func (d *StormDatabase) Import(r io.Reader) (int, error) {
+	defer perf.ScopeTimer()()
+
+	count := 0
+	chunk := []*ExportItem{}
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		tx, err := d.db.Begin(true)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		for _, item := range chunk {
+			if newRecord, ok := stormTypes[item.Bucket[0]]; ok {
+				record := newRecord()
+				if err := d.db.Codec().Unmarshal(item.Value, record); err != nil {
+					return fmt.Errorf("Could not decode %s record %s: %s", item.Bucket[0], item.Key, err)
+				}
+				if err := tx.Save(record); err == storm.ErrAlreadyExists {
+					// Unique index is already taken by a local record, which is kept
+					log.Warningf("Skipping %s record %s, conflicting with existing one", item.Bucket[0], item.Key)
+					continue
+				} else if err != nil {
+					return err
+				}
+				count++
+				continue
+			}
+
+			if err := tx.Set(item.Bucket[0], item.Key, item.Value); err != nil {
+				return err
+			}
+			count++
+		}
+
+		chunk = []*ExportItem{}
+		return tx.Commit()
+	}
+
+	err := decodeExport(r, func(item *ExportItem) error {
+		if len(item.Bucket) == 0 {
+			return fmt.Errorf("Missing bucket for key %s", item.Key)
+		} else if len(item.Bucket) > 1 {
+			// Nested buckets are storm indexes and metadata, they are rebuilt by Save
+			return nil
+		}
+
+		chunk = append(chunk, item)
+		if len(chunk) >= importChunkSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	err = flush()
+	return count, err
+}
+
+// decodeExport reads export lines one by one, so that whole export is never kept in memory
+func decodeExport(r io.Reader, callback func(item *ExportItem) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; ; line++ {
+		item := &ExportItem{}
+		if err := dec.Decode(item); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("Could not decode item %d: %s", line, err)
+		}
+
+		if err := callback(item); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// checkFile runs consistency check on the database file,
+// opening corrupted file can panic, so panics are reported as errors.
+// Both bolt engines are using the same file format, so bbolt is used for them.
+func checkFile(path string) (err error) {
+	if isSQLiteFile(path) {
+		return checkSQLiteFile(path)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  15 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		for errCheck := range tx.Check() {
+			// Drain the channel, so that checker goroutine can finish
+			if err == nil {
+				err = errCheck
+			}
+		}
+		return err
+	})
+}
+
+// CheckIntegrity verifies database file before it is opened. If corruption
+// is detected, damaged file is moved aside with a timestamp and the newest valid
+// backup generation is restored, otherwise database starts from scratch.
+// User is informed with a dialog about the result.
+func CheckIntegrity(databasePath string, backupPath string) {
+	defer perf.ScopeTimer()()
+
+	if IsReadOnly() || isLocked(databasePath) {
+		setReadOnly(databasePath)
+		return
+	}
+
+	applyPendingRestore(databasePath)
+
+	if _, err := os.Stat(databasePath); err != nil {
+		return
+	}
+
+	err := checkFile(databasePath)
+	if err == nil {
+		return
+	}
+	log.Errorf("Database at %s is corrupted: %s", databasePath, err)
+
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", databasePath, time.Now().Format(backupTimeFormat))
+	if err := os.Rename(databasePath, corruptPath); err != nil {
+		log.Errorf("Could not move corrupted database to %s: %s", corruptPath, err)
+		return
+	}
+	log.Warningf("Corrupted database moved to %s", corruptPath)
+
+	fileName := filepath.Base(databasePath)
+	message := fmt.Sprintf("LOCALIZE[30708];;%s", fileName)
+	for _, generation := range BackupGenerations(backupPath) {
+		if err := checkFile(generation); err != nil {
+			log.Warningf("Backup at %s is corrupted too: %s", generation, err)
+			continue
+		}
+
+		if err := RestoreBackup(databasePath, generation); err != nil {
+			log.Warningf("Could not restore backup %s, trying older one: %s", generation, err)
+			continue
+		}
+		message = fmt.Sprintf("LOCALIZE[30707];;%s", fileName)
+		break
+	}
+
+	// Do not block the startup while dialog is shown
+	go xbmc.Dialog("projectx", message)
+}
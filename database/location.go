@@ -0,0 +1,60 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/util"
+)
+
+// databaseDir returns folder for the database file, configured in settings,
+// cache and library databases can be kept outside of the profile folder
+func databaseDir(conf *config.Configuration, fileName string) string {
+	dir := conf.LibraryDBPath
+	if fileName == cacheFileName || fileName == sqliteName(cacheFileName) {
+		dir = conf.CachePath
+	}
+
+	if dir == "" {
+		return conf.Info.Profile
+	}
+	return dir
+}
+
+// relocateDatabase moves database and its backups from the profile folder
+// into configured location, only if there is no database there yet
+func relocateDatabase(conf *config.Configuration, fileName string, backupFileName string) {
+	dir := databaseDir(conf, fileName)
+	if dir == conf.Info.Profile || IsReadOnly() {
+		return
+	}
+
+	from := filepath.Join(conf.Info.Profile, fileName)
+	to := filepath.Join(dir, fileName)
+	if _, err := os.Stat(from); err != nil {
+		return
+	} else if _, err := os.Stat(to); err == nil {
+		log.Warningf("Database %s already exists, keeping %s in profile folder", to, from)
+		return
+	}
+
+	log.Infof("Moving database from %s to %s", from, to)
+	if _, err := util.Move(from, to); err != nil {
+		log.Errorf("Could not move database to %s: %s", to, err)
+		os.Remove(to)
+		return
+	}
+
+	backupPath := filepath.Join(conf.Info.Profile, backupFileName)
+	for _, path := range BackupGenerations(backupPath) {
+		target := filepath.Join(dir, filepath.Base(path))
+		if _, err := util.Move(path, target); err != nil {
+			log.Warningf("Could not move backup %s: %s", path, err)
+			continue
+		}
+		if _, err := os.Stat(path + checksumSuffix); err == nil {
+			util.Move(path+checksumSuffix, target+checksumSuffix)
+		}
+	}
+}
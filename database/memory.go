@@ -0,0 +1,464 @@
+package database
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const memoryFlushInterval = 30 * time.Second
+
+// memoryStore keeps recently used items of underlying Store in LRU list.
+// Writes, made through write-back view, are kept only in memory
+// and flushed to the disk periodically, or when item is evicted.
+type memoryStore struct {
+	Store
+
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+	quit  chan struct{}
+	once  sync.Once
+	// version is the last version of items, it is never reused, even for evicted items
+	version uint64
+	// writes is a number of items and transactions, being written to the underlying Store,
+	// and changes is a number of finished writes, values, read from the Store, are kept
+	// in memory, only if nothing was written meanwhile, otherwise they could be outdated
+	writes  int
+	changes uint64
+}
+
+type memoryItem struct {
+	id      string
+	bucket  []byte
+	key     []byte
+	value   []byte
+	dirty   bool
+	deleted bool
+	// version is changed on every put, so Flush knows, whether item was changed during writing
+	version uint64
+}
+
+// memoryView is a Store, sharing LRU list with other views of the same memoryStore,
+// so that write-through and write-back users see the same data.
+type memoryView struct {
+	*memoryStore
+	writeBack bool
+}
+
+type memoryTx struct {
+	StoreTx
+	m *memoryStore
+}
+
+type memoryBucket struct {
+	StoreBucket
+	m      *memoryStore
+	bucket []byte
+}
+
+func newMemoryStore(store Store, size int) *memoryStore {
+	m := &memoryStore{
+		Store: store,
+		size:  size,
+		items: map[string]*list.Element{},
+		order: list.New(),
+		quit:  make(chan struct{}),
+	}
+
+	go m.flusher()
+	return m
+}
+
+func memoryID(bucket []byte, key []byte) string {
+	return string(bucket) + "\x00" + string(key)
+}
+
+func (m *memoryStore) flusher() {
+	ticker := time.NewTicker(memoryFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Flush(); err != nil {
+				log.Warningf("Could not flush memory cache: %s", err)
+			}
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// put saves item in the list and returns dirty items, evicted from the list,
+// they are counted as a running write, until they are saved with write
+func (m *memoryStore) put(bucket []byte, key []byte, value []byte, dirty bool, deleted bool) (evicted []*memoryItem) {
+	id := memoryID(bucket, key)
+	if el, ok := m.items[id]; ok {
+		item := el.Value.(*memoryItem)
+		item.value = value
+		item.dirty = dirty
+		item.deleted = deleted
+		item.version = m.nextVersion()
+		m.order.MoveToFront(el)
+	} else {
+		m.items[id] = m.order.PushFront(&memoryItem{
+			id:      id,
+			bucket:  bucket,
+			key:     append([]byte{}, key...),
+			value:   value,
+			dirty:   dirty,
+			deleted: deleted,
+			version: m.nextVersion(),
+		})
+	}
+
+	for m.order.Len() > m.size {
+		el := m.order.Back()
+		item := el.Value.(*memoryItem)
+		m.order.Remove(el)
+		delete(m.items, item.id)
+		if item.dirty {
+			evicted = append(evicted, item)
+		}
+	}
+	m.writes += len(evicted)
+
+	return
+}
+
+// writing counts a write to the underlying Store, returned function is called, when it is done
+func (m *memoryStore) writing() func() {
+	m.mu.Lock()
+	m.writes++
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		m.writes--
+		m.changes++
+		m.mu.Unlock()
+	}
+}
+
+// unchanged checks, that nothing was written since changes value was taken,
+// it is called with the lock held
+func (m *memoryStore) unchanged(changes uint64) bool {
+	return m.writes == 0 && m.changes == changes
+}
+
+func (m *memoryStore) nextVersion() uint64 {
+	m.version++
+	return m.version
+}
+
+func (m *memoryStore) forget(bucket []byte, key []byte) {
+	id := memoryID(bucket, key)
+	if el, ok := m.items[id]; ok {
+		m.order.Remove(el)
+		delete(m.items, id)
+	}
+}
+
+// write saves items to the underlying Store, items are counted as a running write by the caller
+func (m *memoryStore) write(items []*memoryItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	defer func() {
+		m.mu.Lock()
+		m.writes -= len(items)
+		m.changes++
+		m.mu.Unlock()
+	}()
+
+	byBucket := map[string][]*memoryItem{}
+	for _, item := range items {
+		byBucket[string(item.bucket)] = append(byBucket[string(item.bucket)], item)
+	}
+
+	for bucket, bucketItems := range byBucket {
+		err := m.Store.Batch([]byte(bucket), func(b StoreBucket) error {
+			for _, item := range bucketItems {
+				if item.deleted {
+					b.Delete(item.key)
+				} else if err := b.Put(item.key, item.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush writes all pending changes to the underlying Store
+func (m *memoryStore) Flush() error {
+	if IsReadOnly() {
+		return nil
+	}
+
+	m.mu.Lock()
+	pending := []*memoryItem{}
+	for el := m.order.Front(); el != nil; el = el.Next() {
+		if item := el.Value.(*memoryItem); item.dirty {
+			pending = append(pending, &memoryItem{id: item.id, bucket: item.bucket, key: item.key, value: item.value, deleted: item.deleted, version: item.version})
+		}
+	}
+	m.writes += len(pending)
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := m.write(pending); err != nil {
+		// Items stay dirty, so they are written with the next flush
+		return err
+	}
+
+	// Items, changed during writing, stay dirty, as their new values are not written yet
+	m.mu.Lock()
+	for _, written := range pending {
+		if el, ok := m.items[written.id]; ok {
+			if item := el.Value.(*memoryItem); item.version == written.version {
+				item.dirty = false
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (v *memoryView) Get(bucket []byte, key []byte) ([]byte, error) {
+	v.mu.Lock()
+	changes := v.changes
+	if el, ok := v.items[memoryID(bucket, key)]; ok {
+		v.order.MoveToFront(el)
+		// Item is changed in place by put, so value is copied before unlocking
+		var value []byte
+		if item := el.Value.(*memoryItem); !item.deleted && item.value != nil {
+			value = append([]byte{}, item.value...)
+		}
+		v.mu.Unlock()
+
+		return value, nil
+	}
+	v.mu.Unlock()
+
+	value, err := v.Store.Get(bucket, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	v.mu.Lock()
+	var evicted []*memoryItem
+	if _, ok := v.items[memoryID(bucket, key)]; !ok && v.unchanged(changes) {
+		evicted = v.put(bucket, key, value, false, false)
+	}
+	v.mu.Unlock()
+
+	return value, v.write(evicted)
+}
+
+// GetMulti returns values, kept in memory, and reads the rest
+// from the underlying Store in one transaction
+func (v *memoryView) GetMulti(bucket []byte, keys [][]byte) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	missing := []int{}
+
+	v.mu.Lock()
+	changes := v.changes
+	for i, key := range keys {
+		if el, ok := v.items[memoryID(bucket, key)]; ok {
+			v.order.MoveToFront(el)
+			if item := el.Value.(*memoryItem); !item.deleted && item.value != nil {
+				values[i] = append([]byte{}, item.value...)
+			}
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	v.mu.Unlock()
+
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	missingKeys := make([][]byte, len(missing))
+	for i, idx := range missing {
+		missingKeys[i] = keys[idx]
+	}
+	read, err := v.Store.GetMulti(bucket, missingKeys)
+	if err != nil {
+		return values, err
+	}
+
+	var evicted []*memoryItem
+	v.mu.Lock()
+	unchanged := v.unchanged(changes)
+	for i, idx := range missing {
+		if read[i] == nil {
+			continue
+		}
+
+		values[idx] = read[i]
+		if _, ok := v.items[memoryID(bucket, keys[idx])]; !ok && unchanged {
+			evicted = append(evicted, v.put(bucket, keys[idx], read[i], false, false)...)
+		}
+	}
+	v.mu.Unlock()
+
+	return values, v.write(evicted)
+}
+
+func (v *memoryView) Set(bucket []byte, key []byte, value []byte) error {
+	value = append([]byte{}, value...)
+
+	if !v.writeBack {
+		done := v.writing()
+		err := v.Store.Set(bucket, key, value)
+		done()
+		if err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	evicted := v.put(bucket, key, value, v.writeBack, false)
+	v.mu.Unlock()
+
+	return v.write(evicted)
+}
+
+func (v *memoryView) Delete(bucket []byte, key []byte) error {
+	if !v.writeBack {
+		// Write is counted before the item is dropped, so that it is not read back from the Store
+		defer v.writing()()
+
+		v.mu.Lock()
+		v.forget(bucket, key)
+		v.mu.Unlock()
+
+		return v.Store.Delete(bucket, key)
+	}
+
+	v.mu.Lock()
+	evicted := v.put(bucket, key, nil, true, true)
+	v.mu.Unlock()
+
+	return v.write(evicted)
+}
+
+func (v *memoryView) Seek(bucket []byte, prefix []byte, callback callBackWithError) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	return v.Store.Seek(bucket, prefix, callback)
+}
+
+func (v *memoryView) SeekReverse(bucket []byte, prefix []byte, callback callBackWithError) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	return v.Store.SeekReverse(bucket, prefix, callback)
+}
+
+func (v *memoryView) Batch(bucket []byte, fn func(b StoreBucket) error) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	defer v.writing()()
+	return v.Store.Batch(bucket, func(b StoreBucket) error {
+		return fn(&memoryBucket{StoreBucket: b, m: v.memoryStore, bucket: bucket})
+	})
+}
+
+func (v *memoryView) Update(fn func(tx StoreTx) error) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	defer v.writing()()
+	return v.Store.Update(func(tx StoreTx) error {
+		return fn(&memoryTx{StoreTx: tx, m: v.memoryStore})
+	})
+}
+
+func (v *memoryView) DeleteBucket(bucket []byte) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	for el := v.order.Front(); el != nil; {
+		next := el.Next()
+		if item := el.Value.(*memoryItem); string(item.bucket) == string(bucket) {
+			v.order.Remove(el)
+			delete(v.items, item.id)
+		}
+		el = next
+	}
+	v.mu.Unlock()
+
+	defer v.writing()()
+	return v.Store.DeleteBucket(bucket)
+}
+
+func (v *memoryView) Backup(path string) error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	return v.Store.Backup(path)
+}
+
+func (v *memoryView) Stats() (*DatabaseStats, error) {
+	if err := v.Flush(); err != nil {
+		return nil, err
+	}
+	return v.Store.Stats()
+}
+
+func (v *memoryView) Compact() error {
+	if err := v.Flush(); err != nil {
+		return err
+	}
+	return v.Store.Compact()
+}
+
+func (v *memoryView) Close() error {
+	v.once.Do(func() {
+		close(v.quit)
+	})
+	if err := v.Flush(); err != nil {
+		log.Warningf("Could not flush memory cache: %s", err)
+	}
+	return v.Store.Close()
+}
+
+func (t *memoryTx) Bucket(name []byte) StoreBucket {
+	if b := t.StoreTx.Bucket(name); b != nil {
+		return &memoryBucket{StoreBucket: b, m: t.m, bucket: name}
+	}
+	return nil
+}
+
+func (b *memoryBucket) Put(key []byte, value []byte) error {
+	b.m.mu.Lock()
+	b.m.forget(b.bucket, key)
+	b.m.mu.Unlock()
+
+	return b.StoreBucket.Put(key, value)
+}
+
+func (b *memoryBucket) Delete(key []byte) error {
+	b.m.mu.Lock()
+	b.m.forget(b.bucket, key)
+	b.m.mu.Unlock()
+
+	return b.StoreBucket.Delete(key)
+}
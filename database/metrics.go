@@ -0,0 +1,234 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	durationBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+	batchBuckets    = []float64{1, 10, 50, 100, 500, 1000, 5000}
+
+	metrics = &storeMetrics{
+		operations: map[metricLabels]uint64{},
+		errors:     map[metricLabels]uint64{},
+		durations:  map[metricLabels]*histogram{},
+		batches:    map[metricLabels]*histogram{},
+	}
+)
+
+type metricLabels struct {
+	op     string
+	bucket string
+}
+
+func (l metricLabels) String() string {
+	return fmt.Sprintf(`op="%s",bucket="%s"`, l.op, strings.Replace(l.bucket, `"`, `\"`, -1))
+}
+
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// storeMetrics collects usage of database operations, to be scraped by Prometheus
+type storeMetrics struct {
+	mu         sync.Mutex
+	operations map[metricLabels]uint64
+	errors     map[metricLabels]uint64
+	durations  map[metricLabels]*histogram
+	batches    map[metricLabels]*histogram
+}
+
+func (m *storeMetrics) observe(op string, bucket []byte, started time.Time, err error) {
+	labels := metricLabels{op: op, bucket: string(bucket)}
+	elapsed := time.Since(started).Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.operations[labels]++
+	if err != nil && err != errStopIteration {
+		m.errors[labels]++
+	}
+
+	h, ok := m.durations[labels]
+	if !ok {
+		h = newHistogram(durationBuckets)
+		m.durations[labels] = h
+	}
+	h.observe(elapsed)
+}
+
+func (m *storeMetrics) observeBatch(op string, bucket []byte, size int) {
+	labels := metricLabels{op: op, bucket: string(bucket)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.batches[labels]
+	if !ok {
+		h = newHistogram(batchBuckets)
+		m.batches[labels] = h
+	}
+	h.observe(float64(size))
+}
+
+func sortedLabels(m interface{}) []metricLabels {
+	ret := []metricLabels{}
+	switch t := m.(type) {
+	case map[metricLabels]uint64:
+		for l := range t {
+			ret = append(ret, l)
+		}
+	case map[metricLabels]*histogram:
+		for l := range t {
+			ret = append(ret, l)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].op != ret[j].op {
+			return ret[i].op < ret[j].op
+		}
+		return ret[i].bucket < ret[j].bucket
+	})
+	return ret
+}
+
+func writeCounter(w io.Writer, name string, help string, values map[metricLabels]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, l := range sortedLabels(values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, l, values[l])
+	}
+}
+
+func writeHistogram(w io.Writer, name string, help string, values map[metricLabels]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, l := range sortedLabels(values) {
+		h := values[l]
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", name, l, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, l, h.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l, h.count)
+	}
+}
+
+// MetricsHandler exposes database metrics in Prometheus text format
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+
+		writeCounter(w, "projectx_db_operations_total", "Number of database operations.", metrics.operations)
+		writeCounter(w, "projectx_db_errors_total", "Number of failed database operations.", metrics.errors)
+		writeHistogram(w, "projectx_db_operation_duration_seconds", "Duration of database operations.", metrics.durations)
+		writeHistogram(w, "projectx_db_batch_size", "Number of keys in batch operations.", metrics.batches)
+	})
+}
+
+// metricsStore counts operations and measures their duration
+type metricsStore struct {
+	Store
+}
+
+func instrumentStore(store Store) Store {
+	return &metricsStore{Store: store}
+}
+
+type metricsBucket struct {
+	StoreBucket
+	size int
+}
+
+func (b *metricsBucket) Put(key []byte, value []byte) error {
+	b.size++
+	return b.StoreBucket.Put(key, value)
+}
+
+func (b *metricsBucket) Delete(key []byte) error {
+	b.size++
+	return b.StoreBucket.Delete(key)
+}
+
+func (s *metricsStore) Get(bucket []byte, key []byte) (value []byte, err error) {
+	defer func(started time.Time) { metrics.observe("get", bucket, started, err) }(time.Now())
+	return s.Store.Get(bucket, key)
+}
+
+func (s *metricsStore) GetMulti(bucket []byte, keys [][]byte) (values [][]byte, err error) {
+	defer func(started time.Time) { metrics.observe("get_multi", bucket, started, err) }(time.Now())
+	metrics.observeBatch("get_multi", bucket, len(keys))
+	return s.Store.GetMulti(bucket, keys)
+}
+
+func (s *metricsStore) Set(bucket []byte, key []byte, value []byte) (err error) {
+	defer func(started time.Time) { metrics.observe("set", bucket, started, err) }(time.Now())
+	return s.Store.Set(bucket, key, value)
+}
+
+func (s *metricsStore) Delete(bucket []byte, key []byte) (err error) {
+	defer func(started time.Time) { metrics.observe("delete", bucket, started, err) }(time.Now())
+	return s.Store.Delete(bucket, key)
+}
+
+func (s *metricsStore) Seek(bucket []byte, prefix []byte, callback callBackWithError) (err error) {
+	defer func(started time.Time) { metrics.observe("seek", bucket, started, err) }(time.Now())
+	return s.Store.Seek(bucket, prefix, callback)
+}
+
+func (s *metricsStore) SeekReverse(bucket []byte, prefix []byte, callback callBackWithError) (err error) {
+	defer func(started time.Time) { metrics.observe("seek", bucket, started, err) }(time.Now())
+	return s.Store.SeekReverse(bucket, prefix, callback)
+}
+
+func (s *metricsStore) Batch(bucket []byte, fn func(b StoreBucket) error) (err error) {
+	defer func(started time.Time) { metrics.observe("batch", bucket, started, err) }(time.Now())
+
+	size := 0
+	err = s.Store.Batch(bucket, func(b StoreBucket) error {
+		mb := &metricsBucket{StoreBucket: b}
+		defer func() { size = mb.size }()
+		return fn(mb)
+	})
+	metrics.observeBatch("batch", bucket, size)
+	return
+}
+
+func (s *metricsStore) Update(fn func(tx StoreTx) error) (err error) {
+	defer func(started time.Time) { metrics.observe("update", nil, started, err) }(time.Now())
+	return s.Store.Update(fn)
+}
+
+func (s *metricsStore) Compact() (err error) {
+	defer func(started time.Time) { metrics.observe("compact", nil, started, err) }(time.Now())
+	return s.Store.Compact()
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/asdine/storm"
+)
+
+const (
+	metaBucket       = "Meta"
+	schemaVersionKey = "schema_version"
+)
+
+// Migration changes stored data from previous schema version,
+// like renaming buckets or converting value formats
+type Migration func() error
+
+var migrations = map[int]Migration{}
+
+// RegisterMigration adds migration to be run at startup, migrations are run
+// in order of versions, and only once for each profile
+func RegisterMigration(version int, fn Migration) {
+	if version <= 0 {
+		panic(fmt.Sprintf("migration version should be positive, got %d", version))
+	} else if _, ok := migrations[version]; ok {
+		panic(fmt.Sprintf("migration %d is already registered", version))
+	}
+
+	migrations[version] = fn
+}
+
+// GetSchemaVersion returns version of the last applied migration
+func GetSchemaVersion() (version int) {
+	if err := stormDatabase.db.Get(metaBucket, schemaVersionKey, &version); err != nil && err != storm.ErrNotFound {
+		log.Warningf("Could not read schema version: %s", err)
+	}
+	return
+}
+
+// RunMigrations applies registered migrations, newer than stored schema version,
+// version is saved after each migration, so failed migration is retried on next start
+func RunMigrations() error {
+	defer perf.ScopeTimer()()
+
+	if IsReadOnly() {
+		return nil
+	}
+
+	current := GetSchemaVersion()
+
+	versions := []int{}
+	for version := range migrations {
+		if version > current {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		log.Infof("Running database migration %d", version)
+		if err := migrations[version](); err != nil {
+			return fmt.Errorf("migration %d failed: %s", version, err)
+		}
+
+		if err := stormDatabase.db.Set(metaBucket, schemaVersionKey, version); err != nil {
+			return fmt.Errorf("could not save schema version %d: %s", version, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/projectx13/projectx/util"
+)
+
+// CachePolicy defines how items of a cache bucket are expired
+type CachePolicy struct {
+	// Interval between cleanups of the bucket
+	Interval time.Duration
+	// TTL caps lifetime of items, zero means only item's own expiration is used
+	TTL time.Duration
+	// PrefixTTL overrides TTL for keys with selected prefix
+	PrefixTTL map[string]time.Duration
+}
+
+const (
+	cleanupChunkSize = 500
+	cleanupJitter    = 0.1
+)
+
+var defaultCachePolicy = CachePolicy{
+	Interval: 24 * time.Hour,
+}
+
+// CachePolicies is a table of expiration policies for cache buckets
+var CachePolicies = map[string]CachePolicy{
+	string(CommonBucket): {
+		Interval: 6 * time.Hour,
+		PrefixTTL: map[string]time.Duration{
+			"page.":       1 * time.Hour,
+			"com.tmdb.":   7 * 24 * time.Hour,
+			"com.fanart.": 30 * 24 * time.Hour,
+		},
+	},
+}
+
+// GetCachePolicy returns expiration policy for the bucket
+func GetCachePolicy(bucket []byte) CachePolicy {
+	if p, ok := CachePolicies[string(bucket)]; ok {
+		return p
+	}
+	return defaultCachePolicy
+}
+
+// KeyTTL returns maximum lifetime for the key, zero means unlimited
+func (p CachePolicy) KeyTTL(key string) time.Duration {
+	ttl := p.TTL
+	matched := ""
+	for prefix, d := range p.PrefixTTL {
+		if len(prefix) > len(matched) && strings.HasPrefix(key, prefix) {
+			ttl = d
+			matched = prefix
+		}
+	}
+
+	return ttl
+}
+
+// CapExpiration limits expiration time of the key according to the bucket policy
+func CapExpiration(bucket []byte, key string, expires time.Duration) time.Duration {
+	if ttl := GetCachePolicy(bucket).KeyTTL(key); ttl > 0 && expires > ttl {
+		return ttl
+	}
+	return expires
+}
+
+// withJitter randomly changes duration by up to 10% in both directions
+func withJitter(d time.Duration) time.Duration {
+	delta := int64(float64(d) * cleanupJitter)
+	if delta <= 0 {
+		return d
+	}
+	return d - time.Duration(delta) + time.Duration(rand.Int63n(2*delta))
+}
+
+// expiryScheduler cleans each cache bucket on its own schedule
+func (d *BoltDatabase) expiryScheduler(bucket []byte, done <-chan struct{}) {
+	policy := GetCachePolicy(bucket)
+	timer := time.NewTimer(withJitter(policy.Interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			d.CleanupBucket(bucket)
+			timer.Reset(withJitter(policy.Interval))
+		case <-done:
+			return
+		}
+	}
+}
+
+// fullCleanupBucket removes expired items from the bucket, visiting every key.
+// Keys are collected in read transaction and removed in small chunks,
+// so that writers are not blocked for the whole cleanup.
+// Remaining items are added to expiry index.
+func (d *BoltDatabase) fullCleanupBucket(bucket []byte) {
+	defer perf.ScopeTimer()()
+
+	if !d.BucketExists(bucket) {
+		return
+	}
+
+	policy := GetCachePolicy(bucket)
+	now := util.NowInt64()
+
+	toRemove := []string{}
+	d.ForEach(bucket, func(key []byte, value []byte) error {
+		expire := ParseCacheExpiration(value)
+		if expire == 0 || expire < now {
+			toRemove = append(toRemove, string(key))
+		} else if ttl := policy.KeyTTL(string(key)); ttl > 0 && expire > now+int64(ttl/time.Second) {
+			toRemove = append(toRemove, string(key))
+		} else {
+			d.indexExpiry(bucket, string(key), value)
+		}
+
+		return nil
+	})
+
+	if len(toRemove) == 0 {
+		return
+	}
+
+	log.Debugf("Removing %d invalidated items from cache bucket %s", len(toRemove), bucket)
+	for len(toRemove) > 0 {
+		size := cleanupChunkSize
+		if size > len(toRemove) {
+			size = len(toRemove)
+		}
+
+		d.BatchDelete(bucket, toRemove[:size])
+		toRemove = toRemove[size:]
+	}
+}
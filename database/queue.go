@@ -0,0 +1,237 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const writeQueueFlushInterval = 15 * time.Second
+
+// writeQueue coalesces frequent writes of the same keys, like resume points
+// during playback, and writes only the latest values in one transaction per bucket,
+// on a ticker or when database is closed
+type writeQueue struct {
+	store Store
+
+	mu      sync.Mutex
+	pending map[string]*memoryItem
+	version uint64
+	quit    chan struct{}
+	once    sync.Once
+}
+
+func newWriteQueue(store Store) *writeQueue {
+	q := &writeQueue{
+		store:   store,
+		pending: map[string]*memoryItem{},
+		quit:    make(chan struct{}),
+	}
+
+	go q.flusher()
+	return q
+}
+
+func (q *writeQueue) flusher() {
+	ticker := time.NewTicker(writeQueueFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := q.Flush(); err != nil {
+				log.Warningf("Could not flush write queue: %s", err)
+			}
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+func (q *writeQueue) put(bucket []byte, key []byte, value []byte, deleted bool) {
+	id := memoryID(bucket, key)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.version++
+	q.pending[id] = &memoryItem{
+		id:      id,
+		bucket:  bucket,
+		key:     key,
+		value:   value,
+		deleted: deleted,
+		version: q.version,
+	}
+}
+
+func (q *writeQueue) forget(bucket []byte, key []byte) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.pending, memoryID(bucket, key))
+}
+
+// forgetPrefix drops pending items of the keys, starting with the prefix
+func (q *writeQueue) forgetPrefix(bucket []byte, prefix []byte) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for id, item := range q.pending {
+		if bytes.Equal(item.bucket, bucket) && bytes.HasPrefix(item.key, prefix) {
+			delete(q.pending, id)
+		}
+	}
+}
+
+// current checks, that item was not queued again or dropped from the queue,
+// since it was taken for writing
+func (q *writeQueue) current(item *memoryItem) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending, ok := q.pending[item.id]
+	return ok && pending.version == item.version
+}
+
+// get returns pending value of the key, ok is false if key is not queued
+func (q *writeQueue) get(bucket []byte, key []byte) (value []byte, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, ok := q.pending[memoryID(bucket, key)]
+	if !ok {
+		return nil, false
+	} else if item.deleted {
+		return nil, true
+	}
+	return item.value, true
+}
+
+// Flush writes pending items to the store. Items stay queued until they are written,
+// so they are still read from the queue during writing, and retried, when writing fails.
+// Items, changed or dropped by direct writes after they were taken, are skipped,
+// otherwise direct writes, done after dropping the item, would be overwritten with older values.
+func (q *writeQueue) Flush() error {
+	if IsReadOnly() {
+		return nil
+	}
+
+	buckets := map[string][]*memoryItem{}
+	q.mu.Lock()
+	for _, item := range q.pending {
+		buckets[string(item.bucket)] = append(buckets[string(item.bucket)], item)
+	}
+	q.mu.Unlock()
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	var ret error
+	written := 0
+	for bucket, items := range buckets {
+		err := q.store.Batch([]byte(bucket), func(b StoreBucket) error {
+			for _, item := range items {
+				if !q.current(item) {
+					continue
+				} else if item.deleted {
+					if err := b.Delete(item.key); err != nil {
+						return err
+					}
+				} else if err := b.Put(item.key, item.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			ret = err
+			continue
+		}
+
+		// Items, queued again during writing, have new version, so they stay there
+		q.mu.Lock()
+		for _, item := range items {
+			if pending, ok := q.pending[item.id]; ok && pending.version == item.version {
+				delete(q.pending, item.id)
+			}
+		}
+		q.mu.Unlock()
+		written += len(items)
+	}
+
+	log.Debugf("Flushed %d queued writes", written)
+	return ret
+}
+
+// Close stops the ticker and flushes pending items
+func (q *writeQueue) Close() error {
+	q.once.Do(func() {
+		close(q.quit)
+	})
+	return q.Flush()
+}
+
+// queued returns value of the key, waiting in the write queue
+func (d *BoltDatabase) queued(bucket []byte, key string) ([]byte, bool) {
+	if d.queue == nil {
+		return nil, false
+	}
+	return d.queue.get(bucket, []byte(key))
+}
+
+// unqueue drops pending write of the key, so it won't override direct writes
+func (d *BoltDatabase) unqueue(bucket []byte, key string) {
+	if d.queue != nil {
+		d.queue.forget(bucket, []byte(key))
+	}
+}
+
+// unqueuePrefix drops pending writes of the keys, starting with the prefix
+func (d *BoltDatabase) unqueuePrefix(bucket []byte, prefix []byte) {
+	if d.queue != nil {
+		d.queue.forgetPrefix(bucket, prefix)
+	}
+}
+
+// QueueSetBytes queues the write, so that frequent updates
+// of the same key end up in a single transaction
+func (d *BoltDatabase) QueueSetBytes(bucket []byte, key string, value []byte) error {
+	if d.queue == nil {
+		return d.SetBytes(bucket, key, value)
+	}
+
+	d.indexExpiry(bucket, key, value)
+	d.queue.put(bucket, []byte(key), value, false)
+	return nil
+}
+
+// QueueSetCachedObject queues the write of an object, expiring in selected seconds
+func (d *BoltDatabase) QueueSetCachedObject(bucket []byte, seconds int, key string, item interface{}) error {
+	buf, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return d.QueueSetBytes(bucket, key, cacheItem(bucket, seconds, key, buf))
+}
+
+// QueueDelete queues deletion of the key
+func (d *BoltDatabase) QueueDelete(bucket []byte, key string) error {
+	if d.queue == nil {
+		return d.Delete(bucket, key)
+	}
+
+	d.queue.put(bucket, []byte(key), nil, true)
+	return nil
+}
+
+// FlushQueue writes queued items to the database
+func (d *BoltDatabase) FlushQueue() error {
+	if d.queue == nil {
+		return nil
+	}
+	return d.queue.Flush()
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	lockCheckTimeout = 500 * time.Millisecond
+	readOnlySuffix   = ".readonly"
+)
+
+var (
+	readOnly     bool
+	readOnlyOnce sync.Once
+)
+
+// IsReadOnly tells if databases are opened read-only, because
+// another daemon instance is running against the same profile
+func IsReadOnly() bool {
+	return readOnly
+}
+
+// isLocked checks if database file is locked by another process,
+// bolt takes exclusive lock on the file, so opening it would hang
+func isLocked(path string) bool {
+	if _, err := os.Stat(path); err != nil || isSQLiteFile(path) {
+		return false
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockCheckTimeout})
+	if err == bolt.ErrTimeout {
+		return true
+	} else if err == nil {
+		db.Close()
+	}
+	return false
+}
+
+// setReadOnly switches all databases to read-only mode and warns the user once
+func setReadOnly(path string) {
+	readOnlyOnce.Do(func() {
+		readOnly = true
+		log.Warningf("Database at %s is locked by another instance, continuing in read-only mode", path)
+
+		// Do not block the startup while dialog is shown
+		go xbmc.Dialog("projectx", "LOCALIZE[30720]")
+	})
+}
+
+// readOnlySnapshot copies locked database file, so it can be opened read-only,
+// since other process keeps exclusive lock on the original file
+func readOnlySnapshot(path string) (string, error) {
+	snapshotPath := path + readOnlySuffix
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(snapshotPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+
+	// File is copied while it can be written, so make sure the copy is usable
+	if err := checkFile(snapshotPath); err != nil {
+		log.Warningf("Read-only copy of %s is inconsistent: %s", path, err)
+		os.Remove(snapshotPath)
+		return "", err
+	}
+
+	return snapshotPath, nil
+}
+
+// openPath returns path of database file to open, which is
+// a read-only copy of the file, when it is locked by another instance
+func openPath(path string) (string, error) {
+	if !IsReadOnly() {
+		return path, nil
+	}
+	return readOnlySnapshot(path)
+}
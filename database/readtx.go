@@ -0,0 +1,72 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// readTxLifetime limits how long shared read transaction is reused,
+// open read transaction holds old pages and delays remapping of the file
+const readTxLifetime = 100 * time.Millisecond
+
+type readTx interface {
+	Rollback() error
+}
+
+// sharedReadTx reuses one read transaction for bursts of reads, like rendering
+// a list with hundreds of cached items, instead of opening transaction per read.
+// Reads are serialized, since transactions are not safe for concurrent use,
+// and transaction is dropped on any write, so reads never see stale data.
+// Zero value is ready to use.
+type sharedReadTx struct {
+	mu         sync.Mutex
+	tx         readTx
+	generation int
+}
+
+// view runs fn inside shared read transaction, opening it with begin, if needed
+func (s *sharedReadTx) view(begin func() (readTx, error), fn func(tx readTx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		tx, err := begin()
+		if err != nil {
+			return err
+		}
+		s.tx = tx
+		s.generation++
+
+		generation := s.generation
+		time.AfterFunc(readTxLifetime, func() {
+			s.expire(generation)
+		})
+	}
+
+	return fn(s.tx)
+}
+
+func (s *sharedReadTx) expire(generation int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation == generation {
+		s.rollback()
+	}
+}
+
+// reset closes shared transaction, it should be called after writes
+// and before closing the database
+func (s *sharedReadTx) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollback()
+}
+
+func (s *sharedReadTx) rollback() {
+	if s.tx != nil {
+		s.tx.Rollback()
+		s.tx = nil
+	}
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	// RemoteBackupNone disables pushing backups to external destinations
+	RemoteBackupNone = iota
+	// RemoteBackupWebDAV uploads backups to WebDAV folder
+	RemoteBackupWebDAV
+	// RemoteBackupSFTP uploads backups to SFTP folder, using rclone's sftp backend
+	RemoteBackupSFTP
+	// RemoteBackupRclone uploads backups to configured rclone remote, like "gdrive:backups"
+	RemoteBackupRclone
+)
+
+const (
+	remoteBackupTimeFormat = "20060102-150405"
+	// remoteBackupTimeout limits single request or rclone call,
+	// so stalled destination does not block backups and syncing forever
+	remoteBackupTimeout = 10 * time.Minute
+)
+
+var (
+	remoteBackupLast    = map[string]time.Time{}
+	remoteBackupRunning = map[string]bool{}
+	remoteBackupMu      sync.Mutex
+
+	webdavClient = &http.Client{
+		Timeout: remoteBackupTimeout,
+	}
+)
+
+// RemoteBackup is an external destination for database backups
+type RemoteBackup interface {
+	Upload(name string, r io.Reader) error
+	List() ([]string, error)
+	Remove(name string) error
+}
+
+// GetRemoteBackup returns configured backup destination, or nil if it is disabled
+func GetRemoteBackup(conf *config.Configuration) (RemoteBackup, error) {
+	if conf.RemoteBackupURL == "" {
+		return nil, nil
+	}
+
+	switch conf.RemoteBackupType {
+	case RemoteBackupWebDAV:
+		return &webdavBackup{
+			url:      strings.TrimSuffix(conf.RemoteBackupURL, "/") + "/",
+			user:     conf.RemoteBackupUser,
+			password: conf.RemoteBackupPassword,
+		}, nil
+	case RemoteBackupSFTP:
+		return newSFTPBackup(conf)
+	case RemoteBackupRclone:
+		return &rcloneBackup{remote: conf.RemoteBackupURL}, nil
+	}
+
+	return nil, nil
+}
+
+// PushRemoteBackup uploads local backup file to external destination,
+// if it is configured and backup interval has passed since last upload,
+// and removes old remote backups according to retention setting.
+func PushRemoteBackup(backupPath string, fileName string) {
+	defer perf.ScopeTimer()()
+
+	conf := config.Get()
+	interval := time.Duration(conf.RemoteBackupInterval) * time.Hour
+
+	remoteBackupMu.Lock()
+	if last, ok := remoteBackupLast[fileName]; (ok && time.Since(last) < interval) || remoteBackupRunning[fileName] {
+		remoteBackupMu.Unlock()
+		return
+	}
+	remoteBackupRunning[fileName] = true
+	remoteBackupMu.Unlock()
+
+	defer func() {
+		remoteBackupMu.Lock()
+		delete(remoteBackupRunning, fileName)
+		remoteBackupMu.Unlock()
+	}()
+
+	remote, err := GetRemoteBackup(conf)
+	if err != nil {
+		log.Warningf("Could not prepare remote backup destination: %s", err)
+		return
+	} else if remote == nil {
+		return
+	}
+
+	f, err := os.Open(backupPath)
+	if err != nil {
+		log.Warningf("Could not open backup at %s: %s", backupPath, err)
+		return
+	}
+	defer f.Close()
+
+	name := fmt.Sprintf("%s.%s", fileName, time.Now().Format(remoteBackupTimeFormat))
+	if err := remote.Upload(name, f); err != nil {
+		log.Warningf("Could not upload backup %s: %s", name, err)
+		return
+	}
+	log.Infof("Database backup uploaded as %s", name)
+
+	// Failed uploads are retried with the next backup, not after the interval
+	remoteBackupMu.Lock()
+	remoteBackupLast[fileName] = time.Now()
+	remoteBackupMu.Unlock()
+
+	if conf.RemoteBackupRetention <= 0 {
+		return
+	}
+
+	names, err := remote.List()
+	if err != nil {
+		log.Warningf("Could not list remote backups: %s", err)
+		return
+	}
+
+	backups := []string{}
+	for _, n := range names {
+		if strings.HasPrefix(n, fileName+".") {
+			backups = append(backups, n)
+		}
+	}
+	// Timestamp suffix makes lexical order chronological
+	sort.Strings(backups)
+
+	for len(backups) > conf.RemoteBackupRetention {
+		log.Debugf("Removing old remote backup %s", backups[0])
+		if err := remote.Remove(backups[0]); err != nil {
+			log.Warningf("Could not remove remote backup %s: %s", backups[0], err)
+		}
+		backups = backups[1:]
+	}
+}
+
+type webdavBackup struct {
+	url      string
+	user     string
+	password string
+}
+
+type webdavMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+	} `xml:"response"`
+}
+
+func (b *webdavBackup) do(method string, name string, body io.Reader, header map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, b.url+url.PathEscape(name), body)
+	if err != nil {
+		return nil, err
+	}
+	if b.user != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := webdavClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s returned %s", method, req.URL.Path, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (b *webdavBackup) Upload(name string, r io.Reader) error {
+	resp, err := b.do("PUT", name, r, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (b *webdavBackup) List() ([]string, error) {
+	resp, err := b.do("PROPFIND", "", bytes.NewBufferString(`<?xml version="1.0"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ms webdavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, r := range ms.Responses {
+		href := strings.TrimSuffix(r.Href, "/")
+		if name, err := url.PathUnescape(path.Base(href)); err == nil && !strings.HasSuffix(r.Href, "/") {
+			ret = append(ret, name)
+		}
+	}
+	return ret, nil
+}
+
+func (b *webdavBackup) Remove(name string) error {
+	resp, err := b.do("DELETE", name, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+type rcloneBackup struct {
+	remote string
+	env    []string
+}
+
+// newSFTPBackup configures rclone's on-the-fly sftp remote from sftp://host[:port]/path URL
+func newSFTPBackup(conf *config.Configuration) (RemoteBackup, error) {
+	u, err := url.Parse(conf.RemoteBackupURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "sftp" {
+		return nil, fmt.Errorf("SFTP backup location should look like sftp://host/path, got %s://%s", u.Scheme, u.Host)
+	}
+
+	user := conf.RemoteBackupUser
+	if user == "" && u.User != nil {
+		user = u.User.Username()
+	}
+
+	b := &rcloneBackup{
+		remote: ":sftp:" + strings.TrimPrefix(u.Path, "/"),
+		env: []string{
+			"RCLONE_SFTP_HOST=" + u.Hostname(),
+			"RCLONE_SFTP_USER=" + user,
+		},
+	}
+	if port := u.Port(); port != "" {
+		b.env = append(b.env, "RCLONE_SFTP_PORT="+port)
+	}
+	if conf.RemoteBackupPassword != "" {
+		// rclone expects passwords to be obscured, password is passed via stdin
+		// to keep it out of process list
+		out, err := b.run(strings.NewReader(conf.RemoteBackupPassword), "obscure", "-")
+		if err != nil {
+			return nil, err
+		}
+		b.env = append(b.env, "RCLONE_SFTP_PASS="+strings.TrimSpace(string(out)))
+	}
+
+	return b, nil
+}
+
+func (b *rcloneBackup) run(stdin io.Reader, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteBackupTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	cmd.Env = append(os.Environ(), b.env...)
+	cmd.Stdin = stdin
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("rclone %s timed out after %s", args[0], remoteBackupTimeout)
+	} else if err != nil {
+		return nil, fmt.Errorf("rclone %s failed: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func (b *rcloneBackup) path(name string) string {
+	if strings.HasSuffix(b.remote, ":") || strings.HasSuffix(b.remote, "/") {
+		return b.remote + name
+	}
+	return b.remote + "/" + name
+}
+
+func (b *rcloneBackup) Upload(name string, r io.Reader) error {
+	_, err := b.run(r, "rcat", b.path(name))
+	return err
+}
+
+func (b *rcloneBackup) List() ([]string, error) {
+	out, err := b.run(nil, "lsf", "--files-only", b.remote)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func (b *rcloneBackup) Remove(name string) error {
+	_, err := b.run(nil, "deletefile", b.path(name))
+	return err
+}
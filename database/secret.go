@@ -0,0 +1,205 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	secretKeyFileName = "secret.key"
+	secretKeySize     = 32
+)
+
+var (
+	// SecretBucket holds encrypted values, like credentials and tokens,
+	// it is not a part of CacheBuckets, so items never expire
+	SecretBucket = []byte("Secret")
+
+	errSecretTooShort = errors.New("encrypted value is too short")
+	errSecretNotFound = errors.New("secret not found")
+
+	secretOnce sync.Once
+	secretAEAD cipher.AEAD
+	secretErr  error
+)
+
+// machineID returns identifier of the device, which is mixed into the key,
+// so copied database and key file are not enough to decrypt secrets elsewhere.
+// Devices without machine-id, like Android and Windows, rely on the key file only,
+// as values like hostname could change, making all secrets undecryptable.
+func machineID() []byte {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if b, err := ioutil.ReadFile(path); err == nil && len(strings.TrimSpace(string(b))) > 0 {
+			return []byte(strings.TrimSpace(string(b)))
+		}
+	}
+
+	return nil
+}
+
+// loadDeviceSecret reads random device secret from the profile folder,
+// creating it on the first run. Broken key file is not replaced,
+// as all secrets, encrypted with it, would be lost.
+func loadDeviceSecret() ([]byte, error) {
+	path := filepath.Join(config.Get().Info.Profile, secretKeyFileName)
+	if b, err := ioutil.ReadFile(path); err == nil {
+		if len(b) != secretKeySize {
+			return nil, fmt.Errorf("device secret at %s has %d bytes instead of %d", path, len(b), secretKeySize)
+		}
+		return b, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	secret := make([]byte, secretKeySize)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, secret, 0600); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Created new device secret at %s", path)
+	return secret, nil
+}
+
+func getSecretAEAD() (cipher.AEAD, error) {
+	secretOnce.Do(func() {
+		var secret []byte
+		if secret, secretErr = loadDeviceSecret(); secretErr != nil {
+			log.Errorf("Could not load device secret: %s", secretErr)
+			return
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(machineID())
+
+		var block cipher.Block
+		if block, secretErr = aes.NewCipher(mac.Sum(nil)); secretErr != nil {
+			return
+		}
+		secretAEAD, secretErr = cipher.NewGCM(block)
+	})
+
+	return secretAEAD, secretErr
+}
+
+// encryptSecret encrypts value with AES-GCM, nonce is prepended to the result,
+// key is used as additional data, so values can't be swapped between keys
+func encryptSecret(key string, value []byte) ([]byte, error) {
+	aead, err := getSecretAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, value, []byte(key)), nil
+}
+
+func decryptSecret(key string, value []byte) ([]byte, error) {
+	aead, err := getSecretAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) < aead.NonceSize() {
+		return nil, errSecretTooShort
+	}
+
+	return aead.Open(nil, value[:aead.NonceSize()], value[aead.NonceSize():], []byte(key))
+}
+
+// SetSecret encrypts and saves value into SecretBucket
+func (d *BoltDatabase) SetSecret(key string, value string) error {
+	encrypted, err := encryptSecret(key, []byte(value))
+	if err != nil {
+		return err
+	}
+
+	return d.SetBytes(SecretBucket, key, encrypted)
+}
+
+// GetSecret reads and decrypts value from SecretBucket
+func (d *BoltDatabase) GetSecret(key string) (string, error) {
+	encrypted, err := d.GetBytes(SecretBucket, key)
+	if err != nil {
+		return "", err
+	} else if len(encrypted) == 0 {
+		return "", errSecretNotFound
+	}
+
+	value, err := decryptSecret(key, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// DeleteSecret removes value from SecretBucket
+func (d *BoltDatabase) DeleteSecret(key string) error {
+	return d.Delete(SecretBucket, key)
+}
+
+// credential returns password, entered in the setting. Entered password is moved into SecretBucket,
+// and the setting is cleared, so it is not kept in plain text in settings.xml.
+// Empty setting means that the password, moved before, is used.
+func credential(setting string, value string) string {
+	db := cacheDatabase
+	if db == nil {
+		return value
+	}
+
+	key := credentialKey(setting)
+	if value == "" {
+		stored, err := db.GetSecret(key)
+		if err != nil && err != errSecretNotFound {
+			log.Errorf("Could not decrypt %s, it should be entered again: %s", setting, err)
+		}
+		return stored
+	}
+
+	if err := db.SetSecret(key, value); err != nil {
+		log.Warningf("Could not encrypt %s, keeping it in settings: %s", setting, err)
+		return value
+	}
+	xbmc.SetSetting(setting, "")
+
+	log.Infof("Moved %s from settings to encrypted storage", setting)
+	return value
+}
+
+// SetCredential saves password or token into encrypted storage, instead of settings.
+// When the storage is not available, like in read-only mode, value is saved into settings.
+func SetCredential(setting string, value string) {
+	if db := cacheDatabase; db != nil && !IsReadOnly() {
+		err := db.SetSecret(credentialKey(setting), value)
+		if err == nil {
+			return
+		}
+		log.Warningf("Could not encrypt %s, saving it in settings: %s", setting, err)
+	}
+
+	xbmc.SetSetting(setting, value)
+}
+
+func credentialKey(setting string) string {
+	return "setting." + setting
+}
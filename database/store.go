@@ -0,0 +1,166 @@
+package database
+
+import (
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	// EngineBolt is the original boltdb engine
+	EngineBolt = iota
+	// EngineBBolt is the etcd fork of boltdb, with hashmap freelist,
+	// which is faster on big databases with many free pages
+	EngineBBolt
+	// EngineSQLite keeps data in SQLite file, which does not need bolt's memory map,
+	// file format is different, so data is copied, when engine is switched
+	EngineSQLite
+)
+
+// Store is a key/value engine used by BoltDatabase to keep data on disk
+type Store interface {
+	// Get returns a copy of the value, or nil if key does not exist
+	Get(bucket []byte, key []byte) ([]byte, error)
+	// GetMulti returns copies of values of all keys, read in one transaction,
+	// values of missing keys are nil
+	GetMulti(bucket []byte, keys [][]byte) ([][]byte, error)
+	Set(bucket []byte, key []byte, value []byte) error
+	Delete(bucket []byte, key []byte) error
+
+	// Seek iterates over keys, having selected prefix, empty prefix means all keys
+	Seek(bucket []byte, prefix []byte, callback callBackWithError) error
+	// SeekReverse iterates over keys, having selected prefix, in descending order
+	SeekReverse(bucket []byte, prefix []byte, callback callBackWithError) error
+
+	// Batch runs multiple writes in one transaction
+	Batch(bucket []byte, fn func(b StoreBucket) error) error
+	// Update runs reads and writes across buckets in one transaction
+	Update(fn func(tx StoreTx) error) error
+
+	Buckets() ([][]byte, error)
+	CreateBucket(bucket []byte) error
+	DeleteBucket(bucket []byte) error
+
+	Backup(path string) error
+	// Stats returns per-bucket usage and free pages of the database file
+	Stats() (*DatabaseStats, error)
+	// Compact rewrites database into a new file, dropping unused pages
+	Compact() error
+	Close() error
+}
+
+// StoreBucket is a bucket handle, valid only inside Batch or Update callback
+type StoreBucket interface {
+	// Get returns the value, which is valid only inside the transaction
+	Get(key []byte) []byte
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+}
+
+// BucketStats is a usage of a single bucket
+type BucketStats struct {
+	Name  string `json:"name"`
+	Keys  int    `json:"keys"`
+	Bytes int    `json:"bytes"`
+}
+
+// DatabaseStats is a usage of the database file
+type DatabaseStats struct {
+	FileName     string        `json:"file_name"`
+	FileSize     int64         `json:"file_size"`
+	FreePages    int           `json:"free_pages"`
+	PendingPages int           `json:"pending_pages"`
+	FreeBytes    int           `json:"free_bytes"`
+	Buckets      []BucketStats `json:"buckets"`
+}
+
+// StoreTx is a transaction handle, valid only inside Update callback
+type StoreTx interface {
+	// Bucket returns nil if bucket does not exist
+	Bucket(name []byte) StoreBucket
+}
+
+// CreateStore opens database file with the engine, selected in the settings.
+// Bolt engines are using the same file format, so switching between them
+// does not require a migration, SQLite file is named after the bolt file,
+// with its own extension, and data is copied into it, when engine is switched.
+func CreateStore(conf *config.Configuration, fileName string, backupFileName string) (store Store, err error) {
+	switch conf.DatabaseEngine {
+	case EngineSQLite:
+		db, err := CreateSQLiteDB(conf, sqliteName(fileName), sqliteName(backupFileName))
+		if err != nil {
+			return nil, err
+		}
+		store = &sqliteStore{db: db}
+	case EngineBBolt:
+		db, err := CreateBBoltDB(conf, fileName, backupFileName)
+		if err != nil {
+			return nil, err
+		}
+		store = &bboltStore{db: db}
+	default:
+		db, err := CreateBoltDB(conf, fileName, backupFileName)
+		if err != nil {
+			return nil, err
+		}
+		store = &boltStore{db: db}
+	}
+
+	migrateStore(conf, store, fileName)
+	return store, nil
+}
+
+// prefixEnd returns the smallest key, greater than all keys with the prefix,
+// or nil if there is no such key, like for empty prefix
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// copyStore copies all buckets and keys from one Store to another
+func copyStore(src Store, dst Store) error {
+	buckets, err := src.Buckets()
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if err := dst.CreateBucket(bucket); err != nil {
+			return err
+		}
+
+		chunk := map[string][]byte{}
+		flush := func() error {
+			err := dst.Batch(bucket, func(b StoreBucket) error {
+				for k, v := range chunk {
+					if err := b.Put([]byte(k), v); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			chunk = map[string][]byte{}
+			return err
+		}
+
+		err := src.Seek(bucket, nil, func(k []byte, v []byte) error {
+			chunk[string(k)] = append([]byte{}, v...)
+			if len(chunk) >= 1000 {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
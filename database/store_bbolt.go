@@ -0,0 +1,339 @@
+package database
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/projectx13/projectx/config"
+)
+
+type bboltStore struct {
+	mu    sync.RWMutex
+	db    *bolt.DB
+	reads sharedReadTx
+}
+
+type bboltTx struct {
+	tx *bolt.Tx
+}
+
+// CreateBBoltDB opens database file with go.etcd.io/bbolt engine
+func CreateBBoltDB(conf *config.Configuration, fileName string, backupFileName string) (*bolt.DB, error) {
+	relocateDatabase(conf, fileName, backupFileName)
+
+	dir := databaseDir(conf, fileName)
+	databasePath := filepath.Join(dir, fileName)
+	backupPath := filepath.Join(dir, backupFileName)
+
+	CheckIntegrity(databasePath, backupPath)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Got critical error while creating BBolt: %v", r)
+			RestoreBackup(databasePath, LatestBackup(backupPath))
+			os.Exit(1)
+		}
+	}()
+
+	path, err := openPath(databasePath)
+	if err != nil {
+		log.Warningf("Could not prepare database at %s: %#v", databasePath, err)
+		return nil, err
+	}
+
+	db, err := openBBoltFile(path)
+	if err != nil {
+		log.Warningf("Could not open database at %s: %#v", path, err)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func openBBoltFile(path string) (*bolt.DB, error) {
+	// Freelist is synced, as boltdb could not open files without it, so engines could be switched.
+	// Files, written without freelist sync before, get freelist written by bbolt on opening.
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		ReadOnly:     IsReadOnly(),
+		Timeout:      15 * time.Second,
+		FreelistType: bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.NoSync = true
+
+	return db, nil
+}
+
+func (s *bboltStore) Get(bucket []byte, key []byte) (value []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.reads.view(s.beginRead, func(tx readTx) error {
+		b := tx.(*bolt.Tx).Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		if v := b.Get(key); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	return
+}
+
+func (s *bboltStore) GetMulti(bucket []byte, keys [][]byte) (values [][]byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values = make([][]byte, len(keys))
+	err = s.reads.view(s.beginRead, func(tx readTx) error {
+		b := tx.(*bolt.Tx).Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		for i, key := range keys {
+			if v := b.Get(key); v != nil {
+				values[i] = append([]byte{}, v...)
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func (s *bboltStore) Set(bucket []byte, key []byte, value []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		return b.Put(key, value)
+	})
+}
+
+func (s *bboltStore) Delete(bucket []byte, key []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		return b.Delete(key)
+	})
+}
+
+func (s *bboltStore) Seek(bucket []byte, prefix []byte, callback callBackWithError) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := callback(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *bboltStore) SeekReverse(bucket []byte, prefix []byte, callback callBackWithError) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+
+		c := b.Cursor()
+		var k, v []byte
+		if end := prefixEnd(prefix); end == nil {
+			k, v = c.Last()
+		} else if k, v = c.Seek(end); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			if err := callback(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *bboltStore) Batch(bucket []byte, fn func(b StoreBucket) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		return fn(b)
+	})
+}
+
+func (s *bboltStore) Update(fn func(tx StoreTx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(bboltTx{tx})
+	})
+}
+
+func (s *bboltStore) Buckets() (ret [][]byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			ret = append(ret, append([]byte{}, name...))
+			return nil
+		})
+	})
+	return
+}
+
+func (s *bboltStore) CreateBucket(bucket []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+}
+
+func (s *bboltStore) DeleteBucket(bucket []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.reads.reset()
+	defer s.reads.reset()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(bucket)
+	})
+}
+
+func (s *bboltStore) Backup(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0600)
+	})
+}
+
+func (s *bboltStore) Stats() (*DatabaseStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dbStats := s.db.Stats()
+	ret := &DatabaseStats{
+		FreePages:    dbStats.FreePageN,
+		PendingPages: dbStats.PendingPageN,
+		FreeBytes:    dbStats.FreeAlloc,
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			st := b.Stats()
+			ret.Buckets = append(ret.Buckets, BucketStats{
+				Name:  string(name),
+				Keys:  st.KeyN,
+				Bytes: st.LeafInuse + st.BranchInuse,
+			})
+			return nil
+		})
+	})
+	return ret, err
+}
+
+func (s *bboltStore) beginRead() (readTx, error) {
+	return s.db.Begin(false)
+}
+
+func (s *bboltStore) Close() error {
+	s.reads.reset()
+	return s.db.Close()
+}
+
+// Compact rewrites database into a new file. Writes wait for the copying,
+// otherwise they would be lost, when the new file replaces the old one.
+func (s *bboltStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.db.Path()
+	compactPath := path + ".compact"
+	os.Remove(compactPath)
+
+	db, err := openBBoltFile(compactPath)
+	if err != nil {
+		return err
+	}
+	// Store methods take the lock, held here, so the file is read through another Store
+	src := &bboltStore{db: s.db}
+	err = copyStore(src, &bboltStore{db: db})
+	src.reads.reset()
+	db.Close()
+	if err != nil {
+		os.Remove(compactPath)
+		return err
+	}
+
+	s.reads.reset()
+	if err := s.db.Close(); err != nil {
+		os.Remove(compactPath)
+		return err
+	}
+
+	errRename := os.Rename(compactPath, path)
+	if s.db, err = openBBoltFile(path); err != nil {
+		return err
+	}
+
+	return errRename
+}
+
+func (t bboltTx) Bucket(name []byte) StoreBucket {
+	// Returning nil *bolt.Bucket as interface would make it non-nil
+	if b := t.tx.Bucket(name); b != nil {
+		return b
+	}
+	return nil
+}
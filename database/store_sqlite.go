@@ -0,0 +1,477 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	// SQLite driver for database/sql
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	sqliteExt    = ".sqlite"
+	sqliteHeader = "SQLite format 3\x00"
+
+	sqliteSchema = `
+		CREATE TABLE IF NOT EXISTS buckets (name BLOB PRIMARY KEY) WITHOUT ROWID;
+		CREATE TABLE IF NOT EXISTS kv (
+			bucket BLOB NOT NULL,
+			key    BLOB NOT NULL,
+			value  BLOB NOT NULL,
+			PRIMARY KEY (bucket, key)
+		) WITHOUT ROWID;`
+)
+
+// sqliteName returns name of SQLite file, which replaces bolt file with the same name
+func sqliteName(fileName string) string {
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)) + sqliteExt
+}
+
+// storeFileNames returns names of database and backup files, used by the engine
+func storeFileNames(engine int, fileName string, backupFileName string) (string, string) {
+	if engine != EngineSQLite {
+		return fileName, backupFileName
+	}
+	return sqliteName(fileName), sqliteName(backupFileName)
+}
+
+// isSQLiteFile checks database file header, to select the right consistency check
+func isSQLiteFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return string(header) == sqliteHeader
+}
+
+func openSQLiteFile(path string) (*sql.DB, error) {
+	// Transactions take write lock at the start, so concurrent Batch calls
+	// wait for each other with busy timeout, instead of failing on lock upgrade
+	dsn := "file:" + path + "?_busy_timeout=15000&_txlock=immediate"
+	if IsReadOnly() {
+		dsn += "&mode=ro"
+	} else {
+		// WAL lets Seek callbacks read, while other goroutines are writing
+		dsn += "&_journal_mode=WAL&_synchronous=NORMAL"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if !IsReadOnly() {
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// checkSQLiteFile runs SQLite consistency check on the database file
+func checkSQLiteFile(path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return err
+	} else if result != "ok" {
+		return errors.New(result)
+	}
+	return nil
+}
+
+// CreateSQLiteDB opens SQLite database file, it is not locked by bolt,
+// so it is opened in place, even in read-only mode
+func CreateSQLiteDB(conf *config.Configuration, fileName string, backupFileName string) (*sql.DB, error) {
+	relocateDatabase(conf, fileName, backupFileName)
+
+	dir := databaseDir(conf, fileName)
+	databasePath := filepath.Join(dir, fileName)
+	backupPath := filepath.Join(dir, backupFileName)
+
+	CheckIntegrity(databasePath, backupPath)
+
+	db, err := openSQLiteFile(databasePath)
+	if err != nil {
+		log.Warningf("Could not open database at %s: %#v", databasePath, err)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+type sqliteStore struct {
+	mu sync.RWMutex
+	db *sql.DB
+}
+
+type sqliteBucket struct {
+	tx   *sql.Tx
+	name []byte
+}
+
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+// queryer is a common part of sql.DB, sql.Conn and sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func bucketExists(q queryer, bucket []byte) (bool, error) {
+	var found int
+	err := q.QueryRowContext(context.Background(), "SELECT 1 FROM buckets WHERE name = ?", bucket).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *sqliteStore) Get(bucket []byte, key []byte) (value []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var v []byte
+	err = s.db.QueryRow("SELECT kv.value FROM buckets LEFT JOIN kv ON kv.bucket = buckets.name AND kv.key = ? WHERE buckets.name = ?", key, bucket).Scan(&v)
+	if err == sql.ErrNoRows {
+		return nil, errBucketNotFound
+	}
+	return v, err
+}
+
+func (s *sqliteStore) GetMulti(bucket []byte, keys [][]byte) (values [][]byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Transactions, started with sql.DB, take write lock, so read transaction
+	// is started manually, it gives the same snapshot for all keys
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN DEFERRED"); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, "ROLLBACK")
+
+	if ok, err := bucketExists(conn, bucket); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errBucketNotFound
+	}
+
+	values = make([][]byte, len(keys))
+	for i, key := range keys {
+		err := conn.QueryRowContext(ctx, "SELECT value FROM kv WHERE bucket = ? AND key = ?", bucket, key).Scan(&values[i])
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (s *sqliteStore) Set(bucket []byte, key []byte, value []byte) error {
+	return s.Batch(bucket, func(b StoreBucket) error {
+		return b.Put(key, value)
+	})
+}
+
+func (s *sqliteStore) Delete(bucket []byte, key []byte) error {
+	return s.Batch(bucket, func(b StoreBucket) error {
+		return b.Delete(key)
+	})
+}
+
+func (s *sqliteStore) Seek(bucket []byte, prefix []byte, callback callBackWithError) error {
+	return s.seek(bucket, prefix, "ASC", callback)
+}
+
+func (s *sqliteStore) SeekReverse(bucket []byte, prefix []byte, callback callBackWithError) error {
+	return s.seek(bucket, prefix, "DESC", callback)
+}
+
+func (s *sqliteStore) seek(bucket []byte, prefix []byte, order string, callback callBackWithError) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if ok, err := bucketExists(s.db, bucket); err != nil {
+		return err
+	} else if !ok {
+		return errBucketNotFound
+	}
+
+	// Keys are compared as bytes, like in bolt, so prefix is selected as a range
+	query := "SELECT key, value FROM kv WHERE bucket = ? AND key >= ?"
+	args := []interface{}{bucket, append([]byte{}, prefix...)}
+	if end := prefixEnd(prefix); end != nil {
+		query += " AND key < ?"
+		args = append(args, end)
+	}
+
+	rows, err := s.db.Query(query+" ORDER BY key "+order, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var k, v sql.RawBytes
+		if err := rows.Scan(&k, &v); err != nil {
+			return err
+		}
+		if err := callback(k, v); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqliteStore) Batch(bucket []byte, fn func(b StoreBucket) error) error {
+	return s.Update(func(tx StoreTx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return errBucketNotFound
+		}
+		return fn(b)
+	})
+}
+
+func (s *sqliteStore) Update(fn func(tx StoreTx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(sqliteTx{tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Buckets() (ret [][]byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query("SELECT name FROM buckets ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name []byte
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		ret = append(ret, name)
+	}
+	return ret, rows.Err()
+}
+
+func (s *sqliteStore) CreateBucket(bucket []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := s.db.Exec("INSERT OR IGNORE INTO buckets (name) VALUES (?)", bucket)
+	return err
+}
+
+func (s *sqliteStore) DeleteBucket(bucket []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if res, err := tx.Exec("DELETE FROM buckets WHERE name = ?", bucket); err != nil {
+		return err
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return errBucketNotFound
+	}
+	if _, err := tx.Exec("DELETE FROM kv WHERE bucket = ?", bucket); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Backup writes consistent copy of the database, without pages of deleted keys
+func (s *sqliteStore) Backup(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// VACUUM INTO does not overwrite existing files
+	os.Remove(path)
+	_, err := s.db.Exec("VACUUM INTO ?", path)
+	return err
+}
+
+func (s *sqliteStore) Stats() (*DatabaseStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ret := &DatabaseStats{}
+	var pageSize int
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRow("PRAGMA freelist_count").Scan(&ret.FreePages); err != nil {
+		return nil, err
+	}
+	ret.FreeBytes = ret.FreePages * pageSize
+
+	rows, err := s.db.Query(`
+		SELECT buckets.name, COUNT(kv.key), COALESCE(SUM(LENGTH(kv.key) + LENGTH(kv.value)), 0)
+		FROM buckets LEFT JOIN kv ON kv.bucket = buckets.name
+		GROUP BY buckets.name ORDER BY buckets.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name []byte
+		st := BucketStats{}
+		if err := rows.Scan(&name, &st.Keys, &st.Bytes); err != nil {
+			return nil, err
+		}
+		st.Name = string(name)
+		ret.Buckets = append(ret.Buckets, st)
+	}
+	return ret, rows.Err()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// Compact rebuilds database file in place, writes wait for it to finish
+func (s *sqliteStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return err
+	}
+	// Return pages of the write-ahead log to the file system too
+	_, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return err
+}
+
+func (t sqliteTx) Bucket(name []byte) StoreBucket {
+	if ok, err := bucketExists(t.tx, name); err != nil || !ok {
+		return nil
+	}
+	return sqliteBucket{tx: t.tx, name: append([]byte{}, name...)}
+}
+
+func (b sqliteBucket) get(key []byte) (value []byte, err error) {
+	err = b.tx.QueryRowContext(context.Background(), "SELECT value FROM kv WHERE bucket = ? AND key = ?", b.name, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return
+}
+
+// Get returns nil on errors, same as for missing keys, like bolt does
+func (b sqliteBucket) Get(key []byte) []byte {
+	value, err := b.get(key)
+	if err != nil {
+		log.Warningf("Could not read key %s: %s", key, err)
+	}
+	return value
+}
+
+func (b sqliteBucket) Put(key []byte, value []byte) error {
+	if len(key) == 0 {
+		return errors.New("key required")
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := b.tx.Exec("INSERT OR REPLACE INTO kv (bucket, key, value) VALUES (?, ?, ?)", b.name, key, value)
+	return err
+}
+
+func (b sqliteBucket) Delete(key []byte) error {
+	_, err := b.tx.Exec("DELETE FROM kv WHERE bucket = ? AND key = ?", b.name, key)
+	return err
+}
+
+// migrateStore copies data from the file of another engine, when engine is switched in the settings,
+// bolt engines share the file format, so it is needed only between them and SQLite
+func migrateStore(conf *config.Configuration, store Store, fileName string) {
+	if IsReadOnly() {
+		return
+	}
+
+	var (
+		path string
+		src  Store
+	)
+	if conf.DatabaseEngine == EngineSQLite {
+		path = filepath.Join(databaseDir(conf, fileName), fileName)
+		if _, err := os.Stat(path); err != nil || isSQLiteFile(path) {
+			return
+		}
+		db, err := openBoltFile(path)
+		if err != nil {
+			log.Warningf("Could not open %s for migration: %s", path, err)
+			return
+		}
+		src = &boltStore{db: db}
+	} else {
+		path = filepath.Join(databaseDir(conf, sqliteName(fileName)), sqliteName(fileName))
+		if !isSQLiteFile(path) {
+			return
+		}
+		db, err := openSQLiteFile(path)
+		if err != nil {
+			log.Warningf("Could not open %s for migration: %s", path, err)
+			return
+		}
+		src = &sqliteStore{db: db}
+	}
+
+	log.Infof("Migrating database from %s", path)
+	err := copyStore(src, store)
+	src.Close()
+	if err != nil {
+		log.Errorf("Could not migrate database from %s: %s", path, err)
+		return
+	}
+
+	// Keeping old file would bring back outdated data, when engine is switched back
+	for _, p := range []string{path, path + "-wal", path + "-shm"} {
+		os.Remove(p)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
@@ -37,6 +38,7 @@ func InitStormDB(conf *config.Configuration) (*StormDatabase, error) {
 	stormDatabase = &StormDatabase{
 		db:             db,
 		quit:           make(chan struct{}, 2),
+		dir:            databaseDir(conf, stormFileName),
 		fileName:       stormFileName,
 		backupFileName: backupStormFileName,
 	}
@@ -46,19 +48,30 @@ func InitStormDB(conf *config.Configuration) (*StormDatabase, error) {
 
 // CreateStormDB ...
 func CreateStormDB(conf *config.Configuration, fileName string, backupFileName string) (*storm.DB, error) {
-	databasePath := filepath.Join(conf.Info.Profile, fileName)
-	backupPath := filepath.Join(conf.Info.Profile, backupFileName)
+	relocateDatabase(conf, fileName, backupFileName)
+
+	dir := databaseDir(conf, fileName)
+	databasePath := filepath.Join(dir, fileName)
+	backupPath := filepath.Join(dir, backupFileName)
+
+	CheckIntegrity(databasePath, backupPath)
 
 	defer func() {
 		if r := recover(); r != nil {
 			log.Errorf("Got critical error while creating Storm: %v", r)
-			RestoreBackup(databasePath, backupPath)
+			RestoreBackup(databasePath, LatestBackup(backupPath))
 			os.Exit(1)
 		}
 	}()
 
-	db, err := storm.Open(databasePath, storm.BoltOptions(0600, &bolt.Options{
-		ReadOnly: false,
+	path, err := openPath(databasePath)
+	if err != nil {
+		log.Warningf("Could not prepare database at %s: %#v", databasePath, err)
+		return nil, err
+	}
+
+	db, err := storm.Open(path, storm.BoltOptions(0600, &bolt.Options{
+		ReadOnly: IsReadOnly(),
 		Timeout:  15 * time.Second,
 		NoSync:   true,
 	}))
@@ -73,13 +86,20 @@ func CreateStormDB(conf *config.Configuration, fileName string, backupFileName s
 
 // MaintenanceRefreshHandler ...
 func (d *StormDatabase) MaintenanceRefreshHandler() {
-	backupPath := filepath.Join(config.Get().Info.Profile, d.backupFileName)
+	if IsReadOnly() {
+		return
+	}
+
+	backupPath := d.GetBackupPath()
 
 	d.CreateBackup(backupPath)
+	saveClock()
 
-	tickerBackup := time.NewTicker(2 * time.Hour)
+	tickerBackup := time.NewTicker(BackupInterval())
+	tickerClock := time.NewTicker(clockSaveInterval)
 
 	defer tickerBackup.Stop()
+	defer tickerClock.Stop()
 	defer close(d.quit)
 
 	for {
@@ -90,7 +110,10 @@ func (d *StormDatabase) MaintenanceRefreshHandler() {
 			}()
 			// case <-tickerCache.C:
 			// 	go d.CacheCleanup()
+		case <-tickerClock.C:
+			saveClock()
 		case <-d.quit:
+			saveClock()
 			return
 		}
 	}
@@ -100,11 +123,18 @@ func (d *StormDatabase) MaintenanceRefreshHandler() {
 func (d *StormDatabase) CreateBackup(backupPath string) {
 	defer perf.ScopeTimer()()
 
-	d.db.Bolt.View(func(tx *bolt.Tx) error {
-		tx.CopyFile(backupPath, 0600)
-		log.Debugf("Database backup saved at: %s", backupPath)
-		return nil
+	path, err := rotateBackup(backupPath, func(path string) error {
+		return d.db.Bolt.View(func(tx *bolt.Tx) error {
+			return tx.CopyFile(path, 0600)
+		})
 	})
+	if err != nil {
+		log.Warningf("Could not save database backup for %s: %s", backupPath, err)
+		return
+	}
+	log.Debugf("Database backup saved at: %s", path)
+
+	go PushRemoteBackup(path, d.fileName)
 }
 
 // Close ...
@@ -119,6 +149,21 @@ func (d *StormDatabase) GetFilename() string {
 	return d.fileName
 }
 
+// GetBackupFilename returns bolt backup filename
+func (d *StormDatabase) GetBackupFilename() string {
+	return d.backupFileName
+}
+
+// GetPath returns location of bolt file
+func (d *StormDatabase) GetPath() string {
+	return filepath.Join(d.dir, d.fileName)
+}
+
+// GetBackupPath returns location of bolt backup file
+func (d *StormDatabase) GetBackupPath() string {
+	return filepath.Join(d.dir, d.backupFileName)
+}
+
 // AddSearchHistory adds query to search history, according to media type
 func (d *StormDatabase) AddSearchHistory(historyType, query string) {
 	defer perf.ScopeTimer()()
@@ -171,6 +216,308 @@ func (d *StormDatabase) RemoveSearchHistory(historyType, query string) {
 	d.db.ReIndex(&QueryHistory{})
 }
 
+// AddTombstone marks library item as explicitly removed by the user
+func (d *StormDatabase) AddTombstone(tmdbID, mediaType int, title string) {
+	defer perf.ScopeTimer()()
+
+	ts := LibraryTombstone{
+		ID:        fmt.Sprintf("%d|%d", mediaType, tmdbID),
+		TmdbID:    tmdbID,
+		MediaType: mediaType,
+		Title:     title,
+		Dt:        time.Now(),
+	}
+
+	if err := d.db.Save(&ts); err != nil {
+		log.Warningf("Could not save tombstone for %d: %s", tmdbID, err)
+	}
+}
+
+// RemoveTombstone allows library item to be synced again
+func (d *StormDatabase) RemoveTombstone(tmdbID, mediaType int) {
+	defer perf.ScopeTimer()()
+
+	var ts LibraryTombstone
+	if err := d.db.One("ID", fmt.Sprintf("%d|%d", mediaType, tmdbID), &ts); err != nil {
+		if err != storm.ErrNotFound {
+			log.Debugf("Could not find tombstone for %d: %s", tmdbID, err)
+		}
+		return
+	}
+
+	// Deleting the struct drops its index entries too, which raw key deletion leaves behind
+	if err := d.db.DeleteStruct(&ts); err != nil {
+		log.Debugf("Could not delete tombstone for %d: %s", tmdbID, err)
+	}
+	// Index entries, left by raw key deletions before, are dropped as well
+	d.db.ReIndex(&LibraryTombstone{})
+}
+
+// IsTombstoned checks whether library item was explicitly removed by the user
+func (d *StormDatabase) IsTombstoned(tmdbID, mediaType int) bool {
+	defer perf.ScopeTimer()()
+
+	var ts LibraryTombstone
+	return d.db.One("ID", fmt.Sprintf("%d|%d", mediaType, tmdbID), &ts) == nil
+}
+
+// GetTombstones returns all removed library items, most recent first
+func (d *StormDatabase) GetTombstones() (ret []LibraryTombstone) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.AllByIndex("Dt", &ret, storm.Reverse()); err != nil {
+		log.Debugf("Could not get list of tombstones: %s", err)
+	}
+	return
+}
+
+const (
+	// libraryRetryDelay is a delay before the first retry of failed library item
+	libraryRetryDelay = 30 * time.Minute
+	// libraryRetryMaxDelay limits delay between retries
+	libraryRetryMaxDelay = 24 * time.Hour
+	// libraryRetryMaxAttempts is how many times item is tried automatically, it could be retried by the user after that
+	libraryRetryMaxAttempts = 10
+)
+
+// AddLibraryFailure stores failed attempt to write library item, next attempt is delayed
+// twice longer after each failure, starting with libraryRetryDelay
+func (d *StormDatabase) AddLibraryFailure(tmdbID, mediaType int, title string, reason error) *LibraryFailure {
+	defer perf.ScopeTimer()()
+
+	id := fmt.Sprintf("%d|%d", mediaType, tmdbID)
+	f := &LibraryFailure{}
+	if err := d.db.One("ID", id, f); err != nil {
+		f = &LibraryFailure{
+			ID:        id,
+			TmdbID:    tmdbID,
+			MediaType: mediaType,
+		}
+	}
+	if title != "" {
+		f.Title = title
+	}
+	if reason != nil {
+		f.Error = reason.Error()
+	}
+
+	delay := libraryRetryDelay << uint(f.Attempts)
+	if delay <= 0 || delay > libraryRetryMaxDelay {
+		delay = libraryRetryMaxDelay
+	}
+	f.Attempts++
+	f.LastTry = time.Now()
+	f.NextTry = f.LastTry.Add(delay)
+
+	if err := d.db.Save(f); err != nil {
+		log.Warningf("Could not save library failure for %d: %s", tmdbID, err)
+	}
+	return f
+}
+
+// RemoveLibraryFailure forgets failed library item, after it was written or dismissed
+func (d *StormDatabase) RemoveLibraryFailure(tmdbID, mediaType int) {
+	defer perf.ScopeTimer()()
+
+	// Checking first, as it is called for each synced item, and most of them never failed
+	var f LibraryFailure
+	if err := d.db.One("ID", fmt.Sprintf("%d|%d", mediaType, tmdbID), &f); err != nil {
+		return
+	}
+	if err := d.db.DeleteStruct(&f); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not delete library failure for %d: %s", tmdbID, err)
+	}
+}
+
+// GetLibraryFailures returns all failed library items, most recent first
+func (d *StormDatabase) GetLibraryFailures() (ret []LibraryFailure) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.All(&ret); err != nil {
+		log.Debugf("Could not get list of library failures: %s", err)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].LastTry.After(ret[j].LastTry) })
+	return
+}
+
+// GetDueLibraryFailures returns failed library items, which should be retried by now
+func (d *StormDatabase) GetDueLibraryFailures() (ret []LibraryFailure) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.Select(q.Lte("NextTry", time.Now()), q.Lt("Attempts", libraryRetryMaxAttempts)).Find(&ret); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not get list of library failures: %s", err)
+	}
+	return
+}
+
+// failedSearchesMaxSize is how many recent failed searches are kept for the coverage report
+const failedSearchesMaxSize = 500
+
+// AddFailedSearch stores search without acceptable results, dropping the oldest ones over the limit
+func (d *StormDatabase) AddFailedSearch(f *FailedSearch) {
+	defer perf.ScopeTimer()()
+
+	f.Dt = time.Now()
+	if err := d.db.Save(f); err != nil {
+		log.Warningf("Could not save failed search for %s: %s", f.Title, err)
+		return
+	}
+
+	var old []FailedSearch
+	d.db.AllByIndex("Dt", &old, storm.Reverse(), storm.Skip(failedSearchesMaxSize))
+	for _, o := range old {
+		d.db.DeleteStruct(&o)
+	}
+}
+
+// GetFailedSearches returns stored failed searches, most recent first
+func (d *StormDatabase) GetFailedSearches() (ret []FailedSearch) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.AllByIndex("Dt", &ret, storm.Reverse()); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not get list of failed searches: %s", err)
+	}
+	return
+}
+
+// ClearFailedSearches removes all stored failed searches
+func (d *StormDatabase) ClearFailedSearches() {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.Drop(FailedSearchBucket); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not clear failed searches: %s", err)
+	}
+}
+
+// GetEpisodeOrdering returns episode group of the show, or empty string for aired order
+func (d *StormDatabase) GetEpisodeOrdering(showID int) string {
+	defer perf.ScopeTimer()()
+
+	var ordering EpisodeOrdering
+	if err := d.db.One("ShowID", showID, &ordering); err != nil {
+		return ""
+	}
+	return ordering.GroupID
+}
+
+// SetEpisodeOrdering saves episode group of the show, empty group resets to aired order
+func (d *StormDatabase) SetEpisodeOrdering(showID int, groupID string) error {
+	defer perf.ScopeTimer()()
+
+	if groupID == "" {
+		if err := d.db.Delete(EpisodeOrderingBucket, showID); err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		return nil
+	}
+
+	return d.db.Save(&EpisodeOrdering{ShowID: showID, GroupID: groupID})
+}
+
+// GetArtwork returns art of the show, chosen by the user, or nil
+func (d *StormDatabase) GetArtwork(showID int) *Artwork {
+	defer perf.ScopeTimer()()
+
+	var artwork Artwork
+	if err := d.db.One("ShowID", showID, &artwork); err != nil {
+		return nil
+	}
+	return &artwork
+}
+
+// SetArtwork saves art of the show, artwork without any art resets to automatic choice
+func (d *StormDatabase) SetArtwork(artwork *Artwork) error {
+	defer perf.ScopeTimer()()
+
+	if artwork.Poster == "" && artwork.FanArt == "" && artwork.ClearLogo == "" {
+		if err := d.db.Delete(ArtworkBucket, artwork.ShowID); err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		return nil
+	}
+
+	return d.db.Save(artwork)
+}
+
+// AddDigestMessage keeps notification to be shown in the next digest
+func (d *StormDatabase) AddDigestMessage(message string) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&DigestMessage{Message: message, Created: time.Now()})
+}
+
+// TakeDigestMessages returns kept notifications, oldest first, and removes them
+func (d *StormDatabase) TakeDigestMessages() (ret []DigestMessage) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.All(&ret); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not get digest messages: %s", err)
+		return nil
+	}
+	for i := range ret {
+		d.db.DeleteStruct(&ret[i])
+	}
+	return
+}
+
+// GetExternalIDs returns stored IDs of the item, found by IDs field, like "IMDB", and its value
+func (d *StormDatabase) GetExternalIDs(kind string, field string, value interface{}) *ExternalIDs {
+	defer perf.ScopeTimer()()
+
+	var ids ExternalIDs
+	if err := d.db.Select(q.Eq("Kind", kind), q.Eq(field, value)).First(&ids); err != nil {
+		return nil
+	}
+	return &ids
+}
+
+// SaveExternalIDs merges IDs with already stored IDs of the same item, known IDs are not overwritten
+func (d *StormDatabase) SaveExternalIDs(ids *ExternalIDs) error {
+	defer perf.ScopeTimer()()
+
+	if ids == nil || ids.Kind == "" {
+		return errors.New("kind of IDs is not set")
+	}
+
+	var stored *ExternalIDs
+	if ids.TMDB != 0 {
+		stored = d.GetExternalIDs(ids.Kind, "TMDB", ids.TMDB)
+	}
+	if stored == nil && ids.IMDB != "" {
+		stored = d.GetExternalIDs(ids.Kind, "IMDB", ids.IMDB)
+	}
+	if stored == nil && ids.TVDB != 0 {
+		stored = d.GetExternalIDs(ids.Kind, "TVDB", ids.TVDB)
+	}
+	if stored == nil && ids.Trakt != 0 {
+		stored = d.GetExternalIDs(ids.Kind, "Trakt", ids.Trakt)
+	}
+	if stored == nil {
+		ids.Pk = 0
+		return d.db.Save(ids)
+	}
+
+	changed := false
+	if stored.TMDB == 0 && ids.TMDB != 0 {
+		stored.TMDB, changed = ids.TMDB, true
+	}
+	if stored.IMDB == "" && ids.IMDB != "" {
+		stored.IMDB, changed = ids.IMDB, true
+	}
+	if stored.TVDB == 0 && ids.TVDB != 0 {
+		stored.TVDB, changed = ids.TVDB, true
+	}
+	if stored.Trakt == 0 && ids.Trakt != 0 {
+		stored.Trakt, changed = ids.Trakt, true
+	}
+	*ids = *stored
+
+	if !changed {
+		return nil
+	}
+	return d.db.Save(stored)
+}
+
 // CleanupTorrentLink ...
 func (d *StormDatabase) CleanupTorrentLink(infoHash string) {
 	defer perf.ScopeTimer()()
@@ -299,9 +646,67 @@ func (d *StormDatabase) UpdateBTItemFiles(infoHash string, files []string) error
 func (d *StormDatabase) DeleteBTItem(infoHash string) error {
 	defer perf.ScopeTimer()()
 
+	d.ClearEpisodeFiles(infoHash)
 	return d.db.Delete(BTItemBucket, infoHash)
 }
 
+// GetEpisodeFiles returns files of the torrent, assigned by the user to episodes
+func (d *StormDatabase) GetEpisodeFiles(infoHash string) (ret []EpisodeFile) {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.Select(q.Eq("InfoHash", infoHash)).Find(&ret); err != nil && err != storm.ErrNotFound {
+		log.Debugf("Could not get episode files of %s: %s", infoHash, err)
+	}
+	return
+}
+
+// GetEpisodeFile returns path of the file, assigned to the episode, or empty string
+func (d *StormDatabase) GetEpisodeFile(infoHash string, season, episode int) string {
+	defer perf.ScopeTimer()()
+
+	var f EpisodeFile
+	if err := d.db.One("ID", episodeFileID(infoHash, season, episode), &f); err != nil {
+		return ""
+	}
+	return f.Path
+}
+
+// SetEpisodeFile assigns the file to the episode, file is unassigned from other episodes,
+// zero episode only removes assignment of the file
+func (d *StormDatabase) SetEpisodeFile(infoHash string, season, episode int, path string) error {
+	defer perf.ScopeTimer()()
+
+	var old []EpisodeFile
+	d.db.Select(q.Eq("InfoHash", infoHash), q.Eq("Path", path)).Find(&old)
+	for _, f := range old {
+		d.db.DeleteStruct(&f)
+	}
+
+	if episode <= 0 {
+		return nil
+	}
+	return d.db.Save(&EpisodeFile{
+		ID:       episodeFileID(infoHash, season, episode),
+		InfoHash: infoHash,
+		Season:   season,
+		Episode:  episode,
+		Path:     path,
+	})
+}
+
+// ClearEpisodeFiles removes all assignments of the torrent files
+func (d *StormDatabase) ClearEpisodeFiles(infoHash string) {
+	defer perf.ScopeTimer()()
+
+	for _, f := range d.GetEpisodeFiles(infoHash) {
+		d.db.DeleteStruct(&f)
+	}
+}
+
+func episodeFileID(infoHash string, season, episode int) string {
+	return fmt.Sprintf("%s.%d.%d", infoHash, season, episode)
+}
+
 // AddTorrentHistory saves last used torrent
 func (d *StormDatabase) AddTorrentHistory(infoHash, name string, b []byte) {
 	defer perf.ScopeTimer()()
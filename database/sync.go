@@ -0,0 +1,309 @@
+package database
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/asdine/storm"
+
+	"github.com/projectx13/projectx/config"
+)
+
+const (
+	syncFileName    = "projectx-sync.json"
+	syncInterval    = 30 * time.Minute
+	syncManifestKey = "sync.manifest"
+
+	syncLibraryPrefix   = "library/"
+	syncTombstonePrefix = "tombstone/"
+	syncCachePrefix     = "cache/"
+)
+
+// syncCacheKeys are prefixes of cache keys, holding watched state, which are shared between devices
+var syncCacheKeys = []string{"stored.watched_file.", "stored.resume."}
+
+var (
+	// SyncBucket keeps state of the last sync, it is not a part of CacheBuckets, so items never expire
+	SyncBucket = []byte("Sync")
+
+	syncMu sync.Mutex
+)
+
+// SyncEntry is a single synced key, Modified is used to pick the latest
+// version of the key, when it was changed on several devices
+type SyncEntry struct {
+	Value    json.RawMessage `json:"value,omitempty"`
+	Deleted  bool            `json:"deleted,omitempty"`
+	Modified int64           `json:"modified"`
+}
+
+// syncManifestEntry remembers local state of the key after the last sync,
+// so that local changes and deletions get a new modification time
+type syncManifestEntry struct {
+	Hash     string `json:"hash"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	Modified int64  `json:"modified"`
+}
+
+func syncHash(value []byte) string {
+	sum := sha1.Sum(value)
+	return hex.EncodeToString(sum[:])
+}
+
+// SyncHandler pulls shared state from WebDAV on startup and pushes it periodically
+func SyncHandler() {
+	if config.Get().SyncURL == "" {
+		return
+	}
+
+	if err := SyncWithRemote(); err != nil {
+		log.Warningf("Could not sync state with remote: %s", err)
+	}
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if config.Get().SyncURL == "" {
+			return
+		}
+		if err := SyncWithRemote(); err != nil {
+			log.Warningf("Could not sync state with remote: %s", err)
+		}
+	}
+}
+
+// SyncWithRemote merges local library and watched state with the state
+// on WebDAV endpoint, using last-write-wins per key, then applies
+// remote changes locally and uploads merged state
+func SyncWithRemote() error {
+	defer perf.ScopeTimer()()
+
+	conf := config.Get()
+	if conf.SyncURL == "" || stormDatabase == nil || cacheDatabase == nil || IsReadOnly() {
+		return nil
+	}
+
+	syncMu.Lock()
+	defer syncMu.Unlock()
+
+	remote := &webdavBackup{
+		url:      strings.TrimSuffix(conf.SyncURL, "/") + "/",
+		user:     conf.SyncUser,
+		password: conf.SyncPassword,
+	}
+
+	remoteEntries, err := remote.downloadSync()
+	if err != nil {
+		return err
+	}
+
+	local, err := collectSyncValues()
+	if err != nil {
+		return err
+	}
+	localEntries := updateSyncManifest(local)
+
+	merged := map[string]SyncEntry{}
+	for key, e := range localEntries {
+		merged[key] = e
+	}
+	applied := 0
+	for key, e := range remoteEntries {
+		if l, ok := merged[key]; ok && l.Modified >= e.Modified {
+			continue
+		}
+		merged[key] = e
+
+		if err := applySyncEntry(key, e); err != nil {
+			log.Warningf("Could not apply synced key %s: %s", key, err)
+			continue
+		}
+		applied++
+	}
+
+	// Manifest should reflect applied remote values, to not treat them as local changes
+	manifest := map[string]syncManifestEntry{}
+	for key, e := range merged {
+		m := syncManifestEntry{Deleted: e.Deleted, Modified: e.Modified}
+		if !e.Deleted {
+			m.Hash = syncHash(e.Value)
+		}
+		manifest[key] = m
+	}
+	if err := cacheDatabase.SetObject(SyncBucket, syncManifestKey, manifest); err != nil {
+		log.Warningf("Could not save sync manifest: %s", err)
+	}
+
+	buf, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := remote.Upload(syncFileName, bytes.NewReader(buf)); err != nil {
+		return err
+	}
+
+	log.Infof("Synced %d keys with remote, applied %d remote changes", len(merged), applied)
+	return nil
+}
+
+// collectSyncValues returns current local values of synced keys
+func collectSyncValues() (map[string][]byte, error) {
+	ret := map[string][]byte{}
+
+	var items []LibraryItem
+	if err := stormDatabase.db.All(&items); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	for _, item := range items {
+		if buf, err := json.Marshal(item); err == nil {
+			ret[fmt.Sprintf("%s%d", syncLibraryPrefix, item.ID)] = buf
+		}
+	}
+
+	var tombstones []LibraryTombstone
+	if err := stormDatabase.db.All(&tombstones); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	for _, ts := range tombstones {
+		if buf, err := json.Marshal(ts); err == nil {
+			ret[syncTombstonePrefix+ts.ID] = buf
+		}
+	}
+
+	// Queued writes, like resume points, are not in the store yet
+	if err := cacheDatabase.FlushQueue(); err != nil {
+		return nil, err
+	}
+	for _, prefix := range syncCacheKeys {
+		err := cacheDatabase.store.Seek(CommonBucket, []byte(prefix), func(k []byte, v []byte) error {
+			if buf, err := json.Marshal(v); err == nil {
+				ret[syncCachePrefix+string(k)] = buf
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// updateSyncManifest compares local values with the state after the last sync
+// and returns local entries with modification times of changed keys updated
+func updateSyncManifest(values map[string][]byte) map[string]SyncEntry {
+	manifest := map[string]syncManifestEntry{}
+	cacheDatabase.GetObject(SyncBucket, syncManifestKey, &manifest)
+
+	now := time.Now().Unix()
+	ret := map[string]SyncEntry{}
+	for key, value := range values {
+		modified := now
+		if m, ok := manifest[key]; ok && !m.Deleted && m.Hash == syncHash(value) {
+			modified = m.Modified
+		}
+		ret[key] = SyncEntry{Value: value, Modified: modified}
+	}
+
+	for key, m := range manifest {
+		if _, ok := values[key]; ok {
+			continue
+		}
+
+		modified := m.Modified
+		if !m.Deleted {
+			modified = now
+		}
+		ret[key] = SyncEntry{Deleted: true, Modified: modified}
+	}
+
+	return ret
+}
+
+func applySyncEntry(key string, e SyncEntry) error {
+	switch {
+	case strings.HasPrefix(key, syncLibraryPrefix):
+		var item LibraryItem
+		if e.Deleted {
+			id, err := strconv.Atoi(strings.TrimPrefix(key, syncLibraryPrefix))
+			if err != nil {
+				return err
+			}
+			if err := stormDatabase.db.One("ID", id, &item); err != nil {
+				return nil
+			}
+			return stormDatabase.db.DeleteStruct(&item)
+		}
+		if err := json.Unmarshal(e.Value, &item); err != nil {
+			return err
+		}
+		return stormDatabase.db.Save(&item)
+
+	case strings.HasPrefix(key, syncTombstonePrefix):
+		var ts LibraryTombstone
+		if e.Deleted {
+			if err := stormDatabase.db.One("ID", strings.TrimPrefix(key, syncTombstonePrefix), &ts); err != nil {
+				return nil
+			}
+			return stormDatabase.db.DeleteStruct(&ts)
+		}
+		if err := json.Unmarshal(e.Value, &ts); err != nil {
+			return err
+		}
+		return stormDatabase.db.Save(&ts)
+
+	case strings.HasPrefix(key, syncCachePrefix):
+		cacheKey := strings.TrimPrefix(key, syncCachePrefix)
+		if e.Deleted {
+			return cacheDatabase.Delete(CommonBucket, cacheKey)
+		}
+		var value []byte
+		if err := json.Unmarshal(e.Value, &value); err != nil {
+			return err
+		}
+		return cacheDatabase.SetBytes(CommonBucket, cacheKey, value)
+	}
+
+	return nil
+}
+
+// downloadSync reads shared state, missing file is treated as empty state
+func (b *webdavBackup) downloadSync() (map[string]SyncEntry, error) {
+	ret := map[string]SyncEntry{}
+
+	req, err := http.NewRequest("GET", b.url+syncFileName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if b.user != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+
+	resp, err := webdavClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ret, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", req.URL.Path, resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DBTransaction gives access to multiple buckets inside a single update transaction,
+// it is valid only inside WithTransaction callback
+type DBTransaction struct {
+	tx StoreTx
+}
+
+// WithTransaction runs fn in a single update transaction, so that
+// read-modify-write sequences across buckets are atomic.
+// All changes are discarded if fn returns an error.
+func (d *BoltDatabase) WithTransaction(fn func(tx *DBTransaction) error) error {
+	return d.store.Update(func(tx StoreTx) error {
+		return fn(&DBTransaction{tx: tx})
+	})
+}
+
+func (t *DBTransaction) bucket(bucket []byte) (StoreBucket, error) {
+	b := t.tx.Bucket(bucket)
+	if b == nil {
+		return nil, errBucketNotFound
+	}
+	return b, nil
+}
+
+// Has checks for existence of a key
+func (t *DBTransaction) Has(bucket []byte, key string) bool {
+	value, _ := t.GetBytes(bucket, key)
+	return len(value) > 0
+}
+
+// GetBytes returns a copy of the value, or nil if key does not exist
+func (t *DBTransaction) GetBytes(bucket []byte, key string) ([]byte, error) {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := b.Get([]byte(key)); v != nil {
+		return append([]byte{}, v...), nil
+	}
+	return nil, nil
+}
+
+// Get ...
+func (t *DBTransaction) Get(bucket []byte, key string) (string, error) {
+	value, err := t.GetBytes(bucket, key)
+	return string(value), err
+}
+
+// GetObject ...
+func (t *DBTransaction) GetObject(bucket []byte, key string, item interface{}) error {
+	v, err := t.GetBytes(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if len(v) == 0 {
+		return errors.New("Bytes empty")
+	}
+
+	return json.Unmarshal(v, &item)
+}
+
+// SetBytes ...
+func (t *DBTransaction) SetBytes(bucket []byte, key string, value []byte) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+// Set ...
+func (t *DBTransaction) Set(bucket []byte, key string, value string) error {
+	return t.SetBytes(bucket, key, []byte(value))
+}
+
+// SetObject ...
+func (t *DBTransaction) SetObject(bucket []byte, key string, item interface{}) error {
+	buf, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return t.SetBytes(bucket, key, buf)
+}
+
+// Delete ...
+func (t *DBTransaction) Delete(bucket []byte, key string) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete([]byte(key))
+}
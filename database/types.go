@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/asdine/storm"
-	"github.com/boltdb/bolt"
 	"github.com/op/go-logging"
 )
 
@@ -14,14 +13,18 @@ import (
 type StormDatabase struct {
 	db             *storm.DB
 	quit           chan struct{}
+	dir            string
 	fileName       string
 	backupFileName string
 }
 
 // BoltDatabase ...
 type BoltDatabase struct {
-	db             *bolt.DB
+	store          Store
+	queue          *writeQueue
+	expiry         *expiryIndex
 	quit           chan struct{}
+	dir            string
 	fileName       string
 	backupFileName string
 }
@@ -67,6 +70,87 @@ type LibraryItem struct {
 	ShowID    int `storm:"index"`
 }
 
+// LibraryTombstone marks an item that user explicitly removed from the library
+// or watchlist, so that Trakt syncs do not bring it back
+type LibraryTombstone struct {
+	ID        string `storm:"id"`
+	TmdbID    int    `storm:"index"`
+	MediaType int    `storm:"index"`
+	Title     string
+	Dt        time.Time `storm:"index"`
+}
+
+// LibraryFailure is a library item, which metadata or strm files could not be written on sync,
+// it is retried with growing delay, until it succeeds or is dismissed by the user
+type LibraryFailure struct {
+	ID        string `storm:"id"`
+	TmdbID    int    `storm:"index"`
+	MediaType int    `storm:"index"`
+	Title     string
+	Error     string
+	Attempts  int
+	LastTry   time.Time
+	NextTry   time.Time `storm:"index"`
+}
+
+// FailedSearch is a provider search, which has not returned any acceptable result,
+// it is kept with searched item attributes for the coverage report of providers
+type FailedSearch struct {
+	ID         int    `storm:"id,increment"`
+	MediaType  string `storm:"index"`
+	TmdbID     int
+	Title      string
+	Year       int
+	Language   string
+	Resolution string
+	Stage      string
+	Providers  map[string]int
+	Received   int
+	Resolved   int
+	Dt         time.Time `storm:"index"`
+}
+
+// EpisodeOrdering is TMDB episode group, chosen by the user to number episodes of the show
+type EpisodeOrdering struct {
+	ShowID  int `storm:"id"`
+	GroupID string
+}
+
+// EpisodeFile is a file of season pack, assigned by the user to the episode,
+// when automatic matching fails
+type EpisodeFile struct {
+	ID       string `storm:"id"`
+	InfoHash string `storm:"index"`
+	Season   int
+	Episode  int
+	Path     string
+}
+
+// DigestMessage is a low-priority notification, kept to be shown in the notifications digest
+type DigestMessage struct {
+	ID      int `storm:"id,increment"`
+	Message string
+	Created time.Time
+}
+
+// Artwork is art of the show, chosen by the user from TMDB images, kept as TMDB file paths
+type Artwork struct {
+	ShowID    int `storm:"id"`
+	Poster    string
+	FanArt    string
+	ClearLogo string
+}
+
+// ExternalIDs links IDs of the same movie, show or episode in TMDB, IMDB, TVDB and Trakt
+type ExternalIDs struct {
+	Pk    int    `storm:"id,increment"`
+	Kind  string `storm:"index"`
+	TMDB  int    `storm:"index"`
+	IMDB  string `storm:"index"`
+	TVDB  int    `storm:"index"`
+	Trakt int    `storm:"index"`
+}
+
 // QueryHistory ...
 type QueryHistory struct {
 	ID    string    `storm:"id"`
@@ -113,6 +197,9 @@ var (
 	cacheDatabase *BoltDatabase
 	stormDatabase *StormDatabase
 
+	// cacheMemoryDatabase shares in-memory layer with cacheDatabase
+	cacheMemoryDatabase *BoltDatabase
+
 	once sync.Once
 )
 
@@ -151,4 +238,28 @@ const (
 
 	// QueryHistoryBucket ...
 	QueryHistoryBucket = "QueryHistory"
+
+	// LibraryTombstoneBucket ...
+	LibraryTombstoneBucket = "LibraryTombstone"
+
+	// LibraryFailureBucket ...
+	LibraryFailureBucket = "LibraryFailure"
+
+	// FailedSearchBucket ...
+	FailedSearchBucket = "FailedSearch"
+
+	// EpisodeOrderingBucket ...
+	EpisodeOrderingBucket = "EpisodeOrdering"
+
+	// ExternalIDsBucket ...
+	ExternalIDsBucket = "ExternalIDs"
+
+	// ArtworkBucket ...
+	ArtworkBucket = "Artwork"
+
+	// EpisodeFileBucket ...
+	EpisodeFileBucket = "EpisodeFile"
+
+	// DigestMessageBucket ...
+	DigestMessageBucket = "DigestMessage"
 )
@@ -0,0 +1,118 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	// startDelay gives Kodi time to start, before digest of the previous session is shown
+	startDelay    = 30 * time.Second
+	checkInterval = time.Minute
+
+	dateFormat = "2006-01-02"
+)
+
+var (
+	log = logging.MustGetLogger("digest")
+
+	closer    = util.Event{}
+	shownDate string
+)
+
+// Notify shows low-priority notification, like library sync results or finished downloads.
+// With digest enabled it is kept, and shown later together with others, instead of interrupting playback.
+func Notify(message string) {
+	if !config.Get().NotificationDigest {
+		xbmc.Notify("projectx", message, config.AddonIcon())
+		return
+	}
+
+	log.Debugf("Keeping notification for digest: %s", message)
+	if err := database.GetStorm().AddDigestMessage(message); err != nil {
+		log.Warningf("Could not keep notification for digest: %s", err)
+		xbmc.Notify("projectx", message, config.AddonIcon())
+	}
+}
+
+// Start shows notifications, kept in the previous session, and then shows digest at configured hour.
+// Notifications, kept before digest was disabled, are shown on start as well.
+func Start() {
+	go func() {
+		closing := closer.C()
+
+		select {
+		case <-closing:
+			return
+		case <-time.After(startDelay):
+		}
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		started := false
+		for {
+			if !started {
+				started = show()
+			} else if isDue() && show() {
+				shownDate = time.Now().Format(dateFormat)
+			}
+
+			select {
+			case <-closing:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends digest scheduling
+func Stop() {
+	closer.Set()
+}
+
+// isDue checks whether configured hour has come and digest was not shown today yet,
+// digest, postponed by playback, is shown after it ends
+func isDue() bool {
+	if !config.Get().NotificationDigest {
+		return false
+	}
+
+	now := time.Now()
+	return now.Hour() >= config.Get().NotificationDigestHour && now.Format(dateFormat) != shownDate
+}
+
+// show displays kept notifications as one summary, unless something is played.
+// Returns false, if digest is postponed.
+func show() bool {
+	if xbmc.PlayerIsPlaying() {
+		return false
+	}
+
+	messages := database.GetStorm().TakeDigestMessages()
+	if len(messages) == 0 {
+		return true
+	}
+
+	log.Infof("Showing digest of %d notifications", len(messages))
+	if len(messages) == 1 {
+		xbmc.Notify("projectx", messages[0].Message, config.AddonIcon())
+		return true
+	}
+
+	lines := make([]string, 0, len(messages))
+	for _, m := range messages {
+		lines = append(lines, fmt.Sprintf("[B]%s[/B]  %s", m.Created.Format("Jan 2 15:04"), xbmc.Localize(m.Message)))
+	}
+	xbmc.DialogText(fmt.Sprintf("LOCALIZE[30818];;%d", len(messages)), strings.Join(lines, "\n"))
+	return true
+}
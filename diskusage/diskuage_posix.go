@@ -13,9 +13,11 @@ func DiskUsage(path string) (*DiskStatus, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Blocks, reserved for root, are not available for us,
+	// that matters on Android, where storage is emulated.
 	status := &DiskStatus{
 		All:  int64(fs.Blocks) * int64(fs.Bsize),
-		Free: int64(fs.Bfree) * int64(fs.Bsize),
+		Free: int64(fs.Bavail) * int64(fs.Bsize),
 	}
 	status.Used = status.All - status.Free
 	return status, nil
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/projectx13/projectx/broadcast"
+)
+
+// Events are sent to external UIs, connected to /ws, so they react on changes of torrents,
+// playback and library, instead of polling. Every event is {"type": "...", "time": <unix>, "data": {...}}.
+
+// Event types
+const (
+	TorrentAdded     = "torrent.added"
+	TorrentProgress  = "torrent.progress"
+	TorrentCompleted = "torrent.completed"
+	TorrentRemoved   = "torrent.removed"
+	Buffering        = "playback.buffering"
+	PlaybackStarted  = "playback.started"
+	PlaybackStopped  = "playback.stopped"
+	LibraryUpdated   = "library.updated"
+)
+
+var (
+	log = logging.MustGetLogger("events")
+
+	broadcaster = broadcast.NewBroadcaster()
+	listeners   int32
+)
+
+// Event is a message, sent to listeners
+type Event struct {
+	Type string      `json:"type"`
+	Time int64       `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Torrent is data of torrent events, rates are in bytes per second
+type Torrent struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Status       string  `json:"status,omitempty"`
+	Progress     float64 `json:"progress"`
+	DownloadRate int     `json:"download_rate"`
+	UploadRate   int     `json:"upload_rate"`
+}
+
+// Playback is data of buffering and playback events
+type Playback struct {
+	TorrentID   string  `json:"torrent_id"`
+	File        string  `json:"file"`
+	ContentType string  `json:"content_type,omitempty"`
+	TMDBID      int     `json:"tmdb_id,omitempty"`
+	Season      int     `json:"season,omitempty"`
+	Episode     int     `json:"episode,omitempty"`
+	Progress    float64 `json:"progress"`
+	WatchedTime float64 `json:"watched_time,omitempty"`
+	Duration    float64 `json:"duration,omitempty"`
+}
+
+// Library is data of library events
+type Library struct {
+	Reason string `json:"reason"`
+	Added  int    `json:"added,omitempty"`
+}
+
+// HasListeners returns true, when somebody listens to events,
+// so periodic events are not prepared for nobody
+func HasListeners() bool {
+	return atomic.LoadInt32(&listeners) > 0
+}
+
+// Publish sends the event to all listeners
+func Publish(eventType string, data interface{}) {
+	if !HasListeners() {
+		return
+	}
+
+	log.Debugf("Publishing %s event", eventType)
+	broadcaster.Write(&Event{
+		Type: eventType,
+		Time: time.Now().Unix(),
+		Data: data,
+	})
+}
+
+// Listen returns channel of events, and function, which stops listening
+func Listen() (<-chan interface{}, func()) {
+	atomic.AddInt32(&listeners, 1)
+	vc, cc := broadcaster.Listen()
+
+	return vc, func() {
+		atomic.AddInt32(&listeners, -1)
+		close(cc)
+		// Receiver could be blocked on sending, until it notices closing
+		go func() {
+			for range vc {
+			}
+		}()
+	}
+}
@@ -35,7 +35,7 @@ var (
 	cacheExpiration         = 14 * 24 * time.Hour
 )
 
-var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
+var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections).WithPool(util.MetadataPool)
 
 // Movie ...
 type Movie struct {
@@ -0,0 +1,80 @@
+package ids
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/projectx13/projectx/tmdb"
+)
+
+// Kinds of items, see tmdb.IDKindMovie
+const (
+	Movie   = tmdb.IDKindMovie
+	Show    = tmdb.IDKindShow
+	Episode = tmdb.IDKindEpisode
+)
+
+// Resolve returns all known IDs of the item, ID is prefixed with its source, like "tvdb:121361",
+// "trakt:1390" or "tmdb:1399". IMDB IDs are recognized without prefix, like "tt0944947",
+// and plain numbers are treated as TMDB IDs.
+func Resolve(kind string, id string) *tmdb.IDs {
+	source, value := Parse(id)
+	if source == "" {
+		return nil
+	}
+	return tmdb.ResolveIDs(kind, source, value)
+}
+
+// TMDB returns TMDB ID of the item, or 0 if it could not be resolved
+func TMDB(kind string, id string) int {
+	if r := Resolve(kind, id); r != nil {
+		return r.TMDB
+	}
+	return 0
+}
+
+// IMDB formats IMDB ID for Resolve
+func IMDB(id string) string {
+	return tmdb.IDSourceIMDB + ":" + id
+}
+
+// TVDB formats TVDB ID for Resolve
+func TVDB(id int) string {
+	return tmdb.IDSourceTVDB + ":" + strconv.Itoa(id)
+}
+
+// Trakt formats Trakt ID for Resolve
+func Trakt(id int) string {
+	return tmdb.IDSourceTrakt + ":" + strconv.Itoa(id)
+}
+
+// Parse splits ID into source and value, empty source is returned for unknown IDs
+func Parse(id string) (source string, value string) {
+	id = strings.TrimSpace(id)
+	if idx := strings.Index(id, ":"); idx > 0 {
+		source, value = strings.ToLower(id[:idx]), id[idx+1:]
+		switch source {
+		case tmdb.IDSourceTMDB, tmdb.IDSourceIMDB, tmdb.IDSourceTVDB, tmdb.IDSourceTrakt:
+			return source, value
+		}
+		return "", ""
+	}
+
+	if strings.HasPrefix(id, "tt") {
+		return tmdb.IDSourceIMDB, id
+	} else if _, err := strconv.Atoi(id); err == nil {
+		return tmdb.IDSourceTMDB, id
+	}
+	return "", ""
+}
+
+// Store links IDs of the item, taken from other services, like Trakt
+func Store(kind string, tmdbID int, imdbID string, tvdbID int, traktID int) {
+	tmdb.StoreIDs(&tmdb.IDs{
+		Kind:  kind,
+		TMDB:  tmdbID,
+		IMDB:  imdbID,
+		TVDB:  tvdbID,
+		Trakt: traktID,
+	})
+}
@@ -0,0 +1,107 @@
+package library
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// retryFailedInterval is how often failed library items are checked for the next attempt
+const retryFailedInterval = 15 * time.Minute
+
+var retryFailedMu sync.Mutex
+
+// markFailed remembers library item, which could not be written, to retry it later
+func markFailed(tmdbID, mediaType int, title string, err error) {
+	f := database.GetStorm().AddLibraryFailure(tmdbID, mediaType, title, err)
+	log.Warningf("Could not write library item %d (%s), attempt %d, next one at %s: %s", tmdbID, title, f.Attempts, f.NextTry.Format(time.RFC3339), err)
+}
+
+// markWritten forgets failures of library item, which was written successfully
+func markWritten(tmdbID, mediaType int) {
+	database.GetStorm().RemoveLibraryFailure(tmdbID, mediaType)
+}
+
+// RetryFailedItem writes failed library item again
+func RetryFailedItem(tmdbID, mediaType int) error {
+	retryFailedMu.Lock()
+	defer retryFailedMu.Unlock()
+
+	return retryFailed(tmdbID, mediaType, "")
+}
+
+// RetryFailedItems writes library items, which failed on previous syncs, when their next attempt is due,
+// or all of them, if forced by the user
+func RetryFailedItems(force bool) (added int, failed int) {
+	retryFailedMu.Lock()
+	defer retryFailedMu.Unlock()
+
+	var items []database.LibraryFailure
+	if force {
+		items = database.GetStorm().GetLibraryFailures()
+	} else {
+		items = database.GetStorm().GetDueLibraryFailures()
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	log.Infof("Retrying %d failed library items", len(items))
+	for _, f := range items {
+		if err := retryFailed(f.TmdbID, f.MediaType, f.Title); err != nil {
+			failed++
+		} else {
+			added++
+		}
+	}
+
+	if added > 0 {
+		PlanKodiUpdate()
+	}
+	return
+}
+
+func retryFailed(tmdbID, mediaType int, title string) (err error) {
+	if database.GetStorm().IsTombstoned(tmdbID, mediaType) {
+		markWritten(tmdbID, mediaType)
+		return nil
+	}
+
+	switch mediaType {
+	case MovieType:
+		if err = checkMoviesPath(); err != nil {
+			return err
+		}
+		if _, err = writeMovieStrm(strconv.Itoa(tmdbID), false); err == nil {
+			err = updateDBItem(tmdbID, StateActive, MovieType, 0)
+		}
+	case ShowType:
+		if err = checkShowsPath(); err != nil {
+			return err
+		}
+		if _, err = writeShowStrm(tmdbID, true, false); err == nil {
+			err = updateDBItem(tmdbID, StateActive, ShowType, tmdbID)
+		}
+	default:
+		err = fmt.Errorf("Unknown media type %d", mediaType)
+	}
+
+	if err != nil {
+		markFailed(tmdbID, mediaType, title, err)
+		return err
+	}
+
+	log.Noticef("Failed library item %d is written", tmdbID)
+	markWritten(tmdbID, mediaType)
+	return nil
+}
+
+// retryFailedAllowed checks that library sync is allowed at the moment
+func retryFailedAllowed() bool {
+	return config.Get().LibraryEnabled && config.Get().LibrarySyncEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying())
+}
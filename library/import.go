@@ -0,0 +1,205 @@
+package library
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/projectx13/projectx/ids"
+	"github.com/projectx13/projectx/proxy"
+	"github.com/projectx13/projectx/tmdb"
+	"github.com/projectx13/projectx/trakt"
+	"github.com/projectx13/projectx/tvdb"
+)
+
+const (
+	// ImportToLibrary adds imported items to the library
+	ImportToLibrary = "library"
+	// ImportToWatchlist adds imported items to Trakt watchlist
+	ImportToWatchlist = "watchlist"
+
+	imdbListExportURL = "https://www.imdb.com/list/%s/export"
+)
+
+var imdbListRegexp = regexp.MustCompile(`^ls\d+$`)
+
+// ImportResult is a summary of the import
+type ImportResult struct {
+	Added    int
+	Skipped  int
+	NotFound int
+}
+
+// importItem is an item, mapped to TMDB, before it is added
+type importItem struct {
+	mediaType int
+	tmdbID    int
+}
+
+// ImportIMDbList imports public IMDb list, like ls012345678, or a path to CSV file,
+// exported from IMDb lists, watchlist or ratings pages
+func ImportIMDbList(list string, target string) (*ImportResult, error) {
+	defer perf.ScopeTimer()()
+
+	var r io.ReadCloser
+	if imdbListRegexp.MatchString(list) {
+		resp, err := proxy.GetClient().Get(fmt.Sprintf(imdbListExportURL, list))
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("Bad status getting IMDb list %s: %s", list, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(list)
+		if err != nil {
+			return nil, err
+		}
+		r = f
+	}
+	defer r.Close()
+
+	return ImportIMDbCSV(r, target)
+}
+
+// ImportIMDbCSV imports items from IMDb CSV export,
+// items are matched by "Const" column, holding IMDb id
+func ImportIMDbCSV(r io.Reader, target string) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read IMDb CSV header: %s", err)
+	}
+
+	constIdx, typeIdx := -1, -1
+	for i, h := range header {
+		switch strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")) {
+		case "Const":
+			constIdx = i
+		case "Title Type":
+			typeIdx = i
+		}
+	}
+	if constIdx < 0 {
+		return nil, fmt.Errorf("IMDb CSV does not have Const column")
+	}
+
+	result := &ImportResult{}
+	items := []importItem{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Could not read IMDb CSV: %s", err)
+		}
+		if constIdx >= len(record) || !strings.HasPrefix(record[constIdx], "tt") {
+			continue
+		}
+
+		titleType := ""
+		if typeIdx >= 0 && typeIdx < len(record) {
+			titleType = strings.ToLower(record[typeIdx])
+		}
+
+		if item := findIMDbItem(record[constIdx], titleType); item != nil {
+			items = append(items, *item)
+		} else {
+			log.Debugf("Could not find TMDB item for IMDb %s", record[constIdx])
+			result.NotFound++
+		}
+	}
+
+	importItems(items, target, result)
+	return result, nil
+}
+
+func findIMDbItem(imdbID string, titleType string) *importItem {
+	found := tmdb.Find(imdbID, "imdb_id")
+	if found == nil {
+		return nil
+	}
+
+	isShow := strings.HasPrefix(titleType, "tv") && titleType != "tvmovie" && titleType != "tv movie"
+	if !isShow && len(found.MovieResults) > 0 {
+		return &importItem{mediaType: MovieType, tmdbID: found.MovieResults[0].ID}
+	} else if len(found.TVResults) > 0 {
+		return &importItem{mediaType: ShowType, tmdbID: found.TVResults[0].ID}
+	} else if len(found.MovieResults) > 0 {
+		return &importItem{mediaType: MovieType, tmdbID: found.MovieResults[0].ID}
+	}
+
+	return nil
+}
+
+// ImportTVDBFavorites imports favorite series of TVDB account
+func ImportTVDBFavorites(accountID string, target string) (*ImportResult, error) {
+	defer perf.ScopeTimer()()
+
+	favorites, err := tvdb.GetFavorites(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	items := []importItem{}
+	for _, id := range favorites {
+		tmdbID := ids.TMDB(ids.Show, ids.TVDB(id))
+		if tmdbID == 0 {
+			log.Debugf("Could not find TMDB item for TVDB %d", id)
+			result.NotFound++
+			continue
+		}
+
+		items = append(items, importItem{mediaType: ShowType, tmdbID: tmdbID})
+	}
+
+	importItems(items, target, result)
+	return result, nil
+}
+
+// importItems feeds mapped items to the library or Trakt watchlist
+func importItems(items []importItem, target string, result *ImportResult) {
+	for _, item := range items {
+		tmdbID := strconv.Itoa(item.tmdbID)
+
+		var err error
+		if target == ImportToWatchlist {
+			itemType := "movies"
+			if item.mediaType == ShowType {
+				itemType = "shows"
+			}
+			_, err = trakt.AddToWatchlist(itemType, tmdbID)
+		} else if item.mediaType == ShowType {
+			if IsDuplicateShow(tmdbID) {
+				result.Skipped++
+				continue
+			}
+			_, err = AddShow(tmdbID, false)
+		} else {
+			if IsDuplicateMovie(tmdbID) {
+				result.Skipped++
+				continue
+			}
+			_, err = AddMovie(tmdbID, false)
+		}
+
+		if err != nil {
+			log.Warningf("Could not import TMDB %s: %s", tmdbID, err)
+			result.Skipped++
+			continue
+		}
+		result.Added++
+	}
+}
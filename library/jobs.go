@@ -0,0 +1,237 @@
+package library
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/events"
+	"github.com/projectx13/projectx/trakt"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// Library job states
+const (
+	JobQueued = iota
+	JobRunning
+	JobDone
+	JobFailed
+)
+
+// JobStateNames are names of job states for JSON API and logs
+var JobStateNames = []string{"queued", "running", "done", "failed"}
+
+// jobsKeep is how long finished jobs stay in the list
+const jobsKeep = time.Hour
+
+// Job is an addition of a movie or a show to the library, which runs in background,
+// so adding many items does not block Kodi, waiting for TMDB and strm files one by one
+type Job struct {
+	ID        int
+	MediaType int
+	TmdbID    string
+	Title     string
+	Force     bool
+	State     int
+	Error     string
+	Created   time.Time
+	Finished  time.Time
+}
+
+// Active returns true, when the job is not finished yet
+func (j *Job) Active() bool {
+	return j.State == JobQueued || j.State == JobRunning
+}
+
+var jobs = struct {
+	sync.Mutex
+
+	lastID int
+	list   []*Job
+	slots  chan struct{}
+
+	// batch collects results of jobs, finished since the queue was empty
+	batch []*Job
+}{}
+
+// AddJob queues addition of the movie or the show to the library, and returns the job.
+// When the same item is queued already, its job is returned.
+func AddJob(mediaType int, tmdbID string, force bool) Job {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	if jobs.slots == nil {
+		jobs.slots = make(chan struct{}, util.GetResources().Workers)
+	}
+
+	for _, j := range jobs.list {
+		if j.Active() && j.MediaType == mediaType && j.TmdbID == tmdbID {
+			return *j
+		}
+	}
+
+	list := jobs.list[:0]
+	for _, j := range jobs.list {
+		if j.Active() || time.Since(j.Finished) < jobsKeep {
+			list = append(list, j)
+		}
+	}
+	jobs.list = list
+
+	jobs.lastID++
+	job := &Job{
+		ID:        jobs.lastID,
+		MediaType: mediaType,
+		TmdbID:    tmdbID,
+		Force:     force,
+		State:     JobQueued,
+		Created:   time.Now(),
+	}
+	jobs.list = append(jobs.list, job)
+
+	go runJob(job)
+	return *job
+}
+
+// Jobs returns queued, running and recently finished jobs, newest first
+func Jobs() []Job {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	ret := make([]Job, 0, len(jobs.list))
+	for _, j := range jobs.list {
+		if j.Active() || time.Since(j.Finished) < jobsKeep {
+			ret = append(ret, *j)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID > ret[j].ID
+	})
+	return ret
+}
+
+func runJob(job *Job) {
+	jobs.slots <- struct{}{}
+	defer func() { <-jobs.slots }()
+
+	jobs.Lock()
+	job.State = JobRunning
+	jobs.Unlock()
+
+	title, err := addJobItem(job.MediaType, job.TmdbID, job.Force)
+
+	jobs.Lock()
+	job.Title = title
+	job.Finished = time.Now()
+	if err != nil {
+		job.State = JobFailed
+		job.Error = err.Error()
+		log.Warningf("Library job %d for %s failed: %s", job.ID, job.TmdbID, err)
+	} else {
+		job.State = JobDone
+	}
+	jobs.batch = append(jobs.batch, job)
+
+	var batch []*Job
+	if !hasActiveJobs() {
+		batch = jobs.batch
+		jobs.batch = nil
+	}
+	jobs.Unlock()
+
+	if batch != nil {
+		finishJobs(batch)
+	}
+}
+
+func hasActiveJobs() bool {
+	for _, j := range jobs.list {
+		if j.Active() {
+			return true
+		}
+	}
+	return false
+}
+
+// addJobItem adds the item to the library, and to Trakt list, when it is synced
+func addJobItem(mediaType int, tmdbID string, force bool) (title string, err error) {
+	if mediaType == ShowType {
+		show, err := AddShow(tmdbID, force)
+		if show != nil {
+			title = show.Name
+		}
+		if err != nil {
+			return title, err
+		}
+		if config.Get().TraktToken != "" && config.Get().TraktSyncAddedShows {
+			go trakt.SyncAddedItem("shows", tmdbID, config.Get().TraktSyncAddedShowsLocation)
+		}
+	} else {
+		movie, err := AddMovie(tmdbID, force)
+		if movie != nil {
+			title = movie.Title
+		}
+		if err != nil {
+			return title, err
+		}
+		if config.Get().TraktToken != "" && config.Get().TraktSyncAddedMovies {
+			go trakt.SyncAddedItem("movies", tmdbID, config.Get().TraktSyncAddedMoviesLocation)
+		}
+	}
+
+	log.Noticef("%s (%s) added to library", title, tmdbID)
+	return title, nil
+}
+
+// finishJobs reports results of jobs, finished together, and scans the library once for all of them
+func finishJobs(batch []*Job) {
+	added := []*Job{}
+	failed := 0
+	for _, j := range batch {
+		if j.State == JobDone {
+			added = append(added, j)
+		} else {
+			failed++
+		}
+	}
+
+	if len(batch) > 1 || failed > 0 {
+		xbmc.Notify("projectx", xbmc.Localizef(30827, len(added), failed), config.AddonIcon())
+	}
+	if len(added) == 0 {
+		return
+	}
+	events.Publish(events.LibraryUpdated, &events.Library{Reason: "added", Added: len(added)})
+
+	label := "LOCALIZE[30288]"
+	if len(added) == 1 {
+		label = fmt.Sprintf("LOCALIZE[30277];;%s", added[0].Title)
+		if added[0].Force {
+			label = fmt.Sprintf("LOCALIZE[30286];;%s", added[0].Title)
+		}
+	}
+
+	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("projectx", label)) {
+		movies, shows := false, false
+		for _, j := range added {
+			if j.MediaType == ShowType {
+				shows = true
+			} else {
+				movies = true
+			}
+		}
+
+		if movies && shows {
+			xbmc.VideoLibraryScan()
+		} else if shows {
+			xbmc.VideoLibraryScanDirectory(ShowsLibraryPath(), true)
+		} else {
+			xbmc.VideoLibraryScanDirectory(MoviesLibraryPath(), true)
+		}
+	} else {
+		ClearPageCache()
+	}
+}
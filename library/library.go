@@ -20,6 +20,8 @@ import (
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/digest"
+	"github.com/projectx13/projectx/ids"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/trakt"
 	"github.com/projectx13/projectx/util"
@@ -278,7 +280,7 @@ func Init() {
 	tmdb.WarmingUp.Set()
 	took := time.Since(started)
 	if took.Seconds() > 30 {
-		xbmc.Notify("projectx", "LOCALIZE[30148]", config.AddonIcon())
+		digest.Notify("LOCALIZE[30148]")
 	}
 	log.Noticef("Caches warmed up in %s", took)
 
@@ -289,11 +291,13 @@ func Init() {
 	traktSyncTicker := time.NewTicker(time.Duration(traktFrequency) * time.Minute)
 	markedForRemovalTicker := time.NewTicker(30 * time.Second)
 	watcherTicker := time.NewTicker(1 * time.Second)
+	retryFailedTicker := time.NewTicker(retryFailedInterval)
 
 	defer updateTicker.Stop()
 	defer traktSyncTicker.Stop()
 	defer markedForRemovalTicker.Stop()
 	defer watcherTicker.Stop()
+	defer retryFailedTicker.Stop()
 
 	closing := closer.C()
 
@@ -327,6 +331,14 @@ func Init() {
 			}
 		case <-traktSyncTicker.C:
 			PlanTraktUpdate()
+		case <-retryFailedTicker.C:
+			if retryFailedAllowed() {
+				go func() {
+					if added, failed := RetryFailedItems(false); added > 0 {
+						digest.Notify(xbmc.Localizef(30777, added, failed))
+					}
+				}()
+			}
 		case <-markedForRemovalTicker.C:
 			var items []database.BTItem
 			database.GetStormDB().Select(q.Eq("State", database.StatusRemove)).Find(&items)
@@ -393,7 +405,10 @@ func updateLibraryShows() error {
 
 		if _, err := writeShowStrm(i.ShowID, false, false); err != nil {
 			log.Errorf("Error updating show: %s", err)
+			markFailed(i.ShowID, ShowType, "", err)
+			continue
 		}
+		markWritten(i.ShowID, ShowType)
 	}
 
 	log.Infof("Library updated in %s", time.Since(begin))
@@ -933,6 +948,11 @@ func updateBatchDBItem(tmdbIds []int, state int, mediaType int, showID int) erro
 func deleteDBItem(tmdbID int, mediaType int, removal bool) error {
 	defer perf.ScopeTimer()()
 
+	if removal {
+		// Removed items should not be written again by retries
+		markWritten(tmdbID, mediaType)
+	}
+
 	var li database.LibraryItem
 	if err := database.GetStormDB().One("ID", tmdbID, &li); err != nil {
 		log.Debugf("Cannot find deleted item: %s", err)
@@ -1103,10 +1123,7 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 		title := movie.Movie.Title
 		// Try to resolve TMDB id through IMDB id, if provided
 		if movie.Movie.IDs.TMDB == 0 && len(movie.Movie.IDs.IMDB) > 0 {
-			r := tmdb.Find(movie.Movie.IDs.IMDB, "imdb_id")
-			if r != nil && len(r.MovieResults) > 0 {
-				movie.Movie.IDs.TMDB = r.MovieResults[0].ID
-			}
+			movie.Movie.IDs.TMDB = ids.TMDB(ids.Movie, ids.IMDB(movie.Movie.IDs.IMDB))
 		}
 
 		if movie.Movie.IDs.TMDB == 0 {
@@ -1120,13 +1137,20 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 			continue
 		}
 
+		if database.GetStorm().IsTombstoned(movie.Movie.IDs.TMDB, MovieType) {
+			log.Debugf("Skipping %s, it was removed by the user", title)
+			continue
+		}
+
 		if IsDuplicateMovie(tmdbID) {
 			continue
 		}
 
 		if _, err := writeMovieStrm(tmdbID, false); err != nil {
+			markFailed(movie.Movie.IDs.TMDB, MovieType, title, err)
 			continue
 		}
+		markWritten(movie.Movie.IDs.TMDB, MovieType)
 
 		movieIDs = append(movieIDs, movie.Movie.IDs.TMDB)
 	}
@@ -1209,16 +1233,10 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 		// Try to resolve TMDB id through IMDB id, if provided
 		if show.Show.IDs.TMDB == 0 {
 			if len(show.Show.IDs.IMDB) > 0 {
-				r := tmdb.Find(show.Show.IDs.IMDB, "imdb_id")
-				if r != nil && len(r.TVResults) > 0 {
-					show.Show.IDs.TMDB = r.TVResults[0].ID
-				}
+				show.Show.IDs.TMDB = ids.TMDB(ids.Show, ids.IMDB(show.Show.IDs.IMDB))
 			}
 			if show.Show.IDs.TMDB == 0 && show.Show.IDs.TVDB != 0 {
-				r := tmdb.Find(strconv.Itoa(show.Show.IDs.TVDB), "tvdb_id")
-				if r != nil && len(r.TVResults) > 0 {
-					show.Show.IDs.TMDB = r.TVResults[0].ID
-				}
+				show.Show.IDs.TMDB = ids.TMDB(ids.Show, ids.TVDB(show.Show.IDs.TVDB))
 			}
 		}
 
@@ -1227,6 +1245,11 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 			continue
 		}
 
+		if database.GetStorm().IsTombstoned(show.Show.IDs.TMDB, ShowType) {
+			log.Debugf("Skipping %s, it was removed by the user", title)
+			continue
+		}
+
 		tmdbID := strconv.Itoa(show.Show.IDs.TMDB)
 		if t, ok := showsLastUpdates[show.Show.IDs.Trakt]; ok && IsDuplicateShow(tmdbID) && !t.Before(show.Show.UpdatedAt) {
 			continue
@@ -1238,8 +1261,10 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 		}
 
 		if _, err := writeShowStrm(show.Show.IDs.TMDB, false, false); err != nil {
+			markFailed(show.Show.IDs.TMDB, ShowType, title, err)
 			continue
 		}
+		markWritten(show.Show.IDs.TMDB, ShowType)
 
 		showIDs = append(showIDs, show.Show.IDs.TMDB)
 	}
@@ -1323,6 +1348,7 @@ func AddMovie(tmdbID string, force bool) (*tmdb.Movie, error) {
 	if err := updateDBItem(ID, StateActive, MovieType, 0); err != nil {
 		return movie, err
 	}
+	database.GetStorm().RemoveTombstone(ID, MovieType)
 
 	log.Noticef("%s added to library", movie.Title)
 	return movie, nil
@@ -1345,6 +1371,7 @@ func AddShow(tmdbID string, force bool) (*tmdb.Show, error) {
 	if err := updateDBItem(ID, StateActive, ShowType, ID); err != nil {
 		return show, err
 	}
+	database.GetStorm().RemoveTombstone(ID, ShowType)
 
 	if _, err := writeShowStrm(ID, true, force); err != nil {
 		log.Errorf("Error writing strm for a show: %s", err)
@@ -16,6 +16,8 @@ import (
 	"github.com/karrick/godirwalk"
 
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/events"
+	"github.com/projectx13/projectx/ids"
 	"github.com/projectx13/projectx/playcount"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/trakt"
@@ -73,6 +75,7 @@ func Refresh() error {
 	}
 
 	log.Debugf("Library refresh finished in %s", time.Since(now))
+	events.Publish(events.LibraryUpdated, &events.Library{Reason: "refresh"})
 	return nil
 }
 
@@ -596,14 +599,18 @@ func parseUniqueID(entityType int, i *UniqueIDs, xbmcIDs *xbmc.UniqueIDs, fileNa
 	}
 
 	// If we get here - we have no TMDB, so try to resolve it
+	kind := ids.Movie
+	if entityType == ShowType {
+		kind = ids.Show
+	}
 	if len(i.IMDB) != 0 {
-		i.TMDB = findTMDBIDs(entityType, "imdb_id", i.IMDB)
+		i.TMDB = ids.TMDB(kind, ids.IMDB(i.IMDB))
 		if i.TMDB != 0 {
 			return
 		}
 	}
 	if i.TVDB != 0 {
-		i.TMDB = findTMDBIDs(entityType, "tvdb_id", strconv.Itoa(i.TVDB))
+		i.TMDB = ids.TMDB(kind, ids.TVDB(i.TVDB))
 		if i.TMDB != 0 {
 			return
 		}
@@ -758,21 +765,6 @@ func findTMDBIDsWithYear(entityType int, source string, id string, year int) int
 	return reserveID
 }
 
-func findTMDBIDs(entityType int, source string, id string) int {
-	results := tmdb.Find(id, source)
-	if results != nil {
-		if entityType == MovieType && len(results.MovieResults) == 1 && results.MovieResults[0] != nil {
-			return results.MovieResults[0].ID
-		} else if entityType == ShowType && len(results.TVResults) == 1 && results.TVResults[0] != nil {
-			return results.TVResults[0].ID
-		} else if entityType == EpisodeType && len(results.TVEpisodeResults) == 1 && results.TVEpisodeResults[0] != nil {
-			return results.TVEpisodeResults[0].ID
-		}
-	}
-
-	return 0
-}
-
 func findTraktIDs(entityType int, source int, id string) (ids *trakt.IDs) {
 	switch entityType {
 	case MovieType:
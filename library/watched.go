@@ -0,0 +1,76 @@
+package library
+
+import (
+	"sort"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/trakt"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// SetMoviesWatched marks movies as watched or unwatched in Kodi library and on Trakt,
+// and updates watched state, shown in lists
+func SetMoviesWatched(tmdbIDs []int, watched bool) (err error) {
+	if len(tmdbIDs) == 0 {
+		return nil
+	}
+
+	for _, id := range tmdbIDs {
+		m, errFind := GetMovieByTMDB(id)
+		if errFind != nil || m.IsWatched() == watched {
+			continue
+		}
+
+		if watched {
+			m.UIDs.Playcount = 1
+			xbmc.SetMovieWatched(m.UIDs.Kodi, 1, 0, 0)
+		} else {
+			m.UIDs.Playcount = 0
+			xbmc.SetMoviePlaycount(m.UIDs.Kodi, 0)
+		}
+	}
+
+	if config.Get().TraktToken != "" {
+		items := make([]*trakt.WatchedItem, 0, len(tmdbIDs))
+		for _, id := range tmdbIDs {
+			items = append(items, &trakt.WatchedItem{
+				MediaType: "movie",
+				Movie:     id,
+				Watched:   watched,
+			})
+		}
+
+		if _, err = trakt.SetMultipleWatched(items); err != nil {
+			log.Warningf("Could not set watched state of %d movies on Trakt: %s", len(items), err)
+		} else {
+			// Watched state from Trakt is reloaded, UIDs are refreshed after that
+			go RefreshTraktWatched(MovieType, true)
+		}
+	}
+
+	RefreshUIDsRunner(true)
+	return
+}
+
+// GetWatchedMovies returns TMDB IDs of watched library movies, recently added first,
+// since Kodi library is not asked for the time movies were played
+func GetWatchedMovies() []int {
+	l.mu.Movies.RLock()
+	movies := make([]*Movie, 0, len(l.Movies))
+	for _, m := range l.Movies {
+		if m != nil && m.UIDs != nil && m.UIDs.TMDB != 0 && m.IsWatched() {
+			movies = append(movies, m)
+		}
+	}
+	l.mu.Movies.RUnlock()
+
+	sort.Slice(movies, func(i int, j int) bool {
+		return movies[i].DateAdded.After(movies[j].DateAdded)
+	})
+
+	ret := make([]int, 0, len(movies))
+	for _, m := range movies {
+		ret = append(ret, m.UIDs.TMDB)
+	}
+	return ret
+}
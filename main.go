@@ -19,10 +19,13 @@ import (
 
 	"github.com/projectx13/projectx/api"
 	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/digest"
 	"github.com/projectx13/projectx/library"
 	"github.com/projectx13/projectx/lockfile"
+	"github.com/projectx13/projectx/mock"
 	"github.com/projectx13/projectx/scrape"
 	"github.com/projectx13/projectx/trakt"
 	"github.com/projectx13/projectx/util"
@@ -71,6 +74,12 @@ func main() {
 	now := time.Now()
 
 	tagflag.Parse(&config.Args)
+	if config.Args.MockPath == "" {
+		config.Args.MockPath = os.Getenv("PROJECTX_MOCK")
+	}
+	if config.Args.MockPath != "" {
+		mock.Enable(config.Args.MockPath, config.Args.MockRecord)
+	}
 
 	// Make sure we are properly multithreaded.
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -82,6 +91,8 @@ func main() {
 
 	log.Infof("Starting projectx daemon")
 	log.Infof("Version: %s LibTorrent: %s Go: %s, Threads: %d", util.GetVersion(), util.GetTorrentVersion(), runtime.Version(), runtime.GOMAXPROCS(0))
+	// Search and torrent limits are scaled by the device profile
+	util.GetResources()
 
 	conf := config.Reload()
 	xbmc.KodiVersion = conf.Platform.Kodi
@@ -107,6 +118,12 @@ func main() {
 		return
 	}
 
+	if err := database.RunMigrations(); err != nil {
+		log.Error(err)
+	}
+	cache.UpgradeNamespaces()
+	database.RestoreClock()
+
 	s := bittorrent.NewService()
 
 	var shutdown = func(fromSignal bool) {
@@ -118,6 +135,7 @@ func main() {
 
 		log.Info("Shutting down...")
 		library.CloseLibrary()
+		digest.Stop()
 		s.Close(true)
 
 		db.Close()
@@ -154,6 +172,7 @@ func main() {
 	}))
 	http.Handle("/debug/all", bittorrent.DebugAll(s))
 	http.Handle("/debug/bundle", bittorrent.DebugBundle(s))
+	http.Handle("/debug/metrics", database.MetricsHandler())
 
 	http.Handle("/files/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Connection", "close")
@@ -206,11 +225,13 @@ func main() {
 		xbmc.ResetRPC()
 	}()
 
+	go database.SyncHandler()
 	go library.Init()
 	go trakt.TokenRefreshHandler()
 	go db.MaintenanceRefreshHandler()
 	go cacheDb.MaintenanceRefreshHandler()
 	go scrape.Start()
+	go digest.Start()
 
 	log.Infof("Prepared in %s", time.Since(now))
 	log.Infof("Starting HTTP server")
@@ -0,0 +1,201 @@
+package mock
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/op/go-logging"
+)
+
+// Mock mode serves canned fixtures instead of network services, so API routes and library sync
+// could be tested without network, and bugs could be reproduced with the same responses.
+//
+// Fixtures directory has:
+//   http/<host>/<path>.json            responses of TMDB, Trakt, Fanart.tv, TVDB and AniList
+//   http/<host>/<path>~<hash>.json     responses of requests with query or body, hash is logged for missing ones
+//   providers/<addon id>/<method>.json results of providers, like "search_movie"
+//   swarm/<infohash>.torrent           torrent, which is added instead of the magnet
+//   swarm/<infohash>/                  files of the torrent, which are complete right after adding
+
+var (
+	log = logging.MustGetLogger("mock")
+
+	fixturesPath string
+	record       bool
+)
+
+// secretParams are not part of fixture names, so fixtures do not depend on user keys
+var secretParams = map[string]bool{
+	"api_key": true,
+	"apikey":  true,
+	"token":   true,
+}
+
+// Enable turns mock mode on with fixtures from the path. With record, missing fixtures are requested
+// from network and saved, to prepare fixtures for a scenario.
+func Enable(path string, recordMissing bool) {
+	fixturesPath = path
+	record = recordMissing
+
+	log.Noticef("Mock mode is enabled with fixtures in %s", path)
+	http.DefaultTransport = &transport{next: http.DefaultTransport}
+}
+
+// Enabled returns true, when network services are replaced with fixtures
+func Enabled() bool {
+	return fixturesPath != ""
+}
+
+// Path returns path inside fixtures directory
+func Path(parts ...string) string {
+	return filepath.Join(append([]string{fixturesPath}, parts...)...)
+}
+
+// ProviderResults returns results of the provider method, like "search_episode", as providers send them
+func ProviderResults(addonID string, method string) []byte {
+	data, err := ioutil.ReadFile(Path("providers", addonID, method+".json"))
+	if err != nil {
+		log.Warningf("Missing provider fixture %s/%s", addonID, method)
+		return []byte("[]")
+	}
+	return data
+}
+
+// Providers returns IDs of providers, which have fixtures
+func Providers() []string {
+	ret := []string{}
+	entries, err := ioutil.ReadDir(Path("providers"))
+	if err != nil {
+		return ret
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			ret = append(ret, e.Name())
+		}
+	}
+	return ret
+}
+
+// SwarmTorrent returns path of fixture torrent file, which has metadata of the magnet, or empty string
+func SwarmTorrent(infoHash string) string {
+	path := Path("swarm", strings.ToLower(infoHash)+".torrent")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// SwarmFiles returns directory with fixture files of the torrent, or empty string
+func SwarmFiles(infoHash string) string {
+	path := Path("swarm", strings.ToLower(infoHash))
+	if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// transport answers HTTP requests with fixtures
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name, err := fixtureName(req)
+	if err != nil {
+		return nil, err
+	}
+
+	path := Path("http", name)
+	if data, err := ioutil.ReadFile(path); err == nil {
+		log.Debugf("Serving %s from fixture %s", req.URL, name)
+		return response(req, http.StatusOK, data), nil
+	}
+
+	if !record {
+		log.Warningf("Missing fixture %s for %s", name, req.URL)
+		return response(req, http.StatusNotFound, []byte("{}")), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			log.Warningf("Could not record fixture %s: %s", name, err)
+		} else {
+			log.Infof("Recorded fixture %s for %s", name, req.URL)
+		}
+	}
+	return response(req, resp.StatusCode, data), nil
+}
+
+// fixtureName returns name of the fixture for the request, by host and path,
+// query without secrets and body are hashed into the name, when they are present
+func fixtureName(req *http.Request) (string, error) {
+	path := strings.Trim(req.URL.Path, "/")
+	if path == "" {
+		path = "index"
+	}
+	name := filepath.Join(req.URL.Host, filepath.FromSlash(path))
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if !secretParams[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	h := sha1.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s&", k, strings.Join(query[k], ","))
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		// Body is read again by the next transport, when fixture is recorded
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+		if len(body) > 0 {
+			keys = append(keys, "body")
+		}
+	}
+
+	if len(keys) == 0 {
+		return name + ".json", nil
+	}
+	return fmt.Sprintf("%s~%s.json", name, hex.EncodeToString(h.Sum(nil))[:12]), nil
+}
+
+func response(req *http.Request, status int, data []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}
+}
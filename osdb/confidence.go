@@ -0,0 +1,70 @@
+package osdb
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Confidence of subtitles, in percents, by what OpenSubtitles matched them with
+const (
+	// ConfidenceHash is for subtitles, uploaded for exactly the same file, they are in sync
+	ConfidenceHash = 100
+	// ConfidenceRelease is for subtitles of the same release name, they are in sync mostly
+	ConfidenceRelease = 80
+	// ConfidenceIMDb is for subtitles of the same movie or episode, but maybe another cut or framerate
+	ConfidenceIMDb = 50
+	// ConfidenceText is for subtitles, found by title only
+	ConfidenceText = 30
+)
+
+var releaseSeparators = regexp.MustCompile(`[\s._\-\[\]()]+`)
+
+// normalizeRelease returns release name without extension, case and separators
+func normalizeRelease(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.Trim(releaseSeparators.ReplaceAllString(strings.ToLower(name), "."), ".")
+}
+
+// Confidence returns how likely the subtitles are in sync with the playing file, in percents
+func (s *Subtitle) Confidence(fileName string) int {
+	ret := ConfidenceText
+	switch s.MatchedBy {
+	case "moviehash":
+		ret = ConfidenceHash
+	case "tag":
+		ret = ConfidenceRelease
+	case "imdbid":
+		ret = ConfidenceIMDb
+	}
+
+	if ret < ConfidenceRelease && fileName != "" && s.MovieReleaseName != "" && normalizeRelease(s.MovieReleaseName) == normalizeRelease(filepath.Base(fileName)) {
+		ret = ConfidenceRelease
+	}
+	// Subtitles, reported as bad by users, are never trusted enough to be loaded silently
+	if s.SubBad != "" && s.SubBad != "0" {
+		ret /= 2
+	}
+	return ret
+}
+
+// SortByConfidence orders subtitles by languages, in order of preference, like "fre,eng",
+// and by confidence for subtitles of the same language
+func (subs Subtitles) SortByConfidence(fileName string, languages []string) {
+	rank := func(s *Subtitle) int {
+		for i, lang := range languages {
+			if s.SubLanguageID == lang {
+				return i
+			}
+		}
+		return len(languages)
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool {
+		if ri, rj := rank(&subs[i]), rank(&subs[j]); ri != rj {
+			return ri < rj
+		}
+		return subs[i].Confidence(fileName) > subs[j].Confidence(fileName)
+	})
+}
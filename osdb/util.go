@@ -59,6 +59,11 @@ func DoDownload(file, dl string) (*os.File, string, error) {
 	}
 	defer reader.Close()
 
+	return SaveSubtitles(file, reader)
+}
+
+// SaveSubtitles writes subtitles file into Subtitles folder, which is created if missing
+func SaveSubtitles(file string, reader io.Reader) (*os.File, string, error) {
 	subtitlesPath := filepath.Join(config.Get().DownloadPath, "Subtitles")
 	if config.Get().DownloadPath == "." {
 		subtitlesPath = filepath.Join(config.Get().TemporaryPath, "Subtitles")
@@ -0,0 +1,122 @@
+package parental
+
+import (
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/tmdb"
+)
+
+// unlockDuration is how long parental control stays off, after PIN is entered
+const unlockDuration = time.Hour
+
+var (
+	log = logging.MustGetLogger("parental")
+
+	mu            sync.RWMutex
+	unlockedUntil time.Time
+)
+
+// IsActive checks whether listings and playback are filtered by age rating
+func IsActive() bool {
+	return config.Get().ParentalControl && !IsUnlocked()
+}
+
+// IsUnlocked checks whether correct PIN was entered recently
+func IsUnlocked() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return time.Now().Before(unlockedUntil)
+}
+
+// Unlock turns parental control off for unlockDuration, if PIN is correct.
+// Parental control without PIN could not be unlocked, it could be only disabled in settings.
+func Unlock(pin string) bool {
+	if config.Get().ParentalPIN == "" || pin != config.Get().ParentalPIN {
+		log.Warning("Wrong parental control PIN entered")
+		return false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlockedUntil = time.Now().Add(unlockDuration)
+	log.Infof("Parental control unlocked until %s", unlockedUntil.Format("15:04"))
+	return true
+}
+
+// Lock turns parental control back on
+func Lock() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlockedUntil = time.Time{}
+}
+
+// AllowMovie checks whether movie is not rated above the limit from settings
+func AllowMovie(movie *tmdb.Movie) bool {
+	if !IsActive() {
+		return true
+	}
+
+	country := tmdb.CertificationCountry()
+	return isAllowed(tmdb.GetMovieCertifications(country), movie.Certification(country), config.Get().ParentalMovieRating)
+}
+
+// AllowShow checks whether show is not rated above the limit from settings
+func AllowShow(show *tmdb.Show) bool {
+	if !IsActive() {
+		return true
+	}
+
+	country := tmdb.CertificationCountry()
+	return isAllowed(tmdb.GetTVCertifications(country), show.Certification(country), config.Get().ParentalShowRating)
+}
+
+// AllowMovieID checks movie by TMDB ID, for listings, which do not come from TMDB
+func AllowMovieID(tmdbID int) bool {
+	if !IsActive() {
+		return true
+	}
+	return AllowMovie(tmdb.GetMovie(tmdbID, config.Get().Language))
+}
+
+// AllowShowID checks show by TMDB ID, for listings, which do not come from TMDB
+func AllowShowID(tmdbID int) bool {
+	if !IsActive() {
+		return true
+	}
+	return AllowShow(tmdb.GetShow(tmdbID, config.Get().Language))
+}
+
+// isAllowed compares certification with the limit by their order in the country system,
+// unrated items are allowed, unless they are blocked in settings
+func isAllowed(certifications []*tmdb.Certification, certification string, limit string) bool {
+	if limit == "" {
+		return true
+	}
+
+	limitOrder := -1
+	order := -1
+	for _, c := range certifications {
+		if c.Certification == limit {
+			limitOrder = c.Order
+		}
+		if c.Certification == certification {
+			order = c.Order
+		}
+	}
+
+	if limitOrder < 0 {
+		// Mistyped limit should not open everything
+		log.Warningf("Unknown age rating limit %s in %s system", limit, tmdb.CertificationCountry())
+		return false
+	} else if certification == "" || order < 0 {
+		return !config.Get().ParentalBlockUnrated
+	}
+	return order <= limitOrder
+}
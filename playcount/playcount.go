@@ -59,6 +59,16 @@ func GetWatchedMovieByTMDB(id int) (ret WatchedState) {
 	return searchForKey(xxhash.Sum64String(fmt.Sprintf("%d_%d_%d", MovieType, TMDBScraper, id)))
 }
 
+// CountWatchedMoviesByTMDB returns number of watched movies from the list
+func CountWatchedMoviesByTMDB(ids []int) (watched int) {
+	for _, id := range ids {
+		if GetWatchedMovieByTMDB(id) {
+			watched++
+		}
+	}
+	return
+}
+
 // GetWatchedMovieByIMDB checks whether item is watched
 func GetWatchedMovieByIMDB(id string) (ret WatchedState) {
 	return searchForKey(xxhash.Sum64String(fmt.Sprintf("%d_%d_%s", MovieType, IMDBScraper, id)))
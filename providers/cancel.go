@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"sync"
+)
+
+var (
+	searchCancelMu sync.Mutex
+	searchCancel   = make(chan struct{})
+)
+
+// searchCanceled returns channel, which is closed when searches,
+// started before the call, are canceled
+func searchCanceled() <-chan struct{} {
+	searchCancelMu.Lock()
+	defer searchCancelMu.Unlock()
+
+	return searchCancel
+}
+
+// CancelSearches stops waiting for providers and resolving links
+// of all running searches, used when user dismisses search dialog in Kodi
+func CancelSearches() {
+	searchCancelMu.Lock()
+	defer searchCancelMu.Unlock()
+
+	log.Info("Canceling running searches")
+	close(searchCancel)
+	searchCancel = make(chan struct{})
+}
+
+func isCanceled(canceled <-chan struct{}) bool {
+	select {
+	case <-canceled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,212 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/tmdb"
+)
+
+// Stages of the search, where all results were lost
+const (
+	stageProviders   = "providers"
+	stageResolve     = "resolve"
+	stagePostProcess = "postprocess"
+)
+
+const coverageReportTop = 5
+
+var stageSuggestions = map[string]string{
+	stageProviders:   "Providers returned nothing. Enable more providers, or providers for the languages below.",
+	stageResolve:     "Torrent files could not be resolved. Check proxy settings and provider links.",
+	stagePostProcess: "Post-processing command removed all results. Check its filters.",
+}
+
+// searchReport collects what happened to results of one search,
+// to remember the search, when it ends without acceptable results
+type searchReport struct {
+	mu     sync.Mutex
+	search database.FailedSearch
+}
+
+func newSearchReport(mediaType string, tmdbID int, title string, year int, language string) *searchReport {
+	resolution := config.Get().ResolutionPreferenceMovies
+	if mediaType != "movie" && mediaType != "query" {
+		resolution = config.Get().ResolutionPreferenceShows
+	}
+
+	return &searchReport{
+		search: database.FailedSearch{
+			MediaType:  mediaType,
+			TmdbID:     tmdbID,
+			Title:      title,
+			Year:       year,
+			Language:   language,
+			Resolution: resolutionPreferenceNames[resolution],
+			Providers:  map[string]int{},
+		},
+	}
+}
+
+func newQueryReport(query string) *searchReport {
+	return newSearchReport("query", 0, query, 0, "")
+}
+
+func newMovieReport(movie *tmdb.Movie) *searchReport {
+	return newSearchReport("movie", movie.ID, movie.Title, movie.Year(), movie.OriginalLanguage)
+}
+
+func newShowReport(mediaType string, show *tmdb.Show, title string) *searchReport {
+	year, _ := strconv.Atoi(strings.Split(show.FirstAirDate, "-")[0])
+	return newSearchReport(mediaType, show.ID, title, year, show.OriginalLanguage)
+}
+
+// providerResults counts results, returned by the provider
+func (r *searchReport) providerResults(searcher interface{}, count int) {
+	name := fmt.Sprintf("%T", searcher)
+	if as, ok := searcher.(*AddonSearcher); ok {
+		name = as.addonID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.search.Providers[name] += count
+}
+
+// received counts results, which came from providers, and which were resolved into unique torrents
+func (r *searchReport) received(received, resolved int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.search.Received = received
+	r.search.Resolved = resolved
+}
+
+// finish remembers the search, if it was not canceled and has no results
+func (r *searchReport) finish(torrents []*bittorrent.TorrentFile, canceled <-chan struct{}) []*bittorrent.TorrentFile {
+	if len(torrents) > 0 || isCanceled(canceled) {
+		return torrents
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.search.Received == 0 {
+		r.search.Stage = stageProviders
+	} else if r.search.Resolved == 0 {
+		r.search.Stage = stageResolve
+	} else {
+		r.search.Stage = stagePostProcess
+	}
+
+	log.Infof("Search for %s has no results, lost at %s stage", r.search.Title, r.search.Stage)
+	database.GetStorm().AddFailedSearch(&r.search)
+	return torrents
+}
+
+// CoverageReport describes recent failed searches, grouped by searched item attributes,
+// and suggests which providers or filters are responsible
+func CoverageReport() []string {
+	searches := database.GetStorm().GetFailedSearches()
+	if len(searches) == 0 {
+		return nil
+	}
+
+	stages := map[string]int{}
+	mediaTypes := map[string]int{}
+	languages := map[string]int{}
+	decades := map[string]int{}
+	resolutions := map[string]int{}
+	// Failed searches, where the provider was asked, and how many of them it returned nothing for
+	providerAsked := map[string]int{}
+	providerEmpty := map[string]int{}
+
+	for _, s := range searches {
+		stages[s.Stage]++
+		mediaTypes[s.MediaType]++
+		if s.Language != "" {
+			languages[s.Language]++
+		}
+		if s.Year > 0 {
+			decades[fmt.Sprintf("%ds", s.Year/10*10)]++
+		}
+		if s.Resolution != "" {
+			resolutions[s.Resolution]++
+		}
+		for name, count := range s.Providers {
+			providerAsked[name]++
+			if count == 0 {
+				providerEmpty[name]++
+			}
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Failed searches: %d, since %s", len(searches), searches[len(searches)-1].Dt.Format("2006-01-02")),
+		"",
+		"Lost at stage:",
+	}
+	lines = append(lines, countLines(stages, 0)...)
+	lines = append(lines, "", "Media type:")
+	lines = append(lines, countLines(mediaTypes, 0)...)
+	lines = append(lines, "", "Original language:")
+	lines = append(lines, countLines(languages, coverageReportTop)...)
+	lines = append(lines, "", "Release decade:")
+	lines = append(lines, countLines(decades, coverageReportTop)...)
+	lines = append(lines, "", "Resolution preference:")
+	lines = append(lines, countLines(resolutions, 0)...)
+
+	lines = append(lines, "", "Providers, returned nothing:")
+	for _, name := range sortedKeys(providerAsked) {
+		lines = append(lines, fmt.Sprintf("  %s: %d of %d", name, providerEmpty[name], providerAsked[name]))
+	}
+
+	lines = append(lines, "", "Suggestions:")
+	for _, stage := range sortedKeys(stages) {
+		if stages[stage]*2 >= len(searches) {
+			lines = append(lines, "  "+stageSuggestions[stage])
+		}
+	}
+	for _, name := range sortedKeys(providerAsked) {
+		if providerAsked[name] > 1 && providerEmpty[name] == providerAsked[name] {
+			lines = append(lines, fmt.Sprintf("  %s has not returned anything in any failed search, check whether it works.", name))
+		}
+	}
+	if top := sortedKeys(languages); len(top) > 0 && languages[top[0]]*2 >= len(searches) {
+		lines = append(lines, fmt.Sprintf("  Most failed searches are for titles in \"%s\", add providers covering this language.", top[0]))
+	}
+
+	return lines
+}
+
+// countLines lists counts, largest first, limited to top entries, if limit is positive
+func countLines(counts map[string]int, limit int) []string {
+	ret := []string{}
+	for i, key := range sortedKeys(counts) {
+		if limit > 0 && i >= limit {
+			break
+		}
+		ret = append(ret, fmt.Sprintf("  %s: %d", key, counts[key]))
+	}
+	return ret
+}
+
+// sortedKeys returns keys, ordered by their counts, largest first
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] == counts[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return counts[keys[i]] > counts[keys[j]]
+	})
+	return keys
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+)
+
+const (
+	selectionReasonExpiration = 30 * 24 * 60 * 60
+	selectionReasonRunnersUp  = 3
+)
+
+var (
+	sortModeNames = map[int]string{
+		SortBySeeders:    "by seeders",
+		SortByResolution: "by resolution, then seeders",
+		SortBalanced:     "balanced, seeders with resolution",
+		SortBySize:       "by size",
+	}
+	resolutionPreferenceNames = map[int]string{
+		Sort1080p720p480p: "1080p > 720p > 480p",
+		Sort720p1080p480p: "720p > 1080p > 480p",
+		Sort720p480p1080p: "720p > 480p > 1080p",
+		Sort480p720p1080p: "480p > 720p > 1080p",
+	}
+)
+
+func selectionReasonKey(infoHash string) string {
+	return "selection.reason." + infoHash
+}
+
+func describeTorrent(t *bittorrent.TorrentFile) string {
+	resolution := "unknown"
+	if t.Resolution > 0 && t.Resolution < len(bittorrent.Resolutions) {
+		resolution = bittorrent.Resolutions[t.Resolution]
+	}
+
+	size := t.Size
+	if size == "" {
+		size = "unknown"
+	}
+
+	return fmt.Sprintf("resolution %s, seeds %d, peers %d, size %s, provider %s", resolution, t.Seeds, t.Peers, size, t.Provider)
+}
+
+// explainSelection describes why first torrent of the sorted list is going
+// to be picked by auto-selection, logs the reason and stores it for the context menu
+func explainSelection(torrents []*bittorrent.TorrentFile, sortType int, sortMode int, resolutionPreference int) {
+	if len(torrents) == 0 || torrents[0].InfoHash == "" {
+		return
+	}
+
+	picked := torrents[0]
+	lines := []string{
+		fmt.Sprintf("Picked: %s", picked.Name),
+		fmt.Sprintf("  %s", describeTorrent(picked)),
+		"",
+	}
+
+	mediaType := "movies"
+	if sortType == SortShows {
+		mediaType = "shows"
+	}
+	lines = append(lines, fmt.Sprintf("Sorting for %s: %s", mediaType, sortModeNames[sortMode]))
+	if sortMode == SortByResolution || sortMode == SortBalanced {
+		lines = append(lines, fmt.Sprintf("Resolution preference: %s", resolutionPreferenceNames[resolutionPreference]))
+	}
+	if sortMode == SortBalanced {
+		lines = append(lines, fmt.Sprintf("Balanced seeds: %.0f, including %d%% additional seeders bonus", Balanced(picked), config.Get().PercentageAdditionalSeeders))
+	}
+	lines = append(lines, fmt.Sprintf("Candidates: %d", len(torrents)))
+
+	if len(torrents) > 1 {
+		lines = append(lines, "", "Next candidates:")
+		for i := 1; i < len(torrents) && i <= selectionReasonRunnersUp; i++ {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, torrents[i].Name), fmt.Sprintf("  %s", describeTorrent(torrents[i])))
+		}
+	}
+
+	log.Infof("Auto-selection reason:\n%s", strings.Join(lines, "\n"))
+	database.GetCache().SetCachedObject(database.CommonBucket, selectionReasonExpiration, selectionReasonKey(picked.InfoHash), lines)
+}
+
+// GetSelectionReason returns explanation of why torrent was picked by auto-selection,
+// or nil if it was not auto-selected
+func GetSelectionReason(infoHash string) []string {
+	var lines []string
+	if err := database.GetCache().GetCachedObject(database.CommonBucket, selectionReasonKey(strings.ToLower(infoHash)), &lines); err != nil {
+		return nil
+	}
+	return lines
+}
@@ -65,6 +65,7 @@ type EpisodeSearchObject struct {
 	Year           int               `json:"year"`
 	Titles         map[string]string `json:"titles"`
 	AbsoluteNumber int               `json:"absolute_number"`
+	AniListID      int               `json:"anilist_id,omitempty"`
 }
 
 func (sp *SearchPayload) String() string {
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/projectx13/projectx/bittorrent"
+	"github.com/projectx13/projectx/config"
+)
+
+const postProcessTimeout = 15 * time.Second
+
+// postProcessLinks passes provider results, as JSON array, to the command, selected
+// in the settings, and takes back JSON array of results, which can be filtered,
+// reordered or modified. Results are matched to the originals by URI, so
+// already resolved torrents are kept. On any failure original results are used.
+func postProcessLinks(torrents []*bittorrent.TorrentFile, sortType int) []*bittorrent.TorrentFile {
+	command := strings.TrimSpace(config.Get().ResultsPostProcessCommand)
+	if command == "" || len(torrents) == 0 {
+		return torrents
+	}
+
+	defer perf.ScopeTimer()()
+
+	input, err := json.Marshal(torrents)
+	if err != nil {
+		log.Warningf("Could not encode results for post-processing: %s", err)
+		return torrents
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), postProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	mediaType := "movie"
+	if sortType == SortShows {
+		mediaType = "show"
+	}
+	cmd.Env = append(os.Environ(), "PROJECTX_MEDIA_TYPE="+mediaType)
+	cmd.Stdin = bytes.NewReader(input)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		log.Warningf("Post-processing command failed: %s: %s", err, strings.TrimSpace(stderr.String()))
+		return torrents
+	}
+
+	var processed []*bittorrent.TorrentFile
+	if err := json.Unmarshal(output, &processed); err != nil {
+		log.Warningf("Could not decode post-processing output: %s", err)
+		return torrents
+	}
+
+	originals := map[string]*bittorrent.TorrentFile{}
+	for _, t := range torrents {
+		originals[t.URI] = t
+	}
+
+	ret := make([]*bittorrent.TorrentFile, 0, len(processed))
+	for _, t := range processed {
+		if t == nil || t.URI == "" {
+			continue
+		}
+
+		if orig, ok := originals[t.URI]; ok {
+			orig.Update(t)
+			t = orig
+		}
+		ret = append(ret, t)
+	}
+
+	log.Infof("Post-processing command returned %d of %d results", len(ret), len(torrents))
+	return ret
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"sort"
@@ -52,8 +53,32 @@ var (
 	log            = logging.MustGetLogger("linkssearch")
 )
 
+var (
+	// searchSlots bounds providers, searched at once, since each of them runs Python code in Kodi,
+	// which is heavy for low-power devices
+	searchSlots     chan struct{}
+	searchSlotsOnce sync.Once
+)
+
+// acquireSearchSlot waits for a free search slot, and returns function, which releases it
+func acquireSearchSlot() func() {
+	searchSlotsOnce.Do(func() {
+		if limit := util.GetResources().SearchConcurrency; limit > 0 {
+			searchSlots = make(chan struct{}, limit)
+		}
+	})
+	if searchSlots == nil {
+		return func() {}
+	}
+
+	searchSlots <- struct{}{}
+	return func() { <-searchSlots }
+}
+
 // Search ...
 func Search(searchers []Searcher, query string) []*bittorrent.TorrentFile {
+	report := newQueryReport(query)
+	canceled := searchCanceled()
 	torrentsChan := make(chan *bittorrent.TorrentFile)
 	go func() {
 		wg := sync.WaitGroup{}
@@ -61,8 +86,15 @@ func Search(searchers []Searcher, query string) []*bittorrent.TorrentFile {
 			wg.Add(1)
 			go func(searcher Searcher) {
 				defer wg.Done()
-				for _, torrent := range searcher.SearchLinks(query) {
-					torrentsChan <- torrent
+				defer acquireSearchSlot()()
+				torrents := searcher.SearchLinks(query)
+				report.providerResults(searcher, len(torrents))
+				for _, torrent := range torrents {
+					select {
+					case torrentsChan <- torrent:
+					case <-canceled:
+						return
+					}
 				}
 			}(searcher)
 		}
@@ -70,11 +102,13 @@ func Search(searchers []Searcher, query string) []*bittorrent.TorrentFile {
 		close(torrentsChan)
 	}()
 
-	return processLinks(torrentsChan, SortMovies, false)
+	return report.finish(processLinks(torrentsChan, canceled, SortMovies, false, report), canceled)
 }
 
 // SearchMovie ...
 func SearchMovie(searchers []MovieSearcher, movie *tmdb.Movie) []*bittorrent.TorrentFile {
+	report := newMovieReport(movie)
+	canceled := searchCanceled()
 	torrentsChan := make(chan *bittorrent.TorrentFile)
 	go func() {
 		wg := sync.WaitGroup{}
@@ -82,8 +116,15 @@ func SearchMovie(searchers []MovieSearcher, movie *tmdb.Movie) []*bittorrent.Tor
 			wg.Add(1)
 			go func(searcher MovieSearcher) {
 				defer wg.Done()
-				for _, torrent := range searcher.SearchMovieLinks(movie) {
-					torrentsChan <- torrent
+				defer acquireSearchSlot()()
+				torrents := searcher.SearchMovieLinks(movie)
+				report.providerResults(searcher, len(torrents))
+				for _, torrent := range torrents {
+					select {
+					case torrentsChan <- torrent:
+					case <-canceled:
+						return
+					}
 				}
 			}(searcher)
 		}
@@ -91,11 +132,13 @@ func SearchMovie(searchers []MovieSearcher, movie *tmdb.Movie) []*bittorrent.Tor
 		close(torrentsChan)
 	}()
 
-	return processLinks(torrentsChan, SortMovies, false)
+	return report.finish(processLinks(torrentsChan, canceled, SortMovies, false, report), canceled)
 }
 
 // SearchMovieSilent ...
 func SearchMovieSilent(searchers []MovieSearcher, movie *tmdb.Movie, withAuth bool) []*bittorrent.TorrentFile {
+	report := newMovieReport(movie)
+	canceled := searchCanceled()
 	torrentsChan := make(chan *bittorrent.TorrentFile)
 	go func() {
 		wg := sync.WaitGroup{}
@@ -103,8 +146,15 @@ func SearchMovieSilent(searchers []MovieSearcher, movie *tmdb.Movie, withAuth bo
 			wg.Add(1)
 			go func(searcher MovieSearcher) {
 				defer wg.Done()
-				for _, torrent := range searcher.SearchMovieLinksSilent(movie, withAuth) {
-					torrentsChan <- torrent
+				defer acquireSearchSlot()()
+				torrents := searcher.SearchMovieLinksSilent(movie, withAuth)
+				report.providerResults(searcher, len(torrents))
+				for _, torrent := range torrents {
+					select {
+					case torrentsChan <- torrent:
+					case <-canceled:
+						return
+					}
 				}
 			}(searcher)
 		}
@@ -112,11 +162,13 @@ func SearchMovieSilent(searchers []MovieSearcher, movie *tmdb.Movie, withAuth bo
 		close(torrentsChan)
 	}()
 
-	return processLinks(torrentsChan, SortMovies, true)
+	return report.finish(processLinks(torrentsChan, canceled, SortMovies, true, report), canceled)
 }
 
 // SearchSeason ...
 func SearchSeason(searchers []SeasonSearcher, show *tmdb.Show, season *tmdb.Season) []*bittorrent.TorrentFile {
+	report := newShowReport("season", show, fmt.Sprintf("%s S%02d", show.Name, season.Season))
+	canceled := searchCanceled()
 	torrentsChan := make(chan *bittorrent.TorrentFile)
 	go func() {
 		wg := sync.WaitGroup{}
@@ -124,8 +176,15 @@ func SearchSeason(searchers []SeasonSearcher, show *tmdb.Show, season *tmdb.Seas
 			wg.Add(1)
 			go func(searcher SeasonSearcher) {
 				defer wg.Done()
-				for _, torrent := range searcher.SearchSeasonLinks(show, season) {
-					torrentsChan <- torrent
+				defer acquireSearchSlot()()
+				torrents := searcher.SearchSeasonLinks(show, season)
+				report.providerResults(searcher, len(torrents))
+				for _, torrent := range torrents {
+					select {
+					case torrentsChan <- torrent:
+					case <-canceled:
+						return
+					}
 				}
 			}(searcher)
 		}
@@ -133,11 +192,13 @@ func SearchSeason(searchers []SeasonSearcher, show *tmdb.Show, season *tmdb.Seas
 		close(torrentsChan)
 	}()
 
-	return processLinks(torrentsChan, SortShows, false)
+	return report.finish(processLinks(torrentsChan, canceled, SortShows, false, report), canceled)
 }
 
 // SearchEpisode ...
 func SearchEpisode(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.Episode) []*bittorrent.TorrentFile {
+	report := newShowReport("episode", show, fmt.Sprintf("%s S%02dE%02d", show.Name, episode.SeasonNumber, episode.EpisodeNumber))
+	canceled := searchCanceled()
 	torrentsChan := make(chan *bittorrent.TorrentFile)
 	go func() {
 		wg := sync.WaitGroup{}
@@ -145,8 +206,15 @@ func SearchEpisode(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.E
 			wg.Add(1)
 			go func(searcher EpisodeSearcher) {
 				defer wg.Done()
-				for _, torrent := range searcher.SearchEpisodeLinks(show, episode) {
-					torrentsChan <- torrent
+				defer acquireSearchSlot()()
+				torrents := searcher.SearchEpisodeLinks(show, episode)
+				report.providerResults(searcher, len(torrents))
+				for _, torrent := range torrents {
+					select {
+					case torrentsChan <- torrent:
+					case <-canceled:
+						return
+					}
 				}
 			}(searcher)
 		}
@@ -154,10 +222,34 @@ func SearchEpisode(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.E
 		close(torrentsChan)
 	}()
 
-	return processLinks(torrentsChan, SortShows, false)
+	return report.finish(processLinks(torrentsChan, canceled, SortShows, false, report), canceled)
+}
+
+// receiveLinks passes links from providers, until all providers are done, or search is canceled
+func receiveLinks(torrentsChan chan *bittorrent.TorrentFile, canceled <-chan struct{}) <-chan *bittorrent.TorrentFile {
+	out := make(chan *bittorrent.TorrentFile)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case torrent, ok := <-torrentsChan:
+				if !ok {
+					return
+				}
+				select {
+				case out <- torrent:
+				case <-canceled:
+					return
+				}
+			case <-canceled:
+				return
+			}
+		}
+	}()
+	return out
 }
 
-func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSilent bool) []*bittorrent.TorrentFile {
+func processLinks(torrentsChan chan *bittorrent.TorrentFile, canceled <-chan struct{}, sortType int, isSilent bool, report *searchReport) []*bittorrent.TorrentFile {
 	trackers := map[string]*bittorrent.Tracker{}
 	torrentsMap := map[string]*bittorrent.TorrentFile{}
 
@@ -176,7 +268,7 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 	}()
 
 	wg := sync.WaitGroup{}
-	for torrent := range torrentsChan {
+	for torrent := range receiveLinks(torrentsChan, canceled) {
 		wg.Add(1)
 		if !strings.HasPrefix(torrent.URI, "magnet") {
 			progressTotal++
@@ -200,6 +292,8 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 				select {
 				case <-time.After(trackerTimeout * 2): // Resolve timeout...
 					return
+				case <-canceled:
+					return
 				case <-failed:
 					return
 				case <-resolved:
@@ -245,13 +339,26 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 	}
 
 	wg.Wait()
+	receivedCount := len(torrents)
+
+	if isCanceled(canceled) {
+		log.Info("Search was canceled")
+		if !isSilent {
+			dialogProgressBG.Close()
+		}
+		return []*bittorrent.TorrentFile{}
+	}
 
 	if !isSilent {
 		dialogProgressBG.Update(100, "projectx", "LOCALIZE[30117]")
 	}
 
 	for _, torrent := range torrents {
-		if torrent.InfoHash == "" {
+		if torrent.IsStream() {
+			// Streams have no info hash and trackers, they are only deduplicated by URI
+			torrentsMap["stream|"+torrent.URI] = torrent
+			continue
+		} else if torrent.InfoHash == "" {
 			continue
 		}
 
@@ -299,7 +406,7 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 			trackers[bTracker.URL.Host] = bTracker
 		}
 
-		if torrent.IsPrivate == false {
+		if torrent.IsPrivate == false && !torrent.HasPrivateTracker() {
 			for _, trackerURL := range bittorrent.DefaultTrackers {
 				if tracker, err := bittorrent.NewTracker(trackerURL); err == nil && tracker != nil {
 					trackers[tracker.URL.Host] = tracker
@@ -308,12 +415,18 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 		}
 	}
 
+	// 4K streams would use too much of mobile data
+	skip4K := config.Get().MeteredBlock4K && bittorrent.IsMeteredNetwork()
 	torrents = make([]*bittorrent.TorrentFile, 0, len(torrentsMap))
 	for _, torrent := range torrentsMap {
+		if skip4K && torrent.Resolution >= bittorrent.Resolution4k {
+			continue
+		}
 		torrents = append(torrents, torrent)
 	}
 
 	log.Infof("Received %d unique links.", len(torrents))
+	report.received(receivedCount, len(torrents))
 
 	if len(torrents) == 0 {
 		if !isSilent {
@@ -473,6 +586,8 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 
 	}
 
+	torrents = postProcessLinks(torrents, sortType)
+
 	// Sorting resulting list of torrents
 	conf := config.Get()
 	sortMode := conf.SortingModeMovies
@@ -529,6 +644,8 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 		}
 	}
 
+	explainSelection(torrents, sortType, sortMode, resolutionPreference)
+
 	// log.Info("Sorted torrent candidates.")
 	// for _, torrent := range torrents {
 	// 	log.Infof("S:%d P:%d %s - %s - %s", torrent.Seeds, torrent.Peers, torrent.Name, torrent.Provider, torrent.URI)
@@ -12,6 +12,8 @@ import (
 
 	"github.com/projectx13/projectx/bittorrent"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/ids"
+	"github.com/projectx13/projectx/mock"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/util"
 	"github.com/projectx13/projectx/xbmc"
@@ -74,6 +76,13 @@ func CallbackHandler(ctx *gin.Context) {
 
 func getSearchers() []interface{} {
 	list := make([]interface{}, 0)
+	if mock.Enabled() {
+		for _, id := range mock.Providers() {
+			list = append(list, NewAddonSearcher(id))
+		}
+		return list
+	}
+
 	for _, addon := range xbmc.GetAddons("xbmc.python.script", "executable", true).Addons {
 		if strings.HasPrefix(addon.ID, "script.projectx.") {
 			list = append(list, NewAddonSearcher(addon.ID))
@@ -172,8 +181,15 @@ func (as *AddonSearcher) GetMovieSearchObject(movie *tmdb.Movie) *MovieSearchObj
 		}
 	}
 
+	imdbID := movie.IMDBId
+	if imdbID == "" {
+		if r := ids.Resolve(ids.Movie, strconv.Itoa(movie.ID)); r != nil {
+			imdbID = r.IMDB
+		}
+	}
+
 	sObject := &MovieSearchObject{
-		IMDBId: movie.IMDBId,
+		IMDBId: imdbID,
 		TMDBId: movie.ID,
 		Title:  NormalizeTitle(title),
 		Year:   year,
@@ -235,6 +251,27 @@ func (as *AddonSearcher) GetMovieSearchObject(movie *tmdb.Movie) *MovieSearchObj
 	return sObject
 }
 
+// showExternalIDs returns IMDB and TVDB IDs of the show, resolving them, when TMDB has not returned them
+func showExternalIDs(show *tmdb.Show) (imdbID string, tvdbID int) {
+	if show.ExternalIDs != nil {
+		imdbID = show.ExternalIDs.IMDBId
+		tvdbID = util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
+	}
+	if imdbID != "" && tvdbID != 0 {
+		return
+	}
+
+	if r := ids.Resolve(ids.Show, strconv.Itoa(show.ID)); r != nil {
+		if imdbID == "" {
+			imdbID = r.IMDB
+		}
+		if tvdbID == 0 {
+			tvdbID = r.TVDB
+		}
+	}
+	return
+}
+
 // GetSeasonSearchObject ...
 func (as *AddonSearcher) GetSeasonSearchObject(show *tmdb.Show, season *tmdb.Season) *SeasonSearchObject {
 	year, _ := strconv.Atoi(strings.Split(season.AirDate, "-")[0])
@@ -243,9 +280,11 @@ func (as *AddonSearcher) GetSeasonSearchObject(show *tmdb.Show, season *tmdb.Sea
 		title = show.OriginalName
 	}
 
+	imdbID, tvdbID := showExternalIDs(show)
+
 	sObject := &SeasonSearchObject{
-		IMDBId:     show.ExternalIDs.IMDBId,
-		TVDBId:     util.StrInterfaceToInt(show.ExternalIDs.TVDBID),
+		IMDBId:     imdbID,
+		TVDBId:     tvdbID,
 		ShowTMDBId: show.ID,
 		Title:      NormalizeTitle(title),
 		Titles:     map[string]string{"original": NormalizeTitle(show.OriginalName), "source": show.OriginalName},
@@ -289,11 +328,12 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 		title = show.OriginalName
 	}
 
-	tvdbID := util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
+	imdbID, tvdbID := showExternalIDs(show)
 
 	// Is this an Anime?
 	absoluteNumber := 0
-	if tvdbID > 0 && show.IsAnime() {
+	isAnime := show.IsAnime()
+	if isAnime && (tvdbID > 0 || config.Get().AnimeMetadata) {
 		an, st := show.AnimeInfo(episode)
 
 		if an != 0 {
@@ -305,7 +345,7 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 	}
 
 	sObject := &EpisodeSearchObject{
-		IMDBId:         show.ExternalIDs.IMDBId,
+		IMDBId:         imdbID,
 		TVDBId:         tvdbID,
 		TMDBId:         episode.ID,
 		ShowTMDBId:     show.ID,
@@ -317,6 +357,17 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 		AbsoluteNumber: absoluteNumber,
 	}
 
+	// Releases are named by the ordering, chosen for the show, like DVD order
+	if ordering := tmdb.GetEpisodeOrdering(show.ID, config.Get().Language); ordering != nil {
+		if s, e, ok := ordering.Number(episode.SeasonNumber, episode.EpisodeNumber); ok {
+			sObject.Season = s
+			sObject.Episode = e
+			if ordering.Type == tmdb.EpisodeGroupAbsolute {
+				sObject.AbsoluteNumber = e
+			}
+		}
+	}
+
 	// Collect titles from AlternativeTitles
 	if show.AlternativeTitles != nil && show.AlternativeTitles.Titles != nil {
 		for _, title := range show.AlternativeTitles.Titles {
@@ -338,7 +389,24 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 		}
 	}
 
-	if show.IsAnime() && config.Get().UseAnimeEnTitle {
+	// Anime releases are named by romaji or English titles, and often by absolute numbers only
+	if isAnime && config.Get().AnimeMetadata {
+		if media := show.AniList(); media != nil {
+			sObject.AniListID = media.ID
+			romaji, english := media.Titles()
+			if romaji != "" {
+				sObject.Titles["romaji"] = NormalizeTitle(romaji)
+			}
+			if english != "" {
+				sObject.Titles["english"] = NormalizeTitle(english)
+				if _, ok := sObject.Titles["en"]; !ok {
+					sObject.Titles["en"] = NormalizeTitle(english)
+				}
+			}
+		}
+	}
+
+	if isAnime && config.Get().UseAnimeEnTitle {
 		if t, ok := sObject.Titles["en"]; ok {
 			sObject.Titles["original"] = t
 		}
@@ -353,6 +421,14 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 
 func (as *AddonSearcher) call(method string, searchObject interface{}) []*bittorrent.TorrentFile {
 	torrents := make([]*bittorrent.TorrentFile, 0)
+	if mock.Enabled() {
+		if err := json.Unmarshal(mock.ProviderResults(as.addonID, method), &torrents); err != nil {
+			log.Errorf("Failed to unmarshal torrents of %s fixture: %s", as.addonID, err)
+		}
+		return torrents
+	}
+
+	canceled := searchCanceled()
 	cid, c := GetCallback()
 	cbURL := fmt.Sprintf("%s/callbacks/%s", util.GetHTTPHost(), cid)
 
@@ -373,6 +449,9 @@ func (as *AddonSearcher) call(method string, searchObject interface{}) []*bittor
 	case <-time.After(timeout):
 		as.log.Warningf("Provider %s was too slow. Ignored.", as.addonID)
 		RemoveCallback(cid)
+	case <-canceled:
+		as.log.Infof("Search in provider %s was canceled", as.addonID)
+		RemoveCallback(cid)
 	case result := <-c:
 		if err := json.Unmarshal(result, &torrents); err != nil {
 			log.Errorf("Failed to unmarshal torrents: %s", err)
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/mock"
 )
 
 var (
@@ -39,6 +40,11 @@ var (
 		Transport: proxyTransport,
 		Timeout:   30 * time.Second,
 	}
+
+	// mockClient uses default transport, which serves fixtures in mock mode
+	mockClient = &http.Client{
+		Timeout: 15 * time.Second,
+	}
 )
 
 // Reload ...
@@ -55,6 +61,9 @@ func Reload() {
 
 // GetClient ...
 func GetClient() *http.Client {
+	if mock.Enabled() {
+		return mockClient
+	}
 	if !config.Get().InternalProxyEnabled {
 		return directClient
 	}
@@ -64,6 +73,9 @@ func GetClient() *http.Client {
 
 // GetDirectClient ...
 func GetDirectClient() *http.Client {
+	if mock.Enabled() {
+		return mockClient
+	}
 	return directClient
 }
 
@@ -11,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/util"
 
 	"github.com/projectxorg/cfbypass"
 	"github.com/elazarl/goproxy"
@@ -49,6 +50,20 @@ func CustomTLS(ca *tls.Certificate) func(host string, ctx *goproxy.ProxyCtx) (*t
 	}
 }
 
+// providerRoundTripper makes provider requests take slots in provider connection pool,
+// so scraping bursts do not starve metadata requests
+var providerRoundTripper goproxy.RoundTripperFunc = func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Response, error) {
+	util.ProviderPool.Acquire()
+	resp, err := ctx.Proxy.Tr.RoundTrip(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		util.ProviderPool.Release()
+		return resp, err
+	}
+
+	resp.Body = util.ProviderPool.ReleaseOnClose(resp.Body)
+	return resp, nil
+}
+
 func handleRequest(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
 	// Removing these headers to ensure cloudflare is not taking these headers into account.
 	req.Header.Del("Connection")
@@ -67,6 +82,7 @@ func handleRequest(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *ht
 	defer req.Body.Close()
 
 	ctx.UserData = bodyBytes
+	ctx.RoundTripper = providerRoundTripper
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
 
 	return req, nil
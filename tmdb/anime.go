@@ -0,0 +1,53 @@
+package tmdb
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/projectx13/projectx/anilist"
+)
+
+// animeKeywordID is TMDB keyword "anime", which marks anime, produced outside of Japan
+const animeKeywordID = 210024
+
+// hasAnimeKeyword checks whether the show is tagged as anime in TMDB
+func (show *Show) hasAnimeKeyword() bool {
+	for _, keyword := range GetShowKeywords(show.ID) {
+		if keyword != nil && keyword.ID == animeKeywordID {
+			return true
+		}
+	}
+	return false
+}
+
+// AniList returns AniList anime of the show, found by original title, and then by name
+func (show *Show) AniList() *anilist.Media {
+	if show == nil {
+		return nil
+	}
+
+	year, _ := strconv.Atoi(strings.Split(show.FirstAirDate, "-")[0])
+	if media := anilist.FindShow(show.OriginalName, year); media != nil {
+		return media
+	}
+	if show.Name != show.OriginalName {
+		return anilist.FindShow(show.Name, year)
+	}
+	return nil
+}
+
+// AbsoluteNumber returns number of the episode, counted from the first episode of the show,
+// specials are not counted. It is used, when TVDB does not have absolute numbers.
+func (show *Show) AbsoluteNumber(episode *Episode) int {
+	if show == nil || episode == nil || episode.SeasonNumber <= 0 {
+		return 0
+	}
+
+	an := episode.EpisodeNumber
+	for _, season := range show.Seasons {
+		if season != nil && season.Season > 0 && season.Season < episode.SeasonNumber {
+			an += season.EpisodeCount
+		}
+	}
+	return an
+}
@@ -0,0 +1,128 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// imageLanguages returns languages of images to request: user's language, English, and images without text
+func imageLanguages(language string) string {
+	return fmt.Sprintf("%s,en,null", imageLanguage(language))
+}
+
+// imageLanguage returns ISO 639-1 code, which TMDB uses for images, of language like "pt-BR"
+func imageLanguage(language string) string {
+	return strings.SplitN(language, "-", 2)[0]
+}
+
+// rankImages returns images, matching languages first, in order of languages, and best voted first.
+// Empty language stands for images without text.
+func rankImages(images []*Image, languages ...string) []*Image {
+	rank := func(img *Image) int {
+		for i, l := range languages {
+			if img.Iso639_1 == l {
+				return i
+			}
+		}
+		return len(languages)
+	}
+
+	ret := make([]*Image, 0, len(images))
+	for _, img := range images {
+		if img != nil && img.FilePath != "" {
+			ret = append(ret, img)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ri, rj := rank(ret[i]), rank(ret[j]); ri != rj {
+			return ri < rj
+		}
+		return ret[i].VoteAverage > ret[j].VoteAverage
+	})
+	return ret
+}
+
+// PosterImages returns posters in user's language first, then English and textless ones
+func (images *Images) PosterImages(language string) []*Image {
+	if images == nil {
+		return nil
+	}
+	return rankImages(images.Posters, imageLanguage(language), "en", "")
+}
+
+// BackdropImages returns textless backdrops first, since text on fanart is covered by skins
+func (images *Images) BackdropImages(language string) []*Image {
+	if images == nil {
+		return nil
+	}
+	return rankImages(images.Backdrops, "", imageLanguage(language), "en")
+}
+
+// LogoImages returns logos in user's language first, then English ones
+func (images *Images) LogoImages(language string) []*Image {
+	if images == nil {
+		return nil
+	}
+	return rankImages(images.Logos, imageLanguage(language), "en", "")
+}
+
+// Fanarts returns fanart URLs to pick randomly from for seasons and episodes,
+// only textless backdrops are used, if there are any
+func (images *Images) Fanarts() []string {
+	ret := []string{}
+	if images == nil {
+		return ret
+	}
+
+	for _, backdrop := range images.Backdrops {
+		if backdrop != nil && backdrop.Iso639_1 == "" {
+			ret = append(ret, ArtURL(backdrop.FilePath, ArtFanart))
+		}
+	}
+	if len(ret) == 0 {
+		for _, backdrop := range images.Backdrops {
+			if backdrop != nil {
+				ret = append(ret, ArtURL(backdrop.FilePath, ArtFanart))
+			}
+		}
+	}
+	return ret
+}
+
+// showFanarts returns fanart, chosen by the user for the show, or textless backdrops to pick from
+func showFanarts(show *Show) []string {
+	if artwork := database.GetStorm().GetArtwork(show.ID); artwork != nil && artwork.FanArt != "" {
+		return []string{ArtURL(artwork.FanArt, ArtFanart)}
+	}
+	return show.Images.Fanarts()
+}
+
+// setImagesArt sets poster, fanart and logo, which suit user's language best
+func setImagesArt(art *xbmc.ListItemArt, images *Images, language string) {
+	if posters := images.PosterImages(language); len(posters) > 0 {
+		art.Poster = ArtURL(posters[0].FilePath, ArtPoster)
+	}
+	if backdrops := images.BackdropImages(language); len(backdrops) > 0 {
+		art.FanArt = ArtURL(backdrops[0].FilePath, ArtFanart)
+	}
+	if logos := images.LogoImages(language); len(logos) > 0 {
+		art.ClearLogo = ArtURL(logos[0].FilePath, ArtLogo)
+	}
+}
+
+// setChosenArt sets art, chosen by the user for the show, over automatic choice
+func setChosenArt(art *xbmc.ListItemArt, artwork *database.Artwork) {
+	if artwork.Poster != "" {
+		art.Poster = ArtURL(artwork.Poster, ArtPoster)
+	}
+	if artwork.FanArt != "" {
+		art.FanArt = ArtURL(artwork.FanArt, ArtFanart)
+	}
+	if artwork.ClearLogo != "" {
+		art.ClearLogo = ArtURL(artwork.ClearLogo, ArtLogo)
+	}
+}
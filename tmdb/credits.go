@@ -0,0 +1,136 @@
+package tmdb
+
+import (
+	"sort"
+
+	"github.com/projectx13/projectx/config"
+)
+
+// AggregateCredits are cast and crew of all episodes of the season, each person has all roles
+type AggregateCredits struct {
+	Cast []*AggregateCast `json:"cast"`
+	Crew []*AggregateCrew `json:"crew"`
+}
+
+// AggregateCast is an actor of the season with characters, played in its episodes
+type AggregateCast struct {
+	IDName
+	ProfilePath       string `json:"profile_path"`
+	Order             int    `json:"order"`
+	TotalEpisodeCount int    `json:"total_episode_count"`
+
+	Roles []*struct {
+		CreditID     string `json:"credit_id"`
+		Character    string `json:"character"`
+		EpisodeCount int    `json:"episode_count"`
+	} `json:"roles"`
+}
+
+// AggregateCrew is a crew member of the season with jobs in its episodes
+type AggregateCrew struct {
+	IDName
+	Department        string `json:"department"`
+	ProfilePath       string `json:"profile_path"`
+	TotalEpisodeCount int    `json:"total_episode_count"`
+
+	Jobs []*struct {
+		CreditID     string `json:"credit_id"`
+		Job          string `json:"job"`
+		EpisodeCount int    `json:"episode_count"`
+	} `json:"jobs"`
+}
+
+// Character returns character, played in most episodes of the season
+func (c *AggregateCast) Character() string {
+	character := ""
+	count := 0
+	for _, r := range c.Roles {
+		if r != nil && r.Character != "" && r.EpisodeCount > count {
+			character = r.Character
+			count = r.EpisodeCount
+		}
+	}
+	return character
+}
+
+// limitCast cuts cast to the size from settings, to keep list items small
+func limitCast(cast [][]string) [][]string {
+	if limit := config.Get().CastMembersLimit; limit > 0 && len(cast) > limit {
+		return cast[:limit]
+	}
+	return cast
+}
+
+// castAndRole returns cast as names and characters for list items
+func castAndRole(cast []*Cast) [][]string {
+	ret := make([][]string, 0, len(cast))
+	for _, c := range cast {
+		if c != nil {
+			ret = append(ret, []string{c.Name, c.Character})
+		}
+	}
+	return limitCast(ret)
+}
+
+// episodeCastAndRole returns main cast of the season, followed by guest stars of the episode.
+// Season aggregate credits have all actors of the season, and season credits only the latest ones.
+func episodeCastAndRole(episode *Episode, season *Season) [][]string {
+	ret := [][]string{}
+	seen := map[int]bool{}
+
+	if season != nil && season.AggregateCredits != nil && len(season.AggregateCredits.Cast) > 0 {
+		cast := make([]*AggregateCast, 0, len(season.AggregateCredits.Cast))
+		for _, c := range season.AggregateCredits.Cast {
+			if c != nil {
+				cast = append(cast, c)
+			}
+		}
+		sort.SliceStable(cast, func(i, j int) bool { return cast[i].Order < cast[j].Order })
+
+		for _, c := range cast {
+			seen[c.ID] = true
+			ret = append(ret, []string{c.Name, c.Character()})
+		}
+	} else if credits := episodeCredits(episode, season); credits != nil {
+		for _, c := range credits.Cast {
+			if c != nil {
+				seen[c.ID] = true
+				ret = append(ret, []string{c.Name, c.Character})
+			}
+		}
+	}
+
+	guestStars := episode.GuestStars
+	if len(guestStars) == 0 && episode.Credits != nil {
+		guestStars = episode.Credits.GuestStars
+	}
+	for _, c := range guestStars {
+		if c != nil && !seen[c.ID] {
+			seen[c.ID] = true
+			ret = append(ret, []string{c.Name, c.Character})
+		}
+	}
+
+	return limitCast(ret)
+}
+
+// episodeCredits returns credits of the episode, or credits of the season, if episode has none
+func episodeCredits(episode *Episode, season *Season) *Credits {
+	if episode.Credits != nil {
+		return episode.Credits
+	} else if season != nil {
+		return season.Credits
+	}
+	return nil
+}
+
+// episodeCrew returns crew of the episode, which comes with season episodes,
+// or crew from credits
+func episodeCrew(episode *Episode, season *Season) []*Crew {
+	if len(episode.Crew) > 0 {
+		return episode.Crew
+	} else if credits := episodeCredits(episode, season); credits != nil {
+		return credits.Crew
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+)
+
+// DiscoverSortTypes are sort orders, supported by discover/movie
+var DiscoverSortTypes = []string{
+	"popularity.desc",
+	"vote_average.desc",
+	"vote_count.desc",
+	"primary_release_date.desc",
+	"primary_release_date.asc",
+	"revenue.desc",
+	"original_title.asc",
+}
+
+// DiscoverQuery is a set of filters for discover/movie,
+// it is kept in URL query, so lists could be paged and bookmarked
+type DiscoverQuery struct {
+	YearFrom int
+	YearTo   int
+	Genres   []string
+	// GenresAll requires all genres, otherwise any of them
+	GenresAll        bool
+	VoteAverage      float64
+	VoteCount        int
+	RuntimeFrom      int
+	RuntimeTo        int
+	Certification    string
+	OriginalLanguage string
+	WatchRegion      string
+	Providers        string
+	SortBy           string
+}
+
+// Certification is an age rating of the country
+type Certification struct {
+	Certification string `json:"certification"`
+	Meaning       string `json:"meaning"`
+	Order         int    `json:"order"`
+}
+
+// ParseDiscoverQuery reads filters from URL query
+func ParseDiscoverQuery(values url.Values) *DiscoverQuery {
+	q := &DiscoverQuery{
+		Certification:    values.Get("certification"),
+		OriginalLanguage: values.Get("language"),
+		WatchRegion:      values.Get("region"),
+		Providers:        values.Get("providers"),
+		SortBy:           values.Get("sort"),
+		GenresAll:        values.Get("genres_all") == "true",
+	}
+	q.YearFrom, _ = strconv.Atoi(values.Get("year_from"))
+	q.YearTo, _ = strconv.Atoi(values.Get("year_to"))
+	q.VoteAverage, _ = strconv.ParseFloat(values.Get("vote_average"), 64)
+	q.VoteCount, _ = strconv.Atoi(values.Get("vote_count"))
+	q.RuntimeFrom, _ = strconv.Atoi(values.Get("runtime_from"))
+	q.RuntimeTo, _ = strconv.Atoi(values.Get("runtime_to"))
+	if genres := values.Get("genres"); genres != "" {
+		q.Genres = strings.Split(genres, ",")
+	}
+
+	return q
+}
+
+// Values returns filters as URL query, only filters with values are added
+func (q *DiscoverQuery) Values() url.Values {
+	values := url.Values{}
+	setInt := func(key string, value int) {
+		if value > 0 {
+			values.Set(key, strconv.Itoa(value))
+		}
+	}
+	setString := func(key string, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	setInt("year_from", q.YearFrom)
+	setInt("year_to", q.YearTo)
+	setString("genres", strings.Join(q.Genres, ","))
+	if q.GenresAll && len(q.Genres) > 1 {
+		values.Set("genres_all", "true")
+	}
+	if q.VoteAverage > 0 {
+		values.Set("vote_average", strconv.FormatFloat(q.VoteAverage, 'f', -1, 64))
+	}
+	setInt("vote_count", q.VoteCount)
+	setInt("runtime_from", q.RuntimeFrom)
+	setInt("runtime_to", q.RuntimeTo)
+	setString("certification", q.Certification)
+	setString("language", q.OriginalLanguage)
+	setString("region", q.WatchRegion)
+	setString("providers", q.Providers)
+	setString("sort", q.SortBy)
+
+	return values
+}
+
+// Params returns request parameters for discover/movie
+func (q *DiscoverQuery) Params(language string) napping.Params {
+	sortBy := q.SortBy
+	if sortBy == "" {
+		sortBy = "popularity.desc"
+	}
+
+	releasedBefore := time.Now().UTC().Format("2006-01-02")
+	if q.YearTo > 0 {
+		if yearEnd := fmt.Sprintf("%d-12-31", q.YearTo); yearEnd < releasedBefore {
+			releasedBefore = yearEnd
+		}
+	}
+
+	p := napping.Params{
+		"language":                 language,
+		"sort_by":                  sortBy,
+		"primary_release_date.lte": releasedBefore,
+	}
+	if q.YearFrom > 0 {
+		p["primary_release_date.gte"] = fmt.Sprintf("%d-01-01", q.YearFrom)
+	}
+	if len(q.Genres) > 0 {
+		separator := "|"
+		if q.GenresAll {
+			separator = ","
+		}
+		p["with_genres"] = strings.Join(q.Genres, separator)
+	}
+	if q.VoteAverage > 0 {
+		p["vote_average.gte"] = strconv.FormatFloat(q.VoteAverage, 'f', -1, 64)
+	}
+	// Sorting by rating without votes limit brings up movies with a single vote
+	if q.VoteCount > 0 {
+		p["vote_count.gte"] = strconv.Itoa(q.VoteCount)
+	} else if q.VoteAverage > 0 || sortBy == "vote_average.desc" {
+		p["vote_count.gte"] = "50"
+	}
+	if q.RuntimeFrom > 0 {
+		p["with_runtime.gte"] = strconv.Itoa(q.RuntimeFrom)
+	}
+	if q.RuntimeTo > 0 {
+		p["with_runtime.lte"] = strconv.Itoa(q.RuntimeTo)
+	}
+	if q.Certification != "" {
+		p["certification_country"] = CertificationCountry()
+		p["certification.lte"] = q.Certification
+	}
+	if q.OriginalLanguage != "" {
+		p["with_original_language"] = q.OriginalLanguage
+	}
+	if q.Providers != "" && q.WatchRegion != "" {
+		p["watch_region"] = q.WatchRegion
+		p["with_watch_providers"] = q.Providers
+		p["with_watch_monetization_types"] = "flatrate"
+	}
+
+	return p
+}
+
+// IsEmpty returns whether no filters are set
+func (q *DiscoverQuery) IsEmpty() bool {
+	return len(q.Values()) == 0
+}
+
+// DiscoverMovies returns movies, matching all filters of the query
+func DiscoverMovies(q *DiscoverQuery, language string, page int) (Movies, int) {
+	return listMovies("discover/movie", "discover."+q.Values().Encode(), q.Params(language), page)
+}
+
+// GetMovieCertifications returns age ratings of the country, from the youngest audience
+func GetMovieCertifications(country string) []*Certification {
+	return getCertifications("movie", country)
+}
+
+// GetTVCertifications returns age ratings of shows in the country, from the youngest audience
+func GetTVCertifications(country string) []*Certification {
+	return getCertifications("tv", country)
+}
+
+func getCertifications(kind string, country string) []*Certification {
+	certifications := []*Certification{}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.certifications.%s.%s", kind, country)
+	if err := cacheStore.Get(key, &certifications); err != nil {
+		var results struct {
+			Certifications map[string][]*Certification `json:"certifications"`
+		}
+
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/certification/%s/list", tmdbEndpoint, kind),
+			Params: napping.Params{
+				"api_key": apiKey,
+			}.AsUrlValues(),
+			Result:      &results,
+			Description: kind + " certifications",
+		})
+		if err != nil {
+			return certifications
+		}
+
+		if list, ok := results.Certifications[country]; ok {
+			certifications = list
+		}
+		sort.Slice(certifications, func(i, j int) bool {
+			return certifications[i].Order < certifications[j].Order
+		})
+		cacheStore.Set(key, certifications, cacheExpiration)
+	}
+	return certifications
+}
@@ -3,6 +3,7 @@ package tmdb
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +22,9 @@ func GetEpisode(showID int, seasonNumber int, episodeNumber int, language string
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.episode.%d.%d.%d.%s", showID, seasonNumber, episodeNumber, language)
 	if err := cacheStore.Get(key, &episode); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/episode/%d", tmdbEndpoint, showID, seasonNumber, episodeNumber),
 			Params: napping.Params{
@@ -31,6 +35,7 @@ func GetEpisode(showID int, seasonNumber int, episodeNumber int, language string
 			Result:      &episode,
 			Description: "episode",
 		})
+		rememberNotFound(cacheStore, key, err)
 
 		if episode != nil {
 			cacheStore.Set(key, episode, cacheExpiration)
@@ -46,10 +51,7 @@ func (episodes EpisodeList) ToListItems(show *Show, season *Season) []*xbmc.List
 		return items
 	}
 
-	fanarts := make([]string, 0)
-	for _, backdrop := range show.Images.Backdrops {
-		fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
-	}
+	fanarts := showFanarts(show)
 
 	now := util.UTCBod()
 	for _, episode := range episodes {
@@ -70,7 +72,7 @@ func (episodes EpisodeList) ToListItems(show *Show, season *Season) []*xbmc.List
 		}
 
 		if item.Art.FanArt == "" && season.Poster != "" {
-			item.Art.Poster = ImageURL(season.Poster, "w500")
+			item.Art.Poster = ArtURL(season.Poster, ArtPoster)
 		}
 
 		items = append(items, item)
@@ -78,6 +80,29 @@ func (episodes EpisodeList) ToListItems(show *Show, season *Season) []*xbmc.List
 	return items
 }
 
+// Duration returns duration of the episode in seconds, or typical duration of show episodes,
+// since TMDB does not have runtime for every episode
+func (episode *Episode) Duration(show *Show) int {
+	if episode.Runtime > 0 {
+		return episode.Runtime * 60
+	}
+	return showRuntime(show)
+}
+
+// showRuntime returns typical duration of show episodes in seconds
+func showRuntime(show *Show) int {
+	if len(show.EpisodeRunTime) > 0 {
+		return show.EpisodeRunTime[len(show.EpisodeRunTime)-1] * 60
+	}
+	return 1800
+}
+
+// airYear returns year of the air date, like "2019-05-19", or 0
+func airYear(date string) int {
+	year, _ := strconv.Atoi(strings.Split(date, "-")[0])
+	return year
+}
+
 // ToListItem ...
 func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 	episodeLabel := episode.Name
@@ -85,11 +110,6 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 		episodeLabel = fmt.Sprintf("%dx%02d %s", episode.SeasonNumber, episode.EpisodeNumber, episode.Name)
 	}
 
-	runtime := 1800
-	if len(show.EpisodeRunTime) > 0 {
-		runtime = show.EpisodeRunTime[len(show.EpisodeRunTime)-1] * 60
-	}
-
 	item := &xbmc.ListItem{
 		Label:  episodeLabel,
 		Label2: fmt.Sprintf("%f", episode.VoteAverage),
@@ -103,8 +123,12 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 			Plot:          episode.Overview,
 			PlotOutline:   episode.Overview,
 			Rating:        episode.VoteAverage,
+			Votes:         strconv.Itoa(episode.VoteCount),
+			Year:          airYear(episode.AirDate),
+			Premiered:     episode.AirDate,
 			Aired:         episode.AirDate,
-			Duration:      runtime,
+			Duration:      episode.Duration(show),
+			Genre:         localizedGenres(show.Genres, true),
 			Code:          show.ExternalIDs.IMDBId,
 			IMDBNumber:    show.ExternalIDs.IMDBId,
 			PlayCount:     playcount.GetWatchedEpisodeByTMDB(show.ID, episode.SeasonNumber, episode.EpisodeNumber).Int(),
@@ -115,22 +139,19 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 	}
 
 	if show.PosterPath != "" {
-		item.Art.TvShowPoster = ImageURL(show.PosterPath, "w500")
-		item.Art.FanArt = ImageURL(show.BackdropPath, "w1280")
-		item.Art.Thumbnail = ImageURL(show.PosterPath, "w500")
-		item.Thumbnail = ImageURL(show.PosterPath, "w500")
+		item.Art.TvShowPoster = ArtURL(show.PosterPath, ArtPoster)
+		item.Art.FanArt = ArtURL(show.BackdropPath, ArtFanart)
+		item.Art.Thumbnail = ArtURL(show.PosterPath, ArtThumb)
+		item.Thumbnail = ArtURL(show.PosterPath, ArtThumb)
 	} else if show.Images != nil {
-		fanarts := []string{}
-		for _, backdrop := range show.Images.Backdrops {
-			fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
-		}
+		fanarts := showFanarts(show)
 		if len(fanarts) > 0 {
 			item.Art.FanArt = fanarts[rand.Intn(len(fanarts))]
 		}
 
 		fanarts = []string{}
 		for _, poster := range show.Images.Posters {
-			fanarts = append(fanarts, ImageURL(poster.FilePath, "w500"))
+			fanarts = append(fanarts, ArtURL(poster.FilePath, ArtPoster))
 		}
 		if len(fanarts) > 0 {
 			item.Art.TvShowPoster = fanarts[rand.Intn(len(fanarts))]
@@ -144,35 +165,30 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 	}
 
 	if episode.StillPath != "" {
-		item.Art.FanArt = ImageURL(episode.StillPath, "w1280")
-		item.Art.Thumbnail = ImageURL(episode.StillPath, "w500")
-		item.Art.Poster = ImageURL(episode.StillPath, "w500")
-		item.Thumbnail = ImageURL(episode.StillPath, "w500")
+		still := ArtURL(episode.StillPath, ArtStill)
+		item.Art.FanArt = ArtURL(episode.StillPath, ArtFanart)
+		item.Art.Thumbnail = still
+		item.Art.Poster = still
+		item.Thumbnail = still
+	} else if season != nil && season.Poster != "" {
+		// Skins show blank thumbs for episodes without stills, so season poster is better than nothing
+		item.Art.Thumbnail = ArtURL(season.Poster, ArtThumb)
+		item.Thumbnail = item.Art.Thumbnail
 	}
 
-	genres := make([]string, 0, len(show.Genres))
-	for _, genre := range show.Genres {
-		genres = append(genres, genre.Name)
-	}
-	item.Info.Genre = strings.Join(genres, " / ")
+	item.Info.Genre = localizedGenres(show.Genres, true)
 
 	for _, company := range show.ProductionCompanies {
 		item.Info.Studio = company.Name
 		break
 	}
 
-	if season != nil && episode.Credits == nil && season.Credits != nil {
-		episode.Credits = season.Credits
-	}
+	item.Info.CastAndRole = episodeCastAndRole(episode, season)
 
-	if episode.Credits != nil {
-		item.Info.CastAndRole = make([][]string, 0)
-		for _, cast := range episode.Credits.Cast {
-			item.Info.CastAndRole = append(item.Info.CastAndRole, []string{cast.Name, cast.Character})
-		}
+	if crews := episodeCrew(episode, season); len(crews) > 0 {
 		directors := make([]string, 0)
 		writers := make([]string, 0)
-		for _, crew := range episode.Credits.Crew {
+		for _, crew := range crews {
 			switch crew.Job {
 			case "Director":
 				directors = append(directors, crew.Name)
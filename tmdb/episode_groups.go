@@ -0,0 +1,139 @@
+package tmdb
+
+import (
+	"fmt"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+	"github.com/projectx13/projectx/database"
+)
+
+// Episode group types, as defined by TMDB
+const (
+	EpisodeGroupOriginalAirDate = iota + 1
+	EpisodeGroupAbsolute
+	EpisodeGroupDVD
+	EpisodeGroupDigital
+	EpisodeGroupStoryArc
+	EpisodeGroupProduction
+	EpisodeGroupTV
+)
+
+// EpisodeGroup is an alternate ordering of show episodes, like DVD or absolute order
+type EpisodeGroup struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Type         int    `json:"type"`
+	EpisodeCount int    `json:"episode_count"`
+	GroupCount   int    `json:"group_count"`
+}
+
+// EpisodeGroupDetails contains groups, which are used as seasons, with episodes in group order
+type EpisodeGroupDetails struct {
+	EpisodeGroup
+
+	Groups []*struct {
+		ID       string     `json:"id"`
+		Name     string     `json:"name"`
+		Order    int        `json:"order"`
+		Episodes []*Episode `json:"episodes"`
+	} `json:"groups"`
+}
+
+// GetEpisodeGroups returns alternate orderings of the show
+func GetEpisodeGroups(showID int, language string) []*EpisodeGroup {
+	groups := []*EpisodeGroup{}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.episodegroups.%d.%s", showID, language)
+	if err := cacheStore.Get(key, &groups); err != nil {
+		if isNotFound(cacheStore, key) {
+			return groups
+		}
+
+		var results struct {
+			Results []*EpisodeGroup `json:"results"`
+		}
+
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/tv/%d/episode_groups", tmdbEndpoint, showID),
+			Params: napping.Params{
+				"api_key":  apiKey,
+				"language": language,
+			}.AsUrlValues(),
+			Result:      &results,
+			Description: "episode groups",
+		})
+		rememberNotFound(cacheStore, key, err)
+		if err != nil {
+			return groups
+		}
+
+		groups = results.Results
+		cacheStore.Set(key, groups, cacheExpiration)
+	}
+	return groups
+}
+
+// GetEpisodeGroup returns episodes of the ordering
+func GetEpisodeGroup(groupID string, language string) *EpisodeGroupDetails {
+	var group *EpisodeGroupDetails
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.episodegroup.%s.%s", groupID, language)
+	if err := cacheStore.Get(key, &group); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/tv/episode_group/%s", tmdbEndpoint, groupID),
+			Params: napping.Params{
+				"api_key":  apiKey,
+				"language": language,
+			}.AsUrlValues(),
+			Result:      &group,
+			Description: "episode group",
+		})
+		rememberNotFound(cacheStore, key, err)
+		if err != nil || group == nil {
+			return nil
+		}
+
+		cacheStore.Set(key, group, cacheExpiration)
+	}
+	return group
+}
+
+// GetEpisodeOrdering returns ordering, chosen by the user for the show,
+// or nil, if episodes are numbered in aired order
+func GetEpisodeOrdering(showID int, language string) *EpisodeGroupDetails {
+	groupID := database.GetStorm().GetEpisodeOrdering(showID)
+	if groupID == "" {
+		return nil
+	}
+	return GetEpisodeGroup(groupID, language)
+}
+
+// Number returns season and episode numbers in this ordering for aired season and episode,
+// groups are used as seasons and episodes are numbered by their order in the group
+func (g *EpisodeGroupDetails) Number(seasonNumber int, episodeNumber int) (int, int, bool) {
+	if g == nil {
+		return seasonNumber, episodeNumber, false
+	}
+
+	for _, group := range g.Groups {
+		for i, e := range group.Episodes {
+			if e != nil && e.SeasonNumber == seasonNumber && e.EpisodeNumber == episodeNumber {
+				// Absolute ordering has only one group, so it is numbered as the first season
+				season := group.Order
+				if g.Type == EpisodeGroupAbsolute {
+					season = 1
+				}
+				return season, i + 1, true
+			}
+		}
+	}
+	return seasonNumber, episodeNumber, false
+}
@@ -0,0 +1,164 @@
+package tmdb
+
+import (
+	"strings"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/config"
+)
+
+// languageChain returns languages to take metadata from, in order:
+// requested language, second language from settings and English
+func languageChain(language string) []string {
+	chain := []string{}
+	for _, l := range []string{language, config.Get().SecondLanguage, "en"} {
+		if l == "" {
+			continue
+		}
+
+		isDuplicate := false
+		for _, c := range chain {
+			if languageCode(c) == languageCode(l) {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+// languageCode returns ISO 639-1 part of the language, like "pt" for "pt-BR"
+func languageCode(language string) string {
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		return strings.ToLower(language[:idx])
+	}
+	return strings.ToLower(language)
+}
+
+func translationMatches(t *Translation, language string) bool {
+	if t == nil || t.Data == nil || t.Iso639_1 != languageCode(language) {
+		return false
+	}
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		return strings.EqualFold(t.Iso3166_1, language[idx+1:])
+	}
+	return true
+}
+
+// findTranslation returns first not empty value from translations to languages of the chain
+func findTranslation(translations []*Translation, chain []string, value func(*TranslationData) string) string {
+	for _, language := range chain {
+		// Country specific translation goes first, like "pt-BR" before "pt-PT"
+		for _, t := range translations {
+			if translationMatches(t, language) && value(t.Data) != "" {
+				return value(t.Data)
+			}
+		}
+		for _, t := range translations {
+			if translationMatches(t, languageCode(language)) && value(t.Data) != "" {
+				return value(t.Data)
+			}
+		}
+	}
+	return ""
+}
+
+// fallbackValue returns value in the first language of the chain, which has it translated,
+// or current value if there are no translations. TMDB returns original title,
+// when there is no translation, so value could be not empty and still not translated.
+func fallbackValue(current string, translations []*Translation, chain []string, value func(*TranslationData) string) string {
+	if current != "" && findTranslation(translations, chain[:1], value) != "" {
+		return current
+	}
+	if translated := findTranslation(translations, chain, value); translated != "" {
+		return translated
+	}
+	return current
+}
+
+func translationTitle(d *TranslationData) string    { return d.Title }
+func translationName(d *TranslationData) string     { return d.Name }
+func translationOverview(d *TranslationData) string { return d.Overview }
+
+// applyLanguageFallback fills empty titles and overviews of request result,
+// using translations to second language and English
+func applyLanguageFallback(r APIRequest) {
+	language := r.Params.Get("language")
+	if language == "" {
+		return
+	}
+	chain := languageChain(language)
+	if len(chain) < 2 {
+		return
+	}
+
+	switch result := r.Result.(type) {
+	case **Movie:
+		if m := *result; m != nil && m.Translations != nil {
+			m.Title = fallbackValue(m.Title, m.Translations.Translations, chain, translationTitle)
+			m.Overview = fallbackValue(m.Overview, m.Translations.Translations, chain, translationOverview)
+		}
+	case **Show:
+		if s := *result; s != nil && s.Translations != nil {
+			s.Name = fallbackValue(s.Name, s.Translations.Translations, chain, translationName)
+			s.Overview = fallbackValue(s.Overview, s.Translations.Translations, chain, translationOverview)
+		}
+	case **Episode:
+		if e := *result; e != nil && e.Translations != nil {
+			e.Name = fallbackValue(e.Name, e.Translations.Translations, chain, translationName)
+			e.Overview = fallbackValue(e.Overview, e.Translations.Translations, chain, translationOverview)
+		}
+	case **Season:
+		if s := *result; s != nil {
+			seasonLanguageFallback(r, s, chain[1:])
+		}
+	}
+}
+
+// seasonLanguageFallback fills episodes with empty names or overviews from the season
+// in fallback languages, with one request per language, instead of requesting each episode
+func seasonLanguageFallback(r APIRequest, season *Season, chain []string) {
+	for _, language := range chain {
+		missing := map[int]*Episode{}
+		for _, e := range season.Episodes {
+			if e != nil && (e.Name == "" || e.Overview == "") {
+				missing[e.EpisodeNumber] = e
+			}
+		}
+		if len(missing) == 0 {
+			return
+		}
+
+		var fallback *Season
+		err := makeRequest(APIRequest{
+			URL: r.URL,
+			Params: napping.Params{
+				"api_key":  apiKey,
+				"language": language,
+			}.AsUrlValues(),
+			Result:      &fallback,
+			Description: "season fallback",
+		})
+		if err != nil || fallback == nil {
+			continue
+		}
+
+		for _, f := range fallback.Episodes {
+			if f == nil {
+				continue
+			}
+			if e, ok := missing[f.EpisodeNumber]; ok {
+				if e.Name == "" {
+					e.Name = f.Name
+				}
+				if e.Overview == "" {
+					e.Overview = f.Overview
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tmdb
+
+import (
+	"strconv"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/util"
+)
+
+// Kinds of items, which IDs are resolved
+const (
+	IDKindMovie   = "movie"
+	IDKindShow    = "show"
+	IDKindEpisode = "episode"
+)
+
+// Sources of IDs
+const (
+	IDSourceTMDB  = "tmdb"
+	IDSourceIMDB  = "imdb"
+	IDSourceTVDB  = "tvdb"
+	IDSourceTrakt = "trakt"
+)
+
+// IDs are IDs of the same item in TMDB, IMDB, TVDB and Trakt, missing IDs are empty
+type IDs struct {
+	Kind  string `json:"kind"`
+	TMDB  int    `json:"tmdb,omitempty"`
+	IMDB  string `json:"imdb,omitempty"`
+	TVDB  int    `json:"tvdb,omitempty"`
+	Trakt int    `json:"trakt,omitempty"`
+}
+
+// ResolveIDs returns all known IDs of the item with ID from the source.
+// Links are kept in the database, missing ones are resolved with TMDB find and external IDs,
+// Trakt IDs are known only after they were stored with StoreIDs.
+func ResolveIDs(kind string, source string, id string) *IDs {
+	if id == "" || id == "0" {
+		return nil
+	}
+
+	field, value := storedIDField(source, id)
+	if field == "" {
+		return nil
+	}
+
+	storm := database.GetStorm()
+	if stored := storm.GetExternalIDs(kind, field, value); stored != nil && stored.TMDB != 0 {
+		return idsFromStored(stored)
+	}
+
+	ids := &IDs{Kind: kind}
+	switch source {
+	case IDSourceTMDB:
+		ids.TMDB, _ = strconv.Atoi(id)
+	case IDSourceIMDB:
+		ids.IMDB = id
+		ids.TMDB = findID(kind, id, "imdb_id")
+	case IDSourceTVDB:
+		ids.TVDB, _ = strconv.Atoi(id)
+		ids.TMDB = findID(kind, id, "tvdb_id")
+	case IDSourceTrakt:
+		// Trakt IDs could not be resolved with TMDB
+		return nil
+	}
+	if ids.TMDB == 0 {
+		return nil
+	}
+
+	fillExternalIDs(ids)
+	if err := StoreIDs(ids); err != nil {
+		log.Warningf("Could not store IDs of %s %s:%s: %s", kind, source, id, err)
+	}
+	return ids
+}
+
+// StoreIDs links IDs of the item, so they could be resolved without requests,
+// like IDs of Trakt items, which contain IDs of all sources
+func StoreIDs(ids *IDs) error {
+	if ids == nil || (ids.TMDB == 0 && ids.IMDB == "" && ids.TVDB == 0 && ids.Trakt == 0) {
+		return nil
+	}
+
+	stored := &database.ExternalIDs{
+		Kind:  ids.Kind,
+		TMDB:  ids.TMDB,
+		IMDB:  ids.IMDB,
+		TVDB:  ids.TVDB,
+		Trakt: ids.Trakt,
+	}
+	if err := database.GetStorm().SaveExternalIDs(stored); err != nil {
+		return err
+	}
+
+	*ids = *idsFromStored(stored)
+	return nil
+}
+
+func idsFromStored(stored *database.ExternalIDs) *IDs {
+	return &IDs{
+		Kind:  stored.Kind,
+		TMDB:  stored.TMDB,
+		IMDB:  stored.IMDB,
+		TVDB:  stored.TVDB,
+		Trakt: stored.Trakt,
+	}
+}
+
+// storedIDField returns field of database.ExternalIDs for the source, and typed value of ID
+func storedIDField(source string, id string) (string, interface{}) {
+	switch source {
+	case IDSourceIMDB:
+		return "IMDB", id
+	case IDSourceTMDB, IDSourceTVDB, IDSourceTrakt:
+		value, err := strconv.Atoi(id)
+		if err != nil || value == 0 {
+			return "", nil
+		}
+		if source == IDSourceTMDB {
+			return "TMDB", value
+		} else if source == IDSourceTVDB {
+			return "TVDB", value
+		}
+		return "Trakt", value
+	}
+	return "", nil
+}
+
+// findID returns TMDB ID of the first item of the kind, found by external ID
+func findID(kind string, id string, externalSource string) int {
+	r := Find(id, externalSource)
+	if r == nil {
+		return 0
+	}
+
+	var results []*Entity
+	switch kind {
+	case IDKindMovie:
+		results = r.MovieResults
+	case IDKindShow:
+		results = r.TVResults
+	case IDKindEpisode:
+		results = r.TVEpisodeResults
+	}
+	if len(results) == 0 || results[0] == nil {
+		return 0
+	}
+	return results[0].ID
+}
+
+// fillExternalIDs takes missing IMDB and TVDB IDs from TMDB item, episodes are not requested,
+// since they could not be requested without show ID
+func fillExternalIDs(ids *IDs) {
+	language := config.Get().Language
+	switch ids.Kind {
+	case IDKindMovie:
+		if m := GetMovie(ids.TMDB, language); m != nil {
+			if ids.IMDB == "" {
+				ids.IMDB = m.IMDBId
+			}
+			if ids.IMDB == "" && m.ExternalIDs != nil {
+				ids.IMDB = m.ExternalIDs.IMDBId
+			}
+		}
+	case IDKindShow:
+		if s := GetShow(ids.TMDB, language); s != nil && s.ExternalIDs != nil {
+			if ids.IMDB == "" {
+				ids.IMDB = s.ExternalIDs.IMDBId
+			}
+			if ids.TVDB == 0 {
+				ids.TVDB = util.StrInterfaceToInt(s.ExternalIDs.TVDBID)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package tmdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+)
+
+// similarKeywordsLimit is how many keywords of the title are used to find similar titles,
+// more keywords give more results, but they are less related
+const similarKeywordsLimit = 5
+
+// Keywords are keywords of the movie, which come in "keywords", or of the show, which come in "results"
+type Keywords struct {
+	ID       int       `json:"id"`
+	Keywords []*IDName `json:"keywords"`
+	Results  []*IDName `json:"results"`
+}
+
+// GetMovieKeywords returns keywords of the movie
+func GetMovieKeywords(movieID int) []*IDName {
+	return getKeywords("movie", movieID)
+}
+
+// GetShowKeywords returns keywords of the show
+func GetShowKeywords(showID int) []*IDName {
+	return getKeywords("tv", showID)
+}
+
+func getKeywords(kind string, id int) []*IDName {
+	var keywords *Keywords
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.%s.%d.keywords", kind, id)
+	if err := cacheStore.Get(key, &keywords); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/%s/%d/keywords", tmdbEndpoint, kind, id),
+			Params: napping.Params{
+				"api_key": apiKey,
+			}.AsUrlValues(),
+			Result:      &keywords,
+			Description: kind + " keywords",
+		})
+		rememberNotFound(cacheStore, key, err)
+
+		if keywords == nil {
+			return nil
+		}
+		cacheStore.Set(key, keywords, cacheExpiration)
+	}
+
+	if len(keywords.Keywords) > 0 {
+		return keywords.Keywords
+	}
+	return keywords.Results
+}
+
+// GetKeyword returns keyword with its name
+func GetKeyword(keywordID int) *IDName {
+	var keyword *IDName
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.keyword.%d", keywordID)
+	if err := cacheStore.Get(key, &keyword); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/keyword/%d", tmdbEndpoint, keywordID),
+			Params: napping.Params{
+				"api_key": apiKey,
+			}.AsUrlValues(),
+			Result:      &keyword,
+			Description: "keyword",
+		})
+		rememberNotFound(cacheStore, key, err)
+
+		if keyword == nil {
+			return nil
+		}
+		cacheStore.Set(key, keyword, cacheExpiration)
+	}
+	return keyword
+}
+
+// MoviesByKeywords returns popular movies, which have any of the keywords
+func MoviesByKeywords(keywordIDs []int, language string, page int) (Movies, int) {
+	keywords := joinKeywords(keywordIDs)
+	p := napping.Params{
+		"language":      language,
+		"sort_by":       "popularity.desc",
+		"with_keywords": keywords,
+	}
+
+	return listMovies("discover/movie", "keywords."+keywords, p, page)
+}
+
+// ShowsByKeywords returns popular shows, which have any of the keywords
+func ShowsByKeywords(keywordIDs []int, language string, page int) (Shows, int) {
+	keywords := joinKeywords(keywordIDs)
+	p := napping.Params{
+		"language":      language,
+		"sort_by":       "popularity.desc",
+		"with_keywords": keywords,
+	}
+
+	return listShows("discover/tv", "keywords."+keywords, p, page)
+}
+
+// SimilarMoviesByKeywords returns movies, which share top keywords with the movie.
+// Unlike TMDB recommendations, which depend on user ratings, it finds titles with the same topics.
+func SimilarMoviesByKeywords(movieID int, language string, page int) (Movies, int) {
+	keywordIDs := topKeywords(GetMovieKeywords(movieID))
+	if len(keywordIDs) == 0 {
+		return nil, 0
+	}
+
+	movies, total := MoviesByKeywords(keywordIDs, language, page)
+	for i, m := range movies {
+		if m != nil && m.ID == movieID {
+			movies[i] = nil
+		}
+	}
+	return movies, total
+}
+
+// SimilarShowsByKeywords returns shows, which share top keywords with the show
+func SimilarShowsByKeywords(showID int, language string, page int) (Shows, int) {
+	keywordIDs := topKeywords(GetShowKeywords(showID))
+	if len(keywordIDs) == 0 {
+		return nil, 0
+	}
+
+	shows, total := ShowsByKeywords(keywordIDs, language, page)
+	for i, s := range shows {
+		if s != nil && s.ID == showID {
+			shows[i] = nil
+		}
+	}
+	return shows, total
+}
+
+// topKeywords returns IDs of the first keywords, in order they are listed by TMDB
+func topKeywords(keywords []*IDName) []int {
+	ret := make([]int, 0, similarKeywordsLimit)
+	for _, k := range keywords {
+		if k == nil || k.ID == 0 {
+			continue
+		}
+		ret = append(ret, k.ID)
+		if len(ret) >= similarKeywordsLimit {
+			break
+		}
+	}
+	return ret
+}
+
+// joinKeywords joins keywords with "|", so TMDB discover returns titles with any of them
+func joinKeywords(keywordIDs []int) string {
+	ret := make([]string, 0, len(keywordIDs))
+	for _, id := range keywordIDs {
+		ret = append(ret, strconv.Itoa(id))
+	}
+	return strings.Join(ret, "|")
+}
@@ -0,0 +1,263 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/util"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+const (
+	tmdbV4Endpoint = "https://api.themoviedb.org/4"
+	// approveURL is the page, where user approves request token for the addon
+	approveURL = "https://www.themoviedb.org/auth/access?request_token=%s"
+	// listCachePrefix is shared by cached list contents, to drop them all after list is changed
+	listCachePrefix = "com.tmdb.list."
+	// listMaxPages bounds requests for big lists, TMDB returns 20 items per page
+	listMaxPages = 25
+)
+
+// UserList is a list, created by the user on TMDB
+type UserList struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	NumberOfItems int    `json:"number_of_items"`
+	Public        int    `json:"public"`
+}
+
+type userListsPage struct {
+	Page       int         `json:"page"`
+	TotalPages int         `json:"total_pages"`
+	Results    []*UserList `json:"results"`
+}
+
+// ListEntry is an item of TMDB list, only what is needed to get full item
+type ListEntry struct {
+	ID        int    `json:"id"`
+	MediaType string `json:"media_type"`
+}
+
+type listEntriesPage struct {
+	Page       int          `json:"page"`
+	TotalPages int          `json:"total_pages"`
+	Results    []*ListEntry `json:"results"`
+}
+
+type listItemsPayload struct {
+	Items []listItemPayload `json:"items"`
+}
+
+type listItemPayload struct {
+	MediaType string `json:"media_type"`
+	MediaID   int    `json:"media_id"`
+}
+
+// ListsAuthorized checks whether user has given access to the TMDB lists
+func ListsAuthorized() bool {
+	return config.Get().TMDBAccessToken != "" && config.Get().TMDBAccountID != ""
+}
+
+// AuthorizeLists asks the user to approve access to the TMDB lists with v4 OAuth flow,
+// and stores received access token in settings
+func AuthorizeLists(fromSettings bool) error {
+	readToken := config.Get().TMDBReadAccessToken
+	if readToken == "" {
+		return errors.New("TMDB read access token is not set")
+	}
+
+	var request struct {
+		RequestToken string `json:"request_token"`
+	}
+	if err := requestV4("POST", "/auth/request_token", readToken, nil, struct{}{}, &request); err != nil {
+		return err
+	} else if request.RequestToken == "" {
+		return errors.New("TMDB has not returned request token")
+	}
+
+	link := fmt.Sprintf(approveURL, request.RequestToken)
+	log.Noticef("Got TMDB request token, approve it at %s", link)
+	if !xbmc.Dialog("LOCALIZE[30798]", fmt.Sprintf("Visit %s, approve access and press OK", link)) {
+		return errors.New("Authentication canceled")
+	}
+
+	var access struct {
+		AccessToken string `json:"access_token"`
+		AccountID   string `json:"account_id"`
+	}
+	payload := map[string]string{"request_token": request.RequestToken}
+	if err := requestV4("POST", "/auth/access_token", readToken, nil, payload, &access); err != nil {
+		return err
+	} else if access.AccessToken == "" {
+		return errors.New("TMDB access was not approved")
+	}
+
+	database.SetCredential("tmdb_access_token", access.AccessToken)
+	xbmc.SetSetting("tmdb_account_id", access.AccountID)
+	config.Get().TMDBAccessToken = access.AccessToken
+	config.Get().TMDBAccountID = access.AccountID
+
+	success := "LOCALIZE[30799]"
+	if fromSettings {
+		success += " (Save your settings!)"
+	}
+	xbmc.Notify("projectx", success, config.AddonIcon())
+	return nil
+}
+
+// UserLists returns lists, created by the authorized user
+func UserLists() (lists []*UserList, err error) {
+	if !ListsAuthorized() {
+		return nil, errors.New("TMDB lists are not authorized")
+	}
+
+	endPoint := fmt.Sprintf("/account/%s/lists", config.Get().TMDBAccountID)
+	for page := 1; page <= listMaxPages; page++ {
+		var res userListsPage
+		params := napping.Params{"page": strconv.Itoa(page)}.AsUrlValues()
+		if err = requestV4("GET", endPoint, config.Get().TMDBAccessToken, &params, nil, &res); err != nil {
+			return
+		}
+
+		lists = append(lists, res.Results...)
+		if res.Page >= res.TotalPages {
+			break
+		}
+	}
+	return
+}
+
+// ListMovies returns page of movies from the user's list
+func ListMovies(listID string, language string, page int) (Movies, int) {
+	ids := listEntryIDs(listID, "movie", page)
+	return GetMovies(ids, language), len(listEntries(listID, "movie"))
+}
+
+// ListShows returns page of shows from the user's list
+func ListShows(listID string, language string, page int) (Shows, int) {
+	ids := listEntryIDs(listID, "tv", page)
+	return GetShows(ids, language), len(listEntries(listID, "tv"))
+}
+
+// AddToList adds movie or show, by mediaType "movie" or "tv", to the user's list
+func AddToList(listID string, mediaType string, tmdbID int) error {
+	return changeList("POST", listID, mediaType, tmdbID)
+}
+
+// RemoveFromList removes movie or show, by mediaType "movie" or "tv", from the user's list
+func RemoveFromList(listID string, mediaType string, tmdbID int) error {
+	return changeList("DELETE", listID, mediaType, tmdbID)
+}
+
+func changeList(method string, listID string, mediaType string, tmdbID int) error {
+	if !ListsAuthorized() {
+		return errors.New("TMDB lists are not authorized")
+	}
+
+	payload := listItemsPayload{
+		Items: []listItemPayload{{MediaType: mediaType, MediaID: tmdbID}},
+	}
+	err := requestV4(method, fmt.Sprintf("/list/%s/items", listID), config.Get().TMDBAccessToken, nil, payload, nil)
+	if err == nil {
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(listCachePrefix+listID))
+	}
+	return err
+}
+
+// listEntryIDs returns TMDB IDs of list items with mediaType, for the page
+func listEntryIDs(listID string, mediaType string, page int) []int {
+	entries := listEntries(listID, mediaType)
+	ids := make([]int, 0, TMDBResultsPerPage)
+	for i := (page - 1) * TMDBResultsPerPage; i < len(entries) && len(ids) < TMDBResultsPerPage; i++ {
+		if i >= 0 {
+			ids = append(ids, entries[i].ID)
+		}
+	}
+	return ids
+}
+
+// listEntries returns all items of the list with mediaType. Lists mix movies and shows,
+// so they are read whole, and paged after filtering.
+func listEntries(listID string, mediaType string) []*ListEntry {
+	var entries []*ListEntry
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("%s%s", listCachePrefix, listID)
+	if err := cacheStore.Get(key, &entries); err != nil {
+		entries = []*ListEntry{}
+		for page := 1; page <= listMaxPages; page++ {
+			var res listEntriesPage
+			params := napping.Params{"page": strconv.Itoa(page)}.AsUrlValues()
+			if err := requestV4("GET", "/list/"+listID, config.Get().TMDBAccessToken, &params, nil, &res); err != nil {
+				return nil
+			}
+
+			entries = append(entries, res.Results...)
+			if res.Page >= res.TotalPages {
+				break
+			}
+		}
+		cacheStore.Set(key, entries, recentExpiration)
+	}
+
+	ret := make([]*ListEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.MediaType == mediaType {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// requestV4 makes request to TMDB v4 API, which is authorized with bearer token instead of API key
+func requestV4(method string, endPoint string, token string, params *url.Values, payload interface{}, result interface{}) (ret error) {
+	header := http.Header{
+		"Content-type":  []string{"application/json;charset=utf-8"},
+		"Authorization": []string{fmt.Sprintf("Bearer %s", token)},
+	}
+	req := napping.Request{
+		Url:     tmdbV4Endpoint + endPoint,
+		Method:  method,
+		Params:  params,
+		Payload: payload,
+		Result:  result,
+		Header:  &header,
+	}
+
+	rl.Call(func() error {
+		resp, err := napping.Send(&req)
+		if err != nil {
+			log.Errorf("Failed to make request to %s: %s", req.Url, err)
+			ret = err
+			return nil
+		}
+
+		if resp.Status() == 429 {
+			log.Warningf("Rate limit exceeded on %s, cooling down...", req.Url)
+			rl.CoolDown(resp.HttpResponse().Header)
+			ret = util.ErrExceeded
+			return util.ErrExceeded
+		} else if resp.Status() == 401 {
+			log.Warningf("TMDB access token is not valid for %s", req.Url)
+			ret = errors.New("TMDB access token is not valid, please, re-authorize TMDB")
+			return nil
+		} else if resp.Status() < 200 || resp.Status() >= 300 {
+			log.Errorf("Bad status on %s: %d", req.Url, resp.Status())
+			ret = util.ErrHTTP
+			return nil
+		}
+
+		ret = nil
+		return nil
+	})
+	return
+}
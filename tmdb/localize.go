@@ -0,0 +1,288 @@
+package tmdb
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+var (
+	genreNamesMu sync.Mutex
+	genreNames   = map[string]map[int]string{}
+
+	countryNamesMu sync.Mutex
+	countryNames   map[string]string
+
+	languageNamesMu sync.Mutex
+	languageNames   map[string]string
+
+	// languageCountries maps interface languages to countries of certification systems,
+	// where upper-cased language code is not a country code
+	languageCountries = map[string]string{
+		"en": "US",
+		"ar": "AE",
+		"ca": "ES",
+		"cs": "CZ",
+		"da": "DK",
+		"el": "GR",
+		"et": "EE",
+		"fa": "IR",
+		"he": "IL",
+		"hi": "IN",
+		"ja": "JP",
+		"ko": "KR",
+		"nb": "NO",
+		"sl": "SI",
+		"sr": "RS",
+		"sv": "SE",
+		"uk": "UA",
+		"vi": "VN",
+		"zh": "CN",
+	}
+)
+
+// genreNamesMap returns genre names in the interface language, keeping them
+// in memory, since list items are converted in bulk
+func genreNamesMap(isShow bool) map[int]string {
+	language := config.Get().Language
+	key := "movies." + language
+	if isShow {
+		key = "shows." + language
+	}
+
+	genreNamesMu.Lock()
+	defer genreNamesMu.Unlock()
+
+	if names, ok := genreNames[key]; ok {
+		return names
+	}
+
+	var genres []*Genre
+	if isShow {
+		genres = GetTVGenres(language)
+	} else {
+		genres = GetMovieGenres(language)
+	}
+	if len(genres) == 0 {
+		return nil
+	}
+
+	names := map[int]string{}
+	for _, g := range genres {
+		names[g.ID] = g.Name
+	}
+	genreNames[key] = names
+	return names
+}
+
+// localizedGenres joins genre names, translated into the interface language,
+// names of unknown genres are used as is
+func localizedGenres(genres []*IDName, isShow bool) string {
+	if len(genres) == 0 {
+		return ""
+	}
+
+	names := genreNamesMap(isShow)
+	ret := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		if name, ok := names[genre.ID]; ok && name != "" {
+			ret = append(ret, name)
+		} else {
+			ret = append(ret, genre.Name)
+		}
+	}
+	return strings.Join(ret, " / ")
+}
+
+// localizedCountries joins English names of countries by their codes,
+// unknown codes are used as is
+func localizedCountries(codes []string) string {
+	if len(codes) == 0 {
+		return ""
+	}
+
+	countryNamesMu.Lock()
+	if countryNames == nil {
+		if countries := GetCountries("en"); len(countries) > 0 {
+			countryNames = map[string]string{}
+			for _, c := range countries {
+				countryNames[c.Iso31661] = c.EnglishName
+			}
+		}
+	}
+	names := countryNames
+	countryNamesMu.Unlock()
+
+	ret := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if name, ok := names[code]; ok && name != "" {
+			ret = append(ret, name)
+		} else {
+			ret = append(ret, code)
+		}
+	}
+	return strings.Join(ret, " / ")
+}
+
+// localizedLanguage returns English name of the language by its code
+func localizedLanguage(code string) string {
+	if code == "" {
+		return ""
+	}
+
+	languageNamesMu.Lock()
+	if languageNames == nil {
+		if languages := GetLanguages("en"); len(languages) > 0 {
+			languageNames = map[string]string{}
+			for _, l := range languages {
+				languageNames[l.Iso639_1] = l.EnglishName
+			}
+		}
+	}
+	names := languageNames
+	languageNamesMu.Unlock()
+
+	if name, ok := names[code]; ok && name != "" {
+		return name
+	}
+	return code
+}
+
+// setOriginProperties keeps original language in list item properties, as Kodi has no info label for it
+func setOriginProperties(item *xbmc.ListItem, language string) {
+	if language == "" {
+		return
+	}
+	if item.Properties == nil {
+		item.Properties = map[string]string{}
+	}
+	item.Properties["original_language"] = localizedLanguage(language)
+	item.Properties["original_language_code"] = language
+}
+
+// CertificationCountry returns country, which certification system and release dates are used for labels,
+// content region from settings, or country of interface language
+func CertificationCountry() string {
+	if region := config.Get().ContentRegion; region != "" {
+		return region
+	}
+
+	language := strings.ToLower(config.Get().Language)
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		return strings.ToUpper(language[idx+1:])
+	}
+	if country, ok := languageCountries[language]; ok {
+		return country
+	}
+	return strings.ToUpper(language)
+}
+
+func findCertification(results *ReleaseDatesResults, country string) string {
+	for _, r := range results.Results {
+		if r == nil || r.Iso3166_1 != country {
+			continue
+		}
+		for _, rd := range r.ReleaseDates {
+			if rd != nil && rd.Certification != "" {
+				return rd.Certification
+			}
+		}
+	}
+	return ""
+}
+
+// Certification returns age rating of the movie in the country
+func (movie *Movie) Certification(country string) string {
+	if movie == nil || movie.ReleaseDates == nil {
+		return ""
+	}
+	return findCertification(movie.ReleaseDates, country)
+}
+
+// Certification returns age rating of the show in the country
+func (show *Show) Certification(country string) string {
+	if show == nil || show.ContentRatings == nil {
+		return ""
+	}
+	for _, r := range show.ContentRatings.Results {
+		if r != nil && r.Iso3166_1 == country && r.Rating != "" {
+			return r.Rating
+		}
+	}
+	return ""
+}
+
+// RegionReleaseDate returns the date of the first theatrical or digital release of the movie in the country,
+// or primary release date, when the movie is not released there
+func (movie *Movie) RegionReleaseDate(country string) string {
+	if movie == nil {
+		return ""
+	}
+
+	date := ""
+	if movie.ReleaseDates != nil {
+		for _, r := range movie.ReleaseDates.Results {
+			if r == nil || r.Iso3166_1 != country {
+				continue
+			}
+			for _, rd := range r.ReleaseDates {
+				if rd == nil || rd.Type < releaseTheatricalLimited || rd.Type > releaseDigital || len(rd.ReleaseDate) < 10 {
+					continue
+				}
+				if d := rd.ReleaseDate[0:10]; date == "" || d < date {
+					date = d
+				}
+			}
+		}
+	}
+	if date == "" {
+		return movie.ReleaseDate
+	}
+	return date
+}
+
+// NextAirDate returns air date of the next episode of the show,
+// or of the last one, when it is aired already
+func (show *Show) NextAirDate() string {
+	if show == nil {
+		return ""
+	} else if show.NextEpisodeToAir != nil && show.NextEpisodeToAir.AirDate != "" {
+		return show.NextEpisodeToAir.AirDate
+	} else if show.LastEpisodeToAir != nil && show.LastEpisodeToAir.AirDate != "" {
+		return show.LastEpisodeToAir.AirDate
+	}
+	return show.LastAirDate
+}
+
+// movieCertification returns certification of the movie in user's country system,
+// falling back to US ratings
+func movieCertification(movie *Movie) string {
+	return movie.CertificationLabel(CertificationCountry())
+}
+
+// showCertification returns certification of the show, like movieCertification
+func showCertification(show *Show) string {
+	return show.CertificationLabel(CertificationCountry())
+}
+
+// CertificationLabel returns age rating label of the movie in the country system, or US rating
+func (movie *Movie) CertificationLabel(country string) string {
+	return certificationLabel(movie.Certification, country)
+}
+
+// CertificationLabel returns age rating label of the show in the country system, or US rating
+func (show *Show) CertificationLabel(country string) string {
+	return certificationLabel(show.Certification, country)
+}
+
+func certificationLabel(certification func(country string) string, country string) string {
+	if cert := certification(country); cert != "" && country != "US" {
+		return country + ":" + cert
+	}
+	if cert := certification("US"); cert != "" {
+		return "Rated " + cert
+	}
+	return ""
+}
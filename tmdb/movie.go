@@ -32,6 +32,9 @@ func GetImages(movieID int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.movie.%d.images", movieID)
 	if err := cacheStore.Get(key, &images); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/movie/%d/images", tmdbEndpoint, movieID),
 			Params: napping.Params{
@@ -41,6 +44,7 @@ func GetImages(movieID int) *Images {
 			Result:      &images,
 			Description: "movie images",
 		})
+		rememberNotFound(cacheStore, key, err)
 
 		if images != nil {
 			cacheStore.Set(key, images, imagesCacheExpiration)
@@ -59,22 +63,30 @@ func GetMovieByID(movieID string, language string) *Movie {
 	var movie *Movie
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.movie.%s.%s", movieID, language)
-	if err := cacheStore.Get(key, &movie); err != nil {
-		err = MakeRequest(APIRequest{
+	if isNotFound(cacheStore, key) {
+		return nil
+	}
+	// Expired movie is shown right away, while it is updated in background,
+	// TMDB is asked, whether it is changed, so unchanged movie is not downloaded again
+	err := cacheStore.GetWithRevalidate(key, &movie, cacheHalfExpiration, func(value interface{}, validator *cache.Validator) (bool, error) {
+		err := MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/movie/%s", tmdbEndpoint, movieID),
 			Params: napping.Params{
-				"api_key":            apiKey,
-				"append_to_response": "credits,images,alternative_titles,translations,external_ids,trailers,release_dates",
-				"language":           language,
+				"api_key":                apiKey,
+				"append_to_response":     "credits,images,alternative_titles,translations,external_ids,trailers,release_dates,watch/providers",
+				"language":               language,
+				"include_image_language": imageLanguages(language),
 			}.AsUrlValues(),
-			Result:      &movie,
+			Result:      value,
 			Description: "movie",
+			Validator:   validator,
 		})
-
-		if movie != nil {
-			cacheStore.Set(key, movie, cacheExpiration)
+		if err == errNotModified {
+			return true, nil
 		}
-	}
+		return false, err
+	})
+	rememberNotFound(cacheStore, key, err)
 	if movie == nil {
 		return nil
 	}
@@ -103,13 +115,68 @@ func GetMovies(tmdbIds []int, language string) Movies {
 	return movies
 }
 
+// GetCollection returns collection with its movies, sorted by release date
+func GetCollection(collectionID int, language string) *Collection {
+	var collection *Collection
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.collection.%d.%s", collectionID, language)
+	if err := cacheStore.Get(key, &collection); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/collection/%d", tmdbEndpoint, collectionID),
+			Params: napping.Params{
+				"api_key":  apiKey,
+				"language": language,
+			}.AsUrlValues(),
+			Result:      &collection,
+			Description: "collection",
+		})
+		rememberNotFound(cacheStore, key, err)
+		if collection == nil {
+			return nil
+		}
+
+		sort.Slice(collection.Parts, func(i, j int) bool {
+			// Unreleased parts have no date and go last
+			a, b := collection.Parts[i].ReleaseDate, collection.Parts[j].ReleaseDate
+			if (a == "") != (b == "") {
+				return b == ""
+			}
+			return a < b
+		})
+		cacheStore.Set(key, collection, cacheExpiration)
+	}
+	return collection
+}
+
+// ReleasedParts returns TMDB IDs of released movies of the collection
+func (c *Collection) ReleasedParts() []int {
+	today := time.Now().UTC().Format("2006-01-02")
+
+	ids := make([]int, 0, len(c.Parts))
+	for _, part := range c.Parts {
+		if part != nil && part.ReleaseDate != "" && part.ReleaseDate <= today {
+			ids = append(ids, part.ID)
+		}
+	}
+	return ids
+}
+
+// WatchedCount returns number of watched and number of released movies of the collection
+func (c *Collection) WatchedCount() (watched int, total int) {
+	ids := c.ReleasedParts()
+	return playcount.CountWatchedMoviesByTMDB(ids), len(ids)
+}
+
 // GetMovieGenres ...
 func GetMovieGenres(language string) []*Genre {
 	genres := GenreList{}
 
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.genres.movies.%s", language)
-	if err := cacheStore.Get(key, &genres); err != nil || true {
+	if err := cacheStore.Get(key, &genres); err != nil {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/genre/movie/list", tmdbEndpoint),
 			Params: napping.Params{
@@ -313,6 +380,7 @@ func listMovies(endpoint string, cacheKey string, params napping.Params, page in
 // PopularMovies ...
 func PopularMovies(params DiscoverFilters, language string, page int) (Movies, int) {
 	var p napping.Params
+	cacheKey := "popular"
 	if params.Genre != "" {
 		p = napping.Params{
 			"language":                 language,
@@ -320,6 +388,15 @@ func PopularMovies(params DiscoverFilters, language string, page int) (Movies, i
 			"primary_release_date.lte": time.Now().UTC().Format("2006-01-02"),
 			"with_genres":              params.Genre,
 		}
+	} else if params.Providers != "" {
+		p = napping.Params{
+			"language":                 language,
+			"sort_by":                  "popularity.desc",
+			"primary_release_date.lte": time.Now().UTC().Format("2006-01-02"),
+			"with_watch_providers":     params.Providers,
+			"watch_region":             config.Get().WatchRegion,
+		}
+		cacheKey = fmt.Sprintf("providers.%s.%s", params.Providers, config.Get().WatchRegion)
 	} else if params.Country != "" {
 		p = napping.Params{
 			"language":                 language,
@@ -342,7 +419,7 @@ func PopularMovies(params DiscoverFilters, language string, page int) (Movies, i
 		}
 	}
 
-	return listMovies("discover/movie", "popular", p, page)
+	return listMovies("discover/movie", cacheKey, p, page)
 }
 
 // RecentMovies ...
@@ -384,6 +461,28 @@ func RecentMovies(params DiscoverFilters, language string, page int) (Movies, in
 	return listMovies("discover/movie", "recent", p, page)
 }
 
+// MoviesByYear returns popular movies, released in the year
+func MoviesByYear(year int, language string, page int) (Movies, int) {
+	p := napping.Params{
+		"language":                 language,
+		"sort_by":                  "popularity.desc",
+		"primary_release_year":     strconv.Itoa(year),
+		"primary_release_date.lte": time.Now().UTC().Format("2006-01-02"),
+	}
+
+	return listMovies("discover/movie", fmt.Sprintf("year.%d", year), p, page)
+}
+
+// GetTrendingMovies returns movies, trending during the time window, "day" or "week"
+func GetTrendingMovies(window string, language string, page int) (Movies, int) {
+	return listMovies(fmt.Sprintf("trending/movie/%s", window), "trending."+window, napping.Params{"language": language}, page)
+}
+
+// UpcomingMovies returns movies, which are released soon in the country
+func UpcomingMovies(country string, language string, page int) (Movies, int) {
+	return listMovies("movie/upcoming", "upcoming", napping.Params{"language": language, "region": country}, page)
+}
+
 // TopRatedMovies ...
 func TopRatedMovies(genre string, language string, page int) (Movies, int) {
 	return listMovies("movie/top_rated", "toprated", napping.Params{"language": language}, page)
@@ -421,6 +520,8 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 	if config.Get().UseOriginalTitle && movie.OriginalTitle != "" {
 		title = movie.OriginalTitle
 	}
+	// Premiere date is the release in user's region, which could be months after the primary one
+	premiered := movie.RegionReleaseDate(CertificationCountry())
 
 	item := &xbmc.ListItem{
 		Label:  title,
@@ -436,7 +537,8 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 			Duration:      movie.Runtime * 60,
 			Code:          movie.IMDBId,
 			IMDBNumber:    movie.IMDBId,
-			Date:          movie.ReleaseDate,
+			Date:          premiered,
+			Premiered:     premiered,
 			Votes:         strconv.Itoa(movie.VoteCount),
 			Rating:        movie.VoteAverage,
 			PlayCount:     playcount.GetWatchedMovieByTMDB(movie.ID).Int(),
@@ -444,10 +546,11 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 			Mediatype:     "movie",
 		},
 		Art: &xbmc.ListItemArt{
-			FanArt: ImageURL(movie.BackdropPath, "w1280"),
-			Poster: ImageURL(movie.PosterPath, "w500"),
+			FanArt: ArtURL(movie.BackdropPath, ArtFanart),
+			Poster: ArtURL(movie.PosterPath, ArtPoster),
 		},
 	}
+	setImagesArt(item.Art, movie.Images, config.Get().Language)
 
 	item.Thumbnail = item.Art.Poster
 	item.Art.Thumbnail = item.Art.Poster
@@ -459,11 +562,10 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 		}
 	}
 
-	genres := make([]string, 0, len(movie.Genres))
-	for _, genre := range movie.Genres {
-		genres = append(genres, genre.Name)
-	}
-	item.Info.Genre = strings.Join(genres, " / ")
+	item.Info.Genre = localizedGenres(movie.Genres, false)
+	item.Info.MPAA = movieCertification(movie)
+	item.Info.Country = localizedCountries(movie.OriginCountry)
+	setOriginProperties(item, movie.OriginalLanguage)
 
 	if movie.Trailers != nil {
 		for _, trailer := range movie.Trailers.Youtube {
@@ -496,10 +598,7 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 		break
 	}
 	if movie.Credits != nil {
-		item.Info.CastAndRole = make([][]string, 0)
-		for _, cast := range movie.Credits.Cast {
-			item.Info.CastAndRole = append(item.Info.CastAndRole, []string{cast.Name, cast.Character})
-		}
+		item.Info.CastAndRole = castAndRole(movie.Credits.Cast)
 		directors := make([]string, 0)
 		writers := make([]string, 0)
 		for _, crew := range movie.Credits.Crew {
@@ -513,5 +612,11 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 		item.Info.Director = strings.Join(directors, " / ")
 		item.Info.Writer = strings.Join(writers, " / ")
 	}
+	if movie.BelongsToCollection != nil {
+		item.Info.Set = movie.BelongsToCollection.Name
+		item.Info.SetID = movie.BelongsToCollection.ID
+	}
+	setWatchProviders(item, movie.WatchProviders)
+
 	return item
 }
@@ -0,0 +1,102 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/xbmc"
+)
+
+// WatchProvider is a streaming service, where movie or show could be watched
+type WatchProvider struct {
+	ID              int    `json:"provider_id"`
+	Name            string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+// WatchProviderCountry lists services, available in the country
+type WatchProviderCountry struct {
+	Link     string           `json:"link"`
+	Flatrate []*WatchProvider `json:"flatrate"`
+	Rent     []*WatchProvider `json:"rent"`
+	Buy      []*WatchProvider `json:"buy"`
+}
+
+// WatchProviders are results of watch/providers, with ISO 3166-1 country code as a key
+type WatchProviders struct {
+	Results map[string]*WatchProviderCountry `json:"results"`
+}
+
+// Streaming returns names of subscription services in the region
+func (wp *WatchProviders) Streaming(region string) []string {
+	ret := []string{}
+	if wp == nil || wp.Results == nil {
+		return ret
+	}
+
+	country, ok := wp.Results[region]
+	if !ok || country == nil {
+		return ret
+	}
+
+	for _, p := range country.Flatrate {
+		ret = append(ret, p.Name)
+	}
+	return ret
+}
+
+// GetWatchProviders returns services, available in the region, for "movie" or "tv"
+func GetWatchProviders(mediaType string, region string, language string) []*WatchProvider {
+	providers := []*WatchProvider{}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.providers.%s.%s.%s", mediaType, region, language)
+	if err := cacheStore.Get(key, &providers); err != nil {
+		var results struct {
+			Results []*WatchProvider `json:"results"`
+		}
+
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/watch/providers/%s", tmdbEndpoint, mediaType),
+			Params: napping.Params{
+				"api_key":      apiKey,
+				"language":     language,
+				"watch_region": region,
+			}.AsUrlValues(),
+			Result:      &results,
+			Description: "watch providers",
+		})
+		if err != nil {
+			return providers
+		}
+
+		providers = results.Results
+		sort.Slice(providers, func(i, j int) bool {
+			return providers[i].DisplayPriority < providers[j].DisplayPriority
+		})
+		cacheStore.Set(key, providers, cacheExpiration)
+	}
+	return providers
+}
+
+// setWatchProviders shows streaming services of the user region in the plot
+// and in "watch_providers" property for skins
+func setWatchProviders(item *xbmc.ListItem, wp *WatchProviders) {
+	names := wp.Streaming(config.Get().WatchRegion)
+	if len(names) == 0 {
+		return
+	}
+
+	if item.Properties == nil {
+		item.Properties = map[string]string{}
+	}
+	item.Properties["watch_providers"] = strings.Join(names, " / ")
+
+	item.Info.Plot = fmt.Sprintf("[B]%s[/B]\n%s", strings.Join(names, ", "), item.Info.Plot)
+}
@@ -0,0 +1,108 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/projectx13/projectx/cache"
+)
+
+// recommendationSources is how many recently watched movies are used to build recommendations
+const recommendationSources = 10
+
+// Recommendation is a movie, recommended by TMDB for watched movies
+type Recommendation struct {
+	ID    int
+	Score float64
+	// Because is the watched movie, which gave the biggest part of the score
+	Because int
+}
+
+// GetMovieRecommendations returns IDs of movies, which TMDB recommends for the movie, best first
+func GetMovieRecommendations(movieID int) []int {
+	var ids []int
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf("com.tmdb.movie.%d.recommendations", movieID)
+	if err := cacheStore.Get(key, &ids); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
+
+		var results *EntityList
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/movie/%d/recommendations", tmdbEndpoint, movieID),
+			Params: napping.Params{
+				"api_key": apiKey,
+			}.AsUrlValues(),
+			Result:      &results,
+			Description: "movie recommendations",
+		})
+		rememberNotFound(cacheStore, key, err)
+
+		if results == nil {
+			return nil
+		}
+
+		ids = make([]int, 0, len(results.Results))
+		for _, r := range results.Results {
+			if r != nil {
+				ids = append(ids, r.ID)
+			}
+		}
+		cacheStore.Set(key, ids, cacheExpiration)
+	}
+	return ids
+}
+
+// RecommendMovies merges TMDB recommendations for recently watched movies, most recent first,
+// into one list. Movies, recommended for several watched movies, or recommended higher,
+// or for more recent ones, get higher score. Watched movies are skipped.
+func RecommendMovies(recent []int, watched map[int]bool) []*Recommendation {
+	if len(recent) > recommendationSources {
+		recent = recent[:recommendationSources]
+	}
+
+	lists := make([][]int, len(recent))
+	var wg sync.WaitGroup
+	wg.Add(len(recent))
+	for i, id := range recent {
+		go func(i int, id int) {
+			defer wg.Done()
+			lists[i] = GetMovieRecommendations(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	byID := map[int]*Recommendation{}
+	best := map[int]float64{}
+	ret := []*Recommendation{}
+	for i, ids := range lists {
+		recency := float64(len(recent)-i) / float64(len(recent))
+		for rank, id := range ids {
+			if watched[id] {
+				continue
+			}
+
+			score := recency * float64(len(ids)-rank) / float64(len(ids))
+			r, ok := byID[id]
+			if !ok {
+				r = &Recommendation{ID: id}
+				byID[id] = r
+				ret = append(ret, r)
+			}
+			r.Score += score
+			if score > best[id] {
+				best[id] = score
+				r.Because = recent[i]
+			}
+		}
+	}
+
+	sort.SliceStable(ret, func(i int, j int) bool {
+		return ret[i].Score > ret[j].Score
+	})
+	return ret
+}
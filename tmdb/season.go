@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/projectx13/projectx/cache"
@@ -15,6 +17,47 @@ import (
 	"github.com/jmcvetta/napping"
 )
 
+const (
+	// seasonsPrefetchInterval prevents prefetching the same show on every visit
+	seasonsPrefetchInterval = 30 * time.Minute
+)
+
+var seasonsPrefetched sync.Map
+
+// PrefetchSeasons requests all seasons of the show in background, so they are cached
+// by the time user opens seasons and episodes, instead of one request per opened season
+func PrefetchSeasons(show *Show, language string) {
+	if show == nil || len(show.Seasons) == 0 {
+		return
+	}
+
+	key := fmt.Sprintf("%d.%s", show.ID, language)
+	if last, ok := seasonsPrefetched.Load(key); ok && time.Since(last.(time.Time)) < seasonsPrefetchInterval {
+		return
+	}
+	seasonsPrefetched.Store(key, time.Now())
+
+	seasons := make(chan int, len(show.Seasons))
+	for _, season := range show.Seasons {
+		if season != nil {
+			seasons <- season.Season
+		}
+	}
+	close(seasons)
+
+	seasonsCount := len(show.Seasons)
+	// Parallel season requests are bounded by the device profile,
+	// to leave request slots and CPU for listings, which are opened at the same time
+	workers := util.GetResources().Workers
+	for i := 0; i < workers; i++ {
+		go func() {
+			for season := range seasons {
+				GetSeason(show.ID, season, language, seasonsCount)
+			}
+		}()
+	}
+}
+
 // GetSeason ...
 func GetSeason(showID int, seasonNumber int, language string, seasonsCount int) *Season {
 	var season *Season
@@ -32,39 +75,27 @@ func GetSeason(showID int, seasonNumber int, language string, seasonsCount int)
 
 	key := fmt.Sprintf("com.tmdb.season.%d.%d.%s", showID, seasonNumber, language)
 	if err := cacheStore.Get(key, &season); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d", tmdbEndpoint, showID, seasonNumber),
 			Params: napping.Params{
 				"api_key":            apiKey,
-				"append_to_response": "credits,images,videos,external_ids,alternative_titles,translations,trailers",
+				"append_to_response": "credits,aggregate_credits,images,videos,external_ids,alternative_titles,translations,trailers",
 				"language":           language,
 			}.AsUrlValues(),
 			Result:      &season,
 			Description: "season",
 		})
-
-		if season == nil && err != nil && err == util.ErrNotFound {
-			cacheStore.Set(key, &season, cacheHalfExpiration)
-		}
+		rememberNotFound(cacheStore, key, err)
 		if season == nil {
 			return nil
 		}
 
+		// Empty episode names and overviews are filled by language fallback in MakeRequest
 		season.EpisodeCount = len(season.Episodes)
 
-		// Fix for shows that have translations but return empty strings
-		// for episode names and overviews.
-		// We detect if episodes have their name filled, and if not re-query
-		// with no language set.
-		// See https://github.com/scakemyer/plugin.video.quasar/issues/249
-		if season.EpisodeCount > 0 {
-			for index := 0; index < season.EpisodeCount && index < len(season.Episodes); index++ {
-				if season.Episodes[index] != nil && season.Episodes[index].Name == "" {
-					season.Episodes[index] = GetEpisode(showID, seasonNumber, index+1, language)
-				}
-			}
-		}
-
 		cacheStore.Set(key, &season, time.Duration(updateFrequency)*time.Minute)
 	}
 	return season
@@ -75,10 +106,7 @@ func (seasons SeasonList) ToListItems(show *Show) []*xbmc.ListItem {
 	items := make([]*xbmc.ListItem, 0, len(seasons))
 	specials := make(xbmc.ListItems, 0)
 
-	fanarts := make([]string, 0)
-	for _, backdrop := range show.Images.Backdrops {
-		fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
-	}
+	fanarts := showFanarts(show)
 
 	now := util.UTCBod()
 
@@ -139,6 +167,11 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 			TVShowTitle:   show.OriginalName,
 			Plot:          show.Overview,
 			PlotOutline:   show.Overview,
+			Year:          airYear(season.AirDate),
+			Premiered:     season.AirDate,
+			Aired:         season.AirDate,
+			Duration:      season.Duration(show),
+			Genre:         localizedGenres(show.Genres, true),
 			DBTYPE:        "season",
 			Mediatype:     "season",
 			Code:          show.ExternalIDs.IMDBId,
@@ -149,14 +182,11 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 	}
 
 	if season.Poster != "" {
-		item.Art.Poster = ImageURL(season.Poster, "w500")
-		item.Art.Thumbnail = ImageURL(season.Poster, "w500")
+		item.Art.Poster = ArtURL(season.Poster, ArtPoster)
+		item.Art.Thumbnail = ArtURL(season.Poster, ArtThumb)
 	}
 
-	fanarts := make([]string, 0)
-	for _, backdrop := range show.Images.Backdrops {
-		fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
-	}
+	fanarts := showFanarts(show)
 	if len(fanarts) > 0 {
 		item.Art.FanArt = fanarts[rand.Intn(len(fanarts))]
 	}
@@ -168,9 +198,39 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 		}
 	}
 
-	if len(show.Genres) > 0 {
-		item.Info.Genre = show.Genres[0].Name
-	}
+	item.Info.Rating, item.Info.Votes = season.rating()
 
 	return item
 }
+
+// rating returns rating of the season, averaged from episodes, when they are loaded,
+// since TMDB does not count votes for seasons
+func (season *Season) rating() (float32, string) {
+	sum := float32(0)
+	votes := 0
+	for _, episode := range season.Episodes {
+		if episode != nil && episode.VoteCount > 0 {
+			sum += episode.VoteAverage * float32(episode.VoteCount)
+			votes += episode.VoteCount
+		}
+	}
+	if votes == 0 {
+		return season.VoteAverage, ""
+	}
+	return sum / float32(votes), strconv.Itoa(votes)
+}
+
+// Duration returns total duration of season episodes in seconds
+func (season *Season) Duration(show *Show) int {
+	if len(season.Episodes) == 0 {
+		return season.EpisodeCount * showRuntime(show)
+	}
+
+	total := 0
+	for _, episode := range season.Episodes {
+		if episode != nil {
+			total += episode.Duration(show)
+		}
+	}
+	return total
+}
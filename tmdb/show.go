@@ -12,6 +12,7 @@ import (
 
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
 	"github.com/projectx13/projectx/fanart"
 	"github.com/projectx13/projectx/playcount"
 	"github.com/projectx13/projectx/tvdb"
@@ -34,6 +35,9 @@ func GetShowImages(showID int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.show.%d.images", showID)
 	if err := cacheStore.Get(key, &images); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/images", tmdbEndpoint, showID),
 			Params: napping.Params{
@@ -43,6 +47,7 @@ func GetShowImages(showID int) *Images {
 			Result:      &images,
 			Description: "show images",
 		})
+		rememberNotFound(cacheStore, key, err)
 
 		if images != nil {
 			cacheStore.Set(key, images, imagesCacheExpiration)
@@ -57,6 +62,9 @@ func GetSeasonImages(showID int, season int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.show.%d.%d.images", showID, season)
 	if err := cacheStore.Get(key, &images); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/images", tmdbEndpoint, showID, season),
 			Params: napping.Params{
@@ -66,6 +74,7 @@ func GetSeasonImages(showID int, season int) *Images {
 			Result:      &images,
 			Description: "season images",
 		})
+		rememberNotFound(cacheStore, key, err)
 
 		if images != nil {
 			cacheStore.Set(key, images, imagesCacheExpiration)
@@ -80,6 +89,9 @@ func GetEpisodeImages(showID, season, episode int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.show.%d.%d.%d.images", showID, season, episode)
 	if err := cacheStore.Get(key, &images); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/episode/%d/images", tmdbEndpoint, showID, season, episode),
 			Params: napping.Params{
@@ -89,6 +101,7 @@ func GetEpisodeImages(showID, season, episode int) *Images {
 			Result:      &images,
 			Description: "season images",
 		})
+		rememberNotFound(cacheStore, key, err)
 
 		if images != nil {
 			cacheStore.Set(key, images, imagesCacheExpiration)
@@ -111,20 +124,21 @@ func GetShow(showID int, language string) (show *Show) {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf("com.tmdb.show.%d.%s", showID, language)
 	if err := cacheStore.Get(key, &show); err != nil {
+		if isNotFound(cacheStore, key) {
+			return nil
+		}
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d", tmdbEndpoint, showID),
 			Params: napping.Params{
-				"api_key":            apiKey,
-				"append_to_response": "credits,images,alternative_titles,translations,external_ids",
-				"language":           language,
+				"api_key":                apiKey,
+				"append_to_response":     "credits,images,alternative_titles,translations,external_ids,content_ratings,watch/providers",
+				"language":               language,
+				"include_image_language": imageLanguages(language),
 			}.AsUrlValues(),
 			Result:      &show,
 			Description: "show",
 		})
-
-		if show == nil && err != nil && err == util.ErrNotFound {
-			cacheStore.Set(key, &show, cacheHalfExpiration)
-		}
+		rememberNotFound(cacheStore, key, err)
 		if show == nil {
 			return nil
 		}
@@ -135,6 +149,11 @@ func GetShow(showID int, language string) (show *Show) {
 		return nil
 	}
 
+	show.parsePopularity()
+	return show
+}
+
+func (show *Show) parsePopularity() {
 	switch t := show.RawPopularity.(type) {
 	case string:
 		if popularity, err := strconv.ParseFloat(t, 64); err == nil {
@@ -143,16 +162,29 @@ func GetShow(showID int, language string) (show *Show) {
 	case float64:
 		show.Popularity = t
 	}
-
-	return show
 }
 
 // GetShows ...
 func GetShows(showIds []int, language string) Shows {
-	var wg sync.WaitGroup
+	// Read cached shows at once, and request only missing ones
+	keys := make([]string, len(showIds))
+	for i, showID := range showIds {
+		keys[i] = fmt.Sprintf("com.tmdb.show.%d.%s", showID, language)
+	}
+
 	shows := make(Shows, len(showIds))
-	wg.Add(len(showIds))
+	found, _ := cache.NewDBStore().BatchGet(keys, &shows)
+
+	var wg sync.WaitGroup
 	for i, showID := range showIds {
+		if i < len(found) && found[i] {
+			if shows[i] != nil {
+				shows[i].parsePopularity()
+			}
+			continue
+		}
+
+		wg.Add(1)
 		go func(i int, showId int) {
 			defer wg.Done()
 			shows[i] = GetShow(showId, language)
@@ -276,6 +308,7 @@ func listShows(endpoint string, cacheKey string, params napping.Params, page int
 // PopularShows ...
 func PopularShows(params DiscoverFilters, language string, page int) (Shows, int) {
 	var p napping.Params
+	cacheKey := "popular"
 	if params.Genre != "" {
 		p = napping.Params{
 			"language":           language,
@@ -283,6 +316,15 @@ func PopularShows(params DiscoverFilters, language string, page int) (Shows, int
 			"first_air_date.lte": time.Now().UTC().Format("2006-01-02"),
 			"with_genres":        params.Genre,
 		}
+	} else if params.Providers != "" {
+		p = napping.Params{
+			"language":             language,
+			"sort_by":              "popularity.desc",
+			"first_air_date.lte":   time.Now().UTC().Format("2006-01-02"),
+			"with_watch_providers": params.Providers,
+			"watch_region":         config.Get().WatchRegion,
+		}
+		cacheKey = fmt.Sprintf("providers.%s.%s", params.Providers, config.Get().WatchRegion)
 	} else if params.Country != "" {
 		p = napping.Params{
 			"language":           language,
@@ -305,7 +347,7 @@ func PopularShows(params DiscoverFilters, language string, page int) (Shows, int
 		}
 	}
 
-	return listShows("discover/tv", "popular", p, page)
+	return listShows("discover/tv", cacheKey, p, page)
 }
 
 // RecentShows ...
@@ -379,6 +421,33 @@ func RecentEpisodes(params DiscoverFilters, language string, page int) (Shows, i
 	return listShows("discover/tv", "recent.episodes", p, page)
 }
 
+// ShowsByYear returns popular shows, first aired in the year
+func ShowsByYear(year int, language string, page int) (Shows, int) {
+	p := napping.Params{
+		"language":            language,
+		"sort_by":             "popularity.desc",
+		"first_air_date_year": strconv.Itoa(year),
+		"first_air_date.lte":  time.Now().UTC().Format("2006-01-02"),
+	}
+
+	return listShows("discover/tv", fmt.Sprintf("year.%d", year), p, page)
+}
+
+// GetTrendingShows returns shows, trending during the time window, "day" or "week"
+func GetTrendingShows(window string, language string, page int) (Shows, int) {
+	return listShows(fmt.Sprintf("trending/tv/%s", window), "trending."+window, napping.Params{"language": language}, page)
+}
+
+// OnTheAirShows returns shows, which have episodes airing in the next 7 days
+func OnTheAirShows(language string, page int) (Shows, int) {
+	return listShows("tv/on_the_air", "ontheair", napping.Params{"language": language}, page)
+}
+
+// AiringTodayShows returns shows, which have episodes airing today
+func AiringTodayShows(language string, page int) (Shows, int) {
+	return listShows("tv/airing_today", "airingtoday", napping.Params{"language": language}, page)
+}
+
 // TopRatedShows ...
 func TopRatedShows(genre string, language string, page int) (Shows, int) {
 	return listShows("tv/top_rated", "toprated", napping.Params{"language": language}, page)
@@ -476,17 +545,20 @@ func (show *Show) IsAnime() bool {
 		}
 	}
 
-	return countryIsJP && genreIsAnim
+	if countryIsJP && genreIsAnim {
+		return true
+	}
+	return config.Get().AnimeMetadata && genreIsAnim && show.hasAnimeKeyword()
 }
 
 // AnimeInfo returns absolute episode number and show title
 func (show *Show) AnimeInfo(episode *Episode) (an int, st string) {
 	tvdbID := util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
-	if tvdbShow, err := tvdb.GetShow(tvdbID, config.Get().Language); err == nil {
-		return show.AnimeInfoWithShow(episode, tvdbShow)
+	var tvdbShow *tvdb.Show
+	if tvdbID > 0 {
+		tvdbShow, _ = tvdb.GetShow(tvdbID, config.Get().Language)
 	}
-
-	return
+	return show.AnimeInfoWithShow(episode, tvdbShow)
 }
 
 // AnimeInfoWithShow ...
@@ -503,6 +575,10 @@ func (show *Show) AnimeInfoWithShow(episode *Episode, tvdbShow *tvdb.Show) (an i
 		}
 	}
 
+	// Anime, which is not in TVDB or has no absolute numbers there, is numbered by TMDB seasons
+	if an == 0 && config.Get().AnimeMetadata {
+		an = show.AbsoluteNumber(episode)
+	}
 	return
 }
 
@@ -536,10 +612,11 @@ func (show *Show) ToListItem() *xbmc.ListItem {
 			Mediatype:     "tvshow",
 		},
 		Art: &xbmc.ListItemArt{
-			FanArt: ImageURL(show.BackdropPath, "w1280"),
-			Poster: ImageURL(show.PosterPath, "w500"),
+			FanArt: ArtURL(show.BackdropPath, ArtFanart),
+			Poster: ArtURL(show.PosterPath, ArtPoster),
 		},
 	}
+	setImagesArt(item.Art, show.Images, config.Get().Language)
 
 	item.Thumbnail = item.Art.Poster
 	item.Art.Thumbnail = item.Art.Poster
@@ -551,27 +628,41 @@ func (show *Show) ToListItem() *xbmc.ListItem {
 		}
 	}
 
-	if show.InProduction {
+	// Art, chosen by the user, is kept over fanart.tv art
+	if artwork := database.GetStorm().GetArtwork(show.ID); artwork != nil {
+		setChosenArt(item.Art, artwork)
+		if artwork.Poster != "" {
+			item.Art.Thumbnail = item.Art.Poster
+			item.Thumbnail = item.Art.Poster
+		}
+	}
+
+	if show.Status != "" {
+		item.Info.Status = show.Status
+	} else if show.InProduction {
 		item.Info.Status = "Continuing"
 	} else {
 		item.Info.Status = "Discontinued"
 	}
 
-	genres := make([]string, 0, len(show.Genres))
-	for _, genre := range show.Genres {
-		genres = append(genres, genre.Name)
-	}
-	item.Info.Genre = strings.Join(genres, " / ")
+	item.Info.Genre = localizedGenres(show.Genres, true)
+	item.Info.MPAA = showCertification(show)
+	item.Info.Country = localizedCountries(show.OriginCountry)
+	setOriginProperties(item, show.OriginalLanguage)
 
-	for _, company := range show.ProductionCompanies {
-		item.Info.Studio = company.Name
+	// Network is what skins show as studio for shows
+	for _, network := range show.Networks {
+		item.Info.Studio = network.Name
 		break
 	}
-	if show.Credits != nil {
-		item.Info.CastAndRole = make([][]string, 0)
-		for _, cast := range show.Credits.Cast {
-			item.Info.CastAndRole = append(item.Info.CastAndRole, []string{cast.Name, cast.Character})
+	if item.Info.Studio == "" {
+		for _, company := range show.ProductionCompanies {
+			item.Info.Studio = company.Name
+			break
 		}
+	}
+	if show.Credits != nil {
+		item.Info.CastAndRole = castAndRole(show.Credits.Cast)
 		directors := make([]string, 0)
 		writers := make([]string, 0)
 		for _, crew := range show.Credits.Crew {
@@ -585,5 +676,7 @@ func (show *Show) ToListItem() *xbmc.ListItem {
 		item.Info.Director = strings.Join(directors, " / ")
 		item.Info.Writer = strings.Join(writers, " / ")
 	}
+	setWatchProviders(item, show.WatchProviders)
+
 	return item
 }
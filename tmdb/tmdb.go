@@ -1,8 +1,10 @@
 package tmdb
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"sort"
 	"time"
@@ -24,6 +26,9 @@ const (
 
 var (
 	log = logging.MustGetLogger("tmdb")
+
+	// errNotModified is returned by conditional requests, when cached response is current
+	errNotModified = errors.New("not modified")
 )
 
 // Movies ...
@@ -45,6 +50,7 @@ type Movie struct {
 	IMDBId              string       `json:"imdb_id"`
 	Overview            string       `json:"overview"`
 	ProductionCompanies []*IDName    `json:"production_companies"`
+	OriginCountry       []string     `json:"origin_country"`
 	Runtime             int          `json:"runtime"`
 	TagLine             string       `json:"tagline"`
 	RawPopularity       interface{}  `json:"popularity"`
@@ -68,6 +74,20 @@ type Movie struct {
 	Images  *Images  `json:"images,omitempty"`
 
 	ReleaseDates *ReleaseDatesResults `json:"release_dates"`
+
+	BelongsToCollection *Collection `json:"belongs_to_collection"`
+
+	WatchProviders *WatchProviders `json:"watch/providers,omitempty"`
+}
+
+// Collection is a set of movies, like all parts of a franchise
+type Collection struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Overview     string    `json:"overview"`
+	PosterPath   string    `json:"poster_path"`
+	BackdropPath string    `json:"backdrop_path"`
+	Parts        []*Entity `json:"parts,omitempty"`
 }
 
 // Show ...
@@ -78,6 +98,8 @@ type Show struct {
 	Homepage            string       `json:"homepage"`
 	InProduction        bool         `json:"in_production"`
 	LastAirDate         string       `json:"last_air_date"`
+	LastEpisodeToAir    *Episode     `json:"last_episode_to_air"`
+	NextEpisodeToAir    *Episode     `json:"next_episode_to_air"`
 	Networks            []*IDName    `json:"networks"`
 	NumberOfEpisodes    int          `json:"number_of_episodes"`
 	NumberOfSeasons     int          `json:"number_of_seasons"`
@@ -100,6 +122,10 @@ type Show struct {
 	Images  *Images  `json:"images,omitempty"`
 
 	Seasons SeasonList `json:"seasons"`
+
+	ContentRatings *ContentRatings `json:"content_ratings,omitempty"`
+
+	WatchProviders *WatchProviders `json:"watch/providers,omitempty"`
 }
 
 // Season ...
@@ -110,6 +136,7 @@ type Season struct {
 	EpisodeCount int          `json:"episode_count,omitempty"`
 	AirDate      string       `json:"air_date"`
 	Poster       string       `json:"poster_path"`
+	VoteAverage  float32      `json:"vote_average"`
 	ExternalIDs  *ExternalIDs `json:"external_ids"`
 
 	AlternativeTitles *struct {
@@ -124,8 +151,9 @@ type Season struct {
 		Youtube []*Trailer `json:"youtube"`
 	} `json:"trailers"`
 
-	Credits *Credits `json:"credits,omitempty"`
-	Images  *Images  `json:"images,omitempty"`
+	Credits          *Credits          `json:"credits,omitempty"`
+	AggregateCredits *AggregateCredits `json:"aggregate_credits,omitempty"`
+	Images           *Images           `json:"images,omitempty"`
 
 	Episodes EpisodeList `json:"episodes"`
 }
@@ -139,6 +167,8 @@ type Episode struct {
 	SeasonNumber  int          `json:"season_number"`
 	EpisodeNumber int          `json:"episode_number"`
 	VoteAverage   float32      `json:"vote_average"`
+	VoteCount     int          `json:"vote_count"`
+	Runtime       int          `json:"runtime"`
 	StillPath     string       `json:"still_path"`
 	ExternalIDs   *ExternalIDs `json:"external_ids"`
 
@@ -154,8 +184,10 @@ type Episode struct {
 		Youtube []*Trailer `json:"youtube"`
 	} `json:"trailers"`
 
-	Credits *Credits `json:"credits,omitempty"`
-	Images  *Images  `json:"images,omitempty"`
+	Credits    *Credits `json:"credits,omitempty"`
+	GuestStars []*Cast  `json:"guest_stars,omitempty"`
+	Crew       []*Crew  `json:"crew,omitempty"`
+	Images     *Images  `json:"images,omitempty"`
 }
 
 // Entity ...
@@ -214,10 +246,11 @@ type LanguageList struct {
 
 // Image ...
 type Image struct {
-	FilePath string `json:"file_path"`
-	Height   int    `json:"height"`
-	Iso639_1 string `json:"iso_639_1"`
-	Width    int    `json:"width"`
+	FilePath    string  `json:"file_path"`
+	Height      int     `json:"height"`
+	Iso639_1    string  `json:"iso_639_1"`
+	Width       int     `json:"width"`
+	VoteAverage float32 `json:"vote_average"`
 }
 
 // Images ...
@@ -225,6 +258,7 @@ type Images struct {
 	Backdrops []*Image `json:"backdrops"`
 	Posters   []*Image `json:"posters"`
 	Stills    []*Image `json:"stills"`
+	Logos     []*Image `json:"logos"`
 }
 
 // Cast ...
@@ -248,8 +282,9 @@ type Crew struct {
 
 // Credits ...
 type Credits struct {
-	Cast []*Cast `json:"cast"`
-	Crew []*Crew `json:"crew"`
+	Cast       []*Cast `json:"cast"`
+	Crew       []*Crew `json:"crew"`
+	GuestStars []*Cast `json:"guest_stars,omitempty"`
 }
 
 // ExternalIDs ...
@@ -331,6 +366,17 @@ type ReleaseDates struct {
 	ReleaseDates []*ReleaseDate `json:"release_dates"`
 }
 
+// ContentRatings are age ratings of the show in each country
+type ContentRatings struct {
+	Results []*ContentRating `json:"results"`
+}
+
+// ContentRating is an age rating of the show in the country
+type ContentRating struct {
+	Iso3166_1 string `json:"iso_3166_1"`
+	Rating    string `json:"rating"`
+}
+
 // ReleaseDate ...
 type ReleaseDate struct {
 	Certification string `json:"certification"`
@@ -340,11 +386,18 @@ type ReleaseDate struct {
 	Type          int    `json:"type"`
 }
 
+// Release types of ReleaseDate, which are used to find when the movie is available in the country
+const (
+	releaseTheatricalLimited = 2
+	releaseDigital           = 4
+)
+
 // DiscoverFilters ...
 type DiscoverFilters struct {
-	Genre    string
-	Country  string
-	Language string
+	Genre     string
+	Country   string
+	Language  string
+	Providers string
 }
 
 // APIRequest ...
@@ -354,16 +407,18 @@ type APIRequest struct {
 	Result      interface{}
 	ErrMsg      interface{}
 	Description string
+	// Validator is a version of the cached response, it is sent with the request,
+	// and is updated with a version of the new response
+	Validator *cache.Validator `msg:"-"`
 }
 
 const (
 	tmdbEndpoint  = "https://api.themoviedb.org/3"
 	imageEndpoint = "http://image.tmdb.org/t/p/"
-	burstRate     = 150
-	burstTime     = 10 * time.Second
-	// Currently TMDB is disabled rates limiting
-	// burstRate               = 40
-	// burstTime               = 10 * time.Second
+	// TMDB allows around 50 requests per second, keeping some room for other clients with the same key
+	requestsPerSecond = 40
+	burstRate         = 40
+	// simultaneousConnections bounds requests in flight, bulk library sync waits for a free slot
 	simultaneousConnections = 20
 	cacheExpiration         = 6 * 24 * time.Hour
 	cacheHalfExpiration     = 3 * 24 * time.Hour
@@ -371,6 +426,10 @@ const (
 	imagesCacheExpiration   = 14 * 24 * time.Hour
 	resolveCacheExpiration  = 14 * 24 * time.Hour
 	findCacheExpiration     = 14 * 24 * time.Hour
+	// notFoundExpiration is short, so entities, which are added to TMDB later, show up soon
+	notFoundExpiration = 2 * time.Hour
+	// notFoundValue is cached under own key, since entity keys are decoded into entity types
+	notFoundValue = "notfound"
 )
 
 var (
@@ -384,7 +443,8 @@ var (
 	WarmingUp = util.Event{}
 )
 
-var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
+// rl is shared by all TMDB requests, so Retry-After from one response pauses all of them
+var rl = util.NewTokenBucketRateLimiter(requestsPerSecond, burstRate, simultaneousConnections).WithPool(util.MetadataPool)
 
 // CheckAPIKey ...
 func CheckAPIKey() {
@@ -450,6 +510,64 @@ func ImageURL(uri string, size string) string {
 	return imageEndpoint + size + uri
 }
 
+// Artwork types, each has own image quality in the settings
+const (
+	ArtPoster = iota
+	ArtFanart
+	ArtThumb
+	ArtStill
+	ArtLogo
+)
+
+// Image qualities, in order of image_quality_* settings
+const (
+	ImageQualityLow = iota
+	ImageQualityMedium
+	ImageQualityHigh
+	ImageQualityOriginal
+)
+
+// imageSizes are TMDB sizes of each artwork type, in order of image qualities.
+// Logos have no own setting and follow thumbnails quality.
+var imageSizes = map[int][]string{
+	ArtPoster: {"w185", "w342", "w500", "original"},
+	ArtFanart: {"w300", "w780", "w1280", "original"},
+	ArtThumb:  {"w154", "w342", "w500", "original"},
+	ArtStill:  {"w92", "w185", "w300", "original"},
+	ArtLogo:   {"w92", "w185", "w500", "original"},
+}
+
+// ImageSize returns TMDB size of the artwork type for the quality, selected in the settings,
+// lower qualities keep Kodi texture cache small on low-memory devices
+func ImageSize(art int) string {
+	sizes, ok := imageSizes[art]
+	if !ok {
+		sizes = imageSizes[ArtPoster]
+	}
+
+	quality := ImageQualityHigh
+	switch art {
+	case ArtPoster:
+		quality = config.Get().ImageQualityPoster
+	case ArtFanart:
+		quality = config.Get().ImageQualityFanart
+	case ArtThumb, ArtLogo:
+		quality = config.Get().ImageQualityThumb
+	case ArtStill:
+		quality = config.Get().ImageQualityStill
+	}
+
+	if quality < ImageQualityLow || quality > ImageQualityOriginal {
+		quality = ImageQualityHigh
+	}
+	return sizes[quality]
+}
+
+// ArtURL returns URL of the image with the size of the artwork type
+func ArtURL(uri string, art int) string {
+	return ImageURL(uri, ImageSize(art))
+}
+
 // ListEntities ...
 // TODO Unused...
 // func ListEntities(endpoint string, params napping.Params) []*Entity {
@@ -578,37 +696,84 @@ func GetLanguages(language string) []*Language {
 	return languages
 }
 
-// MakeRequest used to proxy requests with proper RateLimiter usage and HTTP error processing
+// MakeRequest used to proxy requests with proper RateLimiter usage and HTTP error processing,
+// missing titles and overviews of results are filled from fallback languages
 func MakeRequest(r APIRequest) (ret error) {
+	if ret = makeRequest(r); ret == nil {
+		applyLanguageFallback(r)
+	}
+	return
+}
+
+// makeRequest sends conditional request, when validator of the cached response is set,
+// errNotModified is returned, when TMDB answers, that cached response is current
+func makeRequest(r APIRequest) (ret error) {
 	rl.Call(func() error {
-		resp, err := napping.Get(
-			r.URL,
-			&r.Params,
-			r.Result,
-			r.ErrMsg,
-		)
+		header := http.Header{}
+		if r.Validator != nil && r.Validator.ETag != "" {
+			header.Set("If-None-Match", r.Validator.ETag)
+		}
+		if r.Validator != nil && r.Validator.LastModified != "" {
+			header.Set("If-Modified-Since", r.Validator.LastModified)
+		}
+		req := napping.Request{
+			Url:    r.URL,
+			Method: "GET",
+			Params: &r.Params,
+			Result: r.Result,
+			Error:  r.ErrMsg,
+			Header: &header,
+		}
+
+		resp, err := napping.Send(&req)
 		if err != nil {
 			log.Errorf("Failed to make request to %s for %s with %+v: %s", r.URL, r.Description, r.Params, err)
 			ret = err
-		} else if resp.Status() == 429 {
+			return nil
+		}
+
+		util.AdjustClockFromHeader(resp.HttpResponse().Header)
+		if resp.Status() == 429 {
 			log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
 			rl.CoolDown(resp.HttpResponse().Header)
 			ret = util.ErrExceeded
 			return util.ErrExceeded
 		} else if resp.Status() == 404 {
-			log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
-			rl.CoolDown(resp.HttpResponse().Header)
+			log.Warningf("Not found getting %s with %+v on %s", r.Description, r.Params, r.URL)
 			ret = util.ErrNotFound
 			return util.ErrNotFound
+		} else if resp.Status() == 304 && r.Validator != nil {
+			ret = errNotModified
+			return nil
 		} else if resp.Status() != 200 {
 			log.Errorf("Bad status getting %s with %+v on %s: %d", r.Description, r.Params, r.URL, resp.Status())
 			ret = util.ErrHTTP
 			return util.ErrHTTP
 		}
 
+		if r.Validator != nil {
+			*r.Validator = cache.Validator{
+				ETag:         resp.HttpResponse().Header.Get("ETag"),
+				LastModified: resp.HttpResponse().Header.Get("Last-Modified"),
+			}
+		}
+
 		ret = nil
 		return nil
 	})
 
 	return
 }
+
+// isNotFound checks whether TMDB has recently answered, that entity, cached with the key, does not exist
+func isNotFound(cacheStore *cache.DBStore, key string) bool {
+	var value string
+	return cacheStore.Get(key+".notfound", &value) == nil && value == notFoundValue
+}
+
+// rememberNotFound keeps missing entity for a short time, other errors are temporary and not cached
+func rememberNotFound(cacheStore *cache.DBStore, key string, err error) {
+	if err == util.ErrNotFound {
+		cacheStore.Set(key+".notfound", notFoundValue, notFoundExpiration)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/ids"
 	"github.com/projectx13/projectx/playcount"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/util"
@@ -49,20 +50,20 @@ func setFanart(movie *Movie) *Movie {
 	}
 
 	if len(tmdbImages.Posters) > 0 {
-		posterImage := tmdb.ImageURL(tmdbImages.Posters[0].FilePath, "w500")
+		posterImage := tmdb.ArtURL(tmdbImages.Posters[0].FilePath, tmdb.ArtPoster)
 		for _, image := range tmdbImages.Posters {
 			if image.Iso639_1 == config.Get().Language {
-				posterImage = tmdb.ImageURL(image.FilePath, "w500")
+				posterImage = tmdb.ArtURL(image.FilePath, tmdb.ArtPoster)
 			}
 		}
 		movie.Images.Poster.Full = posterImage
 		movie.Images.Thumbnail.Full = posterImage
 	}
 	if len(tmdbImages.Backdrops) > 0 {
-		backdropImage := tmdb.ImageURL(tmdbImages.Backdrops[0].FilePath, "w1280")
+		backdropImage := tmdb.ArtURL(tmdbImages.Backdrops[0].FilePath, tmdb.ArtFanart)
 		for _, image := range tmdbImages.Backdrops {
 			if image.Iso639_1 == config.Get().Language {
-				backdropImage = tmdb.ImageURL(image.FilePath, "w1280")
+				backdropImage = tmdb.ArtURL(image.FilePath, tmdb.ArtFanart)
 			}
 		}
 		movie.Images.FanArt.Full = backdropImage
@@ -121,6 +122,9 @@ func GetMovie(ID string) (movie *Movie) {
 			log.Warning(err)
 		}
 
+		if movie != nil {
+			storeIDs(ids.Movie, movie.IDs)
+		}
 		cacheStore.Set(key, movie, cacheExpiration)
 	}
 
@@ -150,6 +154,9 @@ func GetMovieByTMDB(tmdbID string) (movie *Movie) {
 		if results != nil && len(results) > 0 && results[0].Movie != nil {
 			movie = results[0].Movie
 		}
+		if movie != nil {
+			storeIDs(ids.Movie, movie.IDs)
+		}
 		cacheStore.Set(key, movie, cacheExpiration)
 	}
 	return
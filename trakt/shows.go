@@ -11,6 +11,7 @@ import (
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
 	"github.com/projectx13/projectx/fanart"
+	"github.com/projectx13/projectx/ids"
 	"github.com/projectx13/projectx/playcount"
 	"github.com/projectx13/projectx/tmdb"
 	"github.com/projectx13/projectx/util"
@@ -49,20 +50,20 @@ func setShowFanart(show *Show) *Show {
 	}
 
 	if len(tmdbImages.Posters) > 0 {
-		posterImage := tmdb.ImageURL(tmdbImages.Posters[0].FilePath, "w500")
+		posterImage := tmdb.ArtURL(tmdbImages.Posters[0].FilePath, tmdb.ArtPoster)
 		for _, image := range tmdbImages.Posters {
 			if image.Iso639_1 == config.Get().Language {
-				posterImage = tmdb.ImageURL(image.FilePath, "w500")
+				posterImage = tmdb.ArtURL(image.FilePath, tmdb.ArtPoster)
 			}
 		}
 		show.Images.Poster.Full = posterImage
 		show.Images.Thumbnail.Full = posterImage
 	}
 	if len(tmdbImages.Backdrops) > 0 {
-		backdropImage := tmdb.ImageURL(tmdbImages.Backdrops[0].FilePath, "w1280")
+		backdropImage := tmdb.ArtURL(tmdbImages.Backdrops[0].FilePath, tmdb.ArtFanart)
 		for _, image := range tmdbImages.Backdrops {
 			if image.Iso639_1 == config.Get().Language {
-				backdropImage = tmdb.ImageURL(image.FilePath, "w1280")
+				backdropImage = tmdb.ArtURL(image.FilePath, tmdb.ArtFanart)
 			}
 		}
 		show.Images.FanArt.Full = backdropImage
@@ -135,6 +136,9 @@ func GetShow(ID string) (show *Show) {
 			log.Warning(err)
 		}
 
+		if show != nil {
+			storeIDs(ids.Show, show.IDs)
+		}
 		cacheStore.Set(key, show, cacheExpiration)
 	}
 
@@ -164,6 +168,9 @@ func GetShowByTMDB(tmdbID string) (show *Show) {
 		if results != nil && len(results) > 0 && results[0].Show != nil {
 			show = results[0].Show
 		}
+		if show != nil {
+			storeIDs(ids.Show, show.IDs)
+		}
 		cacheStore.Set(key, show, cacheExpiration)
 	}
 	return
@@ -187,6 +194,9 @@ func GetShowByTVDB(tvdbID string) (show *Show) {
 		if err := resp.Unmarshal(&show); err != nil {
 			log.Warning(err)
 		}
+		if show != nil {
+			storeIDs(ids.Show, show.IDs)
+		}
 		cacheStore.Set(key, show, cacheExpiration)
 	}
 	return
@@ -883,10 +893,10 @@ func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
 		item.Art.Poster = episode.Images.ScreenShot.Full
 		item.Thumbnail = episode.Images.ScreenShot.Full
 	} else if epi := tmdb.GetEpisode(show.IDs.TMDB, episode.Season, episode.Number, config.Get().Language); epi != nil && epi.StillPath != "" {
-		item.Art.FanArt = tmdb.ImageURL(epi.StillPath, "w1280")
-		item.Art.Thumbnail = tmdb.ImageURL(epi.StillPath, "w500")
-		item.Art.Poster = tmdb.ImageURL(epi.StillPath, "w500")
-		item.Thumbnail = tmdb.ImageURL(epi.StillPath, "w500")
+		item.Art.FanArt = tmdb.ArtURL(epi.StillPath, tmdb.ArtFanart)
+		item.Art.Thumbnail = tmdb.ArtURL(epi.StillPath, tmdb.ArtStill)
+		item.Art.Poster = tmdb.ArtURL(epi.StillPath, tmdb.ArtStill)
+		item.Thumbnail = tmdb.ArtURL(epi.StillPath, tmdb.ArtStill)
 	}
 
 	return item
@@ -13,6 +13,8 @@ import (
 
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/config"
+	"github.com/projectx13/projectx/database"
+	"github.com/projectx13/projectx/ids"
 	"github.com/projectx13/projectx/util"
 	"github.com/projectx13/projectx/xbmc"
 	"github.com/jmcvetta/napping"
@@ -73,7 +75,7 @@ const (
 	ProgressSortAiredOlder
 )
 
-var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
+var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections).WithPool(util.MetadataPool)
 
 // Object ...
 type Object struct {
@@ -294,6 +296,13 @@ type IDs struct {
 	Slug   string `json:"slug"`
 }
 
+// storeIDs links IDs of Trakt item, so other packages resolve them without requests
+func storeIDs(kind string, i *IDs) {
+	if i != nil {
+		ids.Store(kind, i.TMDB, i.IMDB, i.TVDB, i.Trakt)
+	}
+}
+
 // Code ...
 type Code struct {
 	DeviceCode      string `json:"device_code"`
@@ -874,9 +883,8 @@ func TokenRefreshHandler() {
 						log.Error(errUnm)
 					} else {
 						expiry := time.Now().Unix() + int64(token.ExpiresIn)
+						saveToken(token)
 						xbmc.SetSetting("trakt_token_expiry", strconv.Itoa(int(expiry)))
-						xbmc.SetSetting("trakt_token", token.AccessToken)
-						xbmc.SetSetting("trakt_refresh_token", token.RefreshToken)
 						log.Noticef("Token refreshed for Trakt authorization, next refresh in %s", time.Duration(token.ExpiresIn-259200)*time.Second)
 					}
 				} else {
@@ -921,11 +929,8 @@ func Authorize(fromSettings bool) error {
 	}
 
 	expiry := time.Now().Unix() + int64(token.ExpiresIn)
+	saveToken(token)
 	xbmc.SetSetting("trakt_token_expiry", strconv.Itoa(int(expiry)))
-	xbmc.SetSetting("trakt_token", token.AccessToken)
-	xbmc.SetSetting("trakt_refresh_token", token.RefreshToken)
-
-	config.Get().TraktToken = token.AccessToken
 
 	// Getting username for currently authorized user
 	params := napping.Params{}.AsUrlValues()
@@ -947,6 +952,15 @@ func Authorize(fromSettings bool) error {
 	return nil
 }
 
+// saveToken keeps tokens in encrypted storage, instead of settings
+func saveToken(token *Token) {
+	database.SetCredential("trakt_token", token.AccessToken)
+	database.SetCredential("trakt_refresh_token", token.RefreshToken)
+
+	config.Get().TraktToken = token.AccessToken
+	config.Get().TraktRefreshToken = token.RefreshToken
+}
+
 // Authorized ...
 func Authorized() error {
 	if config.Get().TraktToken == "" {
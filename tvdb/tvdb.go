@@ -6,12 +6,14 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/projectx13/projectx/cache"
 	"github.com/projectx13/projectx/proxy"
+	"github.com/projectx13/projectx/util"
 )
 
 //go:generate msgp -o msgp.go -io=false -tests=false
@@ -133,6 +135,9 @@ func getShow(tvdbID int, language string) (*Show, error) {
 		Actors []*Actor `xml:"Actor"`
 	}
 
+	util.MetadataPool.Acquire()
+	defer util.MetadataPool.Release()
+
 	resp, err := proxy.GetClient().Get(fmt.Sprintf("%s/%s/series/%d/all/%s.zip", tvdbEndpoint, apiKey, tvdbID, language))
 	if err != nil {
 		return nil, err
@@ -272,3 +277,29 @@ func (a BannersByRating) Less(i, j int) bool {
 func (s SeasonList) Len() int           { return len(s) }
 func (s SeasonList) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s SeasonList) Less(i, j int) bool { return s[i].Season < s[j].Season }
+
+// GetFavorites returns TVDB ids of series, marked as favorites by the account
+func GetFavorites(accountID string) ([]int, error) {
+	var favorites struct {
+		Series []int `xml:"Series"`
+	}
+
+	util.MetadataPool.Acquire()
+	defer util.MetadataPool.Release()
+
+	resp, err := proxy.GetClient().Get(fmt.Sprintf("%s/User_Favorites.php?accountid=%s", tvdbEndpoint, url.QueryEscape(accountID)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Bad status getting TVDB favorites: %s", resp.Status)
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&favorites); err != nil {
+		return nil, err
+	}
+
+	return favorites.Series, nil
+}
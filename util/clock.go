@@ -0,0 +1,101 @@
+package util
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// clockSkewThreshold is a difference with reference time, below which clock is considered correct
+const clockSkewThreshold = 2 * time.Minute
+
+var (
+	// clockOffset is added to system time, to correct wrong clock of devices
+	// without RTC battery, like Raspberry Pi, in nanoseconds
+	clockOffset int64
+
+	clockVerified     = make(chan struct{})
+	clockVerifiedOnce sync.Once
+)
+
+// Now returns current time in UTC, corrected by detected clock skew
+func Now() time.Time {
+	return time.Now().UTC().Add(ClockOffset())
+}
+
+// ClockOffset returns correction, which is added to system time
+func ClockOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&clockOffset))
+}
+
+// IsClockVerified tells whether system time was compared with reference time, like server Date header
+func IsClockVerified() bool {
+	select {
+	case <-clockVerified:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitClockVerified waits until clock is verified or timeout has passed,
+// returns false, if clock is still not verified, like when there is no network
+func WaitClockVerified(timeout time.Duration) bool {
+	select {
+	case <-clockVerified:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// AdjustClock compares system time with reference time, and sets correction,
+// if they differ more than clockSkewThreshold
+func AdjustClock(reference time.Time) {
+	if reference.IsZero() {
+		return
+	}
+
+	skew := reference.Sub(time.Now())
+	// Small differences are caused by network latency, so correction is changed only on large drift
+	if diff := skew - ClockOffset(); diff >= clockSkewThreshold || diff <= -clockSkewThreshold {
+		if skew < clockSkewThreshold && skew > -clockSkewThreshold {
+			skew = 0
+		}
+		atomic.StoreInt64(&clockOffset, int64(skew))
+
+		if skew == 0 {
+			log.Infof("System clock matches reference time, clock correction is removed")
+		} else {
+			log.Warningf("System clock differs from reference time by %s, correcting cache expiration", skew.Round(time.Second))
+		}
+	}
+
+	clockVerifiedOnce.Do(func() {
+		close(clockVerified)
+	})
+}
+
+// AdjustClockFromHeader uses Date header of HTTP response as reference time
+func AdjustClockFromHeader(header http.Header) {
+	if header == nil || header.Get("Date") == "" {
+		return
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		AdjustClock(date)
+	}
+}
+
+// EnsureClockAfter corrects clock, that is earlier than t, like last time the daemon was running,
+// clock of devices without RTC battery goes back on each reboot, until time is synced
+func EnsureClockAfter(t time.Time) {
+	if IsClockVerified() || t.IsZero() {
+		return
+	}
+
+	if skew := t.Sub(time.Now()); skew >= clockSkewThreshold {
+		log.Warningf("System clock is %s behind last known time, correcting cache expiration", skew.Round(time.Second))
+		atomic.StoreInt64(&clockOffset, int64(skew))
+	}
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"io"
+	"sync"
+
+	"github.com/projectx13/projectx/config"
+)
+
+// ConnectionPool bounds simultaneous network requests of one kind of traffic,
+// so bursts of one kind, like provider scraping, do not take the whole link from others
+type ConnectionPool struct {
+	limit  func() int
+	active int
+	mu     sync.Mutex
+	cond   *sync.Cond
+}
+
+var (
+	// MetadataPool is used by TMDB, Trakt, Fanart.tv and TVDB requests
+	MetadataPool = NewConnectionPool(func() int { return config.Get().MetadataConnectionsLimit })
+	// ProviderPool is used by provider scraping through internal proxy and by torrent file downloads
+	ProviderPool = NewConnectionPool(func() int { return config.Get().ProviderConnectionsLimit })
+)
+
+// NewConnectionPool creates pool with the limit, which is read on each request,
+// so it follows settings changes. Zero or negative limit means unlimited.
+func NewConnectionPool(limit func() int) *ConnectionPool {
+	p := &ConnectionPool{
+		limit: limit,
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Acquire waits for a free slot in the pool
+func (p *ConnectionPool) Acquire() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if limit := p.limit(); limit <= 0 || p.active < limit {
+			break
+		}
+		p.cond.Wait()
+	}
+	p.active++
+}
+
+// Release frees the slot, taken by Acquire
+func (p *ConnectionPool) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active > 0 {
+		p.active--
+	}
+	// Limit could be raised in settings meanwhile, so all waiters check it again
+	p.cond.Broadcast()
+}
+
+// Active returns number of requests in flight
+func (p *ConnectionPool) Active() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.active
+}
+
+// ReleaseOnClose keeps the slot, until response body is closed, as the traffic goes while it is read
+func (p *ConnectionPool) ReleaseOnClose(body io.ReadCloser) io.ReadCloser {
+	return &pooledBody{ReadCloser: body, pool: p}
+}
+
+type pooledBody struct {
+	io.ReadCloser
+	pool *ConnectionPool
+	once sync.Once
+}
+
+func (b *pooledBody) Close() error {
+	b.once.Do(b.pool.Release)
+	return b.ReadCloser.Close()
+}
@@ -24,9 +24,28 @@ type RateLimiter struct {
 	mtx          sync.Mutex
 	times        list.List
 	parallelChan chan bool
-	coolDown     bool
+
+	// Token bucket, used instead of times list when rate is set
+	rate       float64
+	burst      float64
+	tokens     float64
+	lastRefill time.Time
+
+	// pausedUntil is set from Retry-After, all callers wait till that time
+	pausedUntil time.Time
+
+	// pool is shared with other limiters of the same kind of traffic
+	pool *ConnectionPool
 }
 
+const (
+	// defaultCoolDown is used when API returns 429 without Retry-After
+	defaultCoolDown = 2 * time.Second
+	// retryBackoff is a delay before the first retry, doubled for next ones
+	retryBackoff = 1 * time.Second
+	maxRetries   = 3
+)
+
 // ErrExceeded should be returned if we need to rerun the function
 var (
 	ErrExceeded = errors.New("Rate-Limit Exceeded")
@@ -45,6 +64,27 @@ func NewRateLimiter(limit int, interval time.Duration, parallelCount int) *RateL
 	return lim
 }
 
+// NewTokenBucketRateLimiter creates a rate limiter, which allows burst of actions
+// and then spreads them evenly with the rate per second, instead of waiting for the whole interval.
+func NewTokenBucketRateLimiter(rate float64, burst int, parallelCount int) *RateLimiter {
+	lim := &RateLimiter{
+		rate:         rate,
+		burst:        float64(burst),
+		tokens:       float64(burst),
+		lastRefill:   time.Now(),
+		parallelChan: make(chan bool, parallelCount),
+	}
+	lim.times.Init()
+	return lim
+}
+
+// WithPool makes calls of the limiter take slots in the connection pool,
+// which is shared with other limiters of the same kind of traffic
+func (r *RateLimiter) WithPool(pool *ConnectionPool) *RateLimiter {
+	r.pool = pool
+	return r
+}
+
 // Wait blocks if the rate limit has been reached.  Wait offers no guarantees
 // of fairness for multiple actors if the allowed rate has been temporarily
 // exhausted.
@@ -66,6 +106,8 @@ func (r *RateLimiter) ForceWait() {
 	for r.times.Len() < r.limit {
 		r.times.PushBack(now)
 	}
+	r.tokens = 0
+	r.lastRefill = now
 	r.mtx.Unlock()
 
 	r.Wait()
@@ -77,6 +119,24 @@ func (r *RateLimiter) Try() (ok bool, remaining time.Duration) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	now := time.Now()
+	if now.Before(r.pausedUntil) {
+		return false, r.pausedUntil.Sub(now)
+	}
+
+	if r.rate > 0 {
+		r.tokens += now.Sub(r.lastRefill).Seconds() * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+		r.lastRefill = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			return true, 0
+		}
+		return false, time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+	}
+
 	if l := r.times.Len(); l < r.limit {
 		r.times.PushBack(now)
 		return true, 0
@@ -90,38 +150,28 @@ func (r *RateLimiter) Try() (ok bool, remaining time.Duration) {
 	return true, 0
 }
 
-// CoolDown is checking HTTP headers if we need to wait
+// CoolDown is checking HTTP headers if we need to wait. It does not block,
+// all callers wait in Wait until Retry-After passes.
 func (r *RateLimiter) CoolDown(headers http.Header) {
-	if len(headers) == 0 {
-		return
-	}
-	if retryAfter, exists := headers["Retry-After"]; exists {
-		if retryAfter == nil {
-			return
+	timeout := defaultCoolDown
+	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			// Sleeping for requested seconds, but if we get 0, sleeping for some time
+			timeout = time.Duration(seconds) * time.Second
+			if seconds == 0 {
+				timeout = time.Duration(300) * time.Millisecond
+			}
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			timeout = time.Until(date)
 		}
+	}
 
-		coolDown, err := strconv.Atoi(retryAfter[0])
-		if err != nil || r.coolDown {
-			return
-		}
-
-		r.mtx.Lock()
-		log.Debugf("Met a cooldown, sleeping for %#v seconds. Headers: %#v", coolDown, headers)
-
-		// Marking we are going to sleep, so other can see and just return an error
-		// to avoid many sleeps
-		r.coolDown = true
-
-		// Sleeping for requested seconds, but if we get 0, sleeping for some time
-		timeout := time.Duration(coolDown) * time.Second
-		if coolDown == 0 {
-			timeout = time.Duration(300) * time.Millisecond
-		}
-		time.Sleep(timeout)
-		r.mtx.Unlock()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
 
-		r.ForceWait()
-		r.coolDown = false
+	if until := time.Now().Add(timeout); until.After(r.pausedUntil) {
+		log.Debugf("Met a cooldown, pausing requests for %s", timeout)
+		r.pausedUntil = until
 	}
 }
 
@@ -134,14 +184,20 @@ func (r *RateLimiter) Call(f func() error) {
 	// Checking for burst rate
 	r.Wait()
 
-	tries := 0
-	for {
+	if r.pool != nil {
+		r.pool.Acquire()
+		defer r.pool.Release()
+	}
+
+	for tries := 0; ; tries++ {
 		err := f()
-		// If fail occur, we should rerun
-		if err == nil || err != ErrExceeded || tries >= 2 {
+		// If rate limit is exceeded, we should rerun with exponential backoff
+		if err == nil || err != ErrExceeded || tries >= maxRetries {
 			break
 		}
-		tries++
+
+		time.Sleep(retryBackoff << uint(tries))
+		r.Wait()
 	}
 }
 
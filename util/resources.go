@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resource profiles of the device, chosen by CPU cores and memory
+const (
+	ProfileLow = iota
+	ProfileMedium
+	ProfileHigh
+)
+
+// ProfileNames are names of resource profiles for logs and status
+var ProfileNames = []string{"Low", "Medium", "High"}
+
+// Resources is the profile of the device, and concurrency limits, which suit it
+type Resources struct {
+	Profile int
+	Cores   int
+	// Memory is total memory in bytes, or 0, if it could not be detected
+	Memory uint64
+
+	// SearchConcurrency is how many providers are searched at once, 0 means all of them
+	SearchConcurrency int
+	// Workers is size of worker pools, which fetch and decode metadata and torrent files
+	Workers int
+	// ConnectionsLimit is default libtorrent connections limit
+	ConnectionsLimit int
+}
+
+var (
+	resources     *Resources
+	resourcesOnce sync.Once
+)
+
+// GetResources returns profile of the device, detected once on first call
+func GetResources() *Resources {
+	resourcesOnce.Do(func() {
+		resources = detectResources()
+		log.Noticef("Resource profile: %s (%d cores, %d MB memory)", ProfileNames[resources.Profile], resources.Cores, resources.Memory/1024/1024)
+	})
+	return resources
+}
+
+func detectResources() *Resources {
+	r := &Resources{
+		Cores:  runtime.NumCPU(),
+		Memory: totalMemory(),
+	}
+
+	const gb = 1024 * 1024 * 1024
+	switch {
+	case r.Cores <= 2 || (r.Memory > 0 && r.Memory < 3*gb/2):
+		r.Profile = ProfileLow
+		r.SearchConcurrency = 2
+		r.Workers = 2
+		r.ConnectionsLimit = 50
+	case r.Cores <= 4 || (r.Memory > 0 && r.Memory < 4*gb):
+		r.Profile = ProfileMedium
+		r.SearchConcurrency = 4
+		r.Workers = 4
+		r.ConnectionsLimit = 75
+	default:
+		r.Profile = ProfileHigh
+		r.SearchConcurrency = 0
+		r.Workers = 8
+		r.ConnectionsLimit = 150
+	}
+	return r
+}
+
+// totalMemory reads total memory from /proc/meminfo, which exists on Linux and Android,
+// on other systems it is not detected, and profile is chosen by CPU cores only
+func totalMemory() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				return kb * 1024
+			}
+		}
+	}
+	return 0
+}
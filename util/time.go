@@ -4,19 +4,19 @@ import (
 	"time"
 )
 
-// NowInt ...
+// NowInt returns corrected current time in seconds, see Now
 func NowInt() int {
-	return int(time.Now().UTC().Unix())
+	return int(Now().Unix())
 }
 
-// NowInt64 ...
+// NowInt64 returns corrected current time in seconds, see Now
 func NowInt64() int64 {
-	return time.Now().UTC().Unix()
+	return Now().Unix()
 }
 
 // NowPlusSecondsInt ..
 func NowPlusSecondsInt(seconds int) int {
-	return int(time.Now().UTC().Add(time.Duration(seconds) * time.Second).Unix())
+	return int(Now().Add(time.Duration(seconds) * time.Second).Unix())
 }
 
 // Bod returns the start of a day for specific date
@@ -76,6 +76,26 @@ func executeJSONRPCO(method string, retVal interface{}, args Object) error {
 	return errors.New("No available JSON-RPC connection to Kodi")
 }
 
+// executeJSONRPCExTimeout is executeJSONRPCEx, which fails if the add-on does not reply in time,
+// should not be used for dialogs, which wait for user input
+func executeJSONRPCExTimeout(method string, retVal interface{}, args Args, timeout time.Duration) error {
+	if args == nil {
+		args = Args{}
+	}
+	conn, err := getConnection(XBMCExJSONRPCHosts...)
+	if err != nil {
+		return err
+	}
+	if conn == nil {
+		return errors.New("No available JSON-RPC connection to the add-on")
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	client := jsonrpc.NewClient(conn)
+	return client.Call(method, args, retVal)
+}
+
 func executeJSONRPCEx(method string, retVal interface{}, args Args) error {
 	if args == nil {
 		args = Args{}
@@ -0,0 +1,213 @@
+package xbmc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// localizeRegexp matches string references, like "LOCALIZE[30725]"
+var localizeRegexp = regexp.MustCompile(`LOCALIZE\[(\d+)\]`)
+
+var (
+	localizedMu      sync.RWMutex
+	localizedStrings = map[int]string{}
+)
+
+// englishStrings are used, when Kodi does not return translation for the string,
+// for example, when the add-on is older than the daemon and has no such string yet
+var englishStrings = map[int]string{
+	30725: "Database folder is not available, using profile folder",
+	30726: "Trending today",
+	30727: "Trending this week",
+	30728: "Enable super-seeding",
+	30729: "Disable super-seeding",
+	30730: "Upload slots",
+	30731: "Unlimited",
+	30732: "Streaming services",
+	30733: "My streaming services",
+	30734: "Browse collection",
+	30735: "Rotate tracker passkey",
+	30736: "There are no private trackers with passkey in trackers.json",
+	30737: "Enter new passkey",
+	30738: "Passkey is updated in %d torrents",
+	30739: "Torrent did not meet seeding obligations of private tracker. Delete it anyway?",
+	30740: "Discover",
+	30741: "Years: %s",
+	30742: "Genres: %s",
+	30743: "Minimal rating: %s",
+	30744: "Runtime, minutes: %s",
+	30745: "Certification: %s",
+	30746: "Original language: %s",
+	30747: "My streaming services: %s",
+	30748: "Sort by: %s",
+	30749: "Show results",
+	30750: "Any",
+	30751: "Years, like 1990-1999",
+	30752: "Runtime in minutes, like 90-120",
+	30753: "Match all genres: %s",
+	30754: "Popularity",
+	30755: "Rating",
+	30756: "Number of votes",
+	30757: "Newest first",
+	30758: "Oldest first",
+	30759: "Revenue",
+	30760: "Title",
+	30761: "Yes",
+	30762: "No",
+	30763: "Reannounce",
+	30764: "Private tracker does not allow announcing that often",
+	30765: "Episode ordering",
+	30766: "Show has no alternate episode orderings",
+	30767: "Aired order",
+	30768: "Search settings",
+	30769: "No settings found",
+	30770: "Browse collection (%s watched)",
+	30771: "Mark collection as watched",
+	30772: "Mark collection as unwatched",
+	30773: "Failed library items",
+	30774: "Retry",
+	30775: "Dismiss",
+	30776: "Retry all",
+	30777: "Library items written: %d, failed: %d",
+	30778: "Item dismissed",
+	30779: "More like this (by keyword)",
+	30780: "Blocked by parental control",
+	30781: "Enter parental control PIN",
+	30782: "Wrong PIN",
+	30783: "Unlock parental control",
+	30784: "Lock parental control",
+	30785: "Parental control unlocked for an hour",
+	30786: "Parental control locked",
+	30787: "Subtitles added",
+	30788: "Upcoming",
+	30789: "Airing this week",
+	30790: "Airing today",
+	30791: "Search coverage report",
+	30792: "Clear failed searches",
+	30793: "No failed searches recorded",
+	30794: "Failed searches cleared",
+	30795: "TMDB lists",
+	30796: "Add to TMDB list",
+	30797: "Remove from TMDB list",
+	30798: "TMDB authorization",
+	30799: "TMDB authorized",
+	30800: "Added to TMDB list",
+	30801: "Removed from TMDB list",
+	30802: "Corrupt pieces found, downloading again: %s",
+	30803: "Force re-check",
+	30804: "Import torrents from another client",
+	30805: "qBittorrent BT_backup or Transmission config folder",
+	30806: "No torrents with complete files found",
+	30807: "Imported torrents: %s",
+	30808: "Recommended for you",
+	30809: "Choose artwork",
+	30810: "Poster",
+	30811: "Fanart",
+	30812: "Logo",
+	30813: "Automatic artwork",
+	30814: "No artwork found on TMDB",
+	30815: "Assign episode to file",
+	30816: "Torrent is not linked to a show",
+	30817: "Match automatically",
+	30818: "Notifications digest (%s)",
+	30819: "Download finished: %s",
+	30820: "Moved to completed folder: %s",
+	30821: "Resource profile",
+	30822: "Library jobs",
+	30823: "Queued",
+	30824: "Running",
+	30825: "Added",
+	30826: "Failed",
+	30827: "Library jobs finished, added: %d, failed: %d",
+	30828: "Subtitles could be out of sync, choose one",
+	30829: "Set parental control PIN to enable guest mode",
+	30830: "Guest mode could be changed only with PIN",
+	30831: "Folder %s is not supported, choose a local storage folder",
+}
+
+// ResetLocalizedStrings drops cached strings, so they are requested again in the current language
+func ResetLocalizedStrings() {
+	localizedMu.Lock()
+	defer localizedMu.Unlock()
+
+	localizedStrings = map[int]string{}
+}
+
+// LocalizedString returns string in Kodi language, with English fallback,
+// or empty string if string is unknown
+func LocalizedString(id int) string {
+	localizedMu.RLock()
+	str, ok := localizedStrings[id]
+	localizedMu.RUnlock()
+	if ok {
+		return str
+	}
+
+	if str = GetLocalizedString(id); str == "" {
+		str = englishStrings[id]
+	}
+
+	localizedMu.Lock()
+	localizedStrings[id] = str
+	localizedMu.Unlock()
+
+	return str
+}
+
+// Localizef returns string in Kodi language, formatted with arguments,
+// for texts, composed at runtime, like prompts with counts or error details
+func Localizef(id int, args ...interface{}) string {
+	str := LocalizedString(id)
+	if str == "" {
+		return fmt.Sprintf("LOCALIZE[%d]", id)
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// Localize replaces string references in the text, like "LOCALIZE[30608];;arg",
+// where arguments after ";;" are put into "%s" of the string.
+// Text is returned as is, if any of strings is unknown, so the add-on could resolve it.
+func Localize(text string) string {
+	if !strings.Contains(text, "LOCALIZE[") {
+		return text
+	}
+
+	parts := strings.Split(text, ";;")
+	for i, part := range parts {
+		resolved, ok := resolveLocalized(part)
+		if !ok {
+			return text
+		}
+		parts[i] = resolved
+	}
+
+	str := parts[0]
+	for _, arg := range parts[1:] {
+		str = strings.Replace(str, "%s", arg, 1)
+	}
+	return str
+}
+
+func resolveLocalized(text string) (string, bool) {
+	ok := true
+	resolved := localizeRegexp.ReplaceAllStringFunc(text, func(match string) string {
+		id, _ := strconv.Atoi(localizeRegexp.FindStringSubmatch(match)[1])
+		if str := LocalizedString(id); str != "" {
+			return str
+		}
+		ok = false
+		return match
+	})
+	return resolved, ok
+}
+
+func localizeAll(items []string) []string {
+	ret := make([]string, len(items))
+	for i, item := range items {
+		ret[i] = Localize(item)
+	}
+	return ret
+}
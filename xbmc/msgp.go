@@ -694,9 +694,9 @@ func (z *ListItem) Msgsize() (s int) {
 // MarshalMsg implements msgp.Marshaler
 func (z *ListItemArt) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 9
+	// map header, size 11
 	// string "Thumbnail"
-	o = append(o, 0x89, 0xa9, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c)
+	o = append(o, 0x8b, 0xa9, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c)
 	o = msgp.AppendString(o, z.Thumbnail)
 	// string "Poster"
 	o = append(o, 0xa6, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72)
@@ -722,6 +722,12 @@ func (z *ListItemArt) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Icon"
 	o = append(o, 0xa4, 0x49, 0x63, 0x6f, 0x6e)
 	o = msgp.AppendString(o, z.Icon)
+	// string "SetPoster"
+	o = append(o, 0xa9, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72)
+	o = msgp.AppendString(o, z.SetPoster)
+	// string "SetFanArt"
+	o = append(o, 0xa9, 0x53, 0x65, 0x74, 0x46, 0x61, 0x6e, 0x41, 0x72, 0x74)
+	o = msgp.AppendString(o, z.SetFanArt)
 	return
 }
 
@@ -786,6 +792,16 @@ func (z *ListItemArt) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			if err != nil {
 				return
 			}
+		case "SetPoster":
+			z.SetPoster, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				return
+			}
+		case "SetFanArt":
+			z.SetFanArt, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -799,16 +815,16 @@ func (z *ListItemArt) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *ListItemArt) Msgsize() (s int) {
-	s = 1 + 10 + msgp.StringPrefixSize + len(z.Thumbnail) + 7 + msgp.StringPrefixSize + len(z.Poster) + 13 + msgp.StringPrefixSize + len(z.TvShowPoster) + 7 + msgp.StringPrefixSize + len(z.Banner) + 7 + msgp.StringPrefixSize + len(z.FanArt) + 9 + msgp.StringPrefixSize + len(z.ClearArt) + 10 + msgp.StringPrefixSize + len(z.ClearLogo) + 10 + msgp.StringPrefixSize + len(z.Landscape) + 5 + msgp.StringPrefixSize + len(z.Icon)
+	s = 1 + 10 + msgp.StringPrefixSize + len(z.Thumbnail) + 7 + msgp.StringPrefixSize + len(z.Poster) + 13 + msgp.StringPrefixSize + len(z.TvShowPoster) + 7 + msgp.StringPrefixSize + len(z.Banner) + 7 + msgp.StringPrefixSize + len(z.FanArt) + 9 + msgp.StringPrefixSize + len(z.ClearArt) + 10 + msgp.StringPrefixSize + len(z.ClearLogo) + 10 + msgp.StringPrefixSize + len(z.Landscape) + 5 + msgp.StringPrefixSize + len(z.Icon) + 10 + msgp.StringPrefixSize + len(z.SetPoster) + 10 + msgp.StringPrefixSize + len(z.SetFanArt)
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *ListItemInfo) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 44
+	// map header, size 47
 	// string "Count"
-	o = append(o, 0xde, 0x0, 0x2c, 0xa5, 0x43, 0x6f, 0x75, 0x6e, 0x74)
+	o = append(o, 0xde, 0x0, 0x2f, 0xa5, 0x43, 0x6f, 0x75, 0x6e, 0x74)
 	o = msgp.AppendInt(o, z.Count)
 	// string "Size"
 	o = append(o, 0xa4, 0x53, 0x69, 0x7a, 0x65)
@@ -942,6 +958,15 @@ func (z *ListItemInfo) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "IMDBNumber"
 	o = append(o, 0xaa, 0x49, 0x4d, 0x44, 0x42, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72)
 	o = msgp.AppendString(o, z.IMDBNumber)
+	// string "Set"
+	o = append(o, 0xa3, 0x53, 0x65, 0x74)
+	o = msgp.AppendString(o, z.Set)
+	// string "SetID"
+	o = append(o, 0xa5, 0x53, 0x65, 0x74, 0x49, 0x44)
+	o = msgp.AppendInt(o, z.SetID)
+	// string "Country"
+	o = append(o, 0xa7, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79)
+	o = msgp.AppendString(o, z.Country)
 	// string "Lyrics"
 	o = append(o, 0xa6, 0x4c, 0x79, 0x72, 0x69, 0x63, 0x73)
 	o = msgp.AppendString(o, z.Lyrics)
@@ -1227,6 +1252,21 @@ func (z *ListItemInfo) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			if err != nil {
 				return
 			}
+		case "Set":
+			z.Set, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				return
+			}
+		case "SetID":
+			z.SetID, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				return
+			}
+		case "Country":
+			z.Country, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				return
+			}
 		case "Lyrics":
 			z.Lyrics, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
@@ -1270,7 +1310,7 @@ func (z *ListItemInfo) Msgsize() (s int) {
 	for za0004 := range z.Artist {
 		s += msgp.StringPrefixSize + len(z.Artist[za0004])
 	}
-	s += 6 + msgp.StringPrefixSize + len(z.Votes) + 8 + msgp.StringPrefixSize + len(z.Trailer) + 10 + msgp.StringPrefixSize + len(z.DateAdded) + 5 + msgp.IntSize + 7 + msgp.StringPrefixSize + len(z.DBTYPE) + 10 + msgp.StringPrefixSize + len(z.Mediatype) + 11 + msgp.StringPrefixSize + len(z.IMDBNumber) + 7 + msgp.StringPrefixSize + len(z.Lyrics) + 12 + msgp.StringPrefixSize + len(z.PicturePath) + 5 + msgp.StringPrefixSize + len(z.Exif)
+	s += 6 + msgp.StringPrefixSize + len(z.Votes) + 8 + msgp.StringPrefixSize + len(z.Trailer) + 10 + msgp.StringPrefixSize + len(z.DateAdded) + 5 + msgp.IntSize + 7 + msgp.StringPrefixSize + len(z.DBTYPE) + 10 + msgp.StringPrefixSize + len(z.Mediatype) + 11 + msgp.StringPrefixSize + len(z.IMDBNumber) + 4 + msgp.StringPrefixSize + len(z.Set) + 6 + msgp.IntSize + 8 + msgp.StringPrefixSize + len(z.Country) + 7 + msgp.StringPrefixSize + len(z.Lyrics) + 12 + msgp.StringPrefixSize + len(z.PicturePath) + 5 + msgp.StringPrefixSize + len(z.Exif)
 	return
 }
 
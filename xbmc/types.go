@@ -107,6 +107,9 @@ type ListItemInfo struct {
 	DBTYPE        string         `json:"dbtype,omitempty"`
 	Mediatype     string         `json:"mediatype,omitempty"`
 	IMDBNumber    string         `json:"imdbnumber,omitempty"`
+	Set           string         `json:"set,omitempty"`
+	SetID         int            `json:"setid,omitempty"`
+	Country       string         `json:"country,omitempty"`
 
 	// Music Values
 	Lyrics string `json:"lyrics,omitempty"`
@@ -127,6 +130,8 @@ type ListItemArt struct {
 	ClearLogo    string `json:"clearlogo,omitempty"`
 	Landscape    string `json:"landscape,omitempty"`
 	Icon         string `json:"icon,omitempty"`
+	SetPoster    string `json:"set.poster,omitempty"`
+	SetFanArt    string `json:"set.fanart,omitempty"`
 }
 
 // ContextMenuItem ...
@@ -274,6 +279,15 @@ type ActivePlayers []struct {
 	Type string `json:"type"`
 }
 
+// PlayerSubtitles are subtitle streams of the player
+type PlayerSubtitles struct {
+	Subtitles []struct {
+		Index    int    `json:"index"`
+		Language string `json:"language"`
+		Name     string `json:"name"`
+	} `json:"subtitles"`
+}
+
 // FileSources ...
 type FileSources struct {
 	Sources []struct {
@@ -1,6 +1,7 @@
 package xbmc
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -145,6 +146,53 @@ func PlayerGetItem(playerid int) (item *PlayerItemInfo) {
 	return
 }
 
+// PlayerGetSubtitleStreams returns subtitle streams of the player
+func PlayerGetSubtitleStreams(playerid int) (ret *PlayerSubtitles) {
+	params := map[string]interface{}{
+		"playerid":   playerid,
+		"properties": []string{"subtitles"},
+	}
+	executeJSONRPCO("Player.GetProperties", &ret, params)
+	return
+}
+
+// PlayerSetSubtitle switches player to subtitle stream and turns subtitles on
+func PlayerSetSubtitle(playerid int, index int) error {
+	var ret string
+	params := map[string]interface{}{
+		"playerid": playerid,
+		"subtitle": index,
+		"enable":   true,
+	}
+	return executeJSONRPCO("Player.SetSubtitle", &ret, params)
+}
+
+// PlayerAddSubtitles adds subtitles file to the playing video and switches to it,
+// Kodi adds the stream asynchronously, so it is waited for a few seconds
+func PlayerAddSubtitles(path string) error {
+	playerid := PlayerGetActive()
+	if playerid < 0 {
+		return errors.New("No video is playing")
+	}
+
+	before := 0
+	if streams := PlayerGetSubtitleStreams(playerid); streams != nil {
+		before = len(streams.Subtitles)
+	}
+
+	PlayerSetSubtitles([]string{path})
+	for i := 0; i < 10; i++ {
+		time.Sleep(300 * time.Millisecond)
+
+		streams := PlayerGetSubtitleStreams(playerid)
+		if streams == nil || len(streams.Subtitles) <= before {
+			continue
+		}
+		return PlayerSetSubtitle(playerid, streams.Subtitles[len(streams.Subtitles)-1].Index)
+	}
+	return errors.New("Subtitles were not added by Kodi")
+}
+
 // VideoLibraryGetShows ...
 func VideoLibraryGetShows() (shows *VideoLibraryShows, err error) {
 	defer perf.ScopeTimer()()
@@ -527,6 +575,12 @@ func PlayURLWithLabels(url string, listItem *ListItem) {
 	go executeJSONRPCEx("Player_Open_With_Labels", &retVal, Args{url, listItem.Info})
 }
 
+// PlayURLWithListItem plays URL with list item properties, like inputstream settings
+func PlayURLWithListItem(url string, listItem *ListItem) {
+	retVal := ""
+	go executeJSONRPCEx("Player_Open_With_ListItem", &retVal, Args{url, listItem})
+}
+
 // PlayURLWithTimeout ...
 func PlayURLWithTimeout(url string) {
 	retVal := ""
@@ -1,6 +1,9 @@
 package xbmc
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 // AddonInfo ...
 type AddonInfo struct {
@@ -44,6 +47,37 @@ func AddonSettings(addonID string) (retVal string) {
 	return
 }
 
+// AddonSettingsFocus opens add-on settings and moves focus to the setting, with index of category
+// and index of the control in category. Settings dialog can't focus control by request,
+// so navigation is emulated, as for confirmation dialogs.
+func AddonSettingsFocus(addonID string, category int, control int) {
+	go AddonSettings(addonID)
+
+	opened := false
+	for i := 0; i < 30 && !opened; i++ {
+		time.Sleep(100 * time.Millisecond)
+		opened = AddonSettingsOpened()
+	}
+	if !opened {
+		return
+	}
+	// Waiting for the dialog to focus the first category
+	time.Sleep(300 * time.Millisecond)
+
+	retVal := ""
+	for i := 0; i < category; i++ {
+		executeJSONRPC("Input.Down", &retVal, nil)
+	}
+	if control < 0 {
+		return
+	}
+
+	executeJSONRPC("Input.Right", &retVal, nil)
+	for i := 0; i < control; i++ {
+		executeJSONRPC("Input.Down", &retVal, nil)
+	}
+}
+
 // AddonSettingsOpened ...
 func AddonSettingsOpened() bool {
 	retVal := 0
@@ -133,7 +133,7 @@ func (ov *OverlayStatus) Close() {
 // Notify ...
 func Notify(header string, message string, image string) {
 	var retVal string
-	executeJSONRPCEx("Notify", &retVal, Args{header, message, image})
+	executeJSONRPCEx("Notify", &retVal, Args{Localize(header), Localize(message), image})
 }
 
 // InfoLabels ...
@@ -149,6 +149,13 @@ func InfoLabel(label string) string {
 	return labels[label]
 }
 
+// InfoBooleans returns values of Kodi boolean conditions
+func InfoBooleans(conditions ...string) map[string]bool {
+	var retVal map[string]bool
+	executeJSONRPC("XBMC.GetInfoBooleans", &retVal, Args{conditions})
+	return retVal
+}
+
 // GetWindowProperty ...
 func GetWindowProperty(key string) string {
 	var retVal string
@@ -164,6 +171,12 @@ func SetWindowProperty(key string, value string) {
 
 // Keyboard ...
 func Keyboard(args ...interface{}) string {
+	for i, arg := range args {
+		if str, ok := arg.(string); ok {
+			args[i] = Localize(str)
+		}
+	}
+
 	var retVal string
 	executeJSONRPCEx("Keyboard", &retVal, args)
 	return retVal
@@ -172,7 +185,7 @@ func Keyboard(args ...interface{}) string {
 // Dialog ...
 func Dialog(title string, message string) bool {
 	retVal := 0
-	executeJSONRPCEx("Dialog", &retVal, Args{title, message})
+	executeJSONRPCEx("Dialog", &retVal, Args{Localize(title), Localize(message)})
 	return retVal != 0
 }
 
@@ -199,7 +212,7 @@ func dialogConfirmRunner(title, message string, focused bool) bool {
 		}
 
 		retVal := 0
-		executeJSONRPCEx("Dialog_Confirm", &retVal, Args{title, message})
+		executeJSONRPCEx("Dialog_Confirm", &retVal, Args{Localize(title), Localize(message)})
 		c1 <- retVal != 0
 	}()
 
@@ -215,21 +228,21 @@ func dialogConfirmRunner(title, message string, focused bool) bool {
 // DialogText ...
 func DialogText(title string, text string) bool {
 	retVal := 0
-	executeJSONRPCEx("Dialog_Text", &retVal, Args{title, text})
+	executeJSONRPCEx("Dialog_Text", &retVal, Args{Localize(title), Localize(text)})
 	return retVal != 0
 }
 
 // ListDialog ...
 func ListDialog(title string, items ...string) int {
 	retVal := -1
-	executeJSONRPCEx("Dialog_Select", &retVal, Args{title, items})
+	executeJSONRPCEx("Dialog_Select", &retVal, Args{Localize(title), localizeAll(items)})
 	return retVal
 }
 
 // ListDialogLarge ...
 func ListDialogLarge(title string, subject string, items ...string) int {
 	retVal := -1
-	executeJSONRPCEx("Dialog_Select_Large", &retVal, Args{title, subject, items})
+	executeJSONRPCEx("Dialog_Select_Large", &retVal, Args{Localize(title), Localize(subject), localizeAll(items)})
 	return retVal
 }
 
@@ -247,6 +260,14 @@ func PlayerIsPlaying() bool {
 	return retVal != 0
 }
 
+// PlayerHeartbeat returns whether Kodi player is playing, with error,
+// if the add-on does not respond, so dead player could be detected
+func PlayerHeartbeat(timeout time.Duration) (bool, error) {
+	retVal := 0
+	err := executeJSONRPCExTimeout("Player_IsPlaying", &retVal, nil, timeout)
+	return retVal != 0, err
+}
+
 // PlayerSeek ...
 func PlayerSeek(position float64) (ret string) {
 	if position <= 0 {